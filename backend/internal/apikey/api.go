@@ -0,0 +1,79 @@
+package apikey
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+type ApiKeyApi struct {
+	keys *ApiKeys
+}
+
+func (ka *ApiKeyApi) GetApiKeysList(w http.ResponseWriter, req *http.Request) {
+	keys, err := ka.keys.List(user.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading API keys"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetApiKeysResponse{ApiKeys: keys})
+}
+
+// apikeyError points validation errors of new keys at the offending field.
+func apikeyError(err error) error {
+	switch {
+	case errors.Is(err, ErrEmptyName):
+		return apperr.Invalid(err.Error(), apperr.Field("name", err.Error()))
+	case errors.Is(err, ErrExpiryInPast):
+		return apperr.Invalid(err.Error(), apperr.Field("expiresAt", err.Error()))
+	case errors.Is(err, ErrUnknownScope), errors.Is(err, ErrNoScopes):
+		return apperr.Invalid(err.Error(), apperr.Field("scopes", err.Error()))
+	}
+	return apperr.Wrap(err, "API key couldn't be created")
+}
+
+func (ka *ApiKeyApi) CreateApiKey(w http.ResponseWriter, req *http.Request) {
+	var body pb.CreateApiKeyRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	key, token, err := ka.keys.Create(
+		user.IdFromContext(req.Context()),
+		workspace.IdFromContext(req.Context()),
+		body.GetName(),
+		body.GetScopes(),
+		body.GetExpiresAt(),
+	)
+	if err != nil {
+		apperr.Write(w, req, apikeyError(err))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.CreateApiKeyResponse{ApiKey: key, Token: token})
+}
+
+func (ka *ApiKeyApi) RevokeApiKey(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ka.keys.Revoke(int(id), user.IdFromContext(req.Context())); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "API key couldn't be revoked"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewApiKeyApi(ks *ApiKeys) *ApiKeyApi {
+	return &ApiKeyApi{keys: ks}
+}
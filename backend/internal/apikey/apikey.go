@@ -0,0 +1,281 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+)
+
+const (
+	TOKEN_PREFIX = "inv_"
+	// Touching last_used_at on every request would turn each read into a
+	// write, so it is only refreshed once per interval.
+	LAST_USED_RESOLUTION = time.Minute
+)
+
+var (
+	ErrIDNotFound   = apperr.New(apperr.CODE_NOT_FOUND, "API key not found")
+	ErrInvalidToken = apperr.New(apperr.CODE_UNAUTHENTICATED, "API key is invalid, expired or revoked")
+	ErrEmptyName    = apperr.New(apperr.CODE_INVALID_ARGUMENT, "name is empty")
+	ErrExpiryInPast = apperr.New(apperr.CODE_INVALID_ARGUMENT, "expiry date is in the past")
+)
+
+type ApiKeys struct {
+	db *sql.DB
+
+	insert_stmt, retrieve_stmt, retrieve_by_hash_stmt, list_stmt, list_all_stmt, revoke_stmt, touch_stmt *sql.Stmt
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return TOKEN_PREFIX + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func scanApiKey(row interface{ Scan(...any) error }) (*pb.ApiKey, error) {
+	key := &pb.ApiKey{}
+	var scopes string
+	err := row.Scan(
+		&key.Id,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&scopes,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.WorkspaceId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = splitScopes(scopes)
+	return key, nil
+}
+
+func scanApiKeys(rows *sql.Rows) ([]*pb.ApiKey, error) {
+	defer rows.Close()
+
+	keys := []*pb.ApiKey{}
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Create returns the new key together with its token. The token is only
+// known at this point, afterwards just its hash is kept. A workspace_id of 0
+// creates a key that can be used in any workspace of the user.
+func (ks *ApiKeys) Create(user_id uint32, workspace_id uint32, name string, scopes []string, expires_at int64) (*pb.ApiKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrEmptyName
+	}
+
+	scopes, err := ValidateScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().Unix()
+	if expires_at != 0 && expires_at <= now {
+		return nil, "", ErrExpiryInPast
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, err := ks.insert_stmt.Exec(
+		user_id,
+		name,
+		token[:len(TOKEN_PREFIX)+8],
+		hashToken(token),
+		joinScopes(scopes),
+		now,
+		expires_at,
+		helpers.NullableId(workspace_id),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := ks.Retrieve(int(id))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, token, nil
+}
+
+func (ks *ApiKeys) Retrieve(id int) (*pb.ApiKey, error) {
+	return scanApiKey(ks.retrieve_stmt.QueryRow(id))
+}
+
+func (ks *ApiKeys) List(user_id uint32) ([]*pb.ApiKey, error) {
+	rows, err := ks.list_stmt.Query(user_id)
+	if err != nil {
+		return nil, err
+	}
+	return scanApiKeys(rows)
+}
+
+func (ks *ApiKeys) ListAll() ([]*pb.ApiKey, error) {
+	rows, err := ks.list_all_stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	return scanApiKeys(rows)
+}
+
+// Revoke revokes the key if it belongs to user_id. A user_id of 0 revokes
+// the key regardless of its owner.
+func (ks *ApiKeys) Revoke(id int, user_id uint32) error {
+	key, err := ks.Retrieve(id)
+	if err != nil {
+		return err
+	}
+
+	if user_id != 0 && key.UserId != user_id {
+		return ErrIDNotFound
+	}
+
+	_, err = ks.revoke_stmt.Exec(time.Now().Unix(), id)
+	return err
+}
+
+func (ks *ApiKeys) Verify(token string) (*pb.ApiKey, error) {
+	if !strings.HasPrefix(token, TOKEN_PREFIX) {
+		return nil, ErrInvalidToken
+	}
+
+	key, err := scanApiKey(ks.retrieve_by_hash_stmt.QueryRow(hashToken(token)))
+	if err == ErrIDNotFound {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != 0 || (key.ExpiresAt != 0 && key.ExpiresAt <= now.Unix()) {
+		return nil, ErrInvalidToken
+	}
+
+	if now.Unix()-key.LastUsedAt >= int64(LAST_USED_RESOLUTION.Seconds()) {
+		if _, err = ks.touch_stmt.Exec(now.Unix(), key.Id); err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = now.Unix()
+	}
+
+	return key, nil
+}
+
+const API_KEY_COLUMNS = `
+	api_key_id,
+	api_key_user_id,
+	api_key_name,
+	api_key_prefix,
+	api_key_scopes,
+	api_key_created_at,
+	api_key_expires_at,
+	api_key_last_used_at,
+	api_key_revoked_at,
+	COALESCE(api_key_workspace_id, 0)
+`
+
+func NewApiKeys(db *sql.DB) (*ApiKeys, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO api_keys (
+			api_key_user_id,
+			api_key_name,
+			api_key_prefix,
+			api_key_hash,
+			api_key_scopes,
+			api_key_created_at,
+			api_key_expires_at,
+			api_key_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare("SELECT " + API_KEY_COLUMNS + " FROM api_keys WHERE api_key_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_by_hash_stmt, err := db.Prepare("SELECT " + API_KEY_COLUMNS + " FROM api_keys WHERE api_key_hash = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(
+		"SELECT " + API_KEY_COLUMNS + " FROM api_keys WHERE api_key_user_id = ? ORDER BY api_key_created_at ASC",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list_all_stmt, err := db.Prepare(
+		"SELECT " + API_KEY_COLUMNS + " FROM api_keys ORDER BY api_key_created_at ASC",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revoke_stmt, err := db.Prepare(
+		"UPDATE api_keys SET api_key_revoked_at = ? WHERE api_key_id = ? AND api_key_revoked_at = 0",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	touch_stmt, err := db.Prepare("UPDATE api_keys SET api_key_last_used_at = ? WHERE api_key_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApiKeys{
+		db:                    db,
+		insert_stmt:           insert_stmt,
+		retrieve_stmt:         retrieve_stmt,
+		retrieve_by_hash_stmt: retrieve_by_hash_stmt,
+		list_stmt:             list_stmt,
+		list_all_stmt:         list_all_stmt,
+		revoke_stmt:           revoke_stmt,
+		touch_stmt:            touch_stmt,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"sort"
+	"strings"
+)
+
+const (
+	SCOPE_TEMPLATES_READ  = "templates:read"
+	SCOPE_TEMPLATES_WRITE = "templates:write"
+	SCOPE_CLIENTS_READ    = "clients:read"
+	SCOPE_CLIENTS_WRITE   = "clients:write"
+	SCOPE_INVOICES_READ   = "invoices:read"
+	SCOPE_INVOICES_WRITE  = "invoices:write"
+	SCOPE_INVOICES_ISSUE  = "invoices:issue"
+	SCOPE_PAYMENTS_READ   = "payments:read"
+	SCOPE_PAYMENTS_WRITE  = "payments:write"
+)
+
+var (
+	ErrUnknownScope = apperr.New(apperr.CODE_INVALID_ARGUMENT, "unknown scope")
+	ErrNoScopes     = apperr.New(apperr.CODE_INVALID_ARGUMENT, "at least one scope is required")
+
+	Scopes = []string{
+		SCOPE_TEMPLATES_READ,
+		SCOPE_TEMPLATES_WRITE,
+		SCOPE_CLIENTS_READ,
+		SCOPE_CLIENTS_WRITE,
+		SCOPE_INVOICES_READ,
+		SCOPE_INVOICES_WRITE,
+		SCOPE_INVOICES_ISSUE,
+		SCOPE_PAYMENTS_READ,
+		SCOPE_PAYMENTS_WRITE,
+	}
+)
+
+func ValidateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrNoScopes
+	}
+
+	unique := map[string]bool{}
+	for _, scope := range scopes {
+		known := false
+		for _, s := range Scopes {
+			known = known || s == scope
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownScope, scope)
+		}
+		unique[scope] = true
+	}
+
+	valid := []string{}
+	for scope := range unique {
+		valid = append(valid, scope)
+	}
+	sort.Strings(valid)
+	return valid, nil
+}
+
+func joinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func splitScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
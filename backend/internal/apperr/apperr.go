@@ -0,0 +1,184 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// Code classifies an application error. Each code maps to one HTTP status
+// and one Connect code, so REST and RPC handlers report errors the same way.
+type Code string
+
+const (
+	CODE_INVALID_ARGUMENT       Code = "invalid_argument"
+	CODE_UNAUTHENTICATED        Code = "unauthenticated"
+	CODE_PERMISSION_DENIED      Code = "permission_denied"
+	CODE_NOT_FOUND              Code = "not_found"
+	CODE_METHOD_NOT_ALLOWED     Code = "method_not_allowed"
+	CODE_ALREADY_EXISTS         Code = "already_exists"
+	CODE_FAILED_PRECONDITION    Code = "failed_precondition"
+	CODE_PAYLOAD_TOO_LARGE      Code = "payload_too_large"
+	CODE_UNSUPPORTED_MEDIA_TYPE Code = "unsupported_media_type"
+	CODE_RESOURCE_EXHAUSTED     Code = "resource_exhausted"
+	CODE_INTERNAL               Code = "internal"
+	CODE_UNAVAILABLE            Code = "unavailable"
+)
+
+var statuses = map[Code]int{
+	CODE_INVALID_ARGUMENT:       http.StatusBadRequest,
+	CODE_UNAUTHENTICATED:        http.StatusUnauthorized,
+	CODE_PERMISSION_DENIED:      http.StatusForbidden,
+	CODE_NOT_FOUND:              http.StatusNotFound,
+	CODE_METHOD_NOT_ALLOWED:     http.StatusMethodNotAllowed,
+	CODE_ALREADY_EXISTS:         http.StatusConflict,
+	CODE_FAILED_PRECONDITION:    http.StatusConflict,
+	CODE_PAYLOAD_TOO_LARGE:      http.StatusRequestEntityTooLarge,
+	CODE_UNSUPPORTED_MEDIA_TYPE: http.StatusUnsupportedMediaType,
+	CODE_RESOURCE_EXHAUSTED:     http.StatusTooManyRequests,
+	CODE_INTERNAL:               http.StatusInternalServerError,
+	CODE_UNAVAILABLE:            http.StatusServiceUnavailable,
+}
+
+var connect_codes = map[Code]connect.Code{
+	CODE_INVALID_ARGUMENT:       connect.CodeInvalidArgument,
+	CODE_UNAUTHENTICATED:        connect.CodeUnauthenticated,
+	CODE_PERMISSION_DENIED:      connect.CodePermissionDenied,
+	CODE_NOT_FOUND:              connect.CodeNotFound,
+	CODE_METHOD_NOT_ALLOWED:     connect.CodeUnimplemented,
+	CODE_ALREADY_EXISTS:         connect.CodeAlreadyExists,
+	CODE_FAILED_PRECONDITION:    connect.CodeFailedPrecondition,
+	CODE_PAYLOAD_TOO_LARGE:      connect.CodeInvalidArgument,
+	CODE_UNSUPPORTED_MEDIA_TYPE: connect.CodeInvalidArgument,
+	CODE_RESOURCE_EXHAUSTED:     connect.CodeResourceExhausted,
+	CODE_INTERNAL:               connect.CodeInternal,
+	CODE_UNAVAILABLE:            connect.CodeUnavailable,
+}
+
+func (c Code) HttpStatus() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func (c Code) ConnectCode() connect.Code {
+	if code, ok := connect_codes[c]; ok {
+		return code
+	}
+	return connect.CodeInternal
+}
+
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+// Error is the error type handlers report to clients. Message is shown as
+// is, except for internal errors, whose cause is only logged.
+type Error struct {
+	Code       Code
+	Message    string
+	Fields     []FieldError
+	Extensions map[string]string
+
+	// RetryAfter is sent as the Retry-After header, if set.
+	RetryAfter time.Duration
+
+	cause error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func Newf(code Code, format string, args ...any) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Field describes why a single request field is invalid.
+func Field(field, description string) FieldError {
+	return FieldError{Field: field, Description: description}
+}
+
+// Invalid reports a request that failed validation of one or more fields.
+func Invalid(message string, fields ...FieldError) *Error {
+	return &Error{Code: CODE_INVALID_ARGUMENT, Message: message, Fields: fields}
+}
+
+// Converter is implemented by errors of other packages that know their
+// application error, e.g. authorization denials.
+type Converter interface {
+	AppError() *Error
+}
+
+// From returns the application error within err. Context added by wrapping
+// it, as in fmt.Errorf("%w: %q", ErrUnknownRole, name), is kept in the
+// message. Any other error becomes an internal error, with err kept as its
+// cause.
+func From(err error) *Error {
+	var app_err *Error
+	if errors.As(err, &app_err) {
+		if app_err == err || app_err.Code == CODE_INTERNAL {
+			return app_err
+		}
+		wrapped := *app_err
+		wrapped.Message = err.Error()
+		return &wrapped
+	}
+
+	var converter Converter
+	if errors.As(err, &converter) {
+		return converter.AppError()
+	}
+
+	return &Error{Code: CODE_INTERNAL, Message: "internal error", cause: err}
+}
+
+// Wrap returns application errors unchanged and turns anything else into an
+// internal error described by message.
+func Wrap(err error, message string) *Error {
+	var app_err *Error
+	var converter Converter
+	if errors.As(err, &app_err) || errors.As(err, &converter) {
+		return From(err)
+	}
+
+	return &Error{Code: CODE_INTERNAL, Message: message, cause: err}
+}
+
+// CodeOf returns the code of err, CODE_INTERNAL for foreign errors.
+func CodeOf(err error) Code {
+	return From(err).Code
+}
+
+// RetryAfterSeconds rounds up, so that clients never retry too early.
+func (e *Error) RetryAfterSeconds() string {
+	return fmt.Sprint(int64(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+func (c Code) Title() string {
+	return http.StatusText(c.HttpStatus())
+}
+
+// TypeUri identifies the problem type in problem+json responses. It is a
+// relative reference, resolved against the API's own URL.
+func (c Code) TypeUri() string {
+	return "/problems/" + strings.ReplaceAll(string(c), "_", "-")
+}
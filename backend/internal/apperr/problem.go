@@ -0,0 +1,104 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	pb "invoice-manager/main/proto"
+	"log"
+	"net/http"
+
+	"connectrpc.com/connect"
+)
+
+const PROBLEM_CONTENT_TYPE = "application/problem+json"
+
+// Problem renders the error as RFC 7807 problem details. The detail of
+// internal errors never includes their cause.
+func (e *Error) Problem(instance string) *pb.Problem {
+	problem := &pb.Problem{
+		Type:       e.Code.TypeUri(),
+		Title:      e.Code.Title(),
+		Status:     uint32(e.Code.HttpStatus()),
+		Detail:     e.Message,
+		Instance:   instance,
+		Code:       string(e.Code),
+		Extensions: e.Extensions,
+	}
+
+	for _, f := range e.Fields {
+		problem.Errors = append(problem.Errors, &pb.FieldViolation{Field: f.Field, Description: f.Description})
+	}
+
+	return problem
+}
+
+// problemJson flattens the extensions into top-level members, as RFC 7807
+// asks for.
+func problemJson(problem *pb.Problem) map[string]any {
+	body := map[string]any{}
+	for k, v := range problem.Extensions {
+		body[k] = v
+	}
+
+	body["type"] = problem.Type
+	body["title"] = problem.Title
+	body["status"] = problem.Status
+	body["detail"] = problem.Detail
+	body["code"] = problem.Code
+	if problem.Instance != "" {
+		body["instance"] = problem.Instance
+	}
+	if len(problem.Errors) > 0 {
+		body["errors"] = problem.Errors
+	}
+
+	return body
+}
+
+func logError(e *Error, err error) {
+	if e.Code.HttpStatus() >= http.StatusInternalServerError {
+		log.Println(err)
+	}
+}
+
+// Write answers the request with the problem+json representation of err.
+// Handlers should return right after calling it.
+func Write(w http.ResponseWriter, req *http.Request, err error) {
+	app_err := From(err)
+	logError(app_err, err)
+
+	instance := ""
+	if req != nil {
+		instance = req.URL.Path
+	}
+
+	response, _ := json.Marshal(problemJson(app_err.Problem(instance)))
+	w.Header().Set("Content-Type", PROBLEM_CONTENT_TYPE)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if app_err.RetryAfter > 0 {
+		w.Header().Set("Retry-After", app_err.RetryAfterSeconds())
+	}
+	w.WriteHeader(app_err.Code.HttpStatus())
+	w.Write(response)
+}
+
+// ToConnect turns err into a Connect error with the problem details
+// attached, so RPC clients get the same information as REST clients.
+func ToConnect(err error) *connect.Error {
+	var connect_err *connect.Error
+	if errors.As(err, &connect_err) {
+		return connect_err
+	}
+
+	app_err := From(err)
+	logError(app_err, err)
+
+	connect_err = connect.NewError(app_err.Code.ConnectCode(), &Error{Code: app_err.Code, Message: app_err.Message})
+	if app_err.RetryAfter > 0 {
+		connect_err.Meta().Set("Retry-After", app_err.RetryAfterSeconds())
+	}
+	if detail, detail_err := connect.NewErrorDetail(app_err.Problem("")); detail_err == nil {
+		connect_err.AddDetail(detail)
+	}
+	return connect_err
+}
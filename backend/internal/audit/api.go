@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"log"
+	"net/http"
+	"time"
+)
+
+type AuditApi struct {
+	log *Log
+}
+
+func (aa *AuditApi) parseFilter(req *http.Request) (*Filter, error) {
+	filter, err := ParseFilter(req.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	filter.WorkspaceId = workspace.IdFromContext(req.Context())
+	return filter, nil
+}
+
+func (aa *AuditApi) GetAuditLog(w http.ResponseWriter, req *http.Request) {
+	filter, err := aa.parseFilter(req)
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	entries, err := aa.log.Query(filter)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading audit log"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetAuditLogResponse{Entries: entries})
+}
+
+func (aa *AuditApi) ExportAuditLog(w http.ResponseWriter, req *http.Request) {
+	filter, err := aa.parseFilter(req)
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// The status is sent with the first row, later errors can only be logged.
+	if err = aa.log.ExportCsv(w, *filter); err != nil {
+		log.Println(err)
+	}
+}
+
+func NewAuditApi(l *Log) *AuditApi {
+	return &AuditApi{log: l}
+}
@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/auth"
+	pb "invoice-manager/main/proto"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+const (
+	SOURCE_HTTP   = "http"
+	SOURCE_CLI    = "cli"
+	SOURCE_SYSTEM = "system"
+)
+
+var (
+	ErrInvalidFilter = apperr.New(apperr.CODE_INVALID_ARGUMENT, "invalid audit log filter")
+)
+
+// Actor is whoever performs a mutation, it is recorded with every entry.
+type Actor struct {
+	UserId   uint32
+	ApiKeyId uint32
+	Source   string
+	Ip       string
+}
+
+// CliActor is used for changes made with the command-line tool, which runs
+// without a signed in user.
+var CliActor = &Actor{Source: SOURCE_CLI}
+
+// SystemActor is used for changes made by background workers of the server.
+var SystemActor = &Actor{Source: SOURCE_SYSTEM}
+
+func ActorFromRequest(req *http.Request) *Actor {
+	return actorFrom(req.Context(), req.RemoteAddr)
+}
+
+// ActorFromConnect is ActorFromRequest for calls of Connect services.
+func ActorFromConnect(ctx context.Context, peer connect.Peer) *Actor {
+	return actorFrom(ctx, peer.Addr)
+}
+
+func actorFrom(ctx context.Context, remote_addr string) *Actor {
+	actor := &Actor{Source: SOURCE_HTTP, Ip: remote_addr}
+	if host, _, err := net.SplitHostPort(remote_addr); err == nil {
+		actor.Ip = host
+	}
+
+	if principal, ok := auth.FromContext(ctx); ok {
+		actor.UserId = principal.User.Id
+		if principal.ApiKey != nil {
+			actor.ApiKeyId = principal.ApiKey.Id
+		}
+	}
+
+	return actor
+}
+
+// Entry describes a mutation of the target by the actor.
+func (a *Actor) Entry(workspace_id uint32, action string, target_type string, target_id uint32, changes []*pb.AuditChange) *pb.AuditEntry {
+	return &pb.AuditEntry{
+		WorkspaceId:   workspace_id,
+		ActorUserId:   a.UserId,
+		ActorApiKeyId: a.ApiKeyId,
+		Source:        a.Source,
+		Ip:            a.Ip,
+		Action:        action,
+		TargetType:    target_type,
+		TargetId:      target_id,
+		Changes:       changes,
+	}
+}
+
+// Diff lists the fields whose values differ between before and after. A
+// nil before is a creation, a nil after a deletion.
+func Diff(before map[string]string, after map[string]string) []*pb.AuditChange {
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	names := []string{}
+	for field := range fields {
+		if before[field] != after[field] || before == nil || after == nil {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []*pb.AuditChange{}
+	for _, field := range names {
+		changes = append(changes, &pb.AuditChange{Field: field, Before: before[field], After: after[field]})
+	}
+	return changes
+}
+
+// Log is the append-only audit log. Entries are only ever inserted, and
+// triggers in the database reject updates and deletes.
+type Log struct {
+	db *sql.DB
+
+	insert_stmt, query_stmt *sql.Stmt
+}
+
+// Record appends the entry within tx, so that it is only kept if the
+// mutation it describes is committed too.
+func (l *Log) Record(tx *sql.Tx, entry *pb.AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	entry.CreatedAt = time.Now().Unix()
+	res, err := tx.Stmt(l.insert_stmt).Exec(
+		entry.WorkspaceId,
+		entry.ActorUserId,
+		entry.ActorApiKeyId,
+		entry.Source,
+		entry.Ip,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetId,
+		string(changes),
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't write audit log: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	entry.Id = uint32(id)
+	return nil
+}
+
+const AUDIT_COLUMNS = `
+	audit_id,
+	audit_workspace_id,
+	audit_actor_user_id,
+	audit_actor_api_key_id,
+	audit_source,
+	audit_ip,
+	audit_action,
+	audit_target_type,
+	audit_target_id,
+	audit_changes,
+	audit_created_at
+`
+
+func NewLog(db *sql.DB) (*Log, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO audit_log (
+			audit_workspace_id,
+			audit_actor_user_id,
+			audit_actor_api_key_id,
+			audit_source,
+			audit_ip,
+			audit_action,
+			audit_target_type,
+			audit_target_id,
+			audit_changes,
+			audit_created_at
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty filters are passed as 0 or '' and match every entry.
+	query_stmt, err := db.Prepare(`
+		SELECT ` + AUDIT_COLUMNS + `
+		FROM audit_log
+		WHERE audit_workspace_id = ?1
+			AND (?2 = 0 OR audit_actor_user_id = ?2)
+			AND (?3 = '' OR audit_action = ?3)
+			AND (?4 = '' OR audit_target_type = ?4)
+			AND (?5 = 0 OR audit_target_id = ?5)
+			AND (?6 = 0 OR audit_created_at >= ?6)
+			AND (?7 = 0 OR audit_created_at < ?7)
+		ORDER BY audit_id DESC
+		LIMIT ?8 OFFSET ?9
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Log{db: db, insert_stmt: insert_stmt, query_stmt: query_stmt}, nil
+}
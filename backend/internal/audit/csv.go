@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	pb "invoice-manager/main/proto"
+	"io"
+	"time"
+)
+
+var CSV_HEADER = []string{
+	"id",
+	"created_at",
+	"workspace_id",
+	"actor_user_id",
+	"actor_api_key_id",
+	"source",
+	"ip",
+	"action",
+	"target_type",
+	"target_id",
+	"changes",
+}
+
+func formatOptionalId(id uint32) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
+
+// ExportCsv writes all entries matching the filter, ignoring its limit and
+// offset. Changes are written as a JSON array.
+func (l *Log) ExportCsv(w io.Writer, filter Filter) error {
+	filter.Limit = -1
+	filter.Offset = 0
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSV_HEADER); err != nil {
+		return err
+	}
+
+	err := l.Each(&filter, func(entry *pb.AuditEntry) error {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return err
+		}
+
+		return cw.Write([]string{
+			fmt.Sprint(entry.Id),
+			time.Unix(entry.CreatedAt, 0).UTC().Format(time.RFC3339),
+			fmt.Sprint(entry.WorkspaceId),
+			formatOptionalId(entry.ActorUserId),
+			formatOptionalId(entry.ActorApiKeyId),
+			entry.Source,
+			entry.Ip,
+			entry.Action,
+			entry.TargetType,
+			fmt.Sprint(entry.TargetId),
+			string(changes),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
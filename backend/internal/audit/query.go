@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	pb "invoice-manager/main/proto"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	DEFAULT_LIMIT = 100
+	MAX_LIMIT     = 1000
+)
+
+// Filter selects entries of one workspace, zero values match everything.
+// A Limit of -1 returns all matching entries.
+type Filter struct {
+	WorkspaceId uint32
+	ActorUserId uint32
+	Action      string
+	TargetType  string
+	TargetId    uint32
+	From        int64
+	To          int64
+	Limit       int
+	Offset      int
+}
+
+func parseUint32(values url.Values, name string) (uint32, error) {
+	if values.Get(name) == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(values.Get(name), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an ID", ErrInvalidFilter, name)
+	}
+	return uint32(value), nil
+}
+
+// parseTime accepts unix timestamps as well as RFC 3339 dates and times.
+func parseTime(values url.Values, name string) (int64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return unix, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Unix(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s must be a unix timestamp or an RFC 3339 date", ErrInvalidFilter, name)
+}
+
+// ParseFilter reads a filter from the query parameters actor, action,
+// target_type, target_id, from, to, limit and offset.
+func ParseFilter(values url.Values) (*Filter, error) {
+	filter := &Filter{
+		Action:     values.Get("action"),
+		TargetType: values.Get("target_type"),
+		Limit:      DEFAULT_LIMIT,
+	}
+
+	var err error
+	if filter.ActorUserId, err = parseUint32(values, "actor"); err != nil {
+		return nil, err
+	}
+	if filter.TargetId, err = parseUint32(values, "target_id"); err != nil {
+		return nil, err
+	}
+	if filter.From, err = parseTime(values, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime(values, "to"); err != nil {
+		return nil, err
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > MAX_LIMIT {
+			return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MAX_LIMIT)
+		}
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidFilter)
+		}
+	}
+
+	return filter, nil
+}
+
+func scanEntry(row interface{ Scan(...any) error }) (*pb.AuditEntry, error) {
+	entry := &pb.AuditEntry{}
+	var changes string
+	err := row.Scan(
+		&entry.Id,
+		&entry.WorkspaceId,
+		&entry.ActorUserId,
+		&entry.ActorApiKeyId,
+		&entry.Source,
+		&entry.Ip,
+		&entry.Action,
+		&entry.TargetType,
+		&entry.TargetId,
+		&changes,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (l *Log) query(filter *Filter) (*sql.Rows, error) {
+	return l.query_stmt.Query(
+		filter.WorkspaceId,
+		filter.ActorUserId,
+		filter.Action,
+		filter.TargetType,
+		filter.TargetId,
+		filter.From,
+		filter.To,
+		filter.Limit,
+		filter.Offset,
+	)
+}
+
+// Each calls fn for every matching entry, newest first, without loading
+// them all into memory.
+func (l *Log) Each(filter *Filter, fn func(*pb.AuditEntry) error) error {
+	rows, err := l.query(filter)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err = fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (l *Log) Query(filter *Filter) ([]*pb.AuditEntry, error) {
+	entries := []*pb.AuditEntry{}
+	err := l.Each(filter, func(entry *pb.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/apikey"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated = apperr.New(apperr.CODE_UNAUTHENTICATED, "authentication required")
+	ErrSessionRequired = apperr.New(apperr.CODE_PERMISSION_DENIED, "this action requires a signed in user")
+)
+
+// Principal is whoever a request is made by: a signed in user, or a user's
+// API key, which is limited to the scopes it was created with.
+type Principal struct {
+	User   *pb.User
+	ApiKey *pb.ApiKey
+
+	// SessionWorkspaceId is the workspace last switched to in the session.
+	SessionWorkspaceId uint32
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	if p.ApiKey == nil {
+		return true
+	}
+
+	for _, s := range p.ApiKey.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	ctx = user.WithUser(ctx, principal.User)
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok
+}
+
+type Authenticator struct {
+	users      *user.Users
+	keys       *apikey.ApiKeys
+	workspaces *workspace.Workspaces
+	roles      *rbac.Roles
+}
+
+func bearerToken(header http.Header) string {
+	authorization := header.Get("Authorization")
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func sessionToken(header http.Header) string {
+	cookie, err := (&http.Request{Header: header}).Cookie(user.SESSION_COOKIE)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// Authenticate resolves the principal from a bearer API key or, if there is
+// none, from the session cookie.
+func (a *Authenticator) Authenticate(header http.Header) (*Principal, error) {
+	if token := bearerToken(header); token != "" {
+		key, err := a.keys.Verify(token)
+		if err == apikey.ErrInvalidToken {
+			return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		owner, err := a.users.Retrieve(int(key.UserId))
+		if err == user.ErrIDNotFound {
+			return nil, ErrUnauthenticated
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &Principal{User: owner, ApiKey: key}, nil
+	}
+
+	token := sessionToken(header)
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	session, err := a.users.RetrieveSession(token)
+	if err == user.ErrSessionNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthenticated, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{User: session.User, SessionWorkspaceId: session.WorkspaceId}, nil
+}
+
+func NewAuthenticator(us *user.Users, ks *apikey.ApiKeys, ws *workspace.Workspaces, rs *rbac.Roles) *Authenticator {
+	return &Authenticator{users: us, keys: ks, workspaces: ws, roles: rs}
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"invoice-manager/main/internal/apikey"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/workspace"
+	"net/http"
+)
+
+// scopes maps permissions to the API key scope that grants them. Permissions
+// without a scope can't be used with API keys at all.
+var scopes = map[string]string{
+	rbac.PERM_TEMPLATES_READ:   apikey.SCOPE_TEMPLATES_READ,
+	rbac.PERM_TEMPLATES_WRITE:  apikey.SCOPE_TEMPLATES_WRITE,
+	rbac.PERM_TEMPLATES_DELETE: apikey.SCOPE_TEMPLATES_WRITE,
+	rbac.PERM_CLIENTS_READ:     apikey.SCOPE_CLIENTS_READ,
+	rbac.PERM_CLIENTS_WRITE:    apikey.SCOPE_CLIENTS_WRITE,
+	rbac.PERM_INVOICES_READ:    apikey.SCOPE_INVOICES_READ,
+	rbac.PERM_INVOICES_WRITE:   apikey.SCOPE_INVOICES_WRITE,
+	rbac.PERM_INVOICES_ISSUE:   apikey.SCOPE_INVOICES_ISSUE,
+	rbac.PERM_PAYMENTS_READ:    apikey.SCOPE_PAYMENTS_READ,
+	rbac.PERM_PAYMENTS_WRITE:   apikey.SCOPE_PAYMENTS_WRITE,
+}
+
+// Authorize checks that the principal may use the permission in the
+// workspace of the context. Refusals are returned as *rbac.Denial, an API
+// key additionally needs the scope matching the permission.
+func (a *Authenticator) Authorize(ctx context.Context, permission string) error {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	current, ok := workspace.FromContext(ctx)
+	if !ok {
+		return &rbac.Denial{Reason: rbac.REASON_NO_WORKSPACE, Permission: permission}
+	}
+
+	if principal.ApiKey != nil {
+		scope, ok := scopes[permission]
+		if !ok {
+			return &rbac.Denial{Reason: rbac.REASON_SESSION_REQUIRED, Permission: permission}
+		}
+		if !principal.HasScope(scope) {
+			return &rbac.Denial{Reason: rbac.REASON_MISSING_SCOPE, Permission: permission, Scope: scope}
+		}
+	}
+
+	member, err := a.workspaces.RetrieveMember(current.Id, principal.User.Id)
+	if err == workspace.ErrNotMember {
+		return &rbac.Denial{Reason: rbac.REASON_NOT_MEMBER, Permission: permission}
+	}
+	if err != nil {
+		return err
+	}
+
+	allowed, err := a.roles.HasPermission(current.Id, member.Role, permission)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &rbac.Denial{Reason: rbac.REASON_MISSING_PERMISSION, Permission: permission, Role: member.Role}
+	}
+
+	return nil
+}
+
+// Require only lets requests through whose principal has the permission in
+// the current workspace.
+func (a *Authenticator) Require(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := a.Authorize(req.Context(), permission); err != nil {
+			apperr.Write(w, req, apperr.Wrap(err, "Couldn't check permissions"))
+			return
+		}
+
+		next(w, req)
+	}
+}
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apikey"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testAuth is an authenticator with a user for every built-in role in the
+// default workspace, a bookkeeper with a custom role there and the owner of
+// another workspace.
+type testAuth struct {
+	*Authenticator
+	users          map[string]*pb.User
+	current, other *pb.Workspace
+}
+
+func newTestAuth(t *testing.T) *testAuth {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	us, err := user.NewUsers(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks, err := apikey.NewApiKeys(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := workspace.NewWorkspaces(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := rbac.NewRoles(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ta := &testAuth{Authenticator: NewAuthenticator(us, ks, ws, rs), users: map[string]*pb.User{}}
+	if ta.current, err = ws.Retrieve(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = rs.Create(1, "bookkeeper", []string{rbac.PERM_INVOICES_READ, rbac.PERM_PAYMENTS_READ, rbac.PERM_PAYMENTS_WRITE}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, role := range append(slices.Clone(rbac.BuiltinRoleNames), "bookkeeper", "outsider") {
+		u, err := us.Create(role+"@example.com", role, "correct horse battery staple")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ta.users[role] = u
+		if role == "outsider" {
+			break
+		}
+		if _, err = ws.AddMember(1, u.Id, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ta.other, err = ws.Create("other", "Other", "EUR", ta.users["outsider"].Id); err != nil {
+		t.Fatal(err)
+	}
+
+	return ta
+}
+
+// context returns the context of a request the principal makes in the
+// workspace.
+func (ta *testAuth) context(principal *Principal, current *pb.Workspace) context.Context {
+	ctx := context.Background()
+	if current != nil {
+		ctx = workspace.WithWorkspace(ctx, current)
+	}
+	if principal != nil {
+		ctx = WithPrincipal(ctx, principal)
+	}
+	return ctx
+}
+
+// checkDenial fails unless err is a denial with the reason, which is
+// reported as 403 naming the reason.
+func checkDenial(t *testing.T, err error, want *rbac.Denial) {
+	t.Helper()
+
+	var denial *rbac.Denial
+	if !errors.As(err, &denial) {
+		t.Fatalf("Authorize() error = %v, want a %s denial", err, want.Reason)
+	}
+	if *denial != *want {
+		t.Errorf("Authorize() denial = %+v, want %+v", *denial, *want)
+	}
+
+	app_err := apperr.From(err)
+	if status := app_err.Code.HttpStatus(); status != http.StatusForbidden {
+		t.Errorf("denial is reported as %d, want %d", status, http.StatusForbidden)
+	}
+	if app_err.Extensions["reason"] != want.Reason {
+		t.Errorf("denial is reported with reason %q, want %q", app_err.Extensions["reason"], want.Reason)
+	}
+}
+
+func TestAuthorizeRoles(t *testing.T) {
+	ta := newTestAuth(t)
+
+	for _, role := range rbac.BuiltinRoleNames {
+		ctx := ta.context(&Principal{User: ta.users[role]}, ta.current)
+		for _, permission := range rbac.Permissions {
+			err := ta.Authorize(ctx, permission)
+			if slices.Contains(rbac.BuiltinRoles[role], permission) {
+				if err != nil {
+					t.Errorf("Authorize(%s, %s) error = %v", role, permission, err)
+				}
+				continue
+			}
+			checkDenial(t, err, &rbac.Denial{Reason: rbac.REASON_MISSING_PERMISSION, Permission: permission, Role: role})
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ta := newTestAuth(t)
+
+	session := func(role string) *Principal { return &Principal{User: ta.users[role]} }
+	key := func(role string, scopes ...string) *Principal {
+		return &Principal{User: ta.users[role], ApiKey: &pb.ApiKey{UserId: ta.users[role].Id, Scopes: scopes}}
+	}
+
+	tests := []struct {
+		name       string
+		principal  *Principal
+		workspace  *pb.Workspace
+		permission string
+		want       *rbac.Denial
+	}{
+		{
+			name:       "custom role",
+			principal:  session("bookkeeper"),
+			workspace:  ta.current,
+			permission: rbac.PERM_PAYMENTS_WRITE,
+		},
+		{
+			name:       "permission the custom role lacks",
+			principal:  session("bookkeeper"),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_VOID,
+			want:       &rbac.Denial{Reason: rbac.REASON_MISSING_PERMISSION, Permission: rbac.PERM_INVOICES_VOID, Role: "bookkeeper"},
+		},
+		{
+			name:       "no workspace",
+			principal:  session(rbac.ROLE_OWNER),
+			permission: rbac.PERM_INVOICES_READ,
+			want:       &rbac.Denial{Reason: rbac.REASON_NO_WORKSPACE, Permission: rbac.PERM_INVOICES_READ},
+		},
+		{
+			name:       "owner in another workspace",
+			principal:  session(rbac.ROLE_OWNER),
+			workspace:  ta.other,
+			permission: rbac.PERM_INVOICES_READ,
+			want:       &rbac.Denial{Reason: rbac.REASON_NOT_MEMBER, Permission: rbac.PERM_INVOICES_READ},
+		},
+		{
+			name:       "owner of another workspace",
+			principal:  session("outsider"),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_READ,
+			want:       &rbac.Denial{Reason: rbac.REASON_NOT_MEMBER, Permission: rbac.PERM_INVOICES_READ},
+		},
+		{
+			name:       "API key with the scope",
+			principal:  key(rbac.ROLE_EDITOR, apikey.SCOPE_INVOICES_WRITE),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_WRITE,
+		},
+		{
+			name:       "API key without the scope",
+			principal:  key(rbac.ROLE_EDITOR, apikey.SCOPE_INVOICES_READ),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_WRITE,
+			want:       &rbac.Denial{Reason: rbac.REASON_MISSING_SCOPE, Permission: rbac.PERM_INVOICES_WRITE, Scope: apikey.SCOPE_INVOICES_WRITE},
+		},
+		{
+			name:       "API key with a scope beyond the role",
+			principal:  key(rbac.ROLE_VIEWER, apikey.SCOPE_INVOICES_WRITE),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_WRITE,
+			want:       &rbac.Denial{Reason: rbac.REASON_MISSING_PERMISSION, Permission: rbac.PERM_INVOICES_WRITE, Role: rbac.ROLE_VIEWER},
+		},
+		{
+			name:       "API key for a permission without a scope",
+			principal:  key(rbac.ROLE_OWNER, apikey.Scopes...),
+			workspace:  ta.current,
+			permission: rbac.PERM_MEMBERS_MANAGE,
+			want:       &rbac.Denial{Reason: rbac.REASON_SESSION_REQUIRED, Permission: rbac.PERM_MEMBERS_MANAGE},
+		},
+		{
+			name:       "API key of another workspace's owner",
+			principal:  key("outsider", apikey.SCOPE_INVOICES_READ),
+			workspace:  ta.current,
+			permission: rbac.PERM_INVOICES_READ,
+			want:       &rbac.Denial{Reason: rbac.REASON_NOT_MEMBER, Permission: rbac.PERM_INVOICES_READ},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ta.Authorize(ta.context(test.principal, test.workspace), test.permission)
+			if test.want == nil {
+				if err != nil {
+					t.Errorf("Authorize() error = %v", err)
+				}
+				return
+			}
+			checkDenial(t, err, test.want)
+		})
+	}
+
+	if err := ta.Authorize(ta.context(nil, ta.current), rbac.PERM_INVOICES_READ); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Authorize() without a principal error = %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestResolveWorkspaceOfOthers(t *testing.T) {
+	ta := newTestAuth(t)
+	owner := ta.users[rbac.ROLE_OWNER]
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		requested string
+		want      error
+	}{
+		{"member asking for another workspace", &Principal{User: owner}, "other", ErrNoAccess},
+		{"member switched to another workspace", &Principal{User: owner, SessionWorkspaceId: ta.other.Id}, "", nil},
+		{"API key of a workspace asking for another", &Principal{User: owner, ApiKey: &pb.ApiKey{WorkspaceId: 1}}, "other", ErrWorkspaceMismatch},
+		{"unknown workspace", &Principal{User: owner}, "nowhere", ErrNoAccess},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := http.Header{}
+			if test.requested != "" {
+				header.Set(workspace.WORKSPACE_HEADER, test.requested)
+			}
+
+			got, err := ta.ResolveWorkspace(test.principal, header, "localhost")
+			if test.want == nil {
+				// Workspaces the user isn't a member of anymore are
+				// skipped for the first one they are.
+				if err != nil || got.Id != ta.current.Id {
+					t.Errorf("ResolveWorkspace() = %v, %v, want workspace %d", got, err, ta.current.Id)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ResolveWorkspace() = workspace %d, want %v", got.Id, test.want)
+			}
+			if err = workspaceError(err); !errors.Is(err, test.want) {
+				t.Errorf("workspaceError() = %v, want %v", err, test.want)
+			}
+			if status := apperr.From(err).Code.HttpStatus(); status != http.StatusForbidden {
+				t.Errorf("error is reported as %d, want %d", status, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/workspace"
+	"net/http"
+
+	"connectrpc.com/connect"
+)
+
+type interceptor struct {
+	authenticator *Authenticator
+	permissions   map[string]string
+}
+
+func (i *interceptor) authenticate(ctx context.Context, procedure string, header http.Header) (context.Context, error) {
+	principal, err := i.authenticator.Authenticate(header)
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Couldn't verify credentials"))
+	}
+
+	ctx = WithPrincipal(ctx, principal)
+
+	// Services that don't need a workspace, like PingService, keep working
+	// for users without one; the others check workspace.FromContext.
+	host, _ := ctx.Value(hostKey{}).(string)
+	current, err := i.authenticator.ResolveWorkspace(principal, header, host)
+	switch {
+	case err == nil:
+		ctx = workspace.WithWorkspace(ctx, current)
+	case errors.Is(err, workspace.ErrNoWorkspace):
+	default:
+		return nil, apperr.ToConnect(workspaceError(err))
+	}
+
+	if permission, ok := i.permissions[procedure]; ok {
+		if err = i.authenticator.Authorize(ctx, permission); err != nil {
+			return nil, apperr.ToConnect(apperr.Wrap(err, "Couldn't check permissions"))
+		}
+	}
+
+	return ctx, nil
+}
+
+func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+
+		ctx, err := i.authenticate(ctx, req.Spec().Procedure, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.authenticate(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+type hostKey struct{}
+
+// WithRequestHost makes the Host of the request available to the interceptor,
+// which Connect doesn't pass on, so workspaces can be picked by subdomain.
+func WithRequestHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), hostKey{}, req.Host)))
+	})
+}
+
+// Interceptor authenticates Connect requests the same way as Middleware.
+// permissions maps procedure names to the permission needed to call them,
+// procedures without an entry are open to every authenticated principal.
+func (a *Authenticator) Interceptor(permissions map[string]string) connect.Interceptor {
+	return &interceptor{authenticator: a, permissions: permissions}
+}
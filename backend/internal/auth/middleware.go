@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"net/http"
+)
+
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		principal, err := a.Authenticate(req.Header)
+		if errors.Is(err, ErrUnauthenticated) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="invoicer"`)
+			apperr.Write(w, req, err)
+			return
+		}
+		if err != nil {
+			apperr.Write(w, req, apperr.Wrap(err, "Couldn't verify credentials"))
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(WithPrincipal(req.Context(), principal)))
+	})
+}
+
+// RequireSession rejects API keys, e.g. so that a leaked key can't be used
+// to mint new keys.
+func RequireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		principal, ok := FromContext(req.Context())
+		if !ok || principal.ApiKey != nil {
+			apperr.Write(w, req, ErrSessionRequired)
+			return
+		}
+
+		next(w, req)
+	}
+}
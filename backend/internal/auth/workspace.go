@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/constants"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrWorkspaceMismatch = apperr.New(apperr.CODE_PERMISSION_DENIED, "API key belongs to another workspace")
+	ErrNoAccess          = apperr.New(apperr.CODE_PERMISSION_DENIED, "no access to this workspace")
+)
+
+// requestedWorkspace returns the workspace asked for explicitly, either with
+// the workspace header or as the subdomain of the base domain.
+func requestedWorkspace(header http.Header, host string) string {
+	if ref := strings.TrimSpace(header.Get(workspace.WORKSPACE_HEADER)); ref != "" {
+		return ref
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if sub, found := strings.CutSuffix(host, "."+constants.BASE_DOMAIN); found && !strings.Contains(sub, ".") {
+		return sub
+	}
+
+	return ""
+}
+
+// ResolveWorkspace picks the workspace of a request. A workspace bound to
+// the API key wins, then an explicitly requested one, then the one switched
+// to in the session and finally the first workspace the user belongs to.
+// The user always has to be a member of the resolved workspace.
+func (a *Authenticator) ResolveWorkspace(principal *Principal, header http.Header, host string) (*pb.Workspace, error) {
+	requested := requestedWorkspace(header, host)
+
+	var candidate *pb.Workspace
+	var err error
+	switch {
+	case principal.ApiKey != nil && principal.ApiKey.WorkspaceId != 0:
+		candidate, err = a.workspaces.Retrieve(principal.ApiKey.WorkspaceId)
+		if err == nil && requested != "" && requested != candidate.Slug && requested != fmt.Sprint(candidate.Id) {
+			return nil, ErrWorkspaceMismatch
+		}
+
+	case requested != "":
+		candidate, err = a.workspaces.RetrieveByRef(requested)
+
+	case principal.SessionWorkspaceId != 0:
+		candidate, err = a.workspaces.Retrieve(principal.SessionWorkspaceId)
+		if err == nil {
+			if _, member_err := a.workspaces.RetrieveMember(candidate.Id, principal.User.Id); member_err == nil {
+				return candidate, nil
+			}
+		}
+		candidate, err = nil, nil
+	}
+
+	if err == workspace.ErrIDNotFound {
+		return nil, workspace.ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if candidate == nil {
+		workspaces, err := a.workspaces.ListForUser(principal.User.Id)
+		if err != nil {
+			return nil, err
+		}
+		if len(workspaces) == 0 {
+			return nil, workspace.ErrNoWorkspace
+		}
+		return workspaces[0], nil
+	}
+
+	if _, err = a.workspaces.RetrieveMember(candidate.Id, principal.User.Id); err != nil {
+		return nil, err
+	}
+
+	return candidate, nil
+}
+
+// workspaceError reports a workspace the user isn't a member of as a
+// permission error, which ErrNotMember isn't in general.
+func workspaceError(err error) error {
+	if errors.Is(err, workspace.ErrNotMember) {
+		return ErrNoAccess
+	}
+	return apperr.Wrap(err, "Couldn't resolve the workspace")
+}
+
+func (a *Authenticator) WorkspaceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		principal, ok := FromContext(req.Context())
+		if !ok {
+			apperr.Write(w, req, ErrUnauthenticated)
+			return
+		}
+
+		current, err := a.ResolveWorkspace(principal, req.Header, req.Host)
+		if err != nil {
+			apperr.Write(w, req, workspaceError(err))
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(workspace.WithWorkspace(req.Context(), current)))
+	})
+}
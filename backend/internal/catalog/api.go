@@ -0,0 +1,162 @@
+package catalog
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"io"
+	"net/http"
+)
+
+// MAX_IMPORT_BYTES fits MAX_IMPORT_ROWS rows with long descriptions.
+const MAX_IMPORT_BYTES = 8 << 20
+
+type CatalogApi struct {
+	catalog *Catalog
+}
+
+func (ca *CatalogApi) GetCatalogItemsList(w http.ResponseWriter, req *http.Request) {
+	filter, err := ParseFilter(req.URL.Query())
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+	filter.WorkspaceId = workspace.IdFromContext(req.Context())
+
+	items, total, err := ca.catalog.Search(req.Context(), filter)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading the catalog"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCatalogItemsResponse{Items: items, Total: total})
+}
+
+// SuggestCatalogItems completes what has been typed so far, given as the
+// query parameter q.
+func (ca *CatalogApi) SuggestCatalogItems(w http.ResponseWriter, req *http.Request) {
+	items, err := ca.catalog.Suggest(req.Context(), workspace.IdFromContext(req.Context()), req.URL.Query().Get("q"))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading the catalog"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCatalogItemsResponse{Items: items, Total: uint32(len(items))})
+}
+
+func (ca *CatalogApi) GetCatalogItem(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	item, err := ca.catalog.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading catalog item"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.CatalogItemResponse{Item: item})
+}
+
+func (ca *CatalogApi) CreateCatalogItem(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveCatalogItemRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	item, err := ca.catalog.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Catalog item couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.CatalogItemResponse{Item: item})
+}
+
+func (ca *CatalogApi) UpdateCatalogItem(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveCatalogItemRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	item, err := ca.catalog.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Catalog item couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.CatalogItemResponse{Item: item})
+}
+
+func (ca *CatalogApi) setArchived(w http.ResponseWriter, req *http.Request, archived bool) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	item, err := ca.catalog.SetArchived(req.Context(), workspace.IdFromContext(req.Context()), id, archived, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Catalog item couldn't be archived"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.CatalogItemResponse{Item: item})
+}
+
+func (ca *CatalogApi) ArchiveCatalogItem(w http.ResponseWriter, req *http.Request) {
+	ca.setArchived(w, req, true)
+}
+
+func (ca *CatalogApi) UnarchiveCatalogItem(w http.ResponseWriter, req *http.Request) {
+	ca.setArchived(w, req, false)
+}
+
+func (ca *CatalogApi) DeleteCatalogItem(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ca.catalog.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the catalog item"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportCatalog takes a CSV file as the request body.
+func (ca *CatalogApi) ImportCatalog(w http.ResponseWriter, req *http.Request) {
+	req.Body = http.MaxBytesReader(w, req.Body, MAX_IMPORT_BYTES)
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading the catalog from request"))
+		return
+	}
+
+	res, err := ca.catalog.Import(req.Context(), workspace.IdFromContext(req.Context()), data, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Catalog couldn't be imported"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, res)
+}
+
+func NewCatalogApi(c *Catalog) *CatalogApi {
+	return &CatalogApi{catalog: c}
+}
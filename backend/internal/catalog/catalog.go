@@ -0,0 +1,604 @@
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound = apperr.New(apperr.CODE_NOT_FOUND, "catalog item not found")
+	ErrSkuTaken   = apperr.New(apperr.CODE_ALREADY_EXISTS, "SKU is already taken")
+	ErrInUse      = apperr.New(apperr.CODE_FAILED_PRECONDITION, "catalog item is used by invoices, archive it instead")
+)
+
+const (
+	AUDIT_TARGET    = "catalog_item"
+	AUDIT_CREATE    = "catalog_item.create"
+	AUDIT_UPDATE    = "catalog_item.update"
+	AUDIT_DELETE    = "catalog_item.delete"
+	AUDIT_ARCHIVE   = "catalog_item.archive"
+	AUDIT_UNARCHIVE = "catalog_item.unarchive"
+	AUDIT_IMPORT    = "catalog.import"
+)
+
+// Catalog holds the products and services of a workspace.
+type Catalog struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	insert_stmt, retrieve_stmt, retrieve_by_sku_stmt, update_stmt, archive_stmt, delete_stmt *sql.Stmt
+	search_stmt, count_stmt, suggest_stmt                                                    *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(row scanner) (*pb.CatalogItem, error) {
+	item := &pb.CatalogItem{}
+	var prices string
+	err := row.Scan(
+		&item.Id,
+		&item.Sku,
+		&item.Name,
+		&item.Description,
+		&item.Unit,
+		&prices,
+		&item.TaxCategory,
+		&item.ArchivedAt,
+		&item.CreatedAt,
+		&item.UpdatedAt,
+		&item.CreatedBy,
+		&item.UpdatedBy,
+		&item.WorkspaceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(prices), &item.Prices); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func scanItems(rows *sql.Rows) ([]*pb.CatalogItem, error) {
+	defer rows.Close()
+
+	items := []*pb.CatalogItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func marshalPrices(prices []*pb.Price) string {
+	if prices == nil {
+		prices = []*pb.Price{}
+	}
+	b, _ := json.Marshal(prices)
+	return string(b)
+}
+
+func isUniqueViolation(err error) bool {
+	var sqlite_err sqlite3.Error
+	return errors.As(err, &sqlite_err) && sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// auditFields is what the audit log records of a catalog item.
+func auditFields(item *pb.CatalogItem) map[string]string {
+	prices := []string{}
+	for _, price := range item.Prices {
+		prices = append(prices, price.Amount+" "+price.Currency)
+	}
+
+	return map[string]string{
+		"sku":          item.Sku,
+		"name":         item.Name,
+		"description":  item.Description,
+		"unit":         item.Unit,
+		"prices":       strings.Join(prices, ", "),
+		"tax_category": item.TaxCategory,
+	}
+}
+
+func (c *Catalog) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.CatalogItem, error) {
+	item, err := scanItem(tx.Stmt(c.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return item, err
+}
+
+func (c *Catalog) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.CatalogItem, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Retrieve")
+	defer end(&err)
+
+	item, err := scanItem(c.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return item, err
+}
+
+// insert adds an item of a validated request within tx.
+func (c *Catalog) insert(ctx context.Context, tx *sql.Tx, workspace_id uint32, req *pb.SaveCatalogItemRequest, actor *audit.Actor) (*pb.CatalogItem, error) {
+	now := time.Now().Unix()
+	res, err := tx.Stmt(c.insert_stmt).ExecContext(
+		ctx,
+		req.Sku,
+		req.Name,
+		req.Description,
+		req.Unit,
+		marshalPrices(req.Prices),
+		req.TaxCategory,
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: %s", ErrSkuTaken, req.Sku)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := c.retrieve(ctx, tx, workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, item.Id, audit.Diff(nil, auditFields(item)))
+	if err = c.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// update replaces the fields of an item with those of a validated request
+// within tx.
+func (c *Catalog) update(ctx context.Context, tx *sql.Tx, workspace_id uint32, before *pb.CatalogItem, req *pb.SaveCatalogItemRequest, actor *audit.Actor) (*pb.CatalogItem, error) {
+	_, err := tx.Stmt(c.update_stmt).ExecContext(
+		ctx,
+		req.Sku,
+		req.Name,
+		req.Description,
+		req.Unit,
+		marshalPrices(req.Prices),
+		req.TaxCategory,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		before.Id,
+		workspace_id,
+	)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: %s", ErrSkuTaken, req.Sku)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := c.retrieve(ctx, tx, workspace_id, before.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = c.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, before.Id, changes)); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+func (c *Catalog) Create(ctx context.Context, workspace_id uint32, req *pb.SaveCatalogItemRequest, actor *audit.Actor) (_ *pb.CatalogItem, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Create")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	item, err := c.insert(ctx, tx, workspace_id, req, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Update replaces all fields of the item. Line items filled in from it
+// keep the values they were saved with.
+func (c *Catalog) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveCatalogItemRequest, actor *audit.Actor) (_ *pb.CatalogItem, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Update")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := c.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := c.update(ctx, tx, workspace_id, before, req, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Search returns a page of the items whose SKU, name or description
+// contains the query, ordered by name, along with the number of all
+// matching items.
+func (c *Catalog) Search(ctx context.Context, filter *Filter) (_ []*pb.CatalogItem, _ uint32, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Search")
+	defer end(&err)
+
+	pattern := ""
+	if filter.Query != "" {
+		pattern = "%" + likeEscaper.Replace(filter.Query) + "%"
+	}
+
+	var total uint32
+	err = c.count_stmt.QueryRowContext(ctx, filter.WorkspaceId, pattern, filter.Archived).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := c.search_stmt.QueryContext(ctx, filter.WorkspaceId, pattern, filter.Archived, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, err := scanItems(rows)
+	return items, total, err
+}
+
+// Suggest returns up to SUGGEST_LIMIT items that aren't archived and whose
+// SKU, name or a word of their name starts with the prefix. Items with a
+// matching SKU come first.
+func (c *Catalog) Suggest(ctx context.Context, workspace_id uint32, prefix string) (_ []*pb.CatalogItem, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Suggest")
+	defer end(&err)
+
+	prefix = likeEscaper.Replace(strings.TrimSpace(prefix))
+	rows, err := c.suggest_stmt.QueryContext(ctx, workspace_id, prefix+"%", "% "+prefix+"%", SUGGEST_LIMIT)
+	if err != nil {
+		return nil, err
+	}
+	return scanItems(rows)
+}
+
+// SetArchived hides the item from searches and suggestions by default, or
+// brings it back. Archiving an archived item changes nothing.
+func (c *Catalog) SetArchived(ctx context.Context, workspace_id uint32, id uint32, archived bool, actor *audit.Actor) (_ *pb.CatalogItem, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.SetArchived")
+	defer end(&err)
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	item, err := c.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if (item.ArchivedAt != 0) == archived {
+		return item, nil
+	}
+
+	var archived_at any
+	action := AUDIT_UNARCHIVE
+	if archived {
+		archived_at = time.Now().Unix()
+		action = AUDIT_ARCHIVE
+	}
+
+	_, err = tx.Stmt(c.archive_stmt).ExecContext(ctx, archived_at, time.Now().Unix(), helpers.NullableId(actor.UserId), id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.audit.Record(tx, actor.Entry(workspace_id, action, AUDIT_TARGET, id, nil)); err != nil {
+		return nil, err
+	}
+
+	if item, err = c.retrieve(ctx, tx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Delete removes an item no line item references.
+func (c *Catalog) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Delete")
+	defer end(&err)
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	item, err := c.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	// Line items keep their catalog item, SQLite reports a plain constraint
+	// error for the foreign key.
+	_, err = tx.Stmt(c.delete_stmt).ExecContext(ctx, id, workspace_id)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.Code == sqlite3.ErrConstraint {
+		return ErrInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(item), nil))
+	if err = c.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Import creates or updates the items of a CSV file, matched by their SKU,
+// all or none of them. Archived items stay archived.
+func (c *Catalog) Import(ctx context.Context, workspace_id uint32, data []byte, actor *audit.Actor) (_ *pb.ImportCatalogResponse, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Catalog.Import")
+	defer end(&err)
+
+	reqs, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res := &pb.ImportCatalogResponse{}
+	for _, req := range reqs {
+		before, err := scanItem(tx.Stmt(c.retrieve_by_sku_stmt).QueryRowContext(ctx, req.Sku, workspace_id))
+		switch {
+		case err == sql.ErrNoRows:
+			_, err = c.insert(ctx, tx, workspace_id, req, actor)
+			res.Created++
+		case err == nil:
+			_, err = c.update(ctx, tx, workspace_id, before, req, actor)
+			res.Updated++
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_IMPORT, AUDIT_TARGET, 0, audit.Diff(nil, map[string]string{
+		"created": fmt.Sprint(res.Created),
+		"updated": fmt.Sprint(res.Updated),
+	}))
+	if err = c.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Catalog) Close() error {
+	stmts := []*sql.Stmt{
+		c.insert_stmt,
+		c.retrieve_stmt,
+		c.retrieve_by_sku_stmt,
+		c.update_stmt,
+		c.archive_stmt,
+		c.delete_stmt,
+		c.search_stmt,
+		c.count_stmt,
+		c.suggest_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const CATALOG_ITEM_COLUMNS = `
+	catalog_item_id,
+	catalog_item_sku,
+	catalog_item_name,
+	catalog_item_description,
+	catalog_item_unit,
+	catalog_item_prices,
+	catalog_item_tax_category,
+	COALESCE(catalog_item_archived_at, 0),
+	catalog_item_created_at,
+	catalog_item_updated_at,
+	COALESCE(catalog_item_created_by, 0),
+	COALESCE(catalog_item_updated_by, 0),
+	catalog_item_workspace_id
+`
+
+// SEARCH_WHERE matches the SKU, name and description against ?2, and the
+// archive state against ?3.
+const SEARCH_WHERE = `
+	WHERE catalog_item_workspace_id = ?1
+		AND (?2 = '' OR catalog_item_sku LIKE ?2 ESCAPE '\' OR catalog_item_name LIKE ?2 ESCAPE '\' OR catalog_item_description LIKE ?2 ESCAPE '\')
+		AND (?3 = '` + ARCHIVED_ALL + `' OR (?3 = '` + ARCHIVED_ONLY + `') = (catalog_item_archived_at IS NOT NULL))
+`
+
+func NewCatalog(db *sql.DB, audit_log *audit.Log) (*Catalog, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO catalog_items (
+			catalog_item_sku,
+			catalog_item_name,
+			catalog_item_description,
+			catalog_item_unit,
+			catalog_item_prices,
+			catalog_item_tax_category,
+			catalog_item_created_at,
+			catalog_item_updated_at,
+			catalog_item_created_by,
+			catalog_item_updated_by,
+			catalog_item_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + CATALOG_ITEM_COLUMNS + `
+		FROM catalog_items
+		WHERE catalog_item_id = ? AND catalog_item_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_by_sku_stmt, err := db.Prepare(`
+		SELECT ` + CATALOG_ITEM_COLUMNS + `
+		FROM catalog_items
+		WHERE catalog_item_sku = ? AND catalog_item_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE catalog_items
+		SET catalog_item_sku = ?,
+			catalog_item_name = ?,
+			catalog_item_description = ?,
+			catalog_item_unit = ?,
+			catalog_item_prices = ?,
+			catalog_item_tax_category = ?,
+			catalog_item_updated_at = ?,
+			catalog_item_updated_by = ?
+		WHERE catalog_item_id = ? AND catalog_item_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	archive_stmt, err := db.Prepare(`
+		UPDATE catalog_items
+		SET catalog_item_archived_at = ?, catalog_item_updated_at = ?, catalog_item_updated_by = ?
+		WHERE catalog_item_id = ? AND catalog_item_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM catalog_items WHERE catalog_item_id = ? AND catalog_item_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	search_stmt, err := db.Prepare(`
+		SELECT ` + CATALOG_ITEM_COLUMNS + `
+		FROM catalog_items
+		` + SEARCH_WHERE + `
+		ORDER BY catalog_item_name COLLATE NOCASE, catalog_item_id
+		LIMIT ?4 OFFSET ?5
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_stmt, err := db.Prepare("SELECT COUNT(*) FROM catalog_items " + SEARCH_WHERE)
+	if err != nil {
+		return nil, err
+	}
+
+	suggest_stmt, err := db.Prepare(`
+		SELECT ` + CATALOG_ITEM_COLUMNS + `
+		FROM catalog_items
+		WHERE catalog_item_workspace_id = ?1
+			AND catalog_item_archived_at IS NULL
+			AND (catalog_item_sku LIKE ?2 ESCAPE '\' OR catalog_item_name LIKE ?2 ESCAPE '\' OR catalog_item_name LIKE ?3 ESCAPE '\')
+		ORDER BY catalog_item_sku LIKE ?2 ESCAPE '\' DESC, catalog_item_name COLLATE NOCASE, catalog_item_id
+		LIMIT ?4
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Catalog{
+		db:                   db,
+		audit:                audit_log,
+		insert_stmt:          insert_stmt,
+		retrieve_stmt:        retrieve_stmt,
+		retrieve_by_sku_stmt: retrieve_by_sku_stmt,
+		update_stmt:          update_stmt,
+		archive_stmt:         archive_stmt,
+		delete_stmt:          delete_stmt,
+		search_stmt:          search_stmt,
+		count_stmt:           count_stmt,
+		suggest_stmt:         suggest_stmt,
+	}, nil
+}
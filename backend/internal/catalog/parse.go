@@ -0,0 +1,127 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"io"
+	"strings"
+)
+
+// MAX_IMPORT_ROWS keeps an import within one reasonably sized transaction.
+const MAX_IMPORT_ROWS = 10_000
+
+var ErrInvalidFile = apperr.New(apperr.CODE_INVALID_ARGUMENT, "catalog file is invalid")
+
+// headerName folds the ways spreadsheets write a column, e.g. "Tax Category"
+// and "tax-category" are tax_category.
+func headerName(name string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Parse reads catalog items one per row from CSV with a header. The
+// columns sku and name are required, description, unit and tax_category
+// optional, and prices are given in a column per currency named price_EUR,
+// price_USD and so on. Empty prices are left out. Rows are validated the
+// way single items are, the problems of all of them are reported at once.
+func Parse(data []byte) ([]*pb.SaveCatalogItemRequest, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	columns := map[string]int{}
+	type priceColumn struct {
+		currency string
+		index    int
+	}
+	prices := []priceColumn{}
+	for i, name := range header {
+		name = headerName(name)
+		if currency, ok := strings.CutPrefix(name, "price_"); ok {
+			prices = append(prices, priceColumn{currency: strings.ToUpper(currency), index: i})
+			continue
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"sku", "name"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: no %s column", ErrInvalidFile, name)
+		}
+	}
+
+	fields := []apperr.FieldError{}
+	seen := map[string]int{}
+	items := []*pb.SaveCatalogItemRequest{}
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+		}
+		if len(items) == MAX_IMPORT_ROWS {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidFile, MAX_IMPORT_ROWS)
+		}
+
+		value := func(i int, ok bool) string {
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+		column := func(name string) string {
+			i, ok := columns[name]
+			return value(i, ok)
+		}
+
+		item := &pb.SaveCatalogItemRequest{
+			Sku:         column("sku"),
+			Name:        column("name"),
+			Description: column("description"),
+			Unit:        column("unit"),
+			TaxCategory: column("tax_category"),
+			Prices:      []*pb.Price{},
+		}
+		for _, price := range prices {
+			if amount := strings.TrimSpace(value(price.index, true)); amount != "" {
+				item.Prices = append(item.Prices, &pb.Price{Currency: price.currency, Amount: amount})
+			}
+		}
+
+		field := fmt.Sprintf("line %d", line)
+		if err = Validate(item); err != nil {
+			var app_err *apperr.Error
+			if !errors.As(err, &app_err) {
+				return nil, err
+			}
+			for _, f := range app_err.Fields {
+				fields = append(fields, apperr.Field(field+"."+f.Field, f.Description))
+			}
+			continue
+		}
+		if first, ok := seen[item.Sku]; ok {
+			fields = append(fields, apperr.Field(field+".sku", fmt.Sprintf("is the same as on line %d", first)))
+			continue
+		}
+		seen[item.Sku] = line
+
+		items = append(items, item)
+	}
+
+	if len(fields) > 0 {
+		return nil, apperr.Invalid("Catalog file is invalid", fields...)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: no items found", ErrInvalidFile)
+	}
+	return items, nil
+}
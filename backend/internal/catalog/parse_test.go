@@ -0,0 +1,136 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// Items as "sku|name|description|unit|tax category|prices".
+		want []string
+	}{
+		{
+			name: "all columns",
+			data: "sku,name,description,unit,tax_category,price_EUR,price_usd\n" +
+				"CONS-1,Consulting,Per started hour,h,standard,120,130.50\n" +
+				"BOOK-1,Handbook,,pcs,reduced,19.90,\n",
+			want: []string{
+				"CONS-1|Consulting|Per started hour|h|standard|EUR 120, USD 130.5",
+				"BOOK-1|Handbook||pcs|reduced|EUR 19.9",
+			},
+		},
+		{
+			name: "headers the way spreadsheets write them",
+			data: "\xef\xbb\xbfSKU, Name ,Tax Category,Price-CHF\nsupport/1, Support , exempt ,0\n",
+			want: []string{"support/1|Support|||exempt|CHF 0"},
+		},
+		{
+			name: "only the required columns",
+			data: "name,sku\nHosting,HOST-1\n",
+			want: []string{"HOST-1|Hosting||||"},
+		},
+		{
+			name: "short rows",
+			data: "sku,name,unit,price_EUR\nA-1,Apples\n",
+			want: []string{"A-1|Apples||||"},
+		},
+		{
+			name: "quoted fields",
+			data: "sku,name,description\n\"W-1\",\"Widget, large\",\"Two\nlines\"\n",
+			want: []string{"W-1|Widget, large|Two\nlines|||"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, err := Parse([]byte(test.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := []string{}
+			for _, item := range items {
+				prices := []string{}
+				for _, price := range item.Prices {
+					prices = append(prices, price.Currency+" "+price.Amount)
+				}
+				got = append(got, fmt.Sprintf("%s|%s|%s|%s|%s|%s", item.Sku, item.Name, item.Description, item.Unit, item.TaxCategory, strings.Join(prices, ", ")))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("items = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// rowsOf returns a file of n valid items.
+func rowsOf(n int) string {
+	var b strings.Builder
+	b.WriteString("sku,name\n")
+	for i := range n {
+		fmt.Fprintf(&b, "ITEM-%d,Item %d\n", i, i)
+	}
+	return b.String()
+}
+
+func TestParseInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"no sku column", "name,price_EUR\nConsulting,120\n"},
+		{"no name column", "sku,price_EUR\nCONS-1,120\n"},
+		{"header only", "sku,name\n"},
+		{"broken quotes", "sku,name\n\"CONS-1,Consulting\n"},
+		{"too many rows", rowsOf(MAX_IMPORT_ROWS + 1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse([]byte(test.data))
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("Parse() error = %v, want %v", err, ErrInvalidFile)
+			}
+		})
+	}
+}
+
+func TestParseInvalidRows(t *testing.T) {
+	data := "sku,name,tax_category,price_EUR,price_XYZ\n" +
+		"CONS-1,Consulting,standard,120,\n" +
+		"CONS 2,,,,\n" +
+		"CONS-3,Training,luxury,-5,\n" +
+		"CONS-1,Consulting again,,,\n" +
+		"CONS-4,Workshop,,abc,1\n"
+
+	_, err := Parse([]byte(data))
+	var app_err *apperr.Error
+	if !errors.As(err, &app_err) {
+		t.Fatalf("Parse() error = %v, want a field error", err)
+	}
+
+	fields := []string{}
+	for _, field := range app_err.Fields {
+		fields = append(fields, field.Field)
+	}
+	want := []string{
+		"line 3.sku",
+		"line 3.name",
+		"line 4.prices[0].amount",
+		"line 4.taxCategory",
+		"line 5.sku",
+		"line 6.prices[0].amount",
+		"line 6.prices[1].currency",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("invalid fields = %q, want %q", fields, want)
+	}
+}
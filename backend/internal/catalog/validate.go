@@ -0,0 +1,108 @@
+package catalog
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MAX_SKU_LENGTH         = 64
+	MAX_NAME_LENGTH        = 256
+	MAX_DESCRIPTION_LENGTH = 10_000
+	MAX_UNIT_LENGTH        = 32
+)
+
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+func validatePrices(prices []*pb.Price) []apperr.FieldError {
+	fields := []apperr.FieldError{}
+	seen := map[string]bool{}
+	for i, price := range prices {
+		field := fmt.Sprintf("prices[%d]", i)
+		if price == nil {
+			fields = append(fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+
+		price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+		if !money.Valid(price.Currency) {
+			fields = append(fields, apperr.Field(field+".currency", "must be an ISO 4217 code"))
+		} else if seen[price.Currency] {
+			fields = append(fields, apperr.Field(field+".currency", "must be given once"))
+		}
+		seen[price.Currency] = true
+
+		amount, err := decimal.NewFromString(strings.TrimSpace(price.Amount))
+		if err != nil {
+			fields = append(fields, apperr.Field(field+".amount", "must be a decimal number"))
+		} else if amount.IsNegative() {
+			fields = append(fields, apperr.Field(field+".amount", "must not be negative"))
+		} else {
+			price.Amount = amount.String()
+		}
+	}
+	return fields
+}
+
+// Validate checks the request and normalizes it in place. Prices are sorted
+// by currency.
+func Validate(req *pb.SaveCatalogItemRequest) error {
+	fields := []apperr.FieldError{}
+
+	req.Sku = strings.TrimSpace(req.Sku)
+	if !skuPattern.MatchString(req.Sku) {
+		fields = append(fields, apperr.Field("sku", "must be letters, digits, dots, dashes, underscores or slashes"))
+	} else if len(req.Sku) > MAX_SKU_LENGTH {
+		fields = append(fields, apperr.Field("sku", fmt.Sprintf("must not be longer than %d bytes", MAX_SKU_LENGTH)))
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		fields = append(fields, apperr.Field("name", "must not be empty"))
+	} else if len(req.Name) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("name", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	req.Description = strings.TrimSpace(req.Description)
+	if len(req.Description) > MAX_DESCRIPTION_LENGTH {
+		fields = append(fields, apperr.Field("description", fmt.Sprintf("must not be longer than %d bytes", MAX_DESCRIPTION_LENGTH)))
+	}
+
+	req.Unit = strings.TrimSpace(req.Unit)
+	if len(req.Unit) > MAX_UNIT_LENGTH {
+		fields = append(fields, apperr.Field("unit", fmt.Sprintf("must not be longer than %d bytes", MAX_UNIT_LENGTH)))
+	}
+
+	fields = append(fields, validatePrices(req.Prices)...)
+	slices.SortFunc(req.Prices, func(a, b *pb.Price) int {
+		return strings.Compare(a.GetCurrency(), b.GetCurrency())
+	})
+
+	req.TaxCategory = strings.TrimSpace(req.TaxCategory)
+	if req.TaxCategory != "" && !slices.Contains(tax.Categories, req.TaxCategory) {
+		fields = append(fields, apperr.Field("taxCategory", "must be one of "+strings.Join(tax.Categories, ", ")))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Catalog item is invalid", fields...)
+	}
+	return nil
+}
+
+// PriceIn returns the default price of the item in the currency.
+func PriceIn(item *pb.CatalogItem, currency string) (string, bool) {
+	for _, price := range item.Prices {
+		if price.Currency == currency {
+			return price.Amount, true
+		}
+	}
+	return "", false
+}
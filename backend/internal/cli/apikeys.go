@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apikey"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+)
+
+func runApiKeys(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return apiKeysList(env, args[1:])
+	case "create":
+		return apiKeysCreate(env, args[1:])
+	case "revoke":
+		return apiKeysRevoke(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown apikeys command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func openApiKeys() (*apikey.ApiKeys, func(), error) {
+	db, err := openDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ks, err := apikey.NewApiKeys(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return ks, func() { db.Close() }, nil
+}
+
+func formatOptionalTime(unix int64) string {
+	if unix == 0 {
+		return "-"
+	}
+	return formatTime(unix)
+}
+
+func formatOptionalId(id uint32) string {
+	if id == 0 {
+		return "-"
+	}
+	return fmt.Sprint(id)
+}
+
+func printApiKeys(out *output, keys []*pb.ApiKey) error {
+	rows := [][]string{}
+	for _, k := range keys {
+		rows = append(rows, []string{
+			fmt.Sprint(k.Id),
+			fmt.Sprint(k.UserId),
+			formatOptionalId(k.WorkspaceId),
+			k.Name,
+			k.Prefix + "…",
+			strings.Join(k.Scopes, ","),
+			formatOptionalTime(k.ExpiresAt),
+			formatOptionalTime(k.LastUsedAt),
+			formatOptionalTime(k.RevokedAt),
+		})
+	}
+
+	header := []string{"ID", "USER", "WORKSPACE", "NAME", "PREFIX", "SCOPES", "EXPIRES", "LAST USED", "REVOKED"}
+	return out.print(keys, header, rows)
+}
+
+func apiKeysList(env *Env, args []string) error {
+	fs := newFlagSet(env, "apikeys list")
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	ks, close, err := openApiKeys()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	keys, err := ks.ListAll()
+	if err != nil {
+		return err
+	}
+
+	return printApiKeys(out, keys)
+}
+
+func apiKeysCreate(env *Env, args []string) error {
+	fs := newFlagSet(env, "apikeys create")
+	out := addOutputFlag(fs, env)
+	scopes := fs.String("scopes", apikey.SCOPE_TEMPLATES_READ, "comma separated scopes: "+strings.Join(apikey.Scopes, ", "))
+	expires_in := fs.Duration("expires-in", 0, "lifetime of the key, e.g. 720h; 0 never expires")
+	workspace_ref := fs.String("w", "", "workspace ID or slug the key is bound to; empty allows any workspace of the user")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%w: expected <user-id> <name>", ErrUsage)
+	}
+
+	ids, err := parseIds(fs.Args()[:1])
+	if err != nil {
+		return err
+	}
+
+	var expires_at int64
+	if *expires_in > 0 {
+		expires_at = time.Now().Add(*expires_in).Unix()
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var workspace_id uint32
+	if *workspace_ref != "" {
+		if workspace_id, err = resolveWorkspace(db, *workspace_ref); err != nil {
+			return err
+		}
+	}
+
+	ks, err := apikey.NewApiKeys(db)
+	if err != nil {
+		return err
+	}
+
+	key, token, err := ks.Create(
+		uint32(ids[0]),
+		workspace_id,
+		strings.Join(fs.Args()[1:], " "),
+		strings.Split(*scopes, ","),
+		expires_at,
+	)
+	if err != nil {
+		return err
+	}
+
+	if out.format == OUTPUT_JSON {
+		return out.print(&pb.CreateApiKeyResponse{ApiKey: key, Token: token}, nil, nil)
+	}
+
+	if err = printApiKeys(out, []*pb.ApiKey{key}); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "\ntoken (shown only once): %s\n", token)
+	return nil
+}
+
+func apiKeysRevoke(env *Env, args []string) error {
+	fs := newFlagSet(env, "apikeys revoke")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected <id>", ErrUsage)
+	}
+
+	ks, close, err := openApiKeys()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if err = ks.Revoke(ids[0], 0); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "revoked API key %d\n", ids[0])
+	return nil
+}
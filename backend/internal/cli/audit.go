@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"invoice-manager/main/internal/audit"
+	"net/url"
+	"os"
+)
+
+func runAudit(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return auditList(env, args[1:])
+	case "export":
+		return auditExport(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown audit command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+// addFilterFlags takes the same filters as the audit log endpoint.
+func addFilterFlags(fs *flag.FlagSet) url.Values {
+	values := url.Values{}
+	for _, name := range []string{"actor", "action", "target_type", "target_id", "from", "to", "limit", "offset"} {
+		fs.Func(name, "filter by "+name, func(value string) error {
+			values.Set(name, value)
+			return nil
+		})
+	}
+	return values
+}
+
+func openAuditLog(workspace_ref string, values url.Values) (*audit.Log, *audit.Filter, func(), error) {
+	filter, err := audit.ParseFilter(values)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrUsage, err)
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if filter.WorkspaceId, err = resolveWorkspace(db, workspace_ref); err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	l, err := audit.NewLog(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	return l, filter, func() { db.Close() }, nil
+}
+
+func auditList(env *Env, args []string) error {
+	fs := newFlagSet(env, "audit list")
+	out := addOutputFlag(fs, env)
+	workspace_ref := addWorkspaceFlag(fs)
+	values := addFilterFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	l, filter, close, err := openAuditLog(*workspace_ref, values)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	entries, err := l.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, e := range entries {
+		actor := e.Source
+		if e.ActorUserId != 0 {
+			actor = fmt.Sprintf("user %d", e.ActorUserId)
+		}
+		if e.ActorApiKeyId != 0 {
+			actor += fmt.Sprintf(" (key %d)", e.ActorApiKeyId)
+		}
+
+		changes, err := json.Marshal(e.Changes)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, []string{
+			fmt.Sprint(e.Id),
+			formatTime(e.CreatedAt),
+			actor,
+			e.Ip,
+			e.Action,
+			fmt.Sprintf("%s %d", e.TargetType, e.TargetId),
+			string(changes),
+		})
+	}
+
+	header := []string{"ID", "TIME", "ACTOR", "IP", "ACTION", "TARGET", "CHANGES"}
+	return out.print(entries, header, rows)
+}
+
+func auditExport(env *Env, args []string) error {
+	fs := newFlagSet(env, "audit export")
+	dest := fs.String("f", "-", "CSV file to write, - for stdout")
+	workspace_ref := addWorkspaceFlag(fs)
+	values := addFilterFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	l, filter, close, err := openAuditLog(*workspace_ref, values)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *dest == "-" {
+		return l.ExportCsv(env.Stdout, *filter)
+	}
+
+	f, err := os.OpenFile(*dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0660)
+	if err != nil {
+		return err
+	}
+
+	if err = l.ExportCsv(f, *filter); err != nil {
+		f.Close()
+		os.Remove(*dest)
+		return err
+	}
+
+	return f.Close()
+}
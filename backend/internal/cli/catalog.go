@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/catalog"
+	"os"
+)
+
+func runCatalog(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "import":
+		return catalogImport(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown catalog command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func catalogImport(env *Env, args []string) error {
+	fs := newFlagSet(env, "catalog import")
+	workspace_ref := addWorkspaceFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.Stderr, "usage: invoicer catalog import [-w workspace] <file.csv>")
+		return ErrUsage
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspace_id, err := resolveWorkspace(db, *workspace_ref)
+	if err != nil {
+		return err
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		return err
+	}
+	c, err := catalog.NewCatalog(db, audit_log)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	res, err := c.Import(context.Background(), workspace_id, data, audit.CliActor)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "created %d and updated %d catalog items\n", res.Created, res.Updated)
+	return nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/template"
+	"io"
+	"os"
+)
+
+var (
+	ErrUsage = errors.New("invalid usage")
+)
+
+const usage = `Usage: invoicer <command> [arguments]
+
+Commands:
+  serve                            start the HTTP server
+  templates list                   list templates of a workspace, see -h for -w
+  templates upload <file.pdf>...   convert PDFs and store them as templates
+  templates rename <id> <name>     rename a template
+  templates delete <id>...         delete templates and their files
+  templates export [id...]         export templates into a zip archive
+  templates import <archive.zip>   import templates from an exported archive
+  users list                       list users
+  users create <email> [name]      create a user, the password is read from stdin
+  users passwd <id>                set a new password, read from stdin
+  users delete <id>                delete a user and their sessions
+  apikeys list                     list API keys of all users
+  apikeys create <user-id> <name>  create an API key, see -h for scopes and expiry
+  apikeys revoke <id>              revoke an API key
+  workspaces list                  list workspaces
+  workspaces create <slug> <name>  create a workspace, see -h for the base currency
+  workspaces members <workspace>   list members of a workspace
+  workspaces add-member <workspace> <user-id>
+                                   add a user to a workspace
+  workspaces remove-member <workspace> <user-id>
+                                   remove a user from a workspace
+  workspaces set-role <workspace> <user-id> <role>
+                                   change the role of a member
+  workspaces roles <workspace>     list built-in and custom roles
+  rates import <file>              import exchange rates from ECB XML or CSV
+  rates list                       list exchange rates, see -h for filters
+  catalog import <file.csv>        create or update catalog items by SKU
+  audit list                       list audit log entries, see -h for filters
+  audit export                     export audit log entries as CSV
+  db migrate                       apply pending database migrations
+  db backup <file>                 write a consistent copy of the database
+  db restore <file>                replace the database with a backup
+  gc                               remove orphaned files and stale uploads
+
+Run "invoicer <command> -h" for the flags of a command.
+`
+
+type Env struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	Serve  func() error
+}
+
+func Run(env *Env, args []string) error {
+	err := run(env, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func run(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "serve":
+		return env.Serve()
+	case "templates":
+		return runTemplates(env, args[1:])
+	case "users":
+		return runUsers(env, args[1:])
+	case "apikeys":
+		return runApiKeys(env, args[1:])
+	case "workspaces":
+		return runWorkspaces(env, args[1:])
+	case "rates":
+		return runRates(env, args[1:])
+	case "catalog":
+		return runCatalog(env, args[1:])
+	case "audit":
+		return runAudit(env, args[1:])
+	case "db":
+		return runDb(env, args[1:])
+	case "gc":
+		return runGc(env, args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(env.Stdout, usage)
+		return nil
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func DefaultEnv(serve func() error) *Env {
+	return &Env{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr, Serve: serve}
+}
+
+func newFlagSet(env *Env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("invoicer "+name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return ErrUsage
+	}
+	return nil
+}
+
+func openDatabase() (*sql.DB, error) {
+	db, err := database.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = database.Migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func openTemplates(workspace_ref string) (*template.Templates, uint32, func(), error) {
+	db, err := openDatabase()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	workspace_id, err := resolveWorkspace(db, workspace_ref)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	if err = template.CreateStaticDirs(); err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	quotas, err := quota.NewQuotas(db, quota.LimitsFromEnv())
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	ts, err := template.NewTemplates(db, audit_log, quotas)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	return ts, workspace_id, func() {
+		ts.Close()
+		db.Close()
+	}, nil
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/constants"
+	"invoice-manager/main/internal/database"
+	"time"
+)
+
+func runDb(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "migrate":
+		return dbMigrate(env, args[1:])
+	case "backup":
+		return dbBackup(env, args[1:])
+	case "restore":
+		return dbRestore(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown db command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func printMigrations(out *output, migrations []database.Migration) error {
+	type row struct {
+		Version int    `json:"version"`
+		Name    string `json:"name"`
+	}
+
+	data := []row{}
+	rows := [][]string{}
+	for _, m := range migrations {
+		data = append(data, row{Version: m.Version, Name: m.Name})
+		rows = append(rows, []string{fmt.Sprint(m.Version), m.Name})
+	}
+
+	return out.print(data, []string{"VERSION", "NAME"}, rows)
+}
+
+func dbMigrate(env *Env, args []string) error {
+	fs := newFlagSet(env, "db migrate")
+	out := addOutputFlag(fs, env)
+	dry_run := fs.Bool("dry-run", false, "only list pending migrations")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *dry_run {
+		pending, err := database.Pending(db)
+		if err != nil {
+			return err
+		}
+		return printMigrations(out, pending)
+	}
+
+	applied, err := database.Migrate(db)
+	if print_err := printMigrations(out, applied); err == nil {
+		err = print_err
+	}
+	return err
+}
+
+func dbBackup(env *Env, args []string) error {
+	fs := newFlagSet(env, "db backup")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	dest := fs.Arg(0)
+	if dest == "" {
+		dest = fmt.Sprintf("%s.%s.backup", constants.DB_FILE, time.Now().Format("20060102-150405"))
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err = database.Backup(db, dest); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "backed up %s to %s\n", constants.DB_FILE, dest)
+	return nil
+}
+
+func dbRestore(env *Env, args []string) error {
+	fs := newFlagSet(env, "db restore")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected <file>", ErrUsage)
+	}
+
+	previous, err := database.Restore(fs.Arg(0), constants.DB_FILE)
+	if err != nil {
+		return err
+	}
+
+	if previous != "" {
+		fmt.Fprintf(env.Stdout, "previous database moved to %s\n", previous)
+	}
+	fmt.Fprintf(env.Stdout, "restored %s from %s\n", constants.DB_FILE, fs.Arg(0))
+	return nil
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/template"
+	"time"
+)
+
+type gcReport struct {
+	*template.GcReport
+	ExpiredSessions int64 `json:"expiredSessions"`
+}
+
+func runGc(env *Env, args []string) error {
+	fs := newFlagSet(env, "gc")
+	out := addOutputFlag(fs, env)
+	dry_run := fs.Bool("dry-run", false, "report what would be removed without deleting anything")
+	max_temp_age := fs.Duration("temp-age", time.Hour, "minimum age of temporary uploads before they are removed")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	// Garbage is collected across all workspaces, the reference only has
+	// to resolve.
+	ts, _, close, err := openTemplates(DEFAULT_WORKSPACE_REF)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	files_report, err := ts.CollectGarbage(context.Background(), *dry_run, *max_temp_age)
+	if err != nil {
+		return err
+	}
+	report := gcReport{GcReport: files_report}
+
+	if !*dry_run {
+		us, close_users, err := openUsers()
+		if err != nil {
+			return err
+		}
+		defer close_users()
+
+		if report.ExpiredSessions, err = us.DeleteExpiredSessions(); err != nil {
+			return err
+		}
+	}
+
+	rows := [][]string{}
+	for _, path := range report.OrphanedFiles {
+		rows = append(rows, []string{"orphaned", path})
+	}
+	for _, path := range report.StaleTempFiles {
+		rows = append(rows, []string{"stale upload", path})
+	}
+	for _, path := range report.MissingFiles {
+		rows = append(rows, []string{"missing", path})
+	}
+
+	if err = out.print(report, []string{"KIND", "PATH"}, rows); err != nil {
+		return err
+	}
+
+	if out.format == OUTPUT_TABLE {
+		verb := "freed"
+		if *dry_run {
+			verb = "would free"
+		}
+		fmt.Fprintf(env.Stdout, "%s %d bytes\n", verb, report.FreedBytes)
+		if !*dry_run {
+			fmt.Fprintf(env.Stdout, "removed %d expired sessions\n", report.ExpiredSessions)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+)
+
+type output struct {
+	format string
+	w      io.Writer
+}
+
+func addOutputFlag(fs *flag.FlagSet, env *Env) *output {
+	out := &output{w: env.Stdout}
+	fs.StringVar(&out.format, "o", OUTPUT_TABLE, "output format: table or json")
+	return out
+}
+
+func (o *output) validate() error {
+	if o.format != OUTPUT_TABLE && o.format != OUTPUT_JSON {
+		return fmt.Errorf("%w: unknown output format %q", ErrUsage, o.format)
+	}
+	return nil
+}
+
+// print writes data as JSON or, for the table format, the given header and
+// rows. Commands always pass both so the two formats show the same records.
+func (o *output) print(data interface{}, header []string, rows [][]string) error {
+	if o.format == OUTPUT_JSON {
+		enc := json.NewEncoder(o.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	}
+
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
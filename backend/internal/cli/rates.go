@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/exchange"
+	"net/url"
+	"os"
+)
+
+func runRates(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "import":
+		return ratesImport(env, args[1:])
+	case "list":
+		return ratesList(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown rates command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func openRates(workspace_ref string) (*exchange.Rates, uint32, func(), error) {
+	db, err := openDatabase()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	workspace_id, err := resolveWorkspace(db, workspace_ref)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+	rs, err := exchange.NewRates(db, audit_log)
+	if err != nil {
+		db.Close()
+		return nil, 0, nil, err
+	}
+
+	return rs, workspace_id, func() {
+		rs.Close()
+		db.Close()
+	}, nil
+}
+
+func ratesImport(env *Env, args []string) error {
+	fs := newFlagSet(env, "rates import")
+	workspace_ref := addWorkspaceFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(env.Stderr, "usage: invoicer rates import [-w workspace] <file.xml|file.csv>")
+		return ErrUsage
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	rs, workspace_id, close, err := openRates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	res, err := rs.Import(context.Background(), workspace_id, data, audit.CliActor)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "imported %d rates from %s to %s\n", res.Imported, res.From, res.To)
+	return nil
+}
+
+func ratesList(env *Env, args []string) error {
+	fs := newFlagSet(env, "rates list")
+	out := addOutputFlag(fs, env)
+	workspace_ref := addWorkspaceFlag(fs)
+	values := url.Values{}
+	for _, name := range []string{"base", "quote", "from", "to", "limit"} {
+		fs.Func(name, "filter by "+name, func(value string) error {
+			values.Set(name, value)
+			return nil
+		})
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	filter, err := exchange.ParseFilter(values)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUsage, err)
+	}
+
+	rs, workspace_id, close, err := openRates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	filter.WorkspaceId = workspace_id
+	rates, err := rs.List(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, r := range rates {
+		rows = append(rows, []string{r.Date, r.Base, r.Quote, r.Rate, r.Source})
+	}
+
+	return out.print(rates, []string{"DATE", "BASE", "QUOTE", "RATE", "SOURCE"}, rows)
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/template"
+	pb "invoice-manager/main/proto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runTemplates(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return templatesList(env, args[1:])
+	case "upload":
+		return templatesUpload(env, args[1:])
+	case "rename":
+		return templatesRename(env, args[1:])
+	case "delete":
+		return templatesDelete(env, args[1:])
+	case "export":
+		return templatesExport(env, args[1:])
+	case "import":
+		return templatesImport(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown templates command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).Format(time.DateTime)
+}
+
+func printTemplates(out *output, templates []*pb.Template) error {
+	rows := [][]string{}
+	for _, t := range templates {
+		rows = append(rows, []string{
+			fmt.Sprint(t.Id),
+			t.Name,
+			fmt.Sprint(t.Size),
+			formatTime(t.CreatedAt),
+			formatTime(t.UpdatedAt),
+			t.Path,
+		})
+	}
+
+	return out.print(templates, []string{"ID", "NAME", "SIZE", "CREATED", "UPDATED", "PATH"}, rows)
+}
+
+func parseIds(args []string) ([]int, error) {
+	ids := []int{}
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid ID %q", ErrUsage, arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func templatesList(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates list")
+	workspace_ref := addWorkspaceFlag(fs)
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	templates, err := ts.List(context.Background(), workspace_id)
+	if err != nil {
+		return err
+	}
+
+	data := []*pb.Template{}
+	for _, t := range templates {
+		data = append(data, t.Public())
+	}
+
+	return printTemplates(out, data)
+}
+
+func templatesUpload(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates upload")
+	workspace_ref := addWorkspaceFlag(fs)
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: no PDF files given", ErrUsage)
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	uploaded := []*pb.Template{}
+	for _, path := range fs.Args() {
+		new_template, err := uploadFile(ts, workspace_id, path)
+		if err != nil {
+			printTemplates(out, uploaded)
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		uploaded = append(uploaded, new_template.Public())
+	}
+
+	return printTemplates(out, uploaded)
+}
+
+func uploadFile(ts *template.Templates, workspace_id uint32, path string) (*template.Template, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.CreateFromPdf(context.Background(), workspace_id, info.Name(), info.Size(), f, audit.CliActor)
+}
+
+func templatesRename(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates rename")
+	workspace_ref := addWorkspaceFlag(fs)
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%w: expected <id> <name>", ErrUsage)
+	}
+
+	ids, err := parseIds(fs.Args()[:1])
+	if err != nil {
+		return err
+	}
+
+	name := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", ErrUsage)
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	updated_template, err := ts.UpdateName(context.Background(), workspace_id, ids[0], name, audit.CliActor)
+	if err != nil {
+		return err
+	}
+
+	return printTemplates(out, []*pb.Template{updated_template.Public()})
+}
+
+func templatesDelete(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates delete")
+	workspace_ref := addWorkspaceFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: no template IDs given", ErrUsage)
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	for _, id := range ids {
+		if err = ts.Delete(context.Background(), workspace_id, id, audit.CliActor); err != nil {
+			return fmt.Errorf("template %d: %w", id, err)
+		}
+		fmt.Fprintf(env.Stdout, "deleted template %d\n", id)
+	}
+
+	return nil
+}
+
+func templatesExport(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates export")
+	workspace_ref := addWorkspaceFlag(fs)
+	dest := fs.String("f", "templates.zip", "archive file to write, - for stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *dest == "-" {
+		return ts.Export(context.Background(), env.Stdout, workspace_id, ids)
+	}
+
+	f, err := os.OpenFile(*dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0660)
+	if err != nil {
+		return err
+	}
+
+	if err = ts.Export(context.Background(), f, workspace_id, ids); err != nil {
+		f.Close()
+		os.Remove(*dest)
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stderr, "exported templates to %s\n", *dest)
+	return nil
+}
+
+func templatesImport(env *Env, args []string) error {
+	fs := newFlagSet(env, "templates import")
+	workspace_ref := addWorkspaceFlag(fs)
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected <archive.zip>", ErrUsage)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	ts, workspace_id, close, err := openTemplates(*workspace_ref)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	imported, err := ts.Import(context.Background(), f, info.Size(), workspace_id, audit.CliActor)
+	data := []*pb.Template{}
+	for _, t := range imported {
+		data = append(data, t.Public())
+	}
+
+	if print_err := printTemplates(out, data); err == nil {
+		err = print_err
+	}
+	return err
+}
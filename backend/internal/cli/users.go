@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"strings"
+)
+
+func runUsers(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return usersList(env, args[1:])
+	case "create":
+		return usersCreate(env, args[1:])
+	case "passwd":
+		return usersPasswd(env, args[1:])
+	case "delete":
+		return usersDelete(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown users command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func openUsers() (*user.Users, func(), error) {
+	db, err := openDatabase()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	us, err := user.NewUsers(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return us, func() { db.Close() }, nil
+}
+
+func readPassword(env *Env) (string, error) {
+	fmt.Fprint(env.Stderr, "Password: ")
+	line, err := bufio.NewReader(env.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("couldn't read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func printUsers(out *output, users []*pb.User) error {
+	rows := [][]string{}
+	for _, u := range users {
+		rows = append(rows, []string{fmt.Sprint(u.Id), u.Email, u.Name, formatTime(u.CreatedAt)})
+	}
+
+	return out.print(users, []string{"ID", "EMAIL", "NAME", "CREATED"}, rows)
+}
+
+func usersList(env *Env, args []string) error {
+	fs := newFlagSet(env, "users list")
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	us, close, err := openUsers()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	users, err := us.List()
+	if err != nil {
+		return err
+	}
+
+	return printUsers(out, users)
+}
+
+func usersCreate(env *Env, args []string) error {
+	fs := newFlagSet(env, "users create")
+	out := addOutputFlag(fs, env)
+	workspace_ref := addWorkspaceFlag(fs)
+	role := addRoleFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: expected <email> [name]", ErrUsage)
+	}
+
+	password, err := readPassword(env)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	workspace_id, err := resolveWorkspace(db, *workspace_ref)
+	if err != nil {
+		return err
+	}
+
+	us, err := user.NewUsers(db)
+	if err != nil {
+		return err
+	}
+
+	ws, err := workspace.NewWorkspaces(db)
+	if err != nil {
+		return err
+	}
+
+	rs, err := rbac.NewRoles(db)
+	if err != nil {
+		return err
+	}
+
+	member_role, err := memberRole(ws, rs, workspace_id, *role)
+	if err != nil {
+		return err
+	}
+
+	new_user, err := us.Create(fs.Arg(0), strings.Join(fs.Args()[1:], " "), password)
+	if err != nil {
+		return err
+	}
+
+	if _, err = ws.AddMember(workspace_id, new_user.Id, member_role); err != nil {
+		return err
+	}
+
+	return printUsers(out, []*pb.User{new_user})
+}
+
+func usersPasswd(env *Env, args []string) error {
+	fs := newFlagSet(env, "users passwd")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected <id>", ErrUsage)
+	}
+
+	password, err := readPassword(env)
+	if err != nil {
+		return err
+	}
+
+	us, close, err := openUsers()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if err = us.SetPassword(ids[0], password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "password changed for user %d\n", ids[0])
+	return nil
+}
+
+func usersDelete(env *Env, args []string) error {
+	fs := newFlagSet(env, "users delete")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIds(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected <id>", ErrUsage)
+	}
+
+	us, close, err := openUsers()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if err = us.Delete(ids[0]); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "deleted user %d\n", ids[0])
+	return nil
+}
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"strings"
+)
+
+const DEFAULT_WORKSPACE_REF = "default"
+
+func addWorkspaceFlag(fs *flag.FlagSet) *string {
+	return fs.String("w", DEFAULT_WORKSPACE_REF, "workspace ID or slug")
+}
+
+func resolveWorkspace(db *sql.DB, ref string) (uint32, error) {
+	ws, err := workspace.NewWorkspaces(db)
+	if err != nil {
+		return 0, err
+	}
+
+	found, err := ws.RetrieveByRef(ref)
+	if err != nil {
+		return 0, fmt.Errorf("workspace %q: %w", ref, err)
+	}
+
+	return found.Id, nil
+}
+
+func addRoleFlag(fs *flag.FlagSet) *string {
+	return fs.String("role", "", "role of the member; the first member of a workspace defaults to owner, others to "+rbac.DEFAULT_ROLE)
+}
+
+// memberRole checks that the role exists in the workspace, or picks the
+// default one if it is empty.
+func memberRole(ws *workspace.Workspaces, rs *rbac.Roles, workspace_id uint32, role string) (string, error) {
+	if role == "" {
+		members, err := ws.ListMembers(workspace_id)
+		if err != nil {
+			return "", err
+		}
+		if len(members) == 0 {
+			return rbac.ROLE_OWNER, nil
+		}
+		return rbac.DEFAULT_ROLE, nil
+	}
+
+	if _, err := rs.RetrieveByName(workspace_id, role); err != nil {
+		return "", err
+	}
+	return role, nil
+}
+
+func runWorkspaces(env *Env, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.Stderr, usage)
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return workspacesList(env, args[1:])
+	case "create":
+		return workspacesCreate(env, args[1:])
+	case "members":
+		return workspacesMembers(env, args[1:])
+	case "add-member":
+		return workspacesAddMember(env, args[1:])
+	case "remove-member":
+		return workspacesRemoveMember(env, args[1:])
+	case "set-role":
+		return workspacesSetRole(env, args[1:])
+	case "roles":
+		return workspacesRoles(env, args[1:])
+	}
+
+	fmt.Fprintf(env.Stderr, "unknown workspaces command %q\n\n%s", args[0], usage)
+	return ErrUsage
+}
+
+func openWorkspaces() (*workspace.Workspaces, *rbac.Roles, func(), error) {
+	db, err := openDatabase()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ws, err := workspace.NewWorkspaces(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	rs, err := rbac.NewRoles(db)
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, err
+	}
+
+	return ws, rs, func() { db.Close() }, nil
+}
+
+func printWorkspaces(out *output, workspaces []*pb.Workspace) error {
+	rows := [][]string{}
+	for _, w := range workspaces {
+		rows = append(rows, []string{fmt.Sprint(w.Id), w.Slug, w.Name, w.BaseCurrency, formatTime(w.CreatedAt)})
+	}
+
+	return out.print(workspaces, []string{"ID", "SLUG", "NAME", "CURRENCY", "CREATED"}, rows)
+}
+
+func printMembers(out *output, members []*pb.WorkspaceMember) error {
+	rows := [][]string{}
+	for _, m := range members {
+		rows = append(rows, []string{
+			fmt.Sprint(m.User.Id),
+			m.User.Email,
+			m.User.Name,
+			m.Role,
+			formatTime(m.JoinedAt),
+		})
+	}
+
+	return out.print(members, []string{"USER", "EMAIL", "NAME", "ROLE", "JOINED"}, rows)
+}
+
+func workspacesList(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces list")
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	ws, _, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	workspaces, err := ws.List()
+	if err != nil {
+		return err
+	}
+
+	return printWorkspaces(out, workspaces)
+}
+
+func workspacesCreate(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces create")
+	out := addOutputFlag(fs, env)
+	owner := fs.Int("owner", 0, "ID of a user to add as the first member")
+	currency := fs.String("currency", workspace.DEFAULT_BASE_CURRENCY, "ISO 4217 code of the currency reports convert to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%w: expected <slug> <name>", ErrUsage)
+	}
+
+	ws, _, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	new_workspace, err := ws.Create(fs.Arg(0), strings.Join(fs.Args()[1:], " "), *currency, uint32(*owner))
+	if err != nil {
+		return err
+	}
+
+	return printWorkspaces(out, []*pb.Workspace{new_workspace})
+}
+
+func workspacesMembers(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces members")
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected <workspace>", ErrUsage)
+	}
+
+	ws, _, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	found, err := ws.RetrieveByRef(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("workspace %q: %w", fs.Arg(0), err)
+	}
+
+	members, err := ws.ListMembers(found.Id)
+	if err != nil {
+		return err
+	}
+
+	return printMembers(out, members)
+}
+
+func parseMemberArgs(args []string, ws *workspace.Workspaces) (*pb.Workspace, uint32, error) {
+	if len(args) != 2 {
+		return nil, 0, fmt.Errorf("%w: expected <workspace> <user-id>", ErrUsage)
+	}
+
+	ids, err := parseIds(args[1:])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	found, err := ws.RetrieveByRef(args[0])
+	if err != nil {
+		return nil, 0, fmt.Errorf("workspace %q: %w", args[0], err)
+	}
+
+	return found, uint32(ids[0]), nil
+}
+
+func workspacesAddMember(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces add-member")
+	role := addRoleFlag(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ws, rs, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	found, user_id, err := parseMemberArgs(fs.Args(), ws)
+	if err != nil {
+		return err
+	}
+
+	member_role, err := memberRole(ws, rs, found.Id, *role)
+	if err != nil {
+		return err
+	}
+
+	if _, err = ws.AddMember(found.Id, user_id, member_role); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "added user %d to workspace %s as %s\n", user_id, found.Slug, member_role)
+	return nil
+}
+
+func workspacesRemoveMember(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces remove-member")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ws, _, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	found, user_id, err := parseMemberArgs(fs.Args(), ws)
+	if err != nil {
+		return err
+	}
+
+	if err = ws.RemoveMember(found.Id, user_id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "removed user %d from workspace %s\n", user_id, found.Slug)
+	return nil
+}
+
+func workspacesSetRole(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces set-role")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 3 {
+		return fmt.Errorf("%w: expected <workspace> <user-id> <role>", ErrUsage)
+	}
+	role := fs.Arg(2)
+
+	ws, rs, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	found, user_id, err := parseMemberArgs(fs.Args()[:2], ws)
+	if err != nil {
+		return err
+	}
+
+	if _, err = rs.RetrieveByName(found.Id, role); err != nil {
+		return err
+	}
+
+	if _, err = ws.SetMemberRole(found.Id, user_id, role); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stdout, "user %d is now %s in workspace %s\n", user_id, role, found.Slug)
+	return nil
+}
+
+func workspacesRoles(env *Env, args []string) error {
+	fs := newFlagSet(env, "workspaces roles")
+	out := addOutputFlag(fs, env)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected <workspace>", ErrUsage)
+	}
+
+	ws, rs, close, err := openWorkspaces()
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	found, err := ws.RetrieveByRef(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("workspace %q: %w", fs.Arg(0), err)
+	}
+
+	roles, err := rs.List(found.Id)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{}
+	for _, r := range roles {
+		kind := "custom"
+		if r.BuiltIn {
+			kind = "built-in"
+		}
+		rows = append(rows, []string{r.Name, kind, strings.Join(r.Permissions, ",")})
+	}
+
+	return out.print(roles, []string{"NAME", "KIND", "PERMISSIONS"}, rows)
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+type ClientApi struct {
+	clients *Clients
+}
+
+func (ca *ClientApi) GetClientsList(w http.ResponseWriter, req *http.Request) {
+	filter, err := ParseFilter(req.URL.Query())
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+	filter.WorkspaceId = workspace.IdFromContext(req.Context())
+
+	clients, total, err := ca.clients.Search(req.Context(), filter)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading clients"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetClientsResponse{Clients: clients, Total: total})
+}
+
+func (ca *ClientApi) GetClient(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	client, err := ca.clients.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading client"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.ClientResponse{Client: client})
+}
+
+func (ca *ClientApi) CreateClient(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveClientRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	client, err := ca.clients.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Client couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.ClientResponse{Client: client})
+}
+
+func (ca *ClientApi) UpdateClient(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveClientRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	client, err := ca.clients.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Client couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.ClientResponse{Client: client})
+}
+
+func (ca *ClientApi) setArchived(w http.ResponseWriter, req *http.Request, archived bool) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	client, err := ca.clients.SetArchived(req.Context(), workspace.IdFromContext(req.Context()), id, archived, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Client couldn't be archived"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.ClientResponse{Client: client})
+}
+
+func (ca *ClientApi) ArchiveClient(w http.ResponseWriter, req *http.Request) {
+	ca.setArchived(w, req, true)
+}
+
+func (ca *ClientApi) UnarchiveClient(w http.ResponseWriter, req *http.Request) {
+	ca.setArchived(w, req, false)
+}
+
+func (ca *ClientApi) DeleteClient(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ca.clients.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the client"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewClientApi(cs *Clients) *ClientApi {
+	return &ClientApi{clients: cs}
+}
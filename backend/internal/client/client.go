@@ -0,0 +1,664 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound            = apperr.New(apperr.CODE_NOT_FOUND, "client not found")
+	ErrHasInvoices           = apperr.New(apperr.CODE_FAILED_PRECONDITION, "client has invoices, payments or recurring schedules, archive it instead")
+	ErrTemplateNotFound      = apperr.Invalid("Default template doesn't exist", apperr.Field("defaultTemplateId", "no such template in this workspace"))
+	ErrQuoteTemplateNotFound = apperr.Invalid("Default quote template doesn't exist", apperr.Field("defaultQuoteTemplateId", "no such template in this workspace"))
+)
+
+const (
+	AUDIT_TARGET    = "client"
+	AUDIT_CREATE    = "client.create"
+	AUDIT_UPDATE    = "client.update"
+	AUDIT_ARCHIVE   = "client.archive"
+	AUDIT_UNARCHIVE = "client.unarchive"
+	AUDIT_DELETE    = "client.delete"
+)
+
+type Clients struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	insert_stmt, retrieve_stmt, update_stmt, archive_stmt, delete_stmt, search_stmt, count_stmt *sql.Stmt
+	contacts_stmt, insert_contact_stmt, delete_contacts_stmt, template_exists_stmt              *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanClient(row scanner) (*pb.Client, error) {
+	client := &pb.Client{BillingAddress: &pb.Address{}, ShippingAddress: &pb.Address{}}
+	var billing_address, shipping_address string
+	err := row.Scan(
+		&client.Id,
+		&client.LegalName,
+		&client.TaxId,
+		&billing_address,
+		&shipping_address,
+		&client.DefaultCurrency,
+		&client.DefaultLanguage,
+		&client.DefaultPaymentTerms,
+		&client.DefaultTemplateId,
+		&client.DefaultQuoteTemplateId,
+		&client.ArchivedAt,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+		&client.CreatedBy,
+		&client.UpdatedBy,
+		&client.WorkspaceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(billing_address), client.BillingAddress); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(shipping_address), client.ShippingAddress); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func marshalAddress(address *pb.Address) string {
+	if address == nil {
+		return "{}"
+	}
+	b, _ := json.Marshal(address)
+	return string(b)
+}
+
+// auditFields is what the audit log records of a client.
+func auditFields(c *pb.Client) map[string]string {
+	contacts := []string{}
+	for _, contact := range c.Contacts {
+		contacts = append(contacts, fmt.Sprintf("%s <%s>", contact.Name, contact.Email))
+	}
+
+	return map[string]string{
+		"legal_name":                c.LegalName,
+		"tax_id":                    c.TaxId,
+		"billing_address":           marshalAddress(c.BillingAddress),
+		"shipping_address":          marshalAddress(c.ShippingAddress),
+		"contacts":                  strings.Join(contacts, ", "),
+		"default_currency":          c.DefaultCurrency,
+		"default_language":          c.DefaultLanguage,
+		"default_payment_terms":     fmt.Sprint(c.DefaultPaymentTerms),
+		"default_template_id":       fmt.Sprint(c.DefaultTemplateId),
+		"default_quote_template_id": fmt.Sprint(c.DefaultQuoteTemplateId),
+	}
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, args ...any) (*sql.Rows, error)
+}
+
+func (cs *Clients) loadContacts(ctx context.Context, stmt querier, client *pb.Client) error {
+	rows, err := stmt.QueryContext(ctx, client.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	client.Contacts = []*pb.Contact{}
+	for rows.Next() {
+		contact := &pb.Contact{}
+		if err = rows.Scan(&contact.Id, &contact.Name, &contact.Email, &contact.Phone, &contact.Role, &contact.Primary); err != nil {
+			return err
+		}
+		client.Contacts = append(client.Contacts, contact)
+	}
+
+	return rows.Err()
+}
+
+// saveContacts replaces the contacts of the client. Contacts sent back with
+// the ID they were given keep it.
+func (cs *Clients) saveContacts(ctx context.Context, tx *sql.Tx, client_id uint32, previous []*pb.Contact, contacts []*pb.Contact) error {
+	known := map[uint32]bool{}
+	for _, contact := range previous {
+		known[contact.Id] = true
+	}
+
+	if _, err := tx.Stmt(cs.delete_contacts_stmt).ExecContext(ctx, client_id); err != nil {
+		return err
+	}
+
+	for i, contact := range contacts {
+		var id any
+		if known[contact.Id] {
+			id = contact.Id
+		}
+
+		_, err := tx.Stmt(cs.insert_contact_stmt).ExecContext(
+			ctx,
+			id,
+			client_id,
+			contact.Name,
+			contact.Email,
+			contact.Phone,
+			contact.Role,
+			contact.Primary,
+			i,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cs *Clients) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.Client, error) {
+	client, err := scanClient(tx.Stmt(cs.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return client, cs.loadContacts(ctx, tx.Stmt(cs.contacts_stmt), client)
+}
+
+func (cs *Clients) checkTemplate(ctx context.Context, workspace_id uint32, template_id uint32, not_found error) error {
+	if template_id == 0 {
+		return nil
+	}
+
+	var exists int
+	err := cs.template_exists_stmt.QueryRowContext(ctx, template_id, workspace_id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return not_found
+	}
+	return err
+}
+
+func (cs *Clients) checkTemplates(ctx context.Context, workspace_id uint32, req *pb.SaveClientRequest) error {
+	if err := cs.checkTemplate(ctx, workspace_id, req.DefaultTemplateId, ErrTemplateNotFound); err != nil {
+		return err
+	}
+	return cs.checkTemplate(ctx, workspace_id, req.DefaultQuoteTemplateId, ErrQuoteTemplateNotFound)
+}
+
+func (cs *Clients) Create(ctx context.Context, workspace_id uint32, req *pb.SaveClientRequest, actor *audit.Actor) (_ *pb.Client, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.Create")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+	if err = cs.checkTemplates(ctx, workspace_id, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := cs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(cs.insert_stmt).ExecContext(
+		ctx,
+		req.LegalName,
+		req.TaxId,
+		marshalAddress(req.BillingAddress),
+		marshalAddress(req.ShippingAddress),
+		req.DefaultCurrency,
+		req.DefaultLanguage,
+		req.DefaultPaymentTerms,
+		helpers.NullableId(req.DefaultTemplateId),
+		helpers.NullableId(req.DefaultQuoteTemplateId),
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cs.saveContacts(ctx, tx, uint32(id), nil, req.Contacts); err != nil {
+		return nil, err
+	}
+
+	client, err := cs.retrieve(ctx, tx, workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, client.Id, audit.Diff(nil, auditFields(client)))
+	if err = cs.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (cs *Clients) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.Client, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.Retrieve")
+	defer end(&err)
+
+	client, err := scanClient(cs.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return client, cs.loadContacts(ctx, cs.contacts_stmt, client)
+}
+
+// Search returns a page of the clients matching the filter, ordered by
+// their legal name, along with the number of all matching clients.
+func (cs *Clients) Search(ctx context.Context, filter *Filter) (_ []*pb.Client, _ uint32, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.Search")
+	defer end(&err)
+
+	pattern := ""
+	if filter.Query != "" {
+		pattern = "%" + likeEscaper.Replace(filter.Query) + "%"
+	}
+
+	var total uint32
+	err = cs.count_stmt.QueryRowContext(ctx, filter.WorkspaceId, pattern, filter.Archived).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := cs.search_stmt.QueryContext(ctx, filter.WorkspaceId, pattern, filter.Archived, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	clients := []*pb.Client{}
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		clients = append(clients, client)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	rows.Close()
+
+	for _, client := range clients {
+		if err = cs.loadContacts(ctx, cs.contacts_stmt, client); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return clients, total, nil
+}
+
+// Update replaces all fields and contacts of the client.
+func (cs *Clients) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveClientRequest, actor *audit.Actor) (_ *pb.Client, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.Update")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+	if err = cs.checkTemplates(ctx, workspace_id, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := cs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := cs.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Stmt(cs.update_stmt).ExecContext(
+		ctx,
+		req.LegalName,
+		req.TaxId,
+		marshalAddress(req.BillingAddress),
+		marshalAddress(req.ShippingAddress),
+		req.DefaultCurrency,
+		req.DefaultLanguage,
+		req.DefaultPaymentTerms,
+		helpers.NullableId(req.DefaultTemplateId),
+		helpers.NullableId(req.DefaultQuoteTemplateId),
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		id,
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cs.saveContacts(ctx, tx, id, before.Contacts, req.Contacts); err != nil {
+		return nil, err
+	}
+
+	after, err := cs.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = cs.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// SetArchived hides the client from searches by default, or brings it back.
+// Archiving an archived client changes nothing.
+func (cs *Clients) SetArchived(ctx context.Context, workspace_id uint32, id uint32, archived bool, actor *audit.Actor) (_ *pb.Client, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.SetArchived")
+	defer end(&err)
+
+	tx, err := cs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	client, err := cs.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if (client.ArchivedAt != 0) == archived {
+		return client, nil
+	}
+
+	var archived_at any
+	action := AUDIT_UNARCHIVE
+	if archived {
+		archived_at = time.Now().Unix()
+		action = AUDIT_ARCHIVE
+	}
+
+	_, err = tx.Stmt(cs.archive_stmt).ExecContext(ctx, archived_at, time.Now().Unix(), helpers.NullableId(actor.UserId), id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cs.audit.Record(tx, actor.Entry(workspace_id, action, AUDIT_TARGET, id, nil)); err != nil {
+		return nil, err
+	}
+
+	if client, err = cs.retrieve(ctx, tx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (cs *Clients) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Clients.Delete")
+	defer end(&err)
+
+	tx, err := cs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	client, err := cs.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	// Invoices, payments and recurring schedules keep their client, SQLite
+	// reports a plain constraint error for the foreign key.
+	_, err = tx.Stmt(cs.delete_stmt).ExecContext(ctx, id, workspace_id)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.Code == sqlite3.ErrConstraint {
+		return ErrHasInvoices
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(client), nil))
+	if err = cs.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cs *Clients) Close() error {
+	stmts := []*sql.Stmt{
+		cs.insert_stmt,
+		cs.retrieve_stmt,
+		cs.update_stmt,
+		cs.archive_stmt,
+		cs.delete_stmt,
+		cs.search_stmt,
+		cs.count_stmt,
+		cs.contacts_stmt,
+		cs.insert_contact_stmt,
+		cs.delete_contacts_stmt,
+		cs.template_exists_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const CLIENT_COLUMNS = `
+	client_id,
+	client_legal_name,
+	client_tax_id,
+	client_billing_address,
+	client_shipping_address,
+	client_default_currency,
+	client_default_language,
+	client_default_payment_terms,
+	COALESCE(client_default_template_id, 0),
+	COALESCE(client_default_quote_template_id, 0),
+	COALESCE(client_archived_at, 0),
+	client_created_at,
+	client_updated_at,
+	COALESCE(client_created_by, 0),
+	COALESCE(client_updated_by, 0),
+	client_workspace_id
+`
+
+// SEARCH_WHERE matches the legal name, tax ID and the names and emails of
+// contacts against ?2, and the archive state against ?3.
+const SEARCH_WHERE = `
+	WHERE client_workspace_id = ?1
+		AND (?2 = '' OR client_legal_name LIKE ?2 ESCAPE '\' OR client_tax_id LIKE ?2 ESCAPE '\' OR EXISTS (
+			SELECT 1 FROM client_contacts
+			WHERE client_contact_client_id = client_id
+				AND (client_contact_name LIKE ?2 ESCAPE '\' OR client_contact_email LIKE ?2 ESCAPE '\')
+		))
+		AND (?3 = '` + ARCHIVED_ALL + `' OR (?3 = '` + ARCHIVED_ONLY + `') = (client_archived_at IS NOT NULL))
+`
+
+func NewClients(db *sql.DB, audit_log *audit.Log) (*Clients, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO clients (
+			client_legal_name,
+			client_tax_id,
+			client_billing_address,
+			client_shipping_address,
+			client_default_currency,
+			client_default_language,
+			client_default_payment_terms,
+			client_default_template_id,
+			client_default_quote_template_id,
+			client_created_at,
+			client_updated_at,
+			client_created_by,
+			client_updated_by,
+			client_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + CLIENT_COLUMNS + `
+		FROM clients
+		WHERE client_id = ? AND client_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE clients
+		SET client_legal_name = ?,
+			client_tax_id = ?,
+			client_billing_address = ?,
+			client_shipping_address = ?,
+			client_default_currency = ?,
+			client_default_language = ?,
+			client_default_payment_terms = ?,
+			client_default_template_id = ?,
+			client_default_quote_template_id = ?,
+			client_updated_at = ?,
+			client_updated_by = ?
+		WHERE client_id = ? AND client_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	archive_stmt, err := db.Prepare(`
+		UPDATE clients
+		SET client_archived_at = ?, client_updated_at = ?, client_updated_by = ?
+		WHERE client_id = ? AND client_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM clients WHERE client_id = ? AND client_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	search_stmt, err := db.Prepare(`
+		SELECT ` + CLIENT_COLUMNS + `
+		FROM clients
+		` + SEARCH_WHERE + `
+		ORDER BY client_legal_name COLLATE NOCASE, client_id
+		LIMIT ?4 OFFSET ?5
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_stmt, err := db.Prepare("SELECT COUNT(*) FROM clients " + SEARCH_WHERE)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts_stmt, err := db.Prepare(`
+		SELECT
+			client_contact_id,
+			client_contact_name,
+			client_contact_email,
+			client_contact_phone,
+			client_contact_role,
+			client_contact_primary
+		FROM client_contacts
+		WHERE client_contact_client_id = ?
+		ORDER BY client_contact_position
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_contact_stmt, err := db.Prepare(`
+		INSERT INTO client_contacts (
+			client_contact_id,
+			client_contact_client_id,
+			client_contact_name,
+			client_contact_email,
+			client_contact_phone,
+			client_contact_role,
+			client_contact_primary,
+			client_contact_position
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_contacts_stmt, err := db.Prepare("DELETE FROM client_contacts WHERE client_contact_client_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	template_exists_stmt, err := db.Prepare("SELECT 1 FROM templates WHERE template_id = ? AND template_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Clients{
+		db:                   db,
+		audit:                audit_log,
+		insert_stmt:          insert_stmt,
+		retrieve_stmt:        retrieve_stmt,
+		update_stmt:          update_stmt,
+		archive_stmt:         archive_stmt,
+		delete_stmt:          delete_stmt,
+		search_stmt:          search_stmt,
+		count_stmt:           count_stmt,
+		contacts_stmt:        contacts_stmt,
+		insert_contact_stmt:  insert_contact_stmt,
+		delete_contacts_stmt: delete_contacts_stmt,
+		template_exists_stmt: template_exists_stmt,
+	}, nil
+}
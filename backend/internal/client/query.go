@@ -0,0 +1,63 @@
+package client
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DEFAULT_LIMIT = 50
+	MAX_LIMIT     = 500
+
+	ARCHIVED_EXCLUDE = "false"
+	ARCHIVED_ONLY    = "true"
+	ARCHIVED_ALL     = "all"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Filter selects a page of the clients of one workspace. Archived clients
+// are left out unless asked for.
+type Filter struct {
+	WorkspaceId uint32
+	Query       string
+	Archived    string
+	Limit       int
+	Offset      int
+}
+
+// ParseFilter reads a filter from the query parameters q, archived, limit
+// and offset.
+func ParseFilter(values url.Values) (*Filter, error) {
+	filter := &Filter{
+		Query:    strings.TrimSpace(values.Get("q")),
+		Archived: ARCHIVED_EXCLUDE,
+		Limit:    DEFAULT_LIMIT,
+	}
+
+	if archived := values.Get("archived"); archived != "" {
+		if archived != ARCHIVED_EXCLUDE && archived != ARCHIVED_ONLY && archived != ARCHIVED_ALL {
+			return nil, apperr.Invalid("Invalid client filter", apperr.Field("archived", "must be true, false or all"))
+		}
+		filter.Archived = archived
+	}
+
+	var err error
+	if limit := values.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > MAX_LIMIT {
+			return nil, apperr.Invalid("Invalid client filter", apperr.Field("limit", "must be between 1 and "+strconv.Itoa(MAX_LIMIT)))
+		}
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			return nil, apperr.Invalid("Invalid client filter", apperr.Field("offset", "must not be negative"))
+		}
+	}
+
+	return filter, nil
+}
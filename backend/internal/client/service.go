@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	pbconnect "invoice-manager/main/proto/protoconnect"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"connectrpc.com/connect"
+)
+
+// Permissions are those the procedures of ClientService need, the same as
+// the /clients routes.
+var Permissions = map[string]string{
+	pbconnect.ClientServiceListClientsProcedure:       rbac.PERM_CLIENTS_READ,
+	pbconnect.ClientServiceGetClientProcedure:         rbac.PERM_CLIENTS_READ,
+	pbconnect.ClientServiceCreateClientProcedure:      rbac.PERM_CLIENTS_WRITE,
+	pbconnect.ClientServiceUpdateClientProcedure:      rbac.PERM_CLIENTS_WRITE,
+	pbconnect.ClientServiceSetClientArchivedProcedure: rbac.PERM_CLIENTS_WRITE,
+	pbconnect.ClientServiceDeleteClientProcedure:      rbac.PERM_CLIENTS_WRITE,
+}
+
+type ClientServer struct {
+	pbconnect.UnimplementedClientServiceHandler
+	clients *Clients
+}
+
+func (cs *ClientServer) ListClients(
+	ctx context.Context,
+	req *connect.Request[pb.ListClientsRequest],
+) (*connect.Response[pb.GetClientsResponse], error) {
+	// The filter is read like the query of GET /clients, which leaves
+	// unset fields at their defaults.
+	values := url.Values{}
+	values.Set("q", req.Msg.Query)
+	values.Set("archived", req.Msg.Archived)
+	if req.Msg.Limit != 0 {
+		values.Set("limit", strconv.FormatUint(uint64(req.Msg.Limit), 10))
+	}
+	values.Set("offset", strconv.FormatUint(uint64(req.Msg.Offset), 10))
+
+	filter, err := ParseFilter(values)
+	if err != nil {
+		return nil, apperr.ToConnect(err)
+	}
+	filter.WorkspaceId = workspace.IdFromContext(ctx)
+
+	clients, total, err := cs.clients.Search(ctx, filter)
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Error reading clients"))
+	}
+
+	return connect.NewResponse(&pb.GetClientsResponse{Clients: clients, Total: total}), nil
+}
+
+func (cs *ClientServer) GetClient(
+	ctx context.Context,
+	req *connect.Request[pb.GetClientRequest],
+) (*connect.Response[pb.ClientResponse], error) {
+	client, err := cs.clients.Retrieve(ctx, workspace.IdFromContext(ctx), req.Msg.Id)
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Error reading client"))
+	}
+
+	return connect.NewResponse(&pb.ClientResponse{Client: client}), nil
+}
+
+func (cs *ClientServer) CreateClient(
+	ctx context.Context,
+	req *connect.Request[pb.SaveClientRequest],
+) (*connect.Response[pb.ClientResponse], error) {
+	client, err := cs.clients.Create(ctx, workspace.IdFromContext(ctx), req.Msg, audit.ActorFromConnect(ctx, req.Peer()))
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Client couldn't be created"))
+	}
+
+	return connect.NewResponse(&pb.ClientResponse{Client: client}), nil
+}
+
+func (cs *ClientServer) UpdateClient(
+	ctx context.Context,
+	req *connect.Request[pb.UpdateClientRequest],
+) (*connect.Response[pb.ClientResponse], error) {
+	body := req.Msg.Client
+	if body == nil {
+		body = &pb.SaveClientRequest{}
+	}
+
+	client, err := cs.clients.Update(ctx, workspace.IdFromContext(ctx), req.Msg.Id, body, audit.ActorFromConnect(ctx, req.Peer()))
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Client couldn't be updated"))
+	}
+
+	return connect.NewResponse(&pb.ClientResponse{Client: client}), nil
+}
+
+func (cs *ClientServer) SetClientArchived(
+	ctx context.Context,
+	req *connect.Request[pb.SetClientArchivedRequest],
+) (*connect.Response[pb.ClientResponse], error) {
+	client, err := cs.clients.SetArchived(ctx, workspace.IdFromContext(ctx), req.Msg.Id, req.Msg.Archived, audit.ActorFromConnect(ctx, req.Peer()))
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Client couldn't be archived"))
+	}
+
+	return connect.NewResponse(&pb.ClientResponse{Client: client}), nil
+}
+
+func (cs *ClientServer) DeleteClient(
+	ctx context.Context,
+	req *connect.Request[pb.DeleteClientRequest],
+) (*connect.Response[pb.DeleteClientResponse], error) {
+	err := cs.clients.Delete(ctx, workspace.IdFromContext(ctx), req.Msg.Id, audit.ActorFromConnect(ctx, req.Peer()))
+	if err != nil {
+		return nil, apperr.ToConnect(apperr.Wrap(err, "Error while deleting the client"))
+	}
+
+	return connect.NewResponse(&pb.DeleteClientResponse{}), nil
+}
+
+// ClientServiceHandler serves ClientService. Every procedure needs a
+// permission, so it has to be wrapped in an interceptor authorizing them
+// with Permissions.
+func ClientServiceHandler(cs *Clients, opts ...connect.HandlerOption) (string, http.Handler) {
+	return pbconnect.NewClientServiceHandler(&ClientServer{clients: cs}, opts...)
+}
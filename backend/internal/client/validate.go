@@ -0,0 +1,130 @@
+package client
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	pb "invoice-manager/main/proto"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	MAX_PAYMENT_TERMS = 365
+	MAX_CONTACTS      = 50
+	MAX_NAME_LENGTH   = 256
+	MAX_TAX_ID_LENGTH = 32
+)
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	taxIdPattern   = regexp.MustCompile(`^[A-Z0-9/+]+$`)
+
+	// Separators people type into tax IDs, e.g. "DE 123.456.789".
+	taxIdCleaner = strings.NewReplacer(" ", "", ".", "", "-", "")
+)
+
+func NormalizeTaxId(tax_id string) string {
+	return strings.ToUpper(taxIdCleaner.Replace(strings.TrimSpace(tax_id)))
+}
+
+func validateAddress(field string, address *pb.Address) []apperr.FieldError {
+	if address == nil {
+		return nil
+	}
+
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	address.Region = strings.TrimSpace(address.Region)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+
+	if address.Country != "" && !countryPattern.MatchString(address.Country) {
+		return []apperr.FieldError{apperr.Field(field+".country", "must be an ISO 3166-1 alpha-2 code")}
+	}
+	return nil
+}
+
+func validateContacts(contacts []*pb.Contact) []apperr.FieldError {
+	if len(contacts) > MAX_CONTACTS {
+		return []apperr.FieldError{apperr.Field("contacts", fmt.Sprintf("must not be more than %d", MAX_CONTACTS))}
+	}
+
+	fields := []apperr.FieldError{}
+	primary := 0
+	for i, contact := range contacts {
+		field := fmt.Sprintf("contacts[%d]", i)
+		contact.Name = strings.TrimSpace(contact.Name)
+		contact.Phone = strings.TrimSpace(contact.Phone)
+		contact.Role = strings.TrimSpace(contact.Role)
+
+		address, err := mail.ParseAddress(strings.TrimSpace(contact.Email))
+		if err != nil {
+			fields = append(fields, apperr.Field(field+".email", "must be a valid email address"))
+		} else {
+			contact.Email = address.Address
+			if contact.Name == "" {
+				contact.Name = address.Name
+			}
+		}
+
+		if contact.Primary {
+			primary++
+		}
+	}
+
+	if primary > 1 {
+		fields = append(fields, apperr.Field("contacts", "only one contact can be primary"))
+	}
+	return fields
+}
+
+// Validate checks the request and normalizes it in place: names are
+// trimmed, codes upper-cased, tax IDs stripped of separators and languages
+// canonicalized.
+func Validate(req *pb.SaveClientRequest) error {
+	fields := []apperr.FieldError{}
+
+	req.LegalName = strings.TrimSpace(req.LegalName)
+	if req.LegalName == "" {
+		fields = append(fields, apperr.Field("legalName", "must not be empty"))
+	} else if len(req.LegalName) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("legalName", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	req.TaxId = NormalizeTaxId(req.TaxId)
+	if req.TaxId != "" && (len(req.TaxId) > MAX_TAX_ID_LENGTH || !taxIdPattern.MatchString(req.TaxId)) {
+		fields = append(fields, apperr.Field("taxId", "must only contain letters and digits"))
+	}
+
+	fields = append(fields, validateAddress("billingAddress", req.BillingAddress)...)
+	fields = append(fields, validateAddress("shippingAddress", req.ShippingAddress)...)
+	fields = append(fields, validateContacts(req.Contacts)...)
+
+	req.DefaultCurrency = strings.ToUpper(strings.TrimSpace(req.DefaultCurrency))
+	if req.DefaultCurrency != "" && !money.Valid(req.DefaultCurrency) {
+		fields = append(fields, apperr.Field("defaultCurrency", "must be an ISO 4217 code"))
+	}
+
+	if req.DefaultLanguage = strings.TrimSpace(req.DefaultLanguage); req.DefaultLanguage != "" {
+		tag, err := language.Parse(req.DefaultLanguage)
+		if err != nil {
+			fields = append(fields, apperr.Field("defaultLanguage", "must be a BCP 47 language tag"))
+		} else {
+			req.DefaultLanguage = tag.String()
+		}
+	}
+
+	if req.DefaultPaymentTerms > MAX_PAYMENT_TERMS {
+		fields = append(fields, apperr.Field("defaultPaymentTerms", fmt.Sprintf("must not be more than %d days", MAX_PAYMENT_TERMS)))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Client is invalid", fields...)
+	}
+	return nil
+}
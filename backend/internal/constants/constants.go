@@ -2,3 +2,4 @@ package constants
 
 const DB_FILE = "database.db"
 const HTTP_ADDR = "localhost:9002"
+const BASE_DOMAIN = "localhost"
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/constants"
+	"io"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrNotSqlite      = fmt.Errorf("file is not a SQLite database")
+	ErrIntegrityCheck = fmt.Errorf("database integrity check failed")
+)
+
+func Open() (*sql.DB, error) {
+	return OpenFile(constants.DB_FILE)
+}
+
+func OpenFile(file string) (*sql.DB, error) {
+	// Transactions take the write lock right away, so that ones reading
+	// before they write wait for each other instead of failing with
+	// SQLITE_BUSY, e.g. when allocating document numbers.
+	db, err := sql.Open("sqlite3", file+"?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func Backup(db *sql.DB, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("backup destination %s already exists", dest)
+	}
+
+	_, err := db.Exec("VACUUM INTO ?", dest)
+	return err
+}
+
+func Verify(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, 16)
+	if _, err = io.ReadFull(f, header); err != nil || string(header) != "SQLite format 3\x00" {
+		return ErrNotSqlite
+	}
+
+	db, err := OpenFile(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err = db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: %s", ErrIntegrityCheck, result)
+	}
+
+	return nil
+}
+
+var sidecarSuffixes = []string{"-wal", "-shm", "-journal"}
+
+// checkpoint writes the pages still in the WAL of the database into its
+// file, so that the file alone holds everything committed.
+func checkpoint(file string) error {
+	db, err := OpenFile(file)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	return err
+}
+
+// Restore replaces the database file with src. The current database is kept
+// next to it with a timestamp suffix so that a bad restore can be undone. It
+// is checkpointed first, and its WAL and journal files are moved along with
+// it in case that doesn't get everything.
+func Restore(src string, dest string) (previous string, err error) {
+	if err = Verify(src); err != nil {
+		return "", err
+	}
+
+	if _, err = os.Stat(dest); err == nil {
+		if err = checkpoint(dest); err != nil {
+			return "", err
+		}
+
+		previous = fmt.Sprintf("%s.%d.bak", dest, time.Now().Unix())
+		if err = os.Rename(dest, previous); err != nil {
+			return "", err
+		}
+		for _, suffix := range sidecarSuffixes {
+			if err = os.Rename(dest+suffix, previous+suffix); err != nil && !os.IsNotExist(err) {
+				return previous, err
+			}
+		}
+	}
+
+	for _, suffix := range sidecarSuffixes {
+		os.Remove(dest + suffix)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return previous, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0660)
+	if err != nil {
+		return previous, err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return previous, err
+	}
+
+	return previous, out.Close()
+}
@@ -0,0 +1,740 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Sql     string
+}
+
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_templates",
+		Sql: `
+			CREATE TABLE IF NOT EXISTS templates (
+				template_id INTEGER NOT NULL PRIMARY KEY,
+				template_name VARCHAR NOT NULL,
+				template_ext VARCHAR(10) NOT NULL,
+				template_size INTEGER NOT NULL,
+				template_private_path TEXT NOT NULL,
+				template_public_path TEXT NOT NULL,
+				template_private_thumbnail_path TEXT NOT NULL,
+				template_public_thumbnail_path TEXT NOT NULL,
+				template_created_at INTEGER NOT NULL,
+				template_updated_at INTEGER NOT NULL
+			);
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "create_users_and_sessions",
+		Sql: `
+			CREATE TABLE users (
+				user_id INTEGER NOT NULL PRIMARY KEY,
+				user_email VARCHAR NOT NULL UNIQUE COLLATE NOCASE,
+				user_name VARCHAR NOT NULL,
+				user_password_hash VARCHAR NOT NULL,
+				user_created_at INTEGER NOT NULL,
+				user_updated_at INTEGER NOT NULL
+			);
+
+			CREATE TABLE sessions (
+				session_token_hash VARCHAR NOT NULL PRIMARY KEY,
+				session_user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+				session_created_at INTEGER NOT NULL,
+				session_expires_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX sessions_user_id ON sessions(session_user_id);
+
+			ALTER TABLE templates ADD COLUMN template_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+			ALTER TABLE templates ADD COLUMN template_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "create_api_keys",
+		Sql: `
+			CREATE TABLE api_keys (
+				api_key_id INTEGER NOT NULL PRIMARY KEY,
+				api_key_user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+				api_key_name VARCHAR NOT NULL,
+				api_key_prefix VARCHAR NOT NULL,
+				api_key_hash VARCHAR NOT NULL UNIQUE,
+				api_key_scopes TEXT NOT NULL,
+				api_key_created_at INTEGER NOT NULL,
+				api_key_expires_at INTEGER NOT NULL DEFAULT 0,
+				api_key_last_used_at INTEGER NOT NULL DEFAULT 0,
+				api_key_revoked_at INTEGER NOT NULL DEFAULT 0
+			);
+
+			CREATE INDEX api_keys_user_id ON api_keys(api_key_user_id);
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "create_workspaces",
+		Sql: `
+			CREATE TABLE workspaces (
+				workspace_id INTEGER NOT NULL PRIMARY KEY,
+				workspace_slug VARCHAR NOT NULL UNIQUE COLLATE NOCASE,
+				workspace_name VARCHAR NOT NULL,
+				workspace_created_at INTEGER NOT NULL,
+				workspace_updated_at INTEGER NOT NULL
+			);
+
+			INSERT INTO workspaces VALUES(1, 'default', 'Default', unixepoch(), unixepoch());
+
+			CREATE TABLE workspace_members (
+				workspace_member_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				workspace_member_user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+				workspace_member_joined_at INTEGER NOT NULL,
+				PRIMARY KEY (workspace_member_workspace_id, workspace_member_user_id)
+			);
+
+			CREATE INDEX workspace_members_user_id ON workspace_members(workspace_member_user_id);
+
+			INSERT INTO workspace_members SELECT 1, user_id, unixepoch() FROM users;
+
+			-- SQLite can't add a NOT NULL foreign key column, so templates is rebuilt.
+			CREATE TABLE templates_new (
+				template_id INTEGER NOT NULL PRIMARY KEY,
+				template_name VARCHAR NOT NULL,
+				template_ext VARCHAR(10) NOT NULL,
+				template_size INTEGER NOT NULL,
+				template_private_path TEXT NOT NULL,
+				template_public_path TEXT NOT NULL,
+				template_private_thumbnail_path TEXT NOT NULL,
+				template_public_thumbnail_path TEXT NOT NULL,
+				template_created_at INTEGER NOT NULL,
+				template_updated_at INTEGER NOT NULL,
+				template_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				template_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				template_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			INSERT INTO templates_new SELECT *, 1 FROM templates;
+			DROP TABLE templates;
+			ALTER TABLE templates_new RENAME TO templates;
+
+			CREATE INDEX templates_workspace_id ON templates(template_workspace_id);
+
+			ALTER TABLE sessions ADD COLUMN session_workspace_id INTEGER REFERENCES workspaces(workspace_id) ON DELETE SET NULL;
+			ALTER TABLE api_keys ADD COLUMN api_key_workspace_id INTEGER REFERENCES workspaces(workspace_id) ON DELETE CASCADE;
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "create_roles",
+		Sql: `
+			-- Everyone could do everything before roles existed, so existing
+			-- members keep full access.
+			ALTER TABLE workspace_members ADD COLUMN workspace_member_role VARCHAR NOT NULL DEFAULT 'viewer';
+			UPDATE workspace_members SET workspace_member_role = 'owner';
+
+			CREATE TABLE roles (
+				role_id INTEGER NOT NULL PRIMARY KEY,
+				role_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				role_name VARCHAR NOT NULL,
+				role_permissions TEXT NOT NULL,
+				role_created_at INTEGER NOT NULL,
+				role_updated_at INTEGER NOT NULL,
+				UNIQUE (role_workspace_id, role_name)
+			);
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "create_audit_log",
+		Sql: `
+			-- No foreign keys, entries have to outlive the users, keys and
+			-- records they mention.
+			CREATE TABLE audit_log (
+				audit_id INTEGER NOT NULL PRIMARY KEY,
+				audit_workspace_id INTEGER NOT NULL,
+				audit_actor_user_id INTEGER NOT NULL DEFAULT 0,
+				audit_actor_api_key_id INTEGER NOT NULL DEFAULT 0,
+				audit_source VARCHAR NOT NULL,
+				audit_ip VARCHAR NOT NULL DEFAULT '',
+				audit_action VARCHAR NOT NULL,
+				audit_target_type VARCHAR NOT NULL,
+				audit_target_id INTEGER NOT NULL,
+				audit_changes TEXT NOT NULL,
+				audit_created_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX audit_log_workspace_id ON audit_log(audit_workspace_id, audit_id);
+			CREATE INDEX audit_log_target ON audit_log(audit_target_type, audit_target_id);
+
+			CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+			BEGIN
+				SELECT RAISE(ABORT, 'audit log is append-only');
+			END;
+
+			CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+			BEGIN
+				SELECT RAISE(ABORT, 'audit log is append-only');
+			END;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "create_usage_events",
+		Sql: `
+			CREATE TABLE usage_events (
+				usage_event_id INTEGER NOT NULL PRIMARY KEY,
+				usage_event_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				usage_event_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				usage_event_kind VARCHAR NOT NULL,
+				usage_event_created_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX usage_events_workspace_kind ON usage_events(usage_event_workspace_id, usage_event_kind, usage_event_created_at);
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "create_health_probes",
+		Sql: `
+			CREATE TABLE health_probes (
+				health_probe_id INTEGER NOT NULL PRIMARY KEY,
+				health_probe_checked_at INTEGER NOT NULL
+			);
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "create_clients",
+		Sql: `
+			CREATE TABLE clients (
+				client_id INTEGER NOT NULL PRIMARY KEY,
+				client_legal_name VARCHAR NOT NULL,
+				client_tax_id VARCHAR NOT NULL DEFAULT '',
+				client_billing_address TEXT NOT NULL DEFAULT '{}',
+				client_shipping_address TEXT NOT NULL DEFAULT '{}',
+				client_default_currency VARCHAR(3) NOT NULL DEFAULT '',
+				client_default_language VARCHAR NOT NULL DEFAULT '',
+				client_default_payment_terms INTEGER NOT NULL DEFAULT 0,
+				client_default_template_id INTEGER REFERENCES templates(template_id) ON DELETE SET NULL,
+				client_archived_at INTEGER,
+				client_created_at INTEGER NOT NULL,
+				client_updated_at INTEGER NOT NULL,
+				client_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				client_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				client_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX clients_workspace_name ON clients(client_workspace_id, client_legal_name);
+
+			CREATE TABLE client_contacts (
+				client_contact_id INTEGER NOT NULL PRIMARY KEY,
+				client_contact_client_id INTEGER NOT NULL REFERENCES clients(client_id) ON DELETE CASCADE,
+				client_contact_name VARCHAR NOT NULL DEFAULT '',
+				client_contact_email VARCHAR NOT NULL DEFAULT '',
+				client_contact_phone VARCHAR NOT NULL DEFAULT '',
+				client_contact_role VARCHAR NOT NULL DEFAULT '',
+				client_contact_primary INTEGER NOT NULL DEFAULT 0,
+				client_contact_position INTEGER NOT NULL
+			);
+
+			CREATE INDEX client_contacts_client_id ON client_contacts(client_contact_client_id);
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "create_invoices",
+		Sql: `
+			CREATE TABLE invoices (
+				invoice_id INTEGER NOT NULL PRIMARY KEY,
+				invoice_status VARCHAR NOT NULL DEFAULT 'draft',
+				invoice_number VARCHAR NOT NULL DEFAULT '',
+				invoice_client_id INTEGER NOT NULL REFERENCES clients(client_id) ON DELETE RESTRICT,
+				invoice_template_id INTEGER REFERENCES templates(template_id) ON DELETE SET NULL,
+				invoice_currency VARCHAR(3) NOT NULL,
+				invoice_language VARCHAR NOT NULL DEFAULT '',
+				invoice_issue_date VARCHAR NOT NULL,
+				invoice_due_date VARCHAR NOT NULL,
+				invoice_service_date VARCHAR NOT NULL DEFAULT '',
+				invoice_notes TEXT NOT NULL DEFAULT '',
+				invoice_discounts TEXT NOT NULL DEFAULT '[]',
+				invoice_net VARCHAR NOT NULL DEFAULT '0',
+				invoice_tax VARCHAR NOT NULL DEFAULT '0',
+				invoice_gross VARCHAR NOT NULL DEFAULT '0',
+				invoice_created_at INTEGER NOT NULL,
+				invoice_updated_at INTEGER NOT NULL,
+				invoice_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				invoice_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				invoice_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX invoices_workspace_issue_date ON invoices(invoice_workspace_id, invoice_issue_date);
+			CREATE INDEX invoices_client_id ON invoices(invoice_client_id);
+
+			CREATE TABLE invoice_line_items (
+				line_item_id INTEGER NOT NULL PRIMARY KEY,
+				line_item_invoice_id INTEGER NOT NULL REFERENCES invoices(invoice_id) ON DELETE CASCADE,
+				line_item_description TEXT NOT NULL,
+				line_item_quantity VARCHAR NOT NULL,
+				line_item_unit VARCHAR NOT NULL DEFAULT '',
+				line_item_unit_price VARCHAR NOT NULL,
+				line_item_discount TEXT NOT NULL DEFAULT '{}',
+				line_item_tax_rate VARCHAR NOT NULL DEFAULT '0',
+				line_item_position INTEGER NOT NULL
+			);
+
+			CREATE INDEX invoice_line_items_invoice_id ON invoice_line_items(line_item_invoice_id);
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "create_sequences",
+		Sql: `
+			CREATE TABLE sequences (
+				sequence_id INTEGER NOT NULL PRIMARY KEY,
+				sequence_document_type VARCHAR NOT NULL,
+				sequence_issuer_id INTEGER NOT NULL DEFAULT 0,
+				sequence_pattern VARCHAR NOT NULL,
+				sequence_prefix VARCHAR NOT NULL DEFAULT '',
+				sequence_reset_period VARCHAR NOT NULL,
+				sequence_created_at INTEGER NOT NULL,
+				sequence_updated_at INTEGER NOT NULL,
+				sequence_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				sequence_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				sequence_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				UNIQUE (sequence_workspace_id, sequence_document_type, sequence_issuer_id)
+			);
+
+			CREATE TABLE sequence_counters (
+				sequence_counter_sequence_id INTEGER NOT NULL REFERENCES sequences(sequence_id) ON DELETE CASCADE,
+				sequence_counter_period VARCHAR NOT NULL,
+				sequence_counter_value INTEGER NOT NULL,
+				PRIMARY KEY (sequence_counter_sequence_id, sequence_counter_period)
+			);
+
+			CREATE UNIQUE INDEX invoices_workspace_number ON invoices(invoice_workspace_id, invoice_number)
+				WHERE invoice_number != '';
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "create_invoice_events",
+		Sql: `
+			ALTER TABLE invoices ADD COLUMN invoice_snapshot TEXT NOT NULL DEFAULT '{}';
+			ALTER TABLE invoices ADD COLUMN invoice_issued_at INTEGER;
+			ALTER TABLE invoices ADD COLUMN invoice_html_path VARCHAR NOT NULL DEFAULT '';
+			ALTER TABLE invoices ADD COLUMN invoice_pdf_path VARCHAR NOT NULL DEFAULT '';
+
+			CREATE TABLE invoice_events (
+				invoice_event_id INTEGER NOT NULL PRIMARY KEY,
+				invoice_event_invoice_id INTEGER NOT NULL REFERENCES invoices(invoice_id) ON DELETE CASCADE,
+				invoice_event_from_status VARCHAR NOT NULL,
+				invoice_event_to_status VARCHAR NOT NULL,
+				invoice_event_note TEXT NOT NULL DEFAULT '',
+				invoice_event_actor_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				invoice_event_created_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX invoice_events_invoice_id ON invoice_events(invoice_event_invoice_id);
+
+			CREATE TRIGGER invoices_issued_immutable BEFORE UPDATE OF
+				invoice_number,
+				invoice_client_id,
+				invoice_template_id,
+				invoice_currency,
+				invoice_language,
+				invoice_issue_date,
+				invoice_due_date,
+				invoice_service_date,
+				invoice_notes,
+				invoice_discounts,
+				invoice_net,
+				invoice_tax,
+				invoice_gross,
+				invoice_snapshot,
+				invoice_html_path
+			ON invoices
+			WHEN OLD.invoice_status != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+
+			CREATE TRIGGER invoice_line_items_issued_no_insert BEFORE INSERT ON invoice_line_items
+			WHEN (SELECT invoice_status FROM invoices WHERE invoice_id = NEW.line_item_invoice_id) != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+
+			CREATE TRIGGER invoice_line_items_issued_no_update BEFORE UPDATE ON invoice_line_items
+			WHEN (SELECT invoice_status FROM invoices WHERE invoice_id = OLD.line_item_invoice_id) != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "create_payments",
+		Sql: `
+			ALTER TABLE invoices ADD COLUMN invoice_paid VARCHAR NOT NULL DEFAULT '0';
+
+			CREATE TABLE payments (
+				payment_id INTEGER NOT NULL PRIMARY KEY,
+				payment_client_id INTEGER NOT NULL REFERENCES clients(client_id) ON DELETE RESTRICT,
+				payment_amount VARCHAR NOT NULL,
+				payment_currency VARCHAR(3) NOT NULL,
+				payment_date VARCHAR NOT NULL,
+				payment_method VARCHAR NOT NULL,
+				payment_reference VARCHAR NOT NULL DEFAULT '',
+				payment_notes TEXT NOT NULL DEFAULT '',
+				payment_reversed_at INTEGER,
+				payment_reversed_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				payment_reversal_reason TEXT NOT NULL DEFAULT '',
+				payment_created_at INTEGER NOT NULL,
+				payment_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				payment_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX payments_client_id ON payments(payment_client_id);
+
+			CREATE TABLE payment_allocations (
+				payment_allocation_id INTEGER NOT NULL PRIMARY KEY,
+				payment_allocation_payment_id INTEGER NOT NULL REFERENCES payments(payment_id) ON DELETE CASCADE,
+				payment_allocation_invoice_id INTEGER NOT NULL REFERENCES invoices(invoice_id) ON DELETE RESTRICT,
+				payment_allocation_amount VARCHAR NOT NULL,
+				payment_allocation_from_credit INTEGER NOT NULL DEFAULT 0,
+				payment_allocation_created_at INTEGER NOT NULL,
+				payment_allocation_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL
+			);
+
+			CREATE INDEX payment_allocations_payment_id ON payment_allocations(payment_allocation_payment_id);
+			CREATE INDEX payment_allocations_invoice_id ON payment_allocations(payment_allocation_invoice_id);
+		`,
+	},
+	{
+		Version: 14,
+		Name:    "create_credit_notes",
+		Sql: `
+			ALTER TABLE invoices ADD COLUMN invoice_kind VARCHAR NOT NULL DEFAULT 'invoice';
+			ALTER TABLE invoices ADD COLUMN invoice_original_id INTEGER REFERENCES invoices(invoice_id) ON DELETE RESTRICT;
+			ALTER TABLE invoices ADD COLUMN invoice_credited VARCHAR NOT NULL DEFAULT '0';
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_original_id INTEGER REFERENCES invoice_line_items(line_item_id) ON DELETE RESTRICT;
+
+			CREATE INDEX invoices_original_id ON invoices(invoice_original_id);
+			CREATE INDEX invoice_line_items_original_id ON invoice_line_items(line_item_original_id);
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "create_recurring_schedules",
+		Sql: `
+			CREATE TABLE recurring_schedules (
+				schedule_id INTEGER NOT NULL PRIMARY KEY,
+				schedule_name VARCHAR NOT NULL,
+				schedule_client_id INTEGER NOT NULL REFERENCES clients(client_id) ON DELETE RESTRICT,
+				schedule_invoice TEXT NOT NULL,
+				schedule_cadence VARCHAR NOT NULL,
+				schedule_cron VARCHAR NOT NULL DEFAULT '',
+				schedule_start_date VARCHAR NOT NULL,
+				schedule_end_date VARCHAR NOT NULL DEFAULT '',
+				schedule_auto_issue INTEGER NOT NULL DEFAULT 0,
+				schedule_paused INTEGER NOT NULL DEFAULT 0,
+				schedule_periods INTEGER NOT NULL DEFAULT 0,
+				schedule_next_date VARCHAR NOT NULL DEFAULT '',
+				schedule_created_at INTEGER NOT NULL,
+				schedule_updated_at INTEGER NOT NULL,
+				schedule_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				schedule_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				schedule_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX recurring_schedules_next_date ON recurring_schedules(schedule_next_date);
+
+			-- A period is generated at most once, whichever instance gets to it
+			-- first.
+			CREATE TABLE recurring_runs (
+				run_id INTEGER NOT NULL PRIMARY KEY,
+				run_schedule_id INTEGER NOT NULL REFERENCES recurring_schedules(schedule_id) ON DELETE CASCADE,
+				run_period_start VARCHAR NOT NULL,
+				run_period_end VARCHAR NOT NULL,
+				run_invoice_id INTEGER REFERENCES invoices(invoice_id) ON DELETE SET NULL,
+				run_created_at INTEGER NOT NULL,
+				UNIQUE (run_schedule_id, run_period_start)
+			);
+		`,
+	},
+	{
+		Version: 16,
+		Name:    "create_quotes",
+		Sql: `
+			-- Percent of a quote converted into invoices so far, and the percent
+			-- of its quote an invoice bills.
+			ALTER TABLE invoices ADD COLUMN invoice_converted VARCHAR NOT NULL DEFAULT '0';
+			ALTER TABLE invoices ADD COLUMN invoice_share VARCHAR NOT NULL DEFAULT '0';
+			ALTER TABLE clients ADD COLUMN client_default_quote_template_id INTEGER REFERENCES templates(template_id) ON DELETE SET NULL;
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "create_exchange_rates",
+		Sql: `
+			ALTER TABLE workspaces ADD COLUMN workspace_base_currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
+			ALTER TABLE invoices ADD COLUMN invoice_base_currency VARCHAR(3) NOT NULL DEFAULT '';
+			ALTER TABLE invoices ADD COLUMN invoice_exchange_rate VARCHAR NOT NULL DEFAULT '';
+
+			-- Documents issued before rates existed only know their rate if
+			-- they are in the currency the workspace reports in.
+			UPDATE invoices SET invoice_base_currency = invoice_currency, invoice_exchange_rate = '1'
+			WHERE invoice_status != 'draft' AND invoice_currency = 'EUR';
+
+			CREATE TABLE exchange_rates (
+				exchange_rate_id INTEGER NOT NULL PRIMARY KEY,
+				exchange_rate_date VARCHAR NOT NULL,
+				exchange_rate_base VARCHAR(3) NOT NULL,
+				exchange_rate_quote VARCHAR(3) NOT NULL,
+				exchange_rate_value VARCHAR NOT NULL,
+				exchange_rate_source VARCHAR NOT NULL DEFAULT '',
+				exchange_rate_imported_at INTEGER NOT NULL,
+				exchange_rate_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				UNIQUE (exchange_rate_workspace_id, exchange_rate_base, exchange_rate_quote, exchange_rate_date)
+			);
+
+			CREATE TRIGGER invoices_issued_rate_immutable BEFORE UPDATE OF
+				invoice_base_currency,
+				invoice_exchange_rate
+			ON invoices
+			WHEN OLD.invoice_status != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 18,
+		Name:    "create_tax_rates",
+		Sql: `
+			CREATE TABLE tax_rates (
+				tax_rate_id INTEGER NOT NULL PRIMARY KEY,
+				tax_rate_name VARCHAR NOT NULL,
+				tax_rate_country VARCHAR(2) NOT NULL,
+				tax_rate_category VARCHAR NOT NULL,
+				tax_rate_value VARCHAR NOT NULL DEFAULT '0',
+				tax_rate_valid_from VARCHAR NOT NULL,
+				tax_rate_valid_to VARCHAR NOT NULL DEFAULT '',
+				tax_rate_exemption_reason TEXT NOT NULL DEFAULT '',
+				tax_rate_created_at INTEGER NOT NULL,
+				tax_rate_updated_at INTEGER NOT NULL,
+				tax_rate_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				tax_rate_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				tax_rate_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX tax_rates_workspace_id ON tax_rates(tax_rate_workspace_id, tax_rate_country);
+
+			ALTER TABLE workspaces ADD COLUMN workspace_country VARCHAR(2) NOT NULL DEFAULT '';
+			ALTER TABLE workspaces ADD COLUMN workspace_vat_id VARCHAR NOT NULL DEFAULT '';
+
+			ALTER TABLE invoices ADD COLUMN invoice_prices_include_tax BOOLEAN NOT NULL DEFAULT 0;
+			ALTER TABLE invoices ADD COLUMN invoice_reverse_charge BOOLEAN NOT NULL DEFAULT 0;
+
+			-- Lines issued before categories existed have none, they are
+			-- standard or zero rated by their rate.
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_tax_rate_id INTEGER REFERENCES tax_rates(tax_rate_id);
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_tax_category VARCHAR NOT NULL DEFAULT '';
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_exemption_reason TEXT NOT NULL DEFAULT '';
+
+			CREATE TRIGGER invoices_issued_tax_immutable BEFORE UPDATE OF
+				invoice_prices_include_tax,
+				invoice_reverse_charge
+			ON invoices
+			WHEN OLD.invoice_status != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 19,
+		Name:    "create_catalog_items",
+		Sql: `
+			CREATE TABLE catalog_items (
+				catalog_item_id INTEGER NOT NULL PRIMARY KEY,
+				catalog_item_sku VARCHAR NOT NULL,
+				catalog_item_name VARCHAR NOT NULL,
+				catalog_item_description TEXT NOT NULL DEFAULT '',
+				catalog_item_unit VARCHAR NOT NULL DEFAULT '',
+				catalog_item_prices TEXT NOT NULL DEFAULT '[]',
+				catalog_item_tax_category VARCHAR NOT NULL DEFAULT '',
+				catalog_item_archived_at INTEGER,
+				catalog_item_created_at INTEGER NOT NULL,
+				catalog_item_updated_at INTEGER NOT NULL,
+				catalog_item_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				catalog_item_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				catalog_item_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE,
+				UNIQUE (catalog_item_workspace_id, catalog_item_sku)
+			);
+
+			CREATE INDEX catalog_items_name ON catalog_items(catalog_item_workspace_id, catalog_item_name COLLATE NOCASE);
+
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_catalog_item_id INTEGER REFERENCES catalog_items(catalog_item_id);
+			ALTER TABLE invoice_line_items ADD COLUMN line_item_sku VARCHAR NOT NULL DEFAULT '';
+		`,
+	},
+	{
+		Version: 20,
+		Name:    "create_issuers",
+		Sql: `
+			CREATE TABLE issuers (
+				issuer_id INTEGER NOT NULL PRIMARY KEY,
+				issuer_name VARCHAR NOT NULL,
+				issuer_legal_name VARCHAR NOT NULL,
+				issuer_address TEXT NOT NULL DEFAULT '{}',
+				issuer_tax_id VARCHAR NOT NULL DEFAULT '',
+				issuer_vat_id VARCHAR NOT NULL DEFAULT '',
+				issuer_registration_numbers TEXT NOT NULL DEFAULT '[]',
+				issuer_bank_accounts TEXT NOT NULL DEFAULT '[]',
+				issuer_logo VARCHAR NOT NULL DEFAULT '',
+				issuer_default_template_id INTEGER REFERENCES templates(template_id) ON DELETE SET NULL,
+				issuer_footer TEXT NOT NULL DEFAULT '',
+				issuer_created_at INTEGER NOT NULL,
+				issuer_updated_at INTEGER NOT NULL,
+				issuer_created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				issuer_updated_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+				issuer_workspace_id INTEGER NOT NULL REFERENCES workspaces(workspace_id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX issuers_workspace_id ON issuers(issuer_workspace_id);
+
+			ALTER TABLE invoices ADD COLUMN invoice_issuer_id INTEGER REFERENCES issuers(issuer_id);
+
+			CREATE TRIGGER invoices_issued_issuer_immutable BEFORE UPDATE OF invoice_issuer_id
+			ON invoices
+			WHEN OLD.invoice_status != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 21,
+		Name:    "add_invoice_buyer_reference",
+		Sql: `
+			ALTER TABLE invoices ADD COLUMN invoice_buyer_reference VARCHAR NOT NULL DEFAULT '';
+
+			CREATE TRIGGER invoices_issued_buyer_reference_immutable BEFORE UPDATE OF invoice_buyer_reference
+			ON invoices
+			WHEN OLD.invoice_status != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "add_invoice_line_items_issued_no_delete",
+		Sql: `
+			CREATE TRIGGER invoice_line_items_issued_no_delete BEFORE DELETE ON invoice_line_items
+			WHEN (SELECT invoice_status FROM invoices WHERE invoice_id = OLD.line_item_invoice_id) != 'draft'
+			BEGIN
+				SELECT RAISE(ABORT, 'issued invoices are immutable');
+			END;
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "add_usage_events_user_index",
+		Sql: `
+			CREATE INDEX usage_events_user_kind ON usage_events(usage_event_user_id, usage_event_kind, usage_event_created_at);
+		`,
+	},
+}
+
+func createMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			migration_version INTEGER NOT NULL PRIMARY KEY,
+			migration_name VARCHAR NOT NULL,
+			migration_applied_at INTEGER NOT NULL
+		);
+	`)
+	return err
+}
+
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query("SELECT migration_version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]bool{}
+	for rows.Next() {
+		var version int
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func Pending(db *sql.DB) ([]Migration, error) {
+	if err := createMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+func Migrate(db *sql.DB) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range pending {
+		tx, err := db.Begin()
+		if err != nil {
+			return pending[:i], err
+		}
+
+		if _, err = tx.Exec(m.Sql); err != nil {
+			tx.Rollback()
+			return pending[:i], err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO schema_migrations VALUES(?, ?, ?)",
+			m.Version,
+			m.Name,
+			time.Now().Unix(),
+		)
+		if err != nil {
+			tx.Rollback()
+			return pending[:i], err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return pending[:i], err
+		}
+	}
+
+	return pending, nil
+}
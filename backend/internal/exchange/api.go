@@ -0,0 +1,139 @@
+package exchange
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MAX_IMPORT_BYTES fits the full history of the ECB reference rates.
+const MAX_IMPORT_BYTES = 32 << 20
+
+type ExchangeApi struct {
+	rates *Rates
+}
+
+func invalidFilter(field, description string) error {
+	return apperr.Invalid("Invalid exchange rate filter", apperr.Field(field, description))
+}
+
+// ParseFilter reads a filter from the query parameters base, quote, from, to
+// and limit.
+func ParseFilter(values url.Values) (*Filter, error) {
+	filter := &Filter{
+		Base:  strings.ToUpper(values.Get("base")),
+		Quote: strings.ToUpper(values.Get("quote")),
+		Limit: DEFAULT_LIMIT,
+	}
+
+	for name, value := range map[string]string{"base": filter.Base, "quote": filter.Quote} {
+		if value != "" && !money.Valid(value) {
+			return nil, invalidFilter(name, "must be an ISO 4217 code")
+		}
+	}
+
+	for name, value := range map[string]*string{"from": &filter.From, "to": &filter.To} {
+		*value = values.Get(name)
+		if _, err := time.Parse(time.DateOnly, *value); *value != "" && err != nil {
+			return nil, invalidFilter(name, "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > MAX_LIMIT {
+			return nil, invalidFilter("limit", "must be between 1 and "+strconv.Itoa(MAX_LIMIT))
+		}
+	}
+
+	return filter, nil
+}
+
+func (ea *ExchangeApi) GetRatesList(w http.ResponseWriter, req *http.Request) {
+	filter, err := ParseFilter(req.URL.Query())
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+	filter.WorkspaceId = workspace.IdFromContext(req.Context())
+
+	rates, err := ea.rates.List(req.Context(), filter)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading exchange rates"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetExchangeRatesResponse{Rates: rates})
+}
+
+// ImportRates takes an ECB XML or CSV file as the request body.
+func (ea *ExchangeApi) ImportRates(w http.ResponseWriter, req *http.Request) {
+	req.Body = http.MaxBytesReader(w, req.Body, MAX_IMPORT_BYTES)
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading exchange rates from request"))
+		return
+	}
+
+	res, err := ea.rates.Import(req.Context(), workspace.IdFromContext(req.Context()), data, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Exchange rates couldn't be imported"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, res)
+}
+
+// LookupRate takes the currencies and date from the query parameters from,
+// to and date. To defaults to the base currency of the workspace and date
+// to today.
+func (ea *ExchangeApi) LookupRate(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	workspace_id := workspace.IdFromContext(req.Context())
+
+	from, to := strings.ToUpper(query.Get("from")), strings.ToUpper(query.Get("to"))
+	if to == "" {
+		var err error
+		if to, err = ea.rates.BaseCurrency(req.Context(), workspace_id); err != nil {
+			apperr.Write(w, req, apperr.Wrap(err, "Error reading the base currency"))
+			return
+		}
+	}
+	for name, value := range map[string]string{"from": from, "to": to} {
+		if !money.Valid(value) {
+			apperr.Write(w, req, apperr.Invalid("Invalid currency", apperr.Field(name, "must be an ISO 4217 code")))
+			return
+		}
+	}
+
+	date := query.Get("date")
+	if date == "" {
+		date = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		apperr.Write(w, req, apperr.Invalid("Invalid date", apperr.Field("date", "must be a date formatted as YYYY-MM-DD")))
+		return
+	}
+
+	rate, err := ea.rates.Rate(req.Context(), workspace_id, from, to, date)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error looking up the exchange rate"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.ExchangeRateResponse{Rate: rate})
+}
+
+func NewExchangeApi(rs *Rates) *ExchangeApi {
+	return &ExchangeApi{rates: rs}
+}
@@ -0,0 +1,370 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	SOURCE_ECB     = "ecb"
+	SOURCE_CSV     = "csv"
+	SOURCE_DERIVED = "derived"
+
+	// RATE_SCALE is the number of decimal places of rates derived from
+	// others, imported rates are kept as they are.
+	RATE_SCALE = 10
+
+	// MAX_RATE_AGE_DAYS is how far back a lookup goes for the last rate
+	// before a date, central banks publish none on weekends and holidays.
+	MAX_RATE_AGE_DAYS = 14
+
+	DEFAULT_LIMIT = 100
+	MAX_LIMIT     = 1000
+)
+
+var (
+	ErrNoRate      = apperr.New(apperr.CODE_NOT_FOUND, "no exchange rate for the currencies and date")
+	ErrNoWorkspace = apperr.New(apperr.CODE_NOT_FOUND, "workspace not found")
+)
+
+const (
+	AUDIT_TARGET = "exchange_rate"
+	AUDIT_IMPORT = "exchange_rates.import"
+)
+
+// Rates are the exchange rates of a workspace, imported from files so that
+// nothing depends on network access. There is one rate per currency pair and
+// day, importing a day again replaces it.
+type Rates struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	upsert_stmt, list_stmt, lookup_stmt, base_stmt *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRate(row scanner) (*pb.ExchangeRate, error) {
+	rate := &pb.ExchangeRate{}
+	err := row.Scan(
+		&rate.Date,
+		&rate.Base,
+		&rate.Quote,
+		&rate.Rate,
+		&rate.Source,
+		&rate.ImportedAt,
+	)
+	return rate, err
+}
+
+// BaseCurrency returns the currency the workspace reports in.
+func (rs *Rates) BaseCurrency(ctx context.Context, workspace_id uint32) (_ string, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Rates.BaseCurrency")
+	defer end(&err)
+
+	var base string
+	err = rs.base_stmt.QueryRowContext(ctx, workspace_id).Scan(&base)
+	if err == sql.ErrNoRows {
+		return "", ErrNoWorkspace
+	}
+	return base, err
+}
+
+// Import parses an ECB XML or CSV file and adds its rates, replacing those
+// of the same pairs and days.
+func (rs *Rates) Import(ctx context.Context, workspace_id uint32, data []byte, actor *audit.Actor) (_ *pb.ImportExchangeRatesResponse, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Rates.Import")
+	defer end(&err)
+
+	rates, source, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := rs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res := &pb.ImportExchangeRatesResponse{}
+	for _, rate := range rates {
+		_, err = tx.Stmt(rs.upsert_stmt).ExecContext(ctx, rate.Date, rate.Base, rate.Quote, rate.Rate, source, now, workspace_id)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Imported++
+		if res.From == "" || rate.Date < res.From {
+			res.From = rate.Date
+		}
+		if rate.Date > res.To {
+			res.To = rate.Date
+		}
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_IMPORT, AUDIT_TARGET, 0, audit.Diff(nil, map[string]string{
+		"source":   source,
+		"imported": fmt.Sprint(res.Imported),
+		"from":     res.From,
+		"to":       res.To,
+	}))
+	if err = rs.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Filter selects rates of one workspace, zero values match everything.
+// From and To are dates formatted as YYYY-MM-DD.
+type Filter struct {
+	WorkspaceId uint32
+	Base        string
+	Quote       string
+	From        string
+	To          string
+	Limit       int
+}
+
+// List returns the rates matching the filter, newest first.
+func (rs *Rates) List(ctx context.Context, filter *Filter) (_ []*pb.ExchangeRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Rates.List")
+	defer end(&err)
+
+	rows, err := rs.list_stmt.QueryContext(ctx, filter.WorkspaceId, filter.Base, filter.Quote, filter.From, filter.To, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []*pb.ExchangeRate{}
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+type pair struct {
+	base, quote string
+}
+
+// latestRates keeps the newest rate of every pair.
+type latestRates map[pair]*pb.ExchangeRate
+
+// between returns how many units of to one unit of from is worth, from the
+// rate of the pair or the inverse of that of the reverse pair.
+func (l latestRates) between(from string, to string) (decimal.Decimal, *pb.ExchangeRate, bool) {
+	if rate, ok := l[pair{from, to}]; ok {
+		return decimal.RequireFromString(rate.Rate), rate, true
+	}
+	if rate, ok := l[pair{to, from}]; ok {
+		return decimal.NewFromInt(1).Div(decimal.RequireFromString(rate.Rate)), rate, true
+	}
+	return decimal.Zero, nil, false
+}
+
+// Rate returns how many units of to one unit of from was worth on the date,
+// from the last rate up to MAX_RATE_AGE_DAYS before it. Rates missing in
+// the table are derived from the reverse pair or from two rates against a
+// common currency, e.g. USD to CHF from the EUR rates of the ECB.
+func (rs *Rates) Rate(ctx context.Context, workspace_id uint32, from string, to string, date string) (_ *pb.ExchangeRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Rates.Rate")
+	defer end(&err)
+
+	if from == to {
+		return &pb.ExchangeRate{Date: date, Base: from, Quote: to, Rate: "1"}, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, err
+	}
+	oldest := day.AddDate(0, 0, -MAX_RATE_AGE_DAYS).Format(time.DateOnly)
+
+	rows, err := rs.lookup_stmt.QueryContext(ctx, workspace_id, from, to, date, oldest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := latestRates{}
+	for rows.Next() {
+		rate, err := scanRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := latest[pair{rate.Base, rate.Quote}]; !ok {
+			latest[pair{rate.Base, rate.Quote}] = rate
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if value, rate, ok := latest.between(from, to); ok {
+		source := rate.Source
+		if rate.Base != from {
+			source = SOURCE_DERIVED
+		}
+		return &pb.ExchangeRate{
+			Date:       rate.Date,
+			Base:       from,
+			Quote:      to,
+			Rate:       value.Round(RATE_SCALE).String(),
+			Source:     source,
+			ImportedAt: rate.ImportedAt,
+		}, nil
+	}
+
+	common := map[string]bool{}
+	for p := range latest {
+		common[p.base], common[p.quote] = true, true
+	}
+	delete(common, from)
+	delete(common, to)
+	candidates := []string{}
+	for currency := range common {
+		candidates = append(candidates, currency)
+	}
+	sort.Strings(candidates)
+
+	// The cross rate is as old as the older of its two rates, the newest
+	// one wins.
+	var best *pb.ExchangeRate
+	for _, currency := range candidates {
+		first, first_rate, ok := latest.between(from, currency)
+		if !ok {
+			continue
+		}
+		second, second_rate, ok := latest.between(currency, to)
+		if !ok {
+			continue
+		}
+
+		rate := &pb.ExchangeRate{
+			Date:       min(first_rate.Date, second_rate.Date),
+			Base:       from,
+			Quote:      to,
+			Rate:       first.Mul(second).Round(RATE_SCALE).String(),
+			Source:     SOURCE_DERIVED,
+			ImportedAt: max(first_rate.ImportedAt, second_rate.ImportedAt),
+		}
+		if best == nil || rate.Date > best.Date {
+			best = rate
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %s to %s on %s", ErrNoRate, from, to, date)
+	}
+
+	return best, nil
+}
+
+func (rs *Rates) Close() error {
+	stmts := []*sql.Stmt{
+		rs.upsert_stmt,
+		rs.list_stmt,
+		rs.lookup_stmt,
+		rs.base_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const RATE_COLUMNS = `
+	exchange_rate_date,
+	exchange_rate_base,
+	exchange_rate_quote,
+	exchange_rate_value,
+	exchange_rate_source,
+	exchange_rate_imported_at
+`
+
+func NewRates(db *sql.DB, audit_log *audit.Log) (*Rates, error) {
+	upsert_stmt, err := db.Prepare(`
+		INSERT INTO exchange_rates (
+			exchange_rate_date,
+			exchange_rate_base,
+			exchange_rate_quote,
+			exchange_rate_value,
+			exchange_rate_source,
+			exchange_rate_imported_at,
+			exchange_rate_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (exchange_rate_workspace_id, exchange_rate_base, exchange_rate_quote, exchange_rate_date)
+		DO UPDATE SET exchange_rate_value = excluded.exchange_rate_value,
+			exchange_rate_source = excluded.exchange_rate_source,
+			exchange_rate_imported_at = excluded.exchange_rate_imported_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(`
+		SELECT ` + RATE_COLUMNS + `
+		FROM exchange_rates
+		WHERE exchange_rate_workspace_id = ?1
+			AND (?2 = '' OR exchange_rate_base = ?2)
+			AND (?3 = '' OR exchange_rate_quote = ?3)
+			AND (?4 = '' OR exchange_rate_date >= ?4)
+			AND (?5 = '' OR exchange_rate_date <= ?5)
+		ORDER BY exchange_rate_date DESC, exchange_rate_base, exchange_rate_quote
+		LIMIT ?6
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup_stmt, err := db.Prepare(`
+		SELECT ` + RATE_COLUMNS + `
+		FROM exchange_rates
+		WHERE exchange_rate_workspace_id = ?1
+			AND (exchange_rate_base IN (?2, ?3) OR exchange_rate_quote IN (?2, ?3))
+			AND exchange_rate_date <= ?4 AND exchange_rate_date >= ?5
+		ORDER BY exchange_rate_date DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	base_stmt, err := db.Prepare("SELECT workspace_base_currency FROM workspaces WHERE workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rates{
+		db:          db,
+		audit:       audit_log,
+		upsert_stmt: upsert_stmt,
+		list_stmt:   list_stmt,
+		lookup_stmt: lookup_stmt,
+		base_stmt:   base_stmt,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	pb "invoice-manager/main/proto"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ECB_BASE is the currency the rates of the European Central Bank are
+// quoted against.
+const ECB_BASE = "EUR"
+
+var ErrInvalidFile = apperr.New(apperr.CODE_INVALID_ARGUMENT, "exchange rate file is invalid")
+
+// Dates of CSV files, the ECB writes "02 January 2006" in some of its
+// downloads.
+var dateLayouts = []string{time.DateOnly, "02 January 2006", "2 January 2006"}
+
+// ecbEnvelope is the reference rates XML of the ECB, e.g. eurofxref-daily.xml
+// or eurofxref-hist.xml, with a Cube of rates against the euro per day.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Parse reads the rates of an ECB XML file or a CSV file, telling them
+// apart by the leading '<' of XML. The source is ecb or csv.
+func Parse(data []byte) ([]*pb.ExchangeRate, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		rates, err := parseECB(data)
+		return rates, SOURCE_ECB, err
+	}
+	rates, err := parseCSV(data)
+	return rates, SOURCE_CSV, err
+}
+
+func parseECB(data []byte) ([]*pb.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	rates := []*pb.ExchangeRate{}
+	for _, day := range envelope.Days {
+		for _, r := range day.Rates {
+			rate, err := checkRate(day.Time, ECB_BASE, r.Currency, r.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s %s: %s", ErrInvalidFile, day.Time, r.Currency, err)
+			}
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates found", ErrInvalidFile)
+	}
+	return rates, nil
+}
+
+// parseCSV reads rates one per row with the columns date, base, quote and
+// rate in any order, or one day per row the way the ECB publishes them,
+// with a Date column and one column of rates against the euro per
+// currency. Rates of N/A or empty are skipped.
+func parseCSV(data []byte) ([]*pb.ExchangeRate, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("%w: no date column", ErrInvalidFile)
+	}
+	_, has_base := columns["base"]
+	_, has_quote := columns["quote"]
+	_, has_rate := columns["rate"]
+	long := has_base && has_quote && has_rate
+
+	rates := []*pb.ExchangeRate{}
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+		}
+
+		field := func(column int) string {
+			if column < len(record) {
+				return strings.TrimSpace(record[column])
+			}
+			return ""
+		}
+
+		date := field(columns["date"])
+		if long {
+			rate, err := checkRate(date, field(columns["base"]), field(columns["quote"]), field(columns["rate"]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidFile, line, err)
+			}
+			rates = append(rates, rate)
+			continue
+		}
+
+		for column, name := range header {
+			quote := strings.ToUpper(strings.TrimSpace(name))
+			value := field(column)
+			if column == columns["date"] || quote == "" || value == "" || strings.EqualFold(value, "N/A") {
+				continue
+			}
+			rate, err := checkRate(date, ECB_BASE, quote, value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %s", ErrInvalidFile, line, err)
+			}
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates found", ErrInvalidFile)
+	}
+	return rates, nil
+}
+
+// checkRate validates a rate read from a file and normalizes it.
+func checkRate(date string, base string, quote string, value string) (*pb.ExchangeRate, error) {
+	day, err := parseDate(date)
+	if err != nil {
+		return nil, fmt.Errorf("date %q is not formatted as YYYY-MM-DD", date)
+	}
+
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	for _, code := range []string{base, quote} {
+		if !money.Valid(code) {
+			return nil, fmt.Errorf("%q is not an ISO 4217 code", code)
+		}
+	}
+	if base == quote {
+		return nil, fmt.Errorf("%s is quoted against itself", base)
+	}
+
+	rate, err := decimal.NewFromString(value)
+	if err != nil || !rate.IsPositive() {
+		return nil, fmt.Errorf("rate %q is not a decimal number greater than zero", value)
+	}
+
+	return &pb.ExchangeRate{Date: day, Base: base, Quote: quote, Rate: rate.String()}, nil
+}
+
+func parseDate(value string) (string, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var date time.Time
+		if date, err = time.Parse(layout, value); err == nil {
+			return date.Format(time.DateOnly), nil
+		}
+	}
+	return "", err
+}
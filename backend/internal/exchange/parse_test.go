@@ -0,0 +1,115 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+const ecbDaily = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2026-03-06">
+			<Cube currency="USD" rate="1.0832"/>
+			<Cube currency="JPY" rate="162.30"/>
+		</Cube>
+		<Cube time="2026-03-05">
+			<Cube currency="USD" rate="1.0790"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		source string
+		// Rates as "date base quote rate".
+		want []string
+	}{
+		{
+			name:   "ECB XML",
+			data:   ecbDaily,
+			source: SOURCE_ECB,
+			want:   []string{"2026-03-06 EUR USD 1.0832", "2026-03-06 EUR JPY 162.3", "2026-03-05 EUR USD 1.079"},
+		},
+		{
+			name:   "ECB XML with a byte order mark",
+			data:   "\xef\xbb\xbf\n" + ecbDaily,
+			source: SOURCE_ECB,
+			want:   []string{"2026-03-06 EUR USD 1.0832", "2026-03-06 EUR JPY 162.3", "2026-03-05 EUR USD 1.079"},
+		},
+		{
+			name:   "one rate per row",
+			data:   "date,base,quote,rate\n2026-03-06,usd,chf,0.8812\n2026-03-06, GBP, EUR, 1.19\n",
+			source: SOURCE_CSV,
+			want:   []string{"2026-03-06 USD CHF 0.8812", "2026-03-06 GBP EUR 1.19"},
+		},
+		{
+			name:   "columns in another order",
+			data:   "Rate,Quote,Base,Date\n0.8812,CHF,USD,2026-03-06\n",
+			source: SOURCE_CSV,
+			want:   []string{"2026-03-06 USD CHF 0.8812"},
+		},
+		{
+			name:   "one day per row like the ECB",
+			data:   "Date,USD,JPY,BGN,\n06 March 2026,1.0832,162.30,N/A,\n5 March 2026,1.0790,,1.9558,\n",
+			source: SOURCE_CSV,
+			want:   []string{"2026-03-06 EUR USD 1.0832", "2026-03-06 EUR JPY 162.3", "2026-03-05 EUR USD 1.079", "2026-03-05 EUR BGN 1.9558"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rates, source, err := Parse([]byte(test.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if source != test.source {
+				t.Errorf("source = %q, want %q", source, test.source)
+			}
+
+			got := []string{}
+			for _, rate := range rates {
+				got = append(got, fmt.Sprintf("%s %s %s %s", rate.Date, rate.Base, rate.Quote, rate.Rate))
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("rates = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"broken XML", "<Envelope><Cube>"},
+		{"XML without rates", "<Envelope><Cube></Cube></Envelope>"},
+		{"XML with an unknown currency", `<Envelope><Cube><Cube time="2026-03-06"><Cube currency="XYZ" rate="1"/></Cube></Cube></Envelope>`},
+		{"no date column", "base,quote,rate\nEUR,USD,1.08\n"},
+		{"header only", "date,base,quote,rate\n"},
+		{"invalid date", "date,base,quote,rate\n03/06/2026,EUR,USD,1.08\n"},
+		{"currency quoted against itself", "date,base,quote,rate\n2026-03-06,EUR,eur,1\n"},
+		{"zero rate", "date,base,quote,rate\n2026-03-06,EUR,USD,0\n"},
+		{"negative rate", "Date,USD\n2026-03-06,-1.08\n"},
+		{"rate isn't a number", "date,base,quote,rate\n2026-03-06,EUR,USD,1.08 USD\n"},
+		{"unknown currency column", "Date,Dollar\n2026-03-06,1.08\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := Parse([]byte(test.data))
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("Parse() error = %v, want %v", err, ErrInvalidFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	"time"
+)
+
+const (
+	DEFAULT_MIN_FREE_BYTES = 256 << 20
+	DEFAULT_MAX_QUEUE      = 10
+)
+
+// Database checks that SQLite answers and that the database file can be
+// written to, by touching the single row of health_probes.
+func Database(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("database is unreachable: %w", err)
+		}
+
+		_, err := db.ExecContext(ctx, "INSERT OR REPLACE INTO health_probes VALUES(1, ?)", time.Now().Unix())
+		if err != nil {
+			return fmt.Errorf("database is not writable: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// DiskSpace fails when less than min_free bytes are left on the file system
+// of dir, and warns when less than twice that is left.
+func DiskSpace(dir string, min_free int64) Check {
+	return func(ctx context.Context) error {
+		free, err := freeBytes(dir)
+		if err != nil {
+			return err
+		}
+
+		if free < min_free {
+			return fmt.Errorf("%d bytes free in %s, at least %d are needed", free, dir, min_free)
+		}
+		if free < 2*min_free {
+			return &Warning{Detail: fmt.Sprintf("%d bytes free in %s", free, dir)}
+		}
+
+		return nil
+	}
+}
+
+// DiskSpaceFromEnv reads the minimum from INVOICER_HEALTH_MIN_FREE_BYTES.
+func DiskSpaceFromEnv(dir string) Check {
+	return DiskSpace(dir, helpers.EnvInt("INVOICER_HEALTH_MIN_FREE_BYTES", DEFAULT_MIN_FREE_BYTES))
+}
+
+// QueueBacklog warns when more than max uploads wait for a conversion. It
+// never fails, as the queue drains on its own.
+func QueueBacklog(max int64) Check {
+	return func(ctx context.Context) error {
+		if depth := telemetry.QueueDepth(); depth > max {
+			return &Warning{Detail: fmt.Sprintf("%d conversions queued, more than %d", depth, max)}
+		}
+		return nil
+	}
+}
+
+// QueueBacklogFromEnv reads the maximum from INVOICER_HEALTH_MAX_QUEUE.
+func QueueBacklogFromEnv() Check {
+	return QueueBacklog(helpers.EnvInt("INVOICER_HEALTH_MAX_QUEUE", DEFAULT_MAX_QUEUE))
+}
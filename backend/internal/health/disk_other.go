@@ -0,0 +1,7 @@
+//go:build !unix
+
+package health
+
+func freeBytes(dir string) (int64, error) {
+	return 0, &Warning{Detail: "free disk space can't be checked on this platform"}
+}
@@ -0,0 +1,214 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/helpers"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+)
+
+const (
+	STATUS_PASS = "pass"
+	STATUS_WARN = "warn"
+	STATUS_FAIL = "fail"
+
+	// Results are reused for this long, so that probes hitting the endpoints
+	// every second don't run docker or write to the database every second.
+	CACHE_FOR     = 5 * time.Second
+	CHECK_TIMEOUT = 5 * time.Second
+)
+
+// Check inspects one component. It fails the component by returning an
+// error, or degrades it by returning a Warning.
+type Check func(ctx context.Context) error
+
+// Warning is returned by checks of components that still work, but need
+// attention soon.
+type Warning struct {
+	Detail string
+}
+
+func (w *Warning) Error() string {
+	return w.Detail
+}
+
+type component struct {
+	name     string
+	check    Check
+	liveness bool
+}
+
+// Checker runs the checks of all components. It serves /healthz, /readyz
+// and the grpc.health.v1 service.
+type Checker struct {
+	services   map[string]bool
+	components []component
+
+	mu      sync.Mutex
+	results map[string]*pb.ComponentHealth
+	checked map[string]time.Time
+}
+
+// NewChecker reports the overall status for the given gRPC services, e.g.
+// pbconnect.PingServiceName.
+func NewChecker(services ...string) *Checker {
+	names := map[string]bool{"": true, grpchealth.HealthV1ServiceName: true}
+	for _, service := range services {
+		names[service] = true
+	}
+
+	return &Checker{
+		services: names,
+		results:  map[string]*pb.ComponentHealth{},
+		checked:  map[string]time.Time{},
+	}
+}
+
+// Add registers a component the service needs to be ready.
+func (c *Checker) Add(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check})
+}
+
+// AddLiveness registers a component without which the process is broken
+// for good and had better be restarted.
+func (c *Checker) AddLiveness(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check, liveness: true})
+}
+
+func run(ctx context.Context, comp component) *pb.ComponentHealth {
+	// Results are shared, so one client going away mustn't fail the check.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), CHECK_TIMEOUT)
+	defer cancel()
+
+	start := time.Now()
+	err := comp.check(ctx)
+	result := &pb.ComponentHealth{
+		Name:       comp.name,
+		Status:     STATUS_PASS,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+
+	var warning *Warning
+	if errors.As(err, &warning) {
+		result.Status = STATUS_WARN
+		result.Detail = warning.Detail
+	} else if err != nil {
+		result.Status = STATUS_FAIL
+		result.Detail = err.Error()
+	}
+
+	return result
+}
+
+func (c *Checker) cached(name string) *pb.ComponentHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked[name]) > CACHE_FOR {
+		return nil
+	}
+	return c.results[name]
+}
+
+func (c *Checker) store(result *pb.ComponentHealth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results[result.Name] = result
+	c.checked[result.Name] = time.Now()
+}
+
+// Run checks the components concurrently, only liveness ones if asked to.
+// The overall status is the worst status of the components.
+func (c *Checker) Run(ctx context.Context, liveness_only bool) *pb.HealthResponse {
+	components := []component{}
+	for _, comp := range c.components {
+		if !liveness_only || comp.liveness {
+			components = append(components, comp)
+		}
+	}
+
+	res := &pb.HealthResponse{Status: STATUS_PASS, Components: make([]*pb.ComponentHealth, len(components))}
+
+	var wg sync.WaitGroup
+	for i, comp := range components {
+		if res.Components[i] = c.cached(comp.name); res.Components[i] != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, comp component) {
+			defer wg.Done()
+			res.Components[i] = run(ctx, comp)
+			c.store(res.Components[i])
+		}(i, comp)
+	}
+	wg.Wait()
+
+	for _, result := range res.Components {
+		res.Status = worst(res.Status, result.Status)
+	}
+
+	return res
+}
+
+func worst(a, b string) string {
+	if a == STATUS_FAIL || b == STATUS_FAIL {
+		return STATUS_FAIL
+	}
+	if a == STATUS_WARN || b == STATUS_WARN {
+		return STATUS_WARN
+	}
+	return STATUS_PASS
+}
+
+func respond(w http.ResponseWriter, res *pb.HealthResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	code := http.StatusOK
+	if res.Status == STATUS_FAIL {
+		code = http.StatusServiceUnavailable
+	}
+	helpers.JsonResponse(w, code, res)
+}
+
+// Live tells whether the process should be restarted.
+func (c *Checker) Live(w http.ResponseWriter, req *http.Request) {
+	respond(w, c.Run(req.Context(), true))
+}
+
+// Ready tells whether the service can take requests, with the status of
+// every component.
+func (c *Checker) Ready(w http.ResponseWriter, req *http.Request) {
+	respond(w, c.Run(req.Context(), false))
+}
+
+func serving(status string) grpchealth.Status {
+	if status == STATUS_FAIL {
+		return grpchealth.StatusNotServing
+	}
+	return grpchealth.StatusServing
+}
+
+// Check implements grpchealth.Checker. The empty service and the names of
+// the given services report the overall status, component names report
+// that of the component.
+func (c *Checker) Check(ctx context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	res := c.Run(ctx, false)
+	if c.services[req.Service] {
+		return &grpchealth.CheckResponse{Status: serving(res.Status)}, nil
+	}
+
+	for _, result := range res.Components {
+		if result.Name == req.Service {
+			return &grpchealth.CheckResponse{Status: serving(result.Status)}, nil
+		}
+	}
+
+	return nil, connect.NewError(connect.CodeNotFound, errors.New("unknown service "+req.Service))
+}
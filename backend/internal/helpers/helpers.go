@@ -4,31 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"invoice-manager/main/internal/apperr"
 	"invoice-manager/main/internal/constants"
 	"io"
-	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
-)
-
-type malformedRequest struct {
-	status int
-	msg    string
-}
-
-func (mr *malformedRequest) Error() string {
-	return mr.msg
-}
 
-func jsonError(w http.ResponseWriter, err error) {
-	var mr *malformedRequest
-	if errors.As(err, &mr) {
-		http.Error(w, mr.msg, mr.status)
-	} else {
-		log.Print(err.Error())
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-	}
-}
+	"github.com/gorilla/mux"
+)
 
 func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
 	ct := r.Header.Get("Content-Type")
@@ -36,7 +21,7 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(ct, ";")[0]))
 		if mediaType != "application/json" {
 			msg := "Content-Type header is not application/json"
-			return &malformedRequest{status: http.StatusUnsupportedMediaType, msg: msg}
+			return apperr.New(apperr.CODE_UNSUPPORTED_MEDIA_TYPE, msg)
 		}
 	}
 
@@ -56,11 +41,11 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 				"Request body contains badly-formed JSON (at position %d)",
 				syntaxError.Offset,
 			)
-			return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+			return apperr.Invalid(msg)
 
 		case errors.Is(err, io.ErrUnexpectedEOF):
-			msg := fmt.Sprintln("Request body contains badly-formed JSON")
-			return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+			msg := "Request body contains badly-formed JSON"
+			return apperr.Invalid(msg)
 
 		case errors.As(err, &unmarshalTypeError):
 			msg := fmt.Sprintf(
@@ -68,30 +53,30 @@ func DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) err
 				unmarshalTypeError.Field,
 				unmarshalTypeError.Offset,
 			)
-			return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+			return apperr.Invalid(msg, apperr.Field(unmarshalTypeError.Field, "invalid value"))
 
 		case strings.HasPrefix(err.Error(), "json: unknown field "):
 			fieldName := strings.TrimPrefix(err.Error(), "json: unknown field ")
 			msg := fmt.Sprintf("Request body contains unknown field %s", fieldName)
-			return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+			return apperr.Invalid(msg)
 
 		case errors.Is(err, io.EOF):
 			msg := "Request body must not be empty"
-			return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+			return apperr.Invalid(msg)
 
 		case err.Error() == "http: request body too large":
 			msg := "Request body must not be larger than 1MB"
-			return &malformedRequest{status: http.StatusRequestEntityTooLarge, msg: msg}
+			return apperr.New(apperr.CODE_PAYLOAD_TOO_LARGE, msg)
 
 		default:
-			return err
+			return apperr.Wrap(err, "Couldn't read the request body")
 		}
 	}
 
 	err = dec.Decode(&struct{}{})
 	if !errors.Is(err, io.EOF) {
 		msg := "Request body must only contain a single JSON object"
-		return &malformedRequest{status: http.StatusBadRequest, msg: msg}
+		return apperr.Invalid(msg)
 	}
 
 	return nil
@@ -104,11 +89,33 @@ func JsonResponse(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(response)
 }
 
-func ErrorResponse(w http.ResponseWriter, code int, message string, err error) {
-	log.Println(err)
-	JsonResponse(w, code, map[string]string{"error": message})
-}
-
 func PublicUrlToFile(path string) string {
 	return "http://" + constants.HTTP_ADDR + "/" + path
 }
+
+// NullableId maps the zero ID to NULL for optional foreign key columns.
+func NullableId(id uint32) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// PathId parses the ID in the named route variable.
+func PathId(req *http.Request, name string) (uint32, error) {
+	id, err := strconv.ParseUint(mux.Vars(req)[name], 10, 32)
+	if err != nil || id == 0 {
+		return 0, apperr.Invalid("Invalid ID in the URL", apperr.Field(name, "must be a positive integer"))
+	}
+	return uint32(id), nil
+}
+
+// EnvInt reads an integer setting from the environment. Unset or invalid
+// values fall back to the default.
+func EnvInt(name string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,348 @@
+package invoice
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/ubl"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+type InvoiceApi struct {
+	invoices *Invoices
+}
+
+func (ia *InvoiceApi) GetInvoicesList(w http.ResponseWriter, req *http.Request) {
+	filter, err := ParseFilter(req.URL.Query())
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+	filter.WorkspaceId = workspace.IdFromContext(req.Context())
+
+	invoices, total, err := ia.invoices.Search(req.Context(), filter)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading invoices"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetInvoicesResponse{Invoices: invoices, Total: total})
+}
+
+func (ia *InvoiceApi) GetInvoice(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoice, err := ia.invoices.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading invoice"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) CreateInvoice(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveInvoiceRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoice, err := ia.invoices.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) UpdateInvoice(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveInvoiceRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoice, err := ia.invoices.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) DeleteInvoice(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ia.invoices.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the invoice"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ia *InvoiceApi) RenderInvoice(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	filled, err := ia.invoices.Render(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice couldn't be rendered"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, filled)
+}
+
+func (ia *InvoiceApi) IssueInvoice(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoice, err := ia.invoices.Issue(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice couldn't be issued"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) CreateCreditNote(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.CreateCreditNoteRequest
+	if req.ContentLength != 0 {
+		if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	credit_note, err := ia.invoices.CreateCreditNote(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Credit note couldn't be issued"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.InvoiceResponse{Invoice: credit_note})
+}
+
+func (ia *InvoiceApi) GetCreditNotes(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	credit_notes, err := ia.invoices.CreditNotes(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading credit notes"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetInvoicesResponse{Invoices: credit_notes, Total: uint32(len(credit_notes))})
+}
+
+// transition moves the invoice to the status, the request body with a note
+// is optional.
+func (ia *InvoiceApi) transition(w http.ResponseWriter, req *http.Request, to string) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.TransitionInvoiceRequest
+	if req.ContentLength != 0 {
+		if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	invoice, err := ia.invoices.Transition(req.Context(), workspace.IdFromContext(req.Context()), id, to, body.Note, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice status couldn't be changed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) SendInvoice(w http.ResponseWriter, req *http.Request) {
+	ia.transition(w, req, STATUS_SENT)
+}
+
+func (ia *InvoiceApi) VoidInvoice(w http.ResponseWriter, req *http.Request) {
+	ia.transition(w, req, STATUS_VOID)
+}
+
+func (ia *InvoiceApi) AcceptQuote(w http.ResponseWriter, req *http.Request) {
+	ia.transition(w, req, STATUS_ACCEPTED)
+}
+
+func (ia *InvoiceApi) DeclineQuote(w http.ResponseWriter, req *http.Request) {
+	ia.transition(w, req, STATUS_DECLINED)
+}
+
+// ConvertQuote converts an issued quote into a draft invoice, the request
+// body with the percent to convert is optional.
+func (ia *InvoiceApi) ConvertQuote(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.ConvertQuoteRequest
+	if req.ContentLength != 0 {
+		if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	invoice, err := ia.invoices.Convert(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Quote couldn't be converted"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.InvoiceResponse{Invoice: invoice})
+}
+
+func (ia *InvoiceApi) GetConversions(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoices, err := ia.invoices.Conversions(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading quote conversions"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetInvoicesResponse{Invoices: invoices, Total: uint32(len(invoices))})
+}
+
+func (ia *InvoiceApi) GetInvoiceEvents(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	events, err := ia.invoices.Events(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading invoice events"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetInvoiceEventsResponse{Events: events})
+}
+
+func (ia *InvoiceApi) DownloadInvoicePdf(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace_id := workspace.IdFromContext(req.Context())
+	invoice, err := ia.invoices.Retrieve(req.Context(), workspace_id, id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading invoice"))
+		return
+	}
+
+	pdf_path, err := ia.invoices.Pdf(req.Context(), workspace_id, id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice PDF couldn't be printed"))
+		return
+	}
+
+	file, err := os.Open(pdf_path)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice PDF couldn't be read"))
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice PDF couldn't be read"))
+		return
+	}
+
+	filename := filepath.Base(invoice.Number) + ".pdf"
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	http.ServeContent(w, req, filename, info.ModTime(), file)
+}
+
+func (ia *InvoiceApi) DownloadInvoiceUbl(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace_id := workspace.IdFromContext(req.Context())
+	invoice, err := ia.invoices.Retrieve(req.Context(), workspace_id, id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading invoice"))
+		return
+	}
+
+	document, err := ia.invoices.Ubl(req.Context(), workspace_id, id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Invoice couldn't be exported as UBL"))
+		return
+	}
+
+	filename := filepath.Base(invoice.Number) + ".xml"
+	w.Header().Set("Content-Type", ubl.CONTENT_TYPE)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(document)
+}
+
+func NewInvoiceApi(is *Invoices) *InvoiceApi {
+	return &InvoiceApi{invoices: is}
+}
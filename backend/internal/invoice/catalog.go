@@ -0,0 +1,69 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/catalog"
+	pb "invoice-manager/main/proto"
+	"strings"
+)
+
+// fillFromCatalog copies the values of the catalog items lines reference
+// into the fields the lines leave empty, once the currency of the request
+// is known. Lines without a description are new and take the description
+// of the item, those can't reference archived items. Lines keep what they
+// were filled in with when the item changes later.
+func (is *Invoices) fillFromCatalog(ctx context.Context, workspace_id uint32, req *pb.SaveInvoiceRequest) error {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+
+	fields := []apperr.FieldError{}
+	for i, item := range req.Items {
+		if item == nil || item.CatalogItemId == 0 {
+			continue
+		}
+		field := fmt.Sprintf("items[%d]", i)
+
+		entry, err := is.catalog.Retrieve(ctx, workspace_id, item.CatalogItemId)
+		if err == catalog.ErrIDNotFound {
+			fields = append(fields, apperr.Field(field+".catalogItemId", "no such catalog item in this workspace"))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(item.Sku) == "" {
+			item.Sku = entry.Sku
+		}
+		if strings.TrimSpace(item.Description) == "" {
+			if entry.ArchivedAt != 0 {
+				fields = append(fields, apperr.Field(field+".catalogItemId", "is archived"))
+				continue
+			}
+			item.Description = entry.Name
+			if entry.Description != "" {
+				item.Description += "\n" + entry.Description
+			}
+		}
+		if strings.TrimSpace(item.Unit) == "" {
+			item.Unit = entry.Unit
+		}
+		if strings.TrimSpace(item.UnitPrice) == "" {
+			price, ok := catalog.PriceIn(entry, currency)
+			if !ok {
+				fields = append(fields, apperr.Field(field+".unitPrice", "is required, the catalog item has no price in "+currency))
+				continue
+			}
+			item.UnitPrice = price
+		}
+		if strings.TrimSpace(item.TaxCategory) == "" && item.TaxRateId == 0 && strings.TrimSpace(item.TaxRate) == "" {
+			item.TaxCategory = entry.TaxCategory
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Invoice is invalid", fields...)
+	}
+	return nil
+}
@@ -0,0 +1,419 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Issued invoices are corrected with credit notes, which credit some or all
+// of their lines, and cancelled with a cancellation (Storno), which credits
+// all of them and is issued when the invoice is voided. Both reference the
+// invoice, negate its amounts and are numbered by their own sequence.
+const (
+	KIND_INVOICE      = "invoice"
+	KIND_CREDIT_NOTE  = "credit_note"
+	KIND_CANCELLATION = "cancellation"
+	KIND_QUOTE        = "quote"
+)
+
+var Kinds = []string{KIND_INVOICE, KIND_CREDIT_NOTE, KIND_CANCELLATION, KIND_QUOTE}
+
+var (
+	ErrNotCreditable        = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice can't be credited")
+	ErrCreditExceedsBalance = apperr.New(apperr.CODE_FAILED_PRECONDITION, "credit note exceeds the balance of the invoice")
+	ErrPartiallyCredited    = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice has credit notes, credit the rest of it instead")
+)
+
+// creditable are the statuses of invoices credit notes can be issued for.
+// What they credit of a paid invoice is refunded.
+var creditable = append(slices.Clone(payable), STATUS_PAID)
+
+// Refunds takes back what an invoice has been paid beyond its balance once
+// it is credited. The payment store releases it as credit of the client,
+// which can be applied to other invoices or paid out.
+type Refunds interface {
+	Release(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, amount decimal.Decimal, actor *audit.Actor) error
+}
+
+// SetRefunds sets where credit notes refund paid invoices. Payments depend
+// on invoices, so they are set once both exist; until then credit notes
+// can't exceed the balance.
+func (is *Invoices) SetRefunds(refunds Refunds) {
+	is.refunds = refunds
+}
+
+// negateDiscount copies the discount for a credit note. Percentages stay
+// as they are, fixed amounts are negated and reduced to ratio of them.
+func negateDiscount(discount *pb.Discount, ratio decimal.Decimal, currency string) (*pb.Discount, error) {
+	return scaleDiscount(discount, ratio.Neg(), currency)
+}
+
+// scaleDiscount copies the discount with fixed amounts multiplied by ratio
+// and rounded to the minor units of the currency, percentages stay as they
+// are.
+func scaleDiscount(discount *pb.Discount, ratio decimal.Decimal, currency string) (*pb.Discount, error) {
+	scaled := &pb.Discount{}
+	if discount == nil {
+		return scaled, nil
+	}
+
+	scaled.Description = discount.Description
+	scaled.Percent = discount.Percent
+	if discount.Amount != "" {
+		amount, err := parseDecimal(discount.Amount)
+		if err != nil {
+			return nil, err
+		}
+		scaled.Amount = money.Round(amount.Mul(ratio), currency).String()
+	}
+	return scaled, nil
+}
+
+// creditedQuantities returns how much of each line of the invoice its
+// credit notes have credited so far.
+func (is *Invoices) creditedQuantities(ctx context.Context, tx *sql.Tx, original_id uint32) (map[uint32]decimal.Decimal, error) {
+	rows, err := tx.Stmt(is.credited_items_stmt).QueryContext(ctx, original_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credited := map[uint32]decimal.Decimal{}
+	for rows.Next() {
+		var id uint32
+		var quantity string
+		if err = rows.Scan(&id, &quantity); err != nil {
+			return nil, err
+		}
+		q, err := parseDecimal(quantity)
+		if err != nil {
+			return nil, err
+		}
+		credited[id] = credited[id].Sub(q)
+	}
+
+	return credited, rows.Err()
+}
+
+// creditLines copies the lines of the invoice to credit, by default all of
+// what is left of them, along with its discounts. Fixed discounts are
+// reduced in proportion to what is credited.
+func creditLines(original *pb.Invoice, credited map[uint32]decimal.Decimal, lines []*pb.CreditNoteLine) ([]*pb.LineItem, []*pb.Discount, error) {
+	originals := map[uint32]*pb.LineItem{}
+	for _, item := range original.Items {
+		originals[item.Id] = item
+	}
+
+	if len(lines) == 0 {
+		for _, item := range original.Items {
+			quantity, err := parseDecimal(item.Quantity)
+			if err != nil {
+				return nil, nil, err
+			}
+			if quantity.GreaterThan(credited[item.Id]) {
+				lines = append(lines, &pb.CreditNoteLine{LineItemId: item.Id})
+			}
+		}
+		if len(lines) == 0 {
+			return nil, nil, fmt.Errorf("%w: nothing is left to credit", ErrNotCreditable)
+		}
+	}
+	if len(lines) > MAX_LINE_ITEMS {
+		return nil, nil, apperr.Invalid("Credit note is invalid", apperr.Field("items", fmt.Sprintf("must not be more than %d", MAX_LINE_ITEMS)))
+	}
+
+	fields := []apperr.FieldError{}
+	seen := map[uint32]bool{}
+	items := []*pb.LineItem{}
+	for i, line := range lines {
+		field := fmt.Sprintf("items[%d]", i)
+		if line == nil {
+			fields = append(fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+
+		item, ok := originals[line.LineItemId]
+		if !ok || seen[line.LineItemId] {
+			fields = append(fields, apperr.Field(field+".lineItemId", "must be a line of the invoice, given once"))
+			continue
+		}
+		seen[line.LineItemId] = true
+
+		quantity, err := parseDecimal(item.Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		left := quantity.Sub(credited[item.Id])
+		share := left
+		if strings.TrimSpace(line.Quantity) != "" {
+			share, err = decimal.NewFromString(strings.TrimSpace(line.Quantity))
+			if err != nil {
+				fields = append(fields, apperr.Field(field+".quantity", "must be a decimal number"))
+				continue
+			}
+		}
+		if !share.IsPositive() || share.GreaterThan(left) {
+			fields = append(fields, apperr.Field(field+".quantity", fmt.Sprintf("must be more than 0 and at most the %s left to credit", left)))
+			continue
+		}
+
+		discount, err := negateDiscount(item.Discount, share.Div(quantity), original.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, &pb.LineItem{
+			Description:        item.Description,
+			Quantity:           share.Neg().String(),
+			Unit:               item.Unit,
+			UnitPrice:          item.UnitPrice,
+			TaxRate:            item.TaxRate,
+			TaxRateId:          item.TaxRateId,
+			TaxCategory:        item.TaxCategory,
+			ExemptionReason:    item.ExemptionReason,
+			CatalogItemId:      item.CatalogItemId,
+			Sku:                item.Sku,
+			Discount:           discount,
+			OriginalLineItemId: item.Id,
+		})
+	}
+
+	if len(fields) > 0 {
+		return nil, nil, apperr.Invalid("Credit note is invalid", fields...)
+	}
+
+	totals, err := Compute(items, nil, original.Currency, original.PricesIncludeTax)
+	if err != nil {
+		return nil, nil, err
+	}
+	subtotal, err := parseDecimal(original.Totals.Subtotal)
+	if err != nil {
+		return nil, nil, err
+	}
+	ratio := decimal.NewFromInt(1)
+	if !subtotal.IsZero() {
+		ratio = decimal.RequireFromString(totals.Subtotal).Neg().Div(subtotal)
+	}
+
+	discounts := []*pb.Discount{}
+	for _, discount := range original.Discounts {
+		negated, err := negateDiscount(discount, ratio, original.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		discounts = append(discounts, negated)
+	}
+
+	return items, discounts, nil
+}
+
+// credit issues a credit note or cancellation of the invoice within tx and
+// reduces its balance, which settles it. What it credits beyond the balance
+// was paid already and is refunded. The HTML of the document is removed
+// again if that fails, callers remove it if tx doesn't get committed.
+func (is *Invoices) credit(ctx context.Context, tx *sql.Tx, original *pb.Invoice, kind string, lines []*pb.CreditNoteLine, reason string, note string, actor *audit.Actor) (_ *pb.Invoice, _ string, err error) {
+	if original.Kind != KIND_INVOICE || !slices.Contains(creditable, original.Status) {
+		return nil, "", fmt.Errorf("%w: %s %s", ErrNotCreditable, original.Kind, original.Status)
+	}
+	if len(reason) > MAX_NOTES_BYTES {
+		return nil, "", apperr.Invalid("Credit note is invalid", apperr.Field("reason", fmt.Sprintf("must not be longer than %d bytes", MAX_NOTES_BYTES)))
+	}
+
+	credited, err := is.creditedQuantities(ctx, tx, original.Id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items, discounts, err := creditLines(original, credited, lines)
+	if err != nil {
+		return nil, "", err
+	}
+
+	totals, err := Compute(items, discounts, original.Currency, original.PricesIncludeTax)
+	if err != nil {
+		return nil, "", err
+	}
+
+	amount := decimal.RequireFromString(totals.Gross).Neg()
+	balance, err := parseDecimal(original.Balance)
+	if err != nil {
+		return nil, "", err
+	}
+	refund := amount.Sub(balance)
+	if refund.IsPositive() && is.refunds == nil {
+		return nil, "", fmt.Errorf("%w: %s of %s %s", ErrCreditExceedsBalance, formatAmount(amount, original.Currency), original.Balance, original.Currency)
+	}
+
+	service_date := original.ServiceDate
+	if service_date == "" {
+		service_date = original.IssueDate
+	}
+
+	now := time.Now()
+	today := now.Format(time.DateOnly)
+	res, err := tx.Stmt(is.insert_stmt).ExecContext(
+		ctx,
+		STATUS_DRAFT,
+		kind,
+		original.Id,
+		"0",
+		original.ClientId,
+		helpers.NullableId(original.IssuerId),
+		helpers.NullableId(original.TemplateId),
+		original.Currency,
+		original.BaseCurrency,
+		original.ExchangeRate,
+		original.Language,
+		today,
+		today,
+		service_date,
+		reason,
+		original.BuyerReference,
+		marshalJson(discounts),
+		original.PricesIncludeTax,
+		original.ReverseCharge,
+		totals.Net,
+		totals.Tax,
+		totals.Gross,
+		now.Unix(),
+		now.Unix(),
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		original.WorkspaceId,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = is.saveItems(ctx, tx, uint32(id), nil, items); err != nil {
+		return nil, "", err
+	}
+
+	document, err := is.retrieve(ctx, tx, original.WorkspaceId, uint32(id))
+	if err != nil {
+		return nil, "", err
+	}
+
+	entry := actor.Entry(original.WorkspaceId, AUDIT_CREATE, AUDIT_TARGET, document.Id, audit.Diff(nil, auditFields(document)))
+	if err = is.audit.Record(tx, entry); err != nil {
+		return nil, "", err
+	}
+
+	html_path, err := is.issue(ctx, tx, document, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(html_path)
+		}
+	}()
+
+	credited_amount, err := parseDecimal(original.Credited)
+	if err != nil {
+		return nil, "", err
+	}
+	original.Credited = formatAmount(credited_amount.Add(amount), original.Currency)
+
+	_, err = tx.Stmt(is.credited_stmt).ExecContext(ctx, original.Credited, original.Id, original.WorkspaceId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if note == "" {
+		note = fmt.Sprintf("%s %s", strings.ReplaceAll(kind, "_", " "), document.Number)
+	}
+	if !refund.IsPositive() {
+		err = is.settle(ctx, tx, original, note, actor)
+	} else if err = is.refunds.Release(ctx, tx, original, refund, actor); err == nil {
+		err = is.Settle(ctx, tx, original, decimal.RequireFromString(original.Paid).Sub(refund), note, actor)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return document, html_path, nil
+}
+
+// CreateCreditNote credits some or all lines of an issued invoice with a
+// credit note, which is issued right away.
+func (is *Invoices) CreateCreditNote(ctx context.Context, workspace_id uint32, id uint32, req *pb.CreateCreditNoteRequest, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.Start(ctx, "Invoices.CreateCreditNote")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	original, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	credit_note, html_path, err := is.credit(ctx, tx, original, KIND_CREDIT_NOTE, req.Items, strings.TrimSpace(req.Reason), "", actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		os.Remove(html_path)
+		return nil, err
+	}
+
+	is.afterIssue(ctx, credit_note, html_path, actor)
+	return credit_note, nil
+}
+
+// CreditNotes returns the credit notes and cancellations of the invoice,
+// oldest first. Line items are left out.
+func (is *Invoices) CreditNotes(ctx context.Context, workspace_id uint32, id uint32) (_ []*pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.CreditNotes")
+	defer end(&err)
+
+	return is.derived(ctx, workspace_id, id)
+}
+
+// derived returns the documents referencing the invoice or quote, oldest
+// first. Line items are left out.
+func (is *Invoices) derived(ctx context.Context, workspace_id uint32, id uint32) ([]*pb.Invoice, error) {
+	if _, err := is.Retrieve(ctx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := is.derived_stmt.QueryContext(ctx, id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*pb.Invoice{}
+	for rows.Next() {
+		document, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+
+	return documents, rows.Err()
+}
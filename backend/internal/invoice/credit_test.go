@@ -0,0 +1,284 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	pb "invoice-manager/main/proto"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCreditLines(t *testing.T) {
+	original := &pb.Invoice{
+		Currency: "EUR",
+		Items: []*pb.LineItem{
+			{Id: 1, Quantity: "10", UnitPrice: "100", TaxRate: "19", Discount: &pb.Discount{Amount: "50"}},
+			{Id: 2, Quantity: "2", UnitPrice: "30", TaxRate: "19"},
+		},
+		Discounts: []*pb.Discount{{Amount: "101", Description: "Loyalty"}, {Percent: "5"}},
+		Totals:    &pb.Totals{Subtotal: "1010.00"},
+	}
+
+	tests := []struct {
+		name     string
+		credited map[uint32]string
+		lines    []*pb.CreditNoteLine
+		// Lines as "quantity/discount of the line" and the discounts of
+		// the credit note as "amount/percent".
+		want      []string
+		discounts []string
+	}{
+		{
+			name:      "everything",
+			want:      []string{"-10/-50", "-2/"},
+			discounts: []string{"-101/", "/5"},
+		},
+		{
+			name:      "what is left",
+			credited:  map[uint32]string{1: "4"},
+			want:      []string{"-6/-30", "-2/"},
+			discounts: []string{"-63/", "/5"},
+		},
+		{
+			name:      "part of a line",
+			lines:     []*pb.CreditNoteLine{{LineItemId: 1, Quantity: "2.5"}},
+			want:      []string{"-2.5/-12.5"},
+			discounts: []string{"-23.75/", "/5"},
+		},
+		{
+			name:      "all that is left of a line",
+			credited:  map[uint32]string{1: "9"},
+			lines:     []*pb.CreditNoteLine{{LineItemId: 1}},
+			want:      []string{"-1/-5"},
+			discounts: []string{"-9.5/", "/5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			credited := map[uint32]decimal.Decimal{}
+			for id, quantity := range test.credited {
+				credited[id] = decimal.RequireFromString(quantity)
+			}
+
+			items, discounts, err := creditLines(original, credited, test.lines)
+			if err != nil {
+				t.Fatalf("creditLines() error = %v", err)
+			}
+
+			got := []string{}
+			for _, item := range items {
+				got = append(got, item.Quantity+"/"+item.Discount.Amount)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("lines = %v, want %v", got, test.want)
+			}
+			got = []string{}
+			for _, discount := range discounts {
+				got = append(got, discount.Amount+"/"+discount.Percent)
+			}
+			if !slices.Equal(got, test.discounts) {
+				t.Errorf("discounts = %v, want %v", got, test.discounts)
+			}
+		})
+	}
+}
+
+func TestCreditLinesInvalid(t *testing.T) {
+	original := &pb.Invoice{
+		Currency: "EUR",
+		Items: []*pb.LineItem{
+			{Id: 1, Quantity: "10", UnitPrice: "100"},
+			{Id: 2, Quantity: "2", UnitPrice: "30"},
+		},
+		Totals: &pb.Totals{Subtotal: "1060.00"},
+	}
+
+	lines := []*pb.CreditNoteLine{
+		{LineItemId: 1, Quantity: "4"},
+		nil,
+		{LineItemId: 3},
+		{LineItemId: 1},
+		{LineItemId: 2, Quantity: "two"},
+	}
+	_, _, err := creditLines(original, map[uint32]decimal.Decimal{}, lines)
+	var app_err *apperr.Error
+	if !errors.As(err, &app_err) {
+		t.Fatalf("creditLines() error = %v, want a field error", err)
+	}
+	fields := []string{}
+	for _, field := range app_err.Fields {
+		fields = append(fields, field.Field)
+	}
+	want := []string{"items[1]", "items[2].lineItemId", "items[3].lineItemId", "items[4].quantity"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+
+	credited := map[uint32]decimal.Decimal{1: decimal.NewFromInt(8)}
+	for _, quantity := range []string{"0", "-1", "2.5"} {
+		_, _, err = creditLines(original, credited, []*pb.CreditNoteLine{{LineItemId: 1, Quantity: quantity}})
+		if !errors.As(err, &app_err) || app_err.Fields[0].Field != "items[0].quantity" {
+			t.Errorf("creditLines() of %s with 2 left error = %v, want items[0].quantity", quantity, err)
+		}
+	}
+
+	credited = map[uint32]decimal.Decimal{1: decimal.NewFromInt(10), 2: decimal.NewFromInt(2)}
+	if _, _, err = creditLines(original, credited, nil); !errors.Is(err, ErrNotCreditable) {
+		t.Errorf("creditLines() of a credited invoice error = %v, want %v", err, ErrNotCreditable)
+	}
+}
+
+// creditNote credits the lines of the invoice, all that is left of it
+// without any, like CreateCreditNote but without printing the PDF.
+func (ti *testInvoices) creditNote(t *testing.T, id uint32, lines ...*pb.CreditNoteLine) (*pb.Invoice, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := ti.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	original, err := ti.RetrieveTx(ctx, tx, 1, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credit_note, html_path, err := ti.credit(ctx, tx, original, KIND_CREDIT_NOTE, lines, "", "", audit.CliActor)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		os.Remove(html_path)
+		t.Fatal(err)
+	}
+	return credit_note, nil
+}
+
+func TestCredit(t *testing.T) {
+	type step struct {
+		// Paid in total before the credit note, if anything.
+		paid string
+		// Hours of the invoice to credit, all that is left if empty.
+		hours string
+		// Gross amount of the credit note, or the code of the error it
+		// fails with.
+		gross string
+		code  apperr.Code
+		// The invoice afterwards, and what has been refunded of it so far.
+		status   string
+		balance  string
+		refunded string
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "full credit",
+			steps: []step{{gross: "-1190.00", status: STATUS_VOID, balance: "0.00", refunded: "0"}},
+		},
+		{
+			name:  "partial credit",
+			steps: []step{{hours: "2", gross: "-238.00", status: STATUS_ISSUED, balance: "952.00", refunded: "0"}},
+		},
+		{
+			name: "repeated partial credits",
+			steps: []step{
+				{hours: "4", gross: "-476.00", status: STATUS_ISSUED, balance: "714.00", refunded: "0"},
+				{hours: "4", gross: "-476.00", status: STATUS_ISSUED, balance: "238.00", refunded: "0"},
+				{hours: "3", code: apperr.CODE_INVALID_ARGUMENT},
+				{gross: "-238.00", status: STATUS_VOID, balance: "0.00", refunded: "0"},
+				{code: apperr.CODE_FAILED_PRECONDITION},
+			},
+		},
+		{
+			name: "credit of a paid invoice",
+			steps: []step{
+				{paid: "1190", hours: "3", gross: "-357.00", status: STATUS_PAID, balance: "0.00", refunded: "357"},
+				{gross: "-833.00", status: STATUS_VOID, balance: "0.00", refunded: "1190"},
+			},
+		},
+		{
+			name: "credit of a partially paid invoice",
+			steps: []step{
+				{paid: "500", hours: "2", gross: "-238.00", status: STATUS_PARTIALLY_PAID, balance: "452.00", refunded: "0"},
+				{hours: "6", gross: "-714.00", status: STATUS_PAID, balance: "0.00", refunded: "262"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ti := newTestInvoices(t)
+			invoice := ti.issue(t, KIND_INVOICE, [2]string{"10", "100"})
+
+			for i, step := range test.steps {
+				if step.paid != "" {
+					ti.pay(t, invoice.Id, step.paid)
+				}
+				lines := []*pb.CreditNoteLine{}
+				if step.hours != "" {
+					lines = append(lines, &pb.CreditNoteLine{LineItemId: invoice.Items[0].Id, Quantity: step.hours})
+				}
+
+				credit_note, err := ti.creditNote(t, invoice.Id, lines...)
+				if step.code != "" {
+					var app_err *apperr.Error
+					if !errors.As(err, &app_err) || app_err.Code != step.code {
+						t.Fatalf("step %d: credit() error = %v, want %s", i+1, err, step.code)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d: credit() error = %v", i+1, err)
+				}
+
+				if credit_note.Kind != KIND_CREDIT_NOTE || credit_note.Status != STATUS_ISSUED || credit_note.OriginalId != invoice.Id {
+					t.Errorf("step %d: credit note is a %s %s of %d", i+1, credit_note.Status, credit_note.Kind, credit_note.OriginalId)
+				}
+				if credit_note.Totals.Gross != step.gross {
+					t.Errorf("step %d: credit note gross = %s, want %s", i+1, credit_note.Totals.Gross, step.gross)
+				}
+
+				got := ti.retrieve(t, invoice.Id)
+				if got.Status != step.status || got.Balance != step.balance {
+					t.Errorf("step %d: invoice is %s with %s left, want %s with %s", i+1, got.Status, got.Balance, step.status, step.balance)
+				}
+				if !ti.refunds.released.Equal(decimal.RequireFromString(step.refunded)) {
+					t.Errorf("step %d: refunded %s, want %s", i+1, ti.refunds.released, step.refunded)
+				}
+			}
+		})
+	}
+}
+
+func TestCreditNotCreditable(t *testing.T) {
+	ti := newTestInvoices(t)
+
+	draft := ti.create(t, KIND_INVOICE, [2]string{"1", "100"})
+	if _, err := ti.creditNote(t, draft.Id); !errors.Is(err, ErrNotCreditable) {
+		t.Errorf("credit() of a draft error = %v, want %v", err, ErrNotCreditable)
+	}
+
+	quote := ti.issue(t, KIND_QUOTE, [2]string{"1", "100"})
+	if _, err := ti.creditNote(t, quote.Id); !errors.Is(err, ErrNotCreditable) {
+		t.Errorf("credit() of a quote error = %v, want %v", err, ErrNotCreditable)
+	}
+
+	invoice := ti.issue(t, KIND_INVOICE, [2]string{"1", "100"})
+	credit_note, err := ti.creditNote(t, invoice.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ti.creditNote(t, credit_note.Id); !errors.Is(err, ErrNotCreditable) {
+		t.Errorf("credit() of a credit note error = %v, want %v", err, ErrNotCreditable)
+	}
+}
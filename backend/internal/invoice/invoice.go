@@ -0,0 +1,1089 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/catalog"
+	"invoice-manager/main/internal/client"
+	"invoice-manager/main/internal/exchange"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/issuer"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/sequence"
+	"invoice-manager/main/internal/tax"
+	"invoice-manager/main/internal/telemetry"
+	"invoice-manager/main/internal/template"
+	pb "invoice-manager/main/proto"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrIDNotFound       = apperr.New(apperr.CODE_NOT_FOUND, "invoice not found")
+	ErrNotDraft         = apperr.New(apperr.CODE_FAILED_PRECONDITION, "only draft invoices can be changed")
+	ErrClientArchived   = apperr.New(apperr.CODE_FAILED_PRECONDITION, "client is archived")
+	ErrClientNotFound   = apperr.Invalid("Client doesn't exist", apperr.Field("clientId", "no such client in this workspace"))
+	ErrTemplateNotFound = apperr.Invalid("Template doesn't exist", apperr.Field("templateId", "no such template in this workspace"))
+	ErrIssuerNotFound   = apperr.Invalid("Issuer doesn't exist", apperr.Field("issuerId", "no such issuer in this workspace"))
+)
+
+const (
+	AUDIT_TARGET = "invoice"
+	AUDIT_CREATE = "invoice.create"
+	AUDIT_UPDATE = "invoice.update"
+	AUDIT_DELETE = "invoice.delete"
+)
+
+type Invoices struct {
+	db        *sql.DB
+	audit     *audit.Log
+	quotas    *quota.Quotas
+	clients   *client.Clients
+	templates *template.Templates
+	sequences *sequence.Sequences
+	rates     *exchange.Rates
+	taxes     *tax.TaxRates
+	catalog   *catalog.Catalog
+	issuers   *issuer.Issuers
+	refunds   Refunds
+
+	insert_stmt, retrieve_stmt, update_stmt, delete_stmt, search_stmt, count_stmt *sql.Stmt
+	items_stmt, insert_item_stmt, delete_items_stmt                               *sql.Stmt
+	status_stmt, insert_event_stmt, events_stmt, due_stmt                         *sql.Stmt
+	issue_stmt, documents_stmt, pdf_stmt, paid_stmt, reached_stmt                 *sql.Stmt
+	credited_stmt, derived_stmt, credited_items_stmt, converted_stmt              *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvoice(row scanner) (*pb.Invoice, error) {
+	invoice := &pb.Invoice{Totals: &pb.Totals{}}
+	var discounts, snapshot string
+	err := row.Scan(
+		&invoice.Id,
+		&invoice.Status,
+		&invoice.Number,
+		&invoice.ClientId,
+		&invoice.TemplateId,
+		&invoice.Currency,
+		&invoice.Language,
+		&invoice.IssueDate,
+		&invoice.DueDate,
+		&invoice.ServiceDate,
+		&invoice.Notes,
+		&discounts,
+		&invoice.Totals.Net,
+		&invoice.Totals.Tax,
+		&invoice.Totals.Gross,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+		&invoice.CreatedBy,
+		&invoice.UpdatedBy,
+		&invoice.WorkspaceId,
+		&snapshot,
+		&invoice.IssuedAt,
+		&invoice.Paid,
+		&invoice.Kind,
+		&invoice.OriginalId,
+		&invoice.OriginalNumber,
+		&invoice.Credited,
+		&invoice.Converted,
+		&invoice.Share,
+		&invoice.BaseCurrency,
+		&invoice.ExchangeRate,
+		&invoice.PricesIncludeTax,
+		&invoice.ReverseCharge,
+		&invoice.IssuerId,
+		&invoice.BuyerReference,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = computeBalance(invoice); err != nil {
+		return nil, err
+	}
+	if err = computeBaseGross(invoice); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(discounts), &invoice.Discounts); err != nil {
+		return nil, err
+	}
+	if invoice.Status != STATUS_DRAFT {
+		invoice.Snapshot = &pb.InvoiceSnapshot{}
+		if err = json.Unmarshal([]byte(snapshot), invoice.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+	return invoice, nil
+}
+
+// computeBalance normalizes the paid and credited amounts and derives the
+// balance from them. Credit notes have nothing to pay.
+func computeBalance(invoice *pb.Invoice) error {
+	gross, err := parseDecimal(invoice.Totals.Gross)
+	if err != nil {
+		return err
+	}
+	paid, err := parseDecimal(invoice.Paid)
+	if err != nil {
+		return err
+	}
+	credited, err := parseDecimal(invoice.Credited)
+	if err != nil {
+		return err
+	}
+
+	invoice.Paid = formatAmount(paid, invoice.Currency)
+	invoice.Credited = formatAmount(credited, invoice.Currency)
+	invoice.Balance = formatAmount(gross.Sub(paid).Sub(credited), invoice.Currency)
+	if invoice.Kind != KIND_INVOICE {
+		invoice.Balance = formatAmount(decimal.Zero, invoice.Currency)
+	}
+	return nil
+}
+
+// computeBaseGross converts the gross amount to the base currency once the
+// rate of the invoice is known.
+func computeBaseGross(invoice *pb.Invoice) error {
+	if invoice.ExchangeRate == "" {
+		return nil
+	}
+
+	gross, err := parseDecimal(invoice.Totals.Gross)
+	if err != nil {
+		return err
+	}
+	rate, err := parseDecimal(invoice.ExchangeRate)
+	if err != nil {
+		return err
+	}
+
+	invoice.BaseGross = formatAmount(money.Round(gross.Mul(rate), invoice.BaseCurrency), invoice.BaseCurrency)
+	return nil
+}
+
+func marshalJson(value any) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// auditFields is what the audit log records of an invoice.
+func auditFields(i *pb.Invoice) map[string]string {
+	return map[string]string{
+		"kind":               i.Kind,
+		"client_id":          fmt.Sprint(i.ClientId),
+		"issuer_id":          fmt.Sprint(i.IssuerId),
+		"template_id":        fmt.Sprint(i.TemplateId),
+		"currency":           i.Currency,
+		"exchange_rate":      i.ExchangeRate,
+		"language":           i.Language,
+		"issue_date":         i.IssueDate,
+		"due_date":           i.DueDate,
+		"service_date":       i.ServiceDate,
+		"notes":              i.Notes,
+		"buyer_reference":    i.BuyerReference,
+		"items":              marshalJson(i.Items),
+		"discounts":          marshalJson(i.Discounts),
+		"prices_include_tax": fmt.Sprint(i.PricesIncludeTax),
+		"reverse_charge":     fmt.Sprint(i.ReverseCharge),
+		"gross":              i.Totals.GetGross(),
+	}
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, args ...any) (*sql.Rows, error)
+}
+
+// loadItems reads the line items and computes the totals from them.
+func (is *Invoices) loadItems(ctx context.Context, stmt querier, invoice *pb.Invoice) error {
+	rows, err := stmt.QueryContext(ctx, invoice.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	invoice.Items = []*pb.LineItem{}
+	for rows.Next() {
+		item := &pb.LineItem{Discount: &pb.Discount{}}
+		var discount string
+		err = rows.Scan(
+			&item.Id,
+			&item.Description,
+			&item.Quantity,
+			&item.Unit,
+			&item.UnitPrice,
+			&discount,
+			&item.TaxRate,
+			&item.OriginalLineItemId,
+			&item.TaxRateId,
+			&item.TaxCategory,
+			&item.ExemptionReason,
+			&item.CatalogItemId,
+			&item.Sku,
+		)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal([]byte(discount), item.Discount); err != nil {
+			return err
+		}
+		invoice.Items = append(invoice.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	invoice.Totals, err = Compute(invoice.Items, invoice.Discounts, invoice.Currency, invoice.PricesIncludeTax)
+	return err
+}
+
+// saveItems replaces the line items of the invoice. Items sent back with
+// the ID they were given keep it.
+func (is *Invoices) saveItems(ctx context.Context, tx *sql.Tx, invoice_id uint32, previous []*pb.LineItem, items []*pb.LineItem) error {
+	known := map[uint32]bool{}
+	for _, item := range previous {
+		known[item.Id] = true
+	}
+
+	if _, err := tx.Stmt(is.delete_items_stmt).ExecContext(ctx, invoice_id); err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		var id any
+		if known[item.Id] {
+			id = item.Id
+		}
+
+		discount := item.Discount
+		if discount == nil {
+			discount = &pb.Discount{}
+		}
+
+		_, err := tx.Stmt(is.insert_item_stmt).ExecContext(
+			ctx,
+			id,
+			invoice_id,
+			item.Description,
+			item.Quantity,
+			item.Unit,
+			item.UnitPrice,
+			marshalJson(discount),
+			item.TaxRate,
+			i,
+			helpers.NullableId(item.OriginalLineItemId),
+			helpers.NullableId(item.TaxRateId),
+			item.TaxCategory,
+			item.ExemptionReason,
+			helpers.NullableId(item.CatalogItemId),
+			item.Sku,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (is *Invoices) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.Invoice, error) {
+	invoice, err := scanInvoice(tx.Stmt(is.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, is.loadItems(ctx, tx.Stmt(is.items_stmt), invoice)
+}
+
+// prepare fills in what the request leaves empty from the defaults of the
+// issuer and the client, validates it and computes its totals. Quotes are
+// valid for DEFAULT_QUOTE_VALIDITY_DAYS unless their due date says
+// otherwise.
+func (is *Invoices) prepare(ctx context.Context, workspace_id uint32, req *pb.SaveInvoiceRequest) (*pb.Client, *pb.Totals, error) {
+	if req.Kind == "" {
+		req.Kind = KIND_INVOICE
+	}
+	if req.ClientId == 0 {
+		return nil, nil, Validate(req)
+	}
+
+	c, err := is.clients.Retrieve(ctx, workspace_id, req.ClientId)
+	if err == client.ErrIDNotFound {
+		return nil, nil, ErrClientNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iss, err := is.retrieveIssuer(ctx, workspace_id, req.IssuerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.TemplateId == 0 && iss != nil {
+		req.TemplateId = iss.DefaultTemplateId
+	}
+	if req.Currency == "" {
+		req.Currency = c.DefaultCurrency
+	}
+	if req.Language == "" {
+		req.Language = c.DefaultLanguage
+	}
+	if req.TemplateId == 0 && req.Kind == KIND_QUOTE {
+		req.TemplateId = c.DefaultQuoteTemplateId
+	}
+	if req.TemplateId == 0 {
+		req.TemplateId = c.DefaultTemplateId
+	}
+	if req.IssueDate == "" {
+		req.IssueDate = time.Now().Format(time.DateOnly)
+	}
+	if issue_date, err := time.Parse(time.DateOnly, req.IssueDate); err == nil && req.DueDate == "" {
+		days := int(c.DefaultPaymentTerms)
+		if req.Kind == KIND_QUOTE {
+			days = DEFAULT_QUOTE_VALIDITY_DAYS
+		}
+		req.DueDate = issue_date.AddDate(0, 0, days).Format(time.DateOnly)
+	}
+
+	if err = is.fillFromCatalog(ctx, workspace_id, req); err != nil {
+		return nil, nil, err
+	}
+
+	if err = Validate(req); err != nil {
+		return nil, nil, err
+	}
+
+	if req.TemplateId != 0 {
+		_, err = is.templates.Retrieve(ctx, workspace_id, int(req.TemplateId))
+		if err == template.ErrIDNotFound {
+			return nil, nil, ErrTemplateNotFound
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = is.resolveTaxes(ctx, workspace_id, iss, c, req); err != nil {
+		return nil, nil, err
+	}
+
+	totals, err := Compute(req.Items, req.Discounts, req.Currency, req.PricesIncludeTax)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, totals, nil
+}
+
+// pinnedBase returns the base currency a rate set by hand converts to, that
+// of the workspace when the draft is saved. Drafts without one have none.
+func (is *Invoices) pinnedBase(ctx context.Context, workspace_id uint32, req *pb.SaveInvoiceRequest) (string, error) {
+	if req.ExchangeRate == "" {
+		return "", nil
+	}
+	return is.rates.BaseCurrency(ctx, workspace_id)
+}
+
+// Check validates the request the way Create does, without creating
+// anything. The defaults of the client are filled in.
+func (is *Invoices) Check(ctx context.Context, workspace_id uint32, req *pb.SaveInvoiceRequest) error {
+	c, _, err := is.prepare(ctx, workspace_id, req)
+	if err != nil {
+		return err
+	}
+	if c.ArchivedAt != 0 {
+		return ErrClientArchived
+	}
+	return nil
+}
+
+// Create adds a draft invoice or quote.
+func (is *Invoices) Create(ctx context.Context, workspace_id uint32, req *pb.SaveInvoiceRequest, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Create")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := is.CreateTx(ctx, tx, workspace_id, req, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// CreateTx adds a draft invoice within tx, e.g. one that a recurring
+// schedule generates along with its run.
+func (is *Invoices) CreateTx(ctx context.Context, tx *sql.Tx, workspace_id uint32, req *pb.SaveInvoiceRequest, actor *audit.Actor) (*pb.Invoice, error) {
+	return is.create(ctx, tx, workspace_id, req, 0, decimal.Zero, actor)
+}
+
+// create adds a draft within tx. Invoices converted from a quote reference
+// it along with the percent of it they bill.
+func (is *Invoices) create(ctx context.Context, tx *sql.Tx, workspace_id uint32, req *pb.SaveInvoiceRequest, original_id uint32, share decimal.Decimal, actor *audit.Actor) (*pb.Invoice, error) {
+	c, totals, err := is.prepare(ctx, workspace_id, req)
+	if err != nil {
+		return nil, err
+	}
+	if c.ArchivedAt != 0 {
+		return nil, ErrClientArchived
+	}
+
+	base_currency, err := is.pinnedBase(ctx, workspace_id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(is.insert_stmt).ExecContext(
+		ctx,
+		STATUS_DRAFT,
+		req.Kind,
+		helpers.NullableId(original_id),
+		share.String(),
+		req.ClientId,
+		helpers.NullableId(req.IssuerId),
+		helpers.NullableId(req.TemplateId),
+		req.Currency,
+		base_currency,
+		req.ExchangeRate,
+		req.Language,
+		req.IssueDate,
+		req.DueDate,
+		req.ServiceDate,
+		req.Notes,
+		req.BuyerReference,
+		marshalJson(req.Discounts),
+		req.PricesIncludeTax,
+		reverseCharged(req.Items),
+		totals.Net,
+		totals.Tax,
+		totals.Gross,
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = is.saveItems(ctx, tx, uint32(id), nil, req.Items); err != nil {
+		return nil, err
+	}
+
+	invoice, err := is.retrieve(ctx, tx, workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, invoice.Id, audit.Diff(nil, auditFields(invoice)))
+	if err = is.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (is *Invoices) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Retrieve")
+	defer end(&err)
+
+	invoice, err := scanInvoice(is.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, is.loadItems(ctx, is.items_stmt, invoice)
+}
+
+// Search returns a page of the invoices matching the filter, newest first,
+// along with the number of all matching invoices. Line items are left out,
+// the totals only have the net, tax and gross amounts.
+func (is *Invoices) Search(ctx context.Context, filter *Filter) (_ []*pb.Invoice, _ uint32, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Search")
+	defer end(&err)
+
+	args := []any{
+		filter.WorkspaceId,
+		filter.Status,
+		filter.ClientId,
+		filter.From,
+		filter.To,
+		filter.Query,
+		filter.Kind,
+	}
+
+	var total uint32
+	if err = is.count_stmt.QueryRowContext(ctx, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := is.search_stmt.QueryContext(ctx, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	invoices := []*pb.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, total, rows.Err()
+}
+
+// Update replaces all fields and line items of a draft invoice or quote.
+// Drafts keep their kind.
+func (is *Invoices) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveInvoiceRequest, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Update")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != STATUS_DRAFT {
+		return nil, ErrNotDraft
+	}
+
+	if req.Kind == "" {
+		req.Kind = before.Kind
+	}
+	if req.Kind != before.Kind {
+		return nil, apperr.Invalid("Invoice is invalid", apperr.Field("kind", "must not change, create a new draft instead"))
+	}
+
+	c, totals, err := is.prepare(ctx, workspace_id, req)
+	if err != nil {
+		return nil, err
+	}
+	// Drafts already made out to a client that got archived can still be
+	// finished.
+	if c.ArchivedAt != 0 && before.ClientId != c.Id {
+		return nil, ErrClientArchived
+	}
+
+	base_currency, err := is.pinnedBase(ctx, workspace_id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Stmt(is.update_stmt).ExecContext(
+		ctx,
+		req.ClientId,
+		helpers.NullableId(req.IssuerId),
+		helpers.NullableId(req.TemplateId),
+		req.Currency,
+		base_currency,
+		req.ExchangeRate,
+		req.Language,
+		req.IssueDate,
+		req.DueDate,
+		req.ServiceDate,
+		req.Notes,
+		req.BuyerReference,
+		marshalJson(req.Discounts),
+		req.PricesIncludeTax,
+		reverseCharged(req.Items),
+		totals.Net,
+		totals.Tax,
+		totals.Gross,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		id,
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = is.saveItems(ctx, tx, id, before.Items, req.Items); err != nil {
+		return nil, err
+	}
+
+	after, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = is.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Delete removes a draft invoice or quote. The share of a quote that a
+// deleted draft was converted from can be converted again.
+func (is *Invoices) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Delete")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	invoice, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != STATUS_DRAFT {
+		return ErrNotDraft
+	}
+
+	if _, err = tx.Stmt(is.delete_stmt).ExecContext(ctx, id, workspace_id); err != nil {
+		return err
+	}
+
+	if invoice.Kind == KIND_INVOICE && invoice.OriginalId != 0 {
+		if err = is.release(ctx, tx, workspace_id, invoice); err != nil {
+			return err
+		}
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(invoice), nil))
+	if err = is.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (is *Invoices) Close() error {
+	stmts := []*sql.Stmt{
+		is.insert_stmt,
+		is.retrieve_stmt,
+		is.update_stmt,
+		is.delete_stmt,
+		is.search_stmt,
+		is.count_stmt,
+		is.items_stmt,
+		is.insert_item_stmt,
+		is.delete_items_stmt,
+		is.status_stmt,
+		is.insert_event_stmt,
+		is.events_stmt,
+		is.due_stmt,
+		is.issue_stmt,
+		is.documents_stmt,
+		is.pdf_stmt,
+		is.paid_stmt,
+		is.reached_stmt,
+		is.credited_stmt,
+		is.derived_stmt,
+		is.credited_items_stmt,
+		is.converted_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const INVOICE_COLUMNS = `
+	invoice_id,
+	invoice_status,
+	invoice_number,
+	invoice_client_id,
+	COALESCE(invoice_template_id, 0),
+	invoice_currency,
+	invoice_language,
+	invoice_issue_date,
+	invoice_due_date,
+	invoice_service_date,
+	invoice_notes,
+	invoice_discounts,
+	invoice_net,
+	invoice_tax,
+	invoice_gross,
+	invoice_created_at,
+	invoice_updated_at,
+	COALESCE(invoice_created_by, 0),
+	COALESCE(invoice_updated_by, 0),
+	invoice_workspace_id,
+	invoice_snapshot,
+	COALESCE(invoice_issued_at, 0),
+	invoice_paid,
+	invoice_kind,
+	COALESCE(invoice_original_id, 0),
+	COALESCE((SELECT original.invoice_number FROM invoices AS original WHERE original.invoice_id = invoices.invoice_original_id), ''),
+	invoice_credited,
+	invoice_converted,
+	invoice_share,
+	invoice_base_currency,
+	invoice_exchange_rate,
+	invoice_prices_include_tax,
+	invoice_reverse_charge,
+	COALESCE(invoice_issuer_id, 0),
+	invoice_buyer_reference
+`
+
+const SEARCH_WHERE = `
+	WHERE invoice_workspace_id = ?1
+		AND (?2 = '' OR invoice_status = ?2)
+		AND (?3 = 0 OR invoice_client_id = ?3)
+		AND (?4 = '' OR invoice_issue_date >= ?4)
+		AND (?5 = '' OR invoice_issue_date <= ?5)
+		AND (?7 = '' OR invoice_kind = ?7)
+		AND (?6 = '' OR instr(lower(invoice_number), lower(?6)) > 0 OR invoice_client_id IN (
+			SELECT client_id FROM clients WHERE client_workspace_id = ?1 AND instr(lower(client_legal_name), lower(?6)) > 0
+		))
+`
+
+func NewInvoices(db *sql.DB, audit_log *audit.Log, quotas *quota.Quotas, cs *client.Clients, ts *template.Templates, ss *sequence.Sequences, rs *exchange.Rates, xs *tax.TaxRates, catalog *catalog.Catalog, issuers *issuer.Issuers) (*Invoices, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO invoices (
+			invoice_status,
+			invoice_kind,
+			invoice_original_id,
+			invoice_share,
+			invoice_client_id,
+			invoice_issuer_id,
+			invoice_template_id,
+			invoice_currency,
+			invoice_base_currency,
+			invoice_exchange_rate,
+			invoice_language,
+			invoice_issue_date,
+			invoice_due_date,
+			invoice_service_date,
+			invoice_notes,
+			invoice_buyer_reference,
+			invoice_discounts,
+			invoice_prices_include_tax,
+			invoice_reverse_charge,
+			invoice_net,
+			invoice_tax,
+			invoice_gross,
+			invoice_created_at,
+			invoice_updated_at,
+			invoice_created_by,
+			invoice_updated_by,
+			invoice_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + INVOICE_COLUMNS + `
+		FROM invoices
+		WHERE invoice_id = ? AND invoice_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE invoices
+		SET invoice_client_id = ?,
+			invoice_issuer_id = ?,
+			invoice_template_id = ?,
+			invoice_currency = ?,
+			invoice_base_currency = ?,
+			invoice_exchange_rate = ?,
+			invoice_language = ?,
+			invoice_issue_date = ?,
+			invoice_due_date = ?,
+			invoice_service_date = ?,
+			invoice_notes = ?,
+			invoice_buyer_reference = ?,
+			invoice_discounts = ?,
+			invoice_prices_include_tax = ?,
+			invoice_reverse_charge = ?,
+			invoice_net = ?,
+			invoice_tax = ?,
+			invoice_gross = ?,
+			invoice_updated_at = ?,
+			invoice_updated_by = ?
+		WHERE invoice_id = ? AND invoice_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM invoices WHERE invoice_id = ? AND invoice_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	search_stmt, err := db.Prepare(`
+		SELECT ` + INVOICE_COLUMNS + `
+		FROM invoices
+		` + SEARCH_WHERE + `
+		ORDER BY invoice_issue_date DESC, invoice_id DESC
+		LIMIT ?8 OFFSET ?9
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_stmt, err := db.Prepare("SELECT COUNT(*) FROM invoices " + SEARCH_WHERE)
+	if err != nil {
+		return nil, err
+	}
+
+	items_stmt, err := db.Prepare(`
+		SELECT
+			line_item_id,
+			line_item_description,
+			line_item_quantity,
+			line_item_unit,
+			line_item_unit_price,
+			line_item_discount,
+			line_item_tax_rate,
+			IFNULL(line_item_original_id, 0),
+			IFNULL(line_item_tax_rate_id, 0),
+			line_item_tax_category,
+			line_item_exemption_reason,
+			IFNULL(line_item_catalog_item_id, 0),
+			line_item_sku
+		FROM invoice_line_items
+		WHERE line_item_invoice_id = ?
+		ORDER BY line_item_position
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_item_stmt, err := db.Prepare(`
+		INSERT INTO invoice_line_items (
+			line_item_id,
+			line_item_invoice_id,
+			line_item_description,
+			line_item_quantity,
+			line_item_unit,
+			line_item_unit_price,
+			line_item_discount,
+			line_item_tax_rate,
+			line_item_position,
+			line_item_original_id,
+			line_item_tax_rate_id,
+			line_item_tax_category,
+			line_item_exemption_reason,
+			line_item_catalog_item_id,
+			line_item_sku
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_items_stmt, err := db.Prepare("DELETE FROM invoice_line_items WHERE line_item_invoice_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	status_stmt, err := db.Prepare(`
+		UPDATE invoices
+		SET invoice_status = ?,
+			invoice_updated_at = ?,
+			invoice_updated_by = ?
+		WHERE invoice_id = ? AND invoice_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_event_stmt, err := db.Prepare(`
+		INSERT INTO invoice_events (
+			invoice_event_invoice_id,
+			invoice_event_from_status,
+			invoice_event_to_status,
+			invoice_event_note,
+			invoice_event_actor_user_id,
+			invoice_event_created_at
+		) VALUES(?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	events_stmt, err := db.Prepare(`
+		SELECT
+			invoice_event_id,
+			invoice_event_invoice_id,
+			invoice_event_from_status,
+			invoice_event_to_status,
+			invoice_event_note,
+			COALESCE(invoice_event_actor_user_id, 0),
+			invoice_event_created_at
+		FROM invoice_events
+		JOIN invoices ON invoice_id = invoice_event_invoice_id
+		WHERE invoice_event_invoice_id = ? AND invoice_workspace_id = ?
+		ORDER BY invoice_event_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	due_stmt, err := db.Prepare(`
+		SELECT ` + INVOICE_COLUMNS + `
+		FROM invoices
+		WHERE invoice_kind = ? AND invoice_due_date != '' AND invoice_due_date < ? AND invoice_status IN (?, ?, ?)
+		ORDER BY invoice_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	issue_stmt, err := db.Prepare(`
+		UPDATE invoices
+		SET invoice_number = ?,
+			invoice_snapshot = ?,
+			invoice_issued_at = ?,
+			invoice_html_path = ?,
+			invoice_base_currency = ?,
+			invoice_exchange_rate = ?
+		WHERE invoice_id = ? AND invoice_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	documents_stmt, err := db.Prepare(`
+		SELECT invoice_html_path, invoice_pdf_path
+		FROM invoices
+		WHERE invoice_id = ? AND invoice_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf_stmt, err := db.Prepare("UPDATE invoices SET invoice_pdf_path = ? WHERE invoice_id = ? AND invoice_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	paid_stmt, err := db.Prepare("UPDATE invoices SET invoice_paid = ? WHERE invoice_id = ? AND invoice_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	reached_stmt, err := db.Prepare(`
+		SELECT COUNT(*) > 0
+		FROM invoice_events
+		WHERE invoice_event_invoice_id = ? AND invoice_event_to_status = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	credited_stmt, err := db.Prepare("UPDATE invoices SET invoice_credited = ? WHERE invoice_id = ? AND invoice_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	derived_stmt, err := db.Prepare(`
+		SELECT ` + INVOICE_COLUMNS + `
+		FROM invoices
+		WHERE invoice_original_id = ? AND invoice_workspace_id = ?
+		ORDER BY invoice_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	credited_items_stmt, err := db.Prepare(`
+		SELECT line_item_original_id, line_item_quantity
+		FROM invoice_line_items
+		JOIN invoices ON invoice_id = line_item_invoice_id
+		WHERE invoice_original_id = ? AND line_item_original_id IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	converted_stmt, err := db.Prepare("UPDATE invoices SET invoice_converted = ? WHERE invoice_id = ? AND invoice_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Invoices{
+		db:                  db,
+		audit:               audit_log,
+		quotas:              quotas,
+		clients:             cs,
+		templates:           ts,
+		sequences:           ss,
+		rates:               rs,
+		taxes:               xs,
+		catalog:             catalog,
+		issuers:             issuers,
+		insert_stmt:         insert_stmt,
+		retrieve_stmt:       retrieve_stmt,
+		update_stmt:         update_stmt,
+		delete_stmt:         delete_stmt,
+		search_stmt:         search_stmt,
+		count_stmt:          count_stmt,
+		items_stmt:          items_stmt,
+		insert_item_stmt:    insert_item_stmt,
+		delete_items_stmt:   delete_items_stmt,
+		status_stmt:         status_stmt,
+		insert_event_stmt:   insert_event_stmt,
+		events_stmt:         events_stmt,
+		due_stmt:            due_stmt,
+		issue_stmt:          issue_stmt,
+		documents_stmt:      documents_stmt,
+		pdf_stmt:            pdf_stmt,
+		paid_stmt:           paid_stmt,
+		reached_stmt:        reached_stmt,
+		credited_stmt:       credited_stmt,
+		derived_stmt:        derived_stmt,
+		credited_items_stmt: credited_items_stmt,
+		converted_stmt:      converted_stmt,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/catalog"
+	"invoice-manager/main/internal/client"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/exchange"
+	"invoice-manager/main/internal/issuer"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/sequence"
+	"invoice-manager/main/internal/tax"
+	"invoice-manager/main/internal/template"
+	pb "invoice-manager/main/proto"
+	"os"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// testInvoices is an invoice store on a database of its own, with a client
+// and template to issue documents for in the default workspace.
+type testInvoices struct {
+	*Invoices
+	db       *sql.DB
+	client   uint32
+	template uint32
+	refunds  *testRefunds
+}
+
+// testRefunds stands in for the payment store, it takes back whatever it
+// is asked to.
+type testRefunds struct {
+	released decimal.Decimal
+}
+
+func (r *testRefunds) Release(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, amount decimal.Decimal, actor *audit.Actor) error {
+	r.released = r.released.Add(amount)
+	return nil
+}
+
+// newTestInvoices returns invoices in a temporary directory, which issued
+// documents are written below.
+func newTestInvoices(t *testing.T) *testInvoices {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	db, err := database.OpenFile("test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotas, err := quota.NewQuotas(db, quota.DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := client.NewClients(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := template.NewTemplates(db, audit_log, quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := sequence.NewSequences(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := exchange.NewRates(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xs, err := tax.NewTaxRates(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := catalog.NewCatalog(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuers, err := issuer.NewIssuers(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := NewInvoices(db, audit_log, quotas, cs, ts, ss, rs, xs, items, issuers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { is.Close() })
+
+	refunds := &testRefunds{}
+	is.SetRefunds(refunds)
+
+	ctx := context.Background()
+	c, err := cs.Create(ctx, 1, &pb.SaveClientRequest{LegalName: "Acme GmbH", DefaultCurrency: "EUR"}, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile("template.html", []byte("<p>Invoice</p>"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ts.Insert(ctx, &pb.Template{Name: "Plain", Ext: "html", Path: "template.html"}, 1, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testInvoices{Invoices: is, db: db, client: c.Id, template: uint32(tmpl.Data().Id), refunds: refunds}
+}
+
+// create adds a draft of the kind with the lines, given as quantity and
+// unit price, taxed at 19%.
+func (ti *testInvoices) create(t *testing.T, kind string, lines ...[2]string) *pb.Invoice {
+	t.Helper()
+
+	req := &pb.SaveInvoiceRequest{Kind: kind, ClientId: ti.client, TemplateId: ti.template, Currency: "EUR"}
+	for _, line := range lines {
+		req.Items = append(req.Items, &pb.LineItem{Description: "Consulting", Quantity: line[0], UnitPrice: line[1], TaxRate: "19"})
+	}
+	invoice, err := ti.Create(context.Background(), 1, req, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return invoice
+}
+
+// issue creates a document like create and issues it, leaving out the PDF.
+func (ti *testInvoices) issue(t *testing.T, kind string, lines ...[2]string) *pb.Invoice {
+	t.Helper()
+
+	ctx := context.Background()
+	invoice := ti.create(t, kind, lines...)
+	tx, err := ti.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err = ti.IssueTx(ctx, tx, invoice, audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return ti.retrieve(t, invoice.Id)
+}
+
+// pay settles the invoice as if payments of amount in total were allocated
+// to it.
+func (ti *testInvoices) pay(t *testing.T, id uint32, amount string) *pb.Invoice {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := ti.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	invoice, err := ti.RetrieveTx(ctx, tx, 1, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ti.Settle(ctx, tx, invoice, decimal.RequireFromString(amount), "payment", audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return ti.retrieve(t, id)
+}
+
+func (ti *testInvoices) retrieve(t *testing.T, id uint32) *pb.Invoice {
+	t.Helper()
+
+	invoice, err := ti.Retrieve(context.Background(), 1, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return invoice
+}
+
+func TestComputeBalance(t *testing.T) {
+	tests := []struct {
+		kind     string
+		gross    string
+		paid     string
+		credited string
+		want     string
+	}{
+		{KIND_INVOICE, "119", "", "", "119.00"},
+		{KIND_INVOICE, "119", "19.5", "", "99.50"},
+		{KIND_INVOICE, "119", "100", "19", "0.00"},
+		{KIND_CREDIT_NOTE, "-119", "", "", "0.00"},
+	}
+
+	for _, test := range tests {
+		invoice := &pb.Invoice{Kind: test.kind, Currency: "EUR", Paid: test.paid, Credited: test.credited, Totals: &pb.Totals{Gross: test.gross}}
+		if err := computeBalance(invoice); err != nil {
+			t.Fatal(err)
+		}
+		if invoice.Balance != test.want {
+			t.Errorf("balance of %s %s paid %q credited %q = %s, want %s", test.kind, test.gross, test.paid, test.credited, invoice.Balance, test.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package invoice
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/exchange"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/sequence"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/telemetry"
+	"invoice-manager/main/internal/template"
+	pb "invoice-manager/main/proto"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrNoItems        = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice has no line items")
+	ErrNotIssued      = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice hasn't been issued yet")
+	ErrNoExchangeRate = apperr.New(apperr.CODE_FAILED_PRECONDITION, "no exchange rate to the base currency, import rates or set one by hand")
+	ErrNumberTaken    = apperr.New(apperr.CODE_FAILED_PRECONDITION, "the next number is already taken, change the prefix or pattern of the sequence")
+)
+
+// Issue gives a draft its number and freezes it. The HTML is rendered and
+// stored along with a snapshot of the client, issuer and template, so that
+// later changes to them don't affect the issued document. The PDF is printed
+// from the stored HTML after the invoice is committed; if that fails, it is
+// printed again when first downloaded.
+func (is *Invoices) Issue(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.Start(ctx, "Invoices.Issue")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	html_path, err := is.issue(ctx, tx, invoice, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		os.Remove(html_path)
+		return nil, err
+	}
+
+	is.afterIssue(ctx, invoice, html_path, actor)
+	return invoice, nil
+}
+
+// IssueTx issues a draft within tx once the render quotas allow it, e.g. one
+// that a recurring schedule generates. Callers pass the
+// returned HTML to AfterIssue once tx is committed, or remove it otherwise.
+func (is *Invoices) IssueTx(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, actor *audit.Actor) (string, error) {
+	return is.issue(ctx, tx, invoice, actor)
+}
+
+// AfterIssue finishes issuing a document with IssueTx, see afterIssue.
+func (is *Invoices) AfterIssue(ctx context.Context, invoice *pb.Invoice, html_path string, actor *audit.Actor) {
+	is.afterIssue(ctx, invoice, html_path, actor)
+}
+
+// issue numbers the draft within tx, stores its HTML and snapshot and moves
+// it to issued. The render is reserved against the quotas within tx as
+// well. The invoice is updated in place. The HTML is written last, callers
+// remove it if tx doesn't get committed.
+func (is *Invoices) issue(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, actor *audit.Actor) (string, error) {
+	if !CanTransition(invoice.Kind, invoice.Status, STATUS_ISSUED) {
+		return "", fmt.Errorf("%w: %s to %s", ErrInvalidTransition, invoice.Status, STATUS_ISSUED)
+	}
+	if len(invoice.Items) == 0 {
+		return "", ErrNoItems
+	}
+	if invoice.TemplateId == 0 {
+		return "", ErrNoTemplate
+	}
+	if err := is.quotas.ReserveTx(tx, invoice.WorkspaceId, actor.UserId, quota.KIND_RENDER); err != nil {
+		return "", err
+	}
+
+	c, err := is.clients.Retrieve(ctx, invoice.WorkspaceId, invoice.ClientId)
+	if err != nil {
+		return "", err
+	}
+
+	iss, err := is.retrieveIssuer(ctx, invoice.WorkspaceId, invoice.IssuerId)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := is.templates.Retrieve(ctx, invoice.WorkspaceId, int(invoice.TemplateId))
+	if err == template.ErrIDNotFound {
+		return "", ErrNoTemplate
+	}
+	if err != nil {
+		return "", err
+	}
+
+	source, err := os.ReadFile(t.Data().Path)
+	if err != nil {
+		return "", err
+	}
+
+	issue_date, err := time.Parse(time.DateOnly, invoice.IssueDate)
+	if err != nil {
+		return "", err
+	}
+
+	if err = is.recordRate(ctx, invoice); err != nil {
+		return "", err
+	}
+
+	document_type := sequence.DOC_CREDIT_NOTE
+	switch invoice.Kind {
+	case KIND_INVOICE:
+		document_type = sequence.DOC_INVOICE
+	case KIND_QUOTE:
+		document_type = sequence.DOC_QUOTE
+	}
+	invoice.Number, err = is.sequences.Allocate(ctx, tx, invoice.WorkspaceId, document_type, invoice.IssuerId, issue_date)
+	if err != nil {
+		return "", err
+	}
+
+	invoice.IssuedAt = time.Now().Unix()
+	invoice.Snapshot = &pb.InvoiceSnapshot{
+		Client:            c,
+		TemplateId:        invoice.TemplateId,
+		TemplateName:      t.Data().Name,
+		TemplateSha256:    fmt.Sprintf("%x", sha256.Sum256(source)),
+		TemplateUpdatedAt: t.Data().UpdatedAt,
+		Issuer:            iss,
+	}
+
+	dir, err := storage.EnsureDocumentDir(invoice.WorkspaceId, storage.INVOICES)
+	if err != nil {
+		return "", err
+	}
+	html_path := filepath.Join(dir, fmt.Sprintf("%d_%d.html", invoice.Id, time.Now().UnixNano()))
+
+	// The issued invoice is written before it leaves the draft status, the
+	// database refuses to change it afterwards.
+	_, err = tx.Stmt(is.issue_stmt).ExecContext(
+		ctx,
+		invoice.Number,
+		marshalJson(invoice.Snapshot),
+		invoice.IssuedAt,
+		html_path,
+		invoice.BaseCurrency,
+		invoice.ExchangeRate,
+		invoice.Id,
+		invoice.WorkspaceId,
+	)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return "", ErrNumberTaken
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = is.transition(ctx, tx, invoice, STATUS_ISSUED, "", actor); err != nil {
+		return "", err
+	}
+
+	rendered, err := is.renderedIssuer(ctx, invoice.WorkspaceId, iss)
+	if err != nil {
+		return "", err
+	}
+
+	filled, err := Fill(string(source), invoice, c, rendered)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.WriteFile(html_path, []byte(filled), 0660); err != nil {
+		return "", err
+	}
+
+	return html_path, nil
+}
+
+// recordRate sets the rate the invoice converts to the base currency of the
+// workspace with, unless it was set by hand. It is that of the issue date,
+// 1 for invoices in the base currency.
+func (is *Invoices) recordRate(ctx context.Context, invoice *pb.Invoice) error {
+	if invoice.ExchangeRate == "" {
+		base, err := is.rates.BaseCurrency(ctx, invoice.WorkspaceId)
+		if err != nil {
+			return err
+		}
+
+		rate, err := is.rates.Rate(ctx, invoice.WorkspaceId, invoice.Currency, base, invoice.IssueDate)
+		if errors.Is(err, exchange.ErrNoRate) {
+			return fmt.Errorf("%w: %s to %s on %s", ErrNoExchangeRate, invoice.Currency, base, invoice.IssueDate)
+		}
+		if err != nil {
+			return err
+		}
+		invoice.BaseCurrency, invoice.ExchangeRate = base, rate.Rate
+	}
+
+	return computeBaseGross(invoice)
+}
+
+// afterIssue prints the PDF of a committed document. Failures are only
+// logged, the PDF is printed again when downloaded.
+func (is *Invoices) afterIssue(ctx context.Context, invoice *pb.Invoice, html_path string, actor *audit.Actor) {
+	if _, err := is.printPdf(ctx, invoice.WorkspaceId, invoice.Id, html_path); err != nil {
+		log.Println("Error printing invoice", invoice.Id, err)
+	}
+}
+
+// printPdf prints the stored HTML of an issued invoice as a conversion job,
+// so that shutting down waits for it.
+func (is *Invoices) printPdf(ctx context.Context, workspace_id uint32, id uint32, html_path string) (_ string, err error) {
+	job_ctx, done, err := is.templates.Jobs().Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	pdf_path := strings.TrimSuffix(html_path, filepath.Ext(html_path)) + ".pdf"
+	if err = template.ConvertHtmlToPdf(job_ctx, html_path, pdf_path); err != nil {
+		return "", err
+	}
+
+	if _, err = is.pdf_stmt.ExecContext(ctx, pdf_path, id, workspace_id); err != nil {
+		return "", err
+	}
+
+	return pdf_path, nil
+}
+
+// documents returns the paths of the stored HTML and PDF of an invoice,
+// which are empty for drafts and before the PDF is printed.
+func (is *Invoices) documents(ctx context.Context, workspace_id uint32, id uint32) (html_path string, pdf_path string, err error) {
+	err = is.documents_stmt.QueryRowContext(ctx, id, workspace_id).Scan(&html_path, &pdf_path)
+	if err == sql.ErrNoRows {
+		return "", "", ErrIDNotFound
+	}
+	return html_path, pdf_path, err
+}
+
+// Pdf returns the path of the PDF of an issued invoice, printing it first
+// if that failed when the invoice was issued.
+func (is *Invoices) Pdf(ctx context.Context, workspace_id uint32, id uint32) (_ string, err error) {
+	ctx, end := telemetry.Start(ctx, "Invoices.Pdf")
+	defer end(&err)
+
+	html_path, pdf_path, err := is.documents(ctx, workspace_id, id)
+	if err != nil {
+		return "", err
+	}
+	if html_path == "" {
+		return "", ErrNotIssued
+	}
+
+	if pdf_path != "" {
+		if _, err = os.Stat(pdf_path); err == nil {
+			return pdf_path, nil
+		}
+	}
+
+	return is.printPdf(ctx, workspace_id, id, html_path)
+}
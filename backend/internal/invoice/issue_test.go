@@ -0,0 +1,101 @@
+package invoice
+
+import (
+	"context"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/sequence"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// issueOnce issues the draft in a transaction of its own and commits it,
+// or rolls it back if rollback is set.
+func (ti *testInvoices) issueOnce(ctx context.Context, id uint32, rollback bool) error {
+	tx, err := ti.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	invoice, err := ti.RetrieveTx(ctx, tx, 1, id)
+	if err != nil {
+		return err
+	}
+	html_path, err := ti.IssueTx(ctx, tx, invoice, audit.CliActor)
+	if err != nil {
+		return err
+	}
+	if rollback {
+		return os.Remove(html_path)
+	}
+	if err = tx.Commit(); err != nil {
+		os.Remove(html_path)
+	}
+	return err
+}
+
+func TestIssueConcurrently(t *testing.T) {
+	const DRAFTS = 12
+
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	ids := []uint32{}
+	for range DRAFTS {
+		ids = append(ids, ti.create(t, KIND_INVOICE, [2]string{"1", "100"}).Id)
+	}
+
+	// Every third draft is rolled back once before it is issued, the
+	// numbers it held are handed out again.
+	errs := make(chan error, DRAFTS)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%3 == 0 {
+				if err := ti.issueOnce(ctx, id, true); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- ti.issueOnce(ctx, id, false)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("issue error = %v", err)
+		}
+	}
+
+	got := []string{}
+	for _, id := range ids {
+		invoice := ti.retrieve(t, id)
+		if invoice.Status != STATUS_ISSUED {
+			t.Fatalf("invoice %d is %s, want %s", id, invoice.Status, STATUS_ISSUED)
+		}
+		got = append(got, invoice.Number)
+	}
+	slices.Sort(got)
+
+	date, err := time.Parse(time.DateOnly, ti.retrieve(t, ids[0]).IssueDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, seq, err := ti.sequences.Preview(ctx, 1, sequence.DOC_INVOICE, 0, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{}
+	for value := range int64(DRAFTS) {
+		want = append(want, sequence.Format(seq.Pattern, seq.Prefix, date, value+1))
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("numbers = %v, want %v without gaps or duplicates", got, want)
+	}
+}
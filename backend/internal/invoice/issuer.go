@@ -0,0 +1,87 @@
+package invoice
+
+import (
+	"context"
+	"invoice-manager/main/internal/issuer"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"strings"
+)
+
+// retrieveIssuer returns the issuer an invoice is made out from, nil for
+// invoices the workspace issues itself.
+func (is *Invoices) retrieveIssuer(ctx context.Context, workspace_id uint32, id uint32) (*pb.Issuer, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	iss, err := is.issuers.Retrieve(ctx, workspace_id, id)
+	if err == issuer.ErrIDNotFound {
+		return nil, ErrIssuerNotFound
+	}
+	return iss, err
+}
+
+// seller is who invoices are issued from as far as VAT is concerned, the
+// issuer or otherwise the workspace.
+func (is *Invoices) seller(ctx context.Context, workspace_id uint32, iss *pb.Issuer) (tax.Party, error) {
+	if iss == nil {
+		return is.taxes.Seller(ctx, workspace_id)
+	}
+	return tax.Party{Country: iss.Address.GetCountry(), VatId: iss.VatId}, nil
+}
+
+// renderedIssuer is the issuer the placeholders of an invoice are filled
+// with. Invoices the workspace issues itself only know its tax details.
+func (is *Invoices) renderedIssuer(ctx context.Context, workspace_id uint32, iss *pb.Issuer) (*pb.Issuer, error) {
+	if iss != nil {
+		return iss, nil
+	}
+
+	seller, err := is.taxes.Seller(ctx, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Issuer{Address: &pb.Address{Country: seller.Country}, VatId: seller.VatId}, nil
+}
+
+// issuerValues adds the placeholders of the issuer, e.g. {{issuer.iban}}
+// of its primary bank account. The logo is embedded as a data URI.
+func issuerValues(v values, iss *pb.Issuer) error {
+	logo, err := issuer.LogoDataUri(iss.Logo)
+	if err != nil {
+		return err
+	}
+
+	numbers := []string{}
+	for _, number := range iss.RegistrationNumbers {
+		if number.Label != "" {
+			numbers = append(numbers, number.Label+": "+number.Value)
+		} else {
+			numbers = append(numbers, number.Value)
+		}
+	}
+
+	account := issuer.PrimaryAccount(iss)
+	if account == nil {
+		account = &pb.BankAccount{}
+	}
+	holder := account.AccountHolder
+	if holder == "" && account.Iban != "" {
+		holder = iss.LegalName
+	}
+
+	v["issuer.name"] = iss.Name
+	v["issuer.legalName"] = iss.LegalName
+	v["issuer.taxId"] = iss.TaxId
+	v["issuer.vatId"] = iss.VatId
+	v["issuer.registrationNumbers"] = strings.Join(numbers, "\n")
+	v["issuer.iban"] = account.Iban
+	v["issuer.bic"] = account.Bic
+	v["issuer.bankName"] = account.BankName
+	v["issuer.accountHolder"] = holder
+	v["issuer.logo"] = logo
+	v["issuer.footer"] = iss.Footer
+	addressValues(v, "issuer.address", iss.Address)
+	return nil
+}
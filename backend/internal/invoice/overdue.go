@@ -0,0 +1,71 @@
+package invoice
+
+import (
+	"context"
+	"invoice-manager/main/internal/helpers"
+	"log"
+	"time"
+)
+
+const DEFAULT_OVERDUE_INTERVAL = time.Hour
+
+// OverdueMarker periodically moves issued and sent invoices past their due
+// date to overdue, and quotes past their validity date to expired.
+type OverdueMarker struct {
+	invoices *Invoices
+	interval time.Duration
+}
+
+func NewOverdueMarker(is *Invoices, interval time.Duration) *OverdueMarker {
+	return &OverdueMarker{invoices: is, interval: interval}
+}
+
+// OverdueMarkerFromEnv reads the interval in seconds from
+// INVOICER_OVERDUE_INTERVAL.
+func OverdueMarkerFromEnv(is *Invoices) *OverdueMarker {
+	interval := helpers.EnvInt("INVOICER_OVERDUE_INTERVAL", int64(DEFAULT_OVERDUE_INTERVAL/time.Second))
+	return NewOverdueMarker(is, time.Duration(interval)*time.Second)
+}
+
+func (m *OverdueMarker) mark(ctx context.Context) {
+	marked, err := m.invoices.MarkOverdue(ctx, time.Now())
+	if err != nil {
+		log.Println("Error marking overdue invoices:", err)
+		return
+	}
+
+	if marked > 0 {
+		log.Printf("Marked %d invoices as overdue", marked)
+	}
+
+	expired, err := m.invoices.ExpireQuotes(ctx, time.Now())
+	if err != nil {
+		log.Println("Error expiring quotes:", err)
+		return
+	}
+
+	if expired > 0 {
+		log.Printf("Expired %d quotes", expired)
+	}
+}
+
+// Run marks overdue invoices once right away and then every interval, until
+// ctx is done.
+func (m *OverdueMarker) Run(ctx context.Context) error {
+	if m.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.mark(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package invoice
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_LIMIT = 50
+	MAX_LIMIT     = 500
+)
+
+// Filter selects a page of the invoices of one workspace, zero values match
+// everything. From and To are issue dates formatted as YYYY-MM-DD.
+type Filter struct {
+	WorkspaceId uint32
+	Status      string
+	Kind        string
+	ClientId    uint32
+	From        string
+	To          string
+	Query       string
+	Limit       int
+	Offset      int
+}
+
+func invalidFilter(field, description string) error {
+	return apperr.Invalid("Invalid invoice filter", apperr.Field(field, description))
+}
+
+// ParseFilter reads a filter from the query parameters status, kind, client,
+// from, to, q, limit and offset.
+func ParseFilter(values url.Values) (*Filter, error) {
+	filter := &Filter{
+		Status: values.Get("status"),
+		Kind:   values.Get("kind"),
+		Query:  strings.TrimSpace(values.Get("q")),
+		Limit:  DEFAULT_LIMIT,
+	}
+
+	if filter.Kind != "" && !slices.Contains(Kinds, filter.Kind) {
+		return nil, invalidFilter("kind", "must be one of "+strings.Join(Kinds, ", "))
+	}
+
+	if filter.Status != "" && !slices.Contains(Statuses, filter.Status) {
+		return nil, invalidFilter("status", "must be one of "+strings.Join(Statuses, ", "))
+	}
+
+	if client := values.Get("client"); client != "" {
+		id, err := strconv.ParseUint(client, 10, 32)
+		if err != nil {
+			return nil, invalidFilter("client", "must be an ID")
+		}
+		filter.ClientId = uint32(id)
+	}
+
+	for name, value := range map[string]*string{"from": &filter.From, "to": &filter.To} {
+		*value = values.Get(name)
+		if _, err := time.Parse(time.DateOnly, *value); *value != "" && err != nil {
+			return nil, invalidFilter(name, "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+
+	var err error
+	if limit := values.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit < 1 || filter.Limit > MAX_LIMIT {
+			return nil, invalidFilter("limit", "must be between 1 and "+strconv.Itoa(MAX_LIMIT))
+		}
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		filter.Offset, err = strconv.Atoi(offset)
+		if err != nil || filter.Offset < 0 {
+			return nil, invalidFilter("offset", "must not be negative")
+		}
+	}
+
+	return filter, nil
+}
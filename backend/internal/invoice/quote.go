@@ -0,0 +1,182 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Quotes are offers made before invoicing. They are numbered by their own
+// sequence, rendered with the quote template of the client and valid until
+// their due date, after which they expire. Accepted quotes are converted
+// into draft invoices, all at once or in shares for milestone billing.
+const DEFAULT_QUOTE_VALIDITY_DAYS = 30
+
+// convertible are the statuses of quotes that can be converted, issued and
+// sent quotes are accepted by converting them.
+var convertible = []string{STATUS_ISSUED, STATUS_SENT, STATUS_ACCEPTED}
+
+var (
+	ErrNotConvertible    = apperr.New(apperr.CODE_FAILED_PRECONDITION, "only issued quotes can be converted")
+	ErrShareExceedsQuote = apperr.New(apperr.CODE_FAILED_PRECONDITION, "share exceeds what is left of the quote")
+)
+
+// scaleLines copies the lines and discounts of the quote for an invoice
+// billing ratio of it. Quantities and fixed discount amounts are scaled,
+// prices and percentages stay as they are.
+func scaleLines(quote *pb.Invoice, ratio decimal.Decimal) ([]*pb.LineItem, []*pb.Discount, error) {
+	items := []*pb.LineItem{}
+	for _, item := range quote.Items {
+		quantity, err := parseDecimal(item.Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+		discount, err := scaleDiscount(item.Discount, ratio, quote.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, &pb.LineItem{
+			Description:     item.Description,
+			Quantity:        quantity.Mul(ratio).String(),
+			Unit:            item.Unit,
+			UnitPrice:       item.UnitPrice,
+			Discount:        discount,
+			TaxRate:         item.TaxRate,
+			TaxRateId:       item.TaxRateId,
+			TaxCategory:     item.TaxCategory,
+			ExemptionReason: item.ExemptionReason,
+			CatalogItemId:   item.CatalogItemId,
+			Sku:             item.Sku,
+		})
+	}
+
+	discounts := []*pb.Discount{}
+	for _, discount := range quote.Discounts {
+		scaled, err := scaleDiscount(discount, ratio, quote.Currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		discounts = append(discounts, scaled)
+	}
+
+	return items, discounts, nil
+}
+
+// Convert creates a draft invoice billing percent of an issued quote, all
+// that is left of it if the request has none. The quote is accepted if it
+// hasn't been yet. The invoice takes its template, dates and payment terms
+// from the defaults of the client.
+func (is *Invoices) Convert(ctx context.Context, workspace_id uint32, id uint32, req *pb.ConvertQuoteRequest, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Convert")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quote, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Kind != KIND_QUOTE || !slices.Contains(convertible, quote.Status) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotConvertible, strings.ReplaceAll(quote.Kind, "_", " "), quote.Status)
+	}
+
+	converted, err := parseDecimal(quote.Converted)
+	if err != nil {
+		return nil, err
+	}
+	left := hundred.Sub(converted)
+
+	percent := left
+	if req.Percent = strings.TrimSpace(req.Percent); req.Percent != "" {
+		percent, err = decimal.NewFromString(req.Percent)
+		if err != nil || !percent.IsPositive() {
+			return nil, apperr.Invalid("Conversion is invalid", apperr.Field("percent", "must be a decimal number greater than zero"))
+		}
+	}
+	if !left.IsPositive() {
+		return nil, fmt.Errorf("%w: it has been converted in full", ErrShareExceedsQuote)
+	}
+	if percent.GreaterThan(left) {
+		return nil, fmt.Errorf("%w: %s%% of the %s%% left", ErrShareExceedsQuote, percent, left)
+	}
+
+	if quote.Status != STATUS_ACCEPTED {
+		if err = is.transition(ctx, tx, quote, STATUS_ACCEPTED, "converted into an invoice", actor); err != nil {
+			return nil, err
+		}
+	}
+
+	items, discounts, err := scaleLines(quote, percent.Div(hundred))
+	if err != nil {
+		return nil, err
+	}
+
+	invoice, err := is.create(ctx, tx, workspace_id, &pb.SaveInvoiceRequest{
+		ClientId:         quote.ClientId,
+		IssuerId:         quote.IssuerId,
+		Currency:         quote.Currency,
+		Language:         quote.Language,
+		Items:            items,
+		Discounts:        discounts,
+		PricesIncludeTax: quote.PricesIncludeTax,
+		BuyerReference:   quote.BuyerReference,
+	}, quote.Id, percent, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Converted = converted.Add(percent).String()
+	if _, err = tx.Stmt(is.converted_stmt).ExecContext(ctx, quote.Converted, quote.Id, workspace_id); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// release gives the share of the quote a deleted draft was converted from
+// back, so that it can be converted again.
+func (is *Invoices) release(ctx context.Context, tx *sql.Tx, workspace_id uint32, invoice *pb.Invoice) error {
+	quote, err := is.retrieve(ctx, tx, workspace_id, invoice.OriginalId)
+	if err != nil {
+		return err
+	}
+
+	converted, err := parseDecimal(quote.Converted)
+	if err != nil {
+		return err
+	}
+	share, err := parseDecimal(invoice.Share)
+	if err != nil {
+		return err
+	}
+
+	converted = decimal.Max(converted.Sub(share), decimal.Zero)
+	_, err = tx.Stmt(is.converted_stmt).ExecContext(ctx, converted.String(), quote.Id, workspace_id)
+	return err
+}
+
+// Conversions returns the invoices converted from the quote, oldest first.
+// Line items are left out.
+func (is *Invoices) Conversions(ctx context.Context, workspace_id uint32, id uint32) (_ []*pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Conversions")
+	defer end(&err)
+
+	return is.derived(ctx, workspace_id, id)
+}
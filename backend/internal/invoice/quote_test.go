@@ -0,0 +1,213 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	pb "invoice-manager/main/proto"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	type step struct {
+		// Percent of the quote to convert, all that is left if empty.
+		percent string
+		// Quantity and gross of the invoice, or the code of the error the
+		// conversion fails with.
+		quantity string
+		gross    string
+		code     apperr.Code
+		// Converted of the quote afterwards.
+		converted string
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "in full",
+			steps: []step{{quantity: "1", gross: "11900.00", converted: "100"}},
+		},
+		{
+			name:  "percentage",
+			steps: []step{{percent: "30", quantity: "0.3", gross: "3570.00", converted: "30"}},
+		},
+		{
+			name: "milestones",
+			steps: []step{
+				{percent: "30", quantity: "0.3", gross: "3570.00", converted: "30"},
+				{percent: "50", quantity: "0.5", gross: "5950.00", converted: "80"},
+				{percent: "25", code: apperr.CODE_FAILED_PRECONDITION},
+				{quantity: "0.2", gross: "2380.00", converted: "100"},
+				{percent: "1", code: apperr.CODE_FAILED_PRECONDITION},
+				{code: apperr.CODE_FAILED_PRECONDITION},
+			},
+		},
+		{
+			name:  "more than the quote",
+			steps: []step{{percent: "100.5", code: apperr.CODE_FAILED_PRECONDITION}},
+		},
+		{
+			name: "invalid percentages",
+			steps: []step{
+				{percent: "0", code: apperr.CODE_INVALID_ARGUMENT},
+				{percent: "-30", code: apperr.CODE_INVALID_ARGUMENT},
+				{percent: "thirty", code: apperr.CODE_INVALID_ARGUMENT},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ti := newTestInvoices(t)
+			quote := ti.issue(t, KIND_QUOTE, [2]string{"1", "10000"})
+
+			for i, step := range test.steps {
+				invoice, err := ti.Convert(context.Background(), 1, quote.Id, &pb.ConvertQuoteRequest{Percent: step.percent}, audit.CliActor)
+				if step.code != "" {
+					var app_err *apperr.Error
+					if !errors.As(err, &app_err) || app_err.Code != step.code {
+						t.Fatalf("step %d: Convert(%q) error = %v, want %s", i+1, step.percent, err, step.code)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d: Convert(%q) error = %v", i+1, step.percent, err)
+				}
+
+				if invoice.Kind != KIND_INVOICE || invoice.Status != STATUS_DRAFT || invoice.OriginalId != quote.Id {
+					t.Errorf("step %d: conversion is a %s %s of %d", i+1, invoice.Status, invoice.Kind, invoice.OriginalId)
+				}
+				if invoice.Items[0].Quantity != step.quantity || invoice.Items[0].UnitPrice != "10000" {
+					t.Errorf("step %d: conversion bills %s × %s, want %s × 10000", i+1, invoice.Items[0].Quantity, invoice.Items[0].UnitPrice, step.quantity)
+				}
+				if invoice.Totals.Gross != step.gross {
+					t.Errorf("step %d: conversion gross = %s, want %s", i+1, invoice.Totals.Gross, step.gross)
+				}
+
+				got := ti.retrieve(t, quote.Id)
+				if got.Status != STATUS_ACCEPTED || got.Converted != step.converted {
+					t.Errorf("step %d: quote is %s with %s%% converted, want accepted with %s%%", i+1, got.Status, got.Converted, step.converted)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertLines(t *testing.T) {
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	quote, err := ti.Create(ctx, 1, &pb.SaveInvoiceRequest{
+		Kind:       KIND_QUOTE,
+		ClientId:   ti.client,
+		TemplateId: ti.template,
+		Currency:   "EUR",
+		Items: []*pb.LineItem{
+			{Description: "Design", Quantity: "10", Unit: "h", UnitPrice: "120", TaxRate: "19", Discount: &pb.Discount{Amount: "100", Description: "Kick-off"}},
+			{Description: "Hosting", Quantity: "12", Unit: "month", UnitPrice: "25", TaxRate: "7", Discount: &pb.Discount{Percent: "10"}},
+		},
+		Discounts: []*pb.Discount{{Amount: "50", Description: "Loyalty"}, {Percent: "2"}},
+	}, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := ti.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err = ti.IssueTx(ctx, tx, quote, audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	invoice, err := ti.Convert(ctx, 1, quote.Id, &pb.ConvertQuoteRequest{Percent: "50"}, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines as "description quantity unit × price at tax less discount
+	// amount/percent", and the discounts of the invoice as
+	// "amount/percent".
+	want := []string{"Design 5 h × 120 at 19 less 50/", "Hosting 6 month × 25 at 7 less /10"}
+	if len(invoice.Items) != len(want) {
+		t.Fatalf("conversion has %d lines, want %d", len(invoice.Items), len(want))
+	}
+	for i, item := range invoice.Items {
+		got := item.Description + " " + item.Quantity + " " + item.Unit + " × " + item.UnitPrice + " at " + item.TaxRate +
+			" less " + item.Discount.Amount + "/" + item.Discount.Percent
+		if got != want[i] {
+			t.Errorf("line %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+
+	discounts := []string{"25/", "/2"}
+	if len(invoice.Discounts) != len(discounts) {
+		t.Fatalf("conversion has %d discounts, want %d", len(invoice.Discounts), len(discounts))
+	}
+	for i, discount := range invoice.Discounts {
+		if got := discount.Amount + "/" + discount.Percent; got != discounts[i] {
+			t.Errorf("discount %d = %s, want %s", i+1, got, discounts[i])
+		}
+	}
+
+	if invoice.ClientId != quote.ClientId || invoice.Currency != quote.Currency || invoice.Share != "50" {
+		t.Errorf("conversion of client %d in %s for %s%%, want client %d in %s for 50%%", invoice.ClientId, invoice.Currency, invoice.Share, quote.ClientId, quote.Currency)
+	}
+}
+
+func TestConvertOnlyIssuedQuotes(t *testing.T) {
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	draft := ti.create(t, KIND_QUOTE, [2]string{"1", "100"})
+	invoice := ti.issue(t, KIND_INVOICE, [2]string{"1", "100"})
+	declined := ti.issue(t, KIND_QUOTE, [2]string{"1", "100"})
+	if _, err := ti.Transition(ctx, 1, declined.Id, STATUS_DECLINED, "", audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, document := range []*pb.Invoice{draft, invoice, declined} {
+		if _, err := ti.Convert(ctx, 1, document.Id, &pb.ConvertQuoteRequest{}, audit.CliActor); !errors.Is(err, ErrNotConvertible) {
+			t.Errorf("Convert() of a %s %s error = %v, want %v", document.Status, document.Kind, err, ErrNotConvertible)
+		}
+	}
+}
+
+func TestConvertRelease(t *testing.T) {
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	quote := ti.issue(t, KIND_QUOTE, [2]string{"1", "10000"})
+	first, err := ti.Convert(ctx, 1, quote.Id, &pb.ConvertQuoteRequest{Percent: "70"}, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ti.Convert(ctx, 1, quote.Id, &pb.ConvertQuoteRequest{Percent: "30"}, audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting a draft gives its share back to convert again.
+	if err = ti.Delete(ctx, 1, first.Id, audit.CliActor); err != nil {
+		t.Fatal(err)
+	}
+	if got := ti.retrieve(t, quote.Id); got.Converted != "30" {
+		t.Errorf("quote has %s%% converted after deleting a conversion, want 30%%", got.Converted)
+	}
+	if _, err = ti.Convert(ctx, 1, quote.Id, &pb.ConvertQuoteRequest{Percent: "70"}, audit.CliActor); err != nil {
+		t.Errorf("Convert() of the released share error = %v", err)
+	}
+
+	conversions, err := ti.Conversions(ctx, 1, quote.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversions) != 2 {
+		t.Errorf("quote has %d conversions, want 2", len(conversions))
+	}
+}
@@ -0,0 +1,347 @@
+package invoice
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/telemetry"
+	"invoice-manager/main/internal/template"
+	pb "invoice-manager/main/proto"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNoTemplate  = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice has no template")
+	ErrPlaceholder = apperr.New(apperr.CODE_FAILED_PRECONDITION, "template has invalid placeholders")
+)
+
+// Placeholders look like {{invoice.number}}. The HTML between {{#items}}
+// and {{/items}} is repeated for every line item, and that between
+// {{#taxes}} and {{/taxes}} for every tax rate.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([#/]?)\s*([A-Za-z][A-Za-z0-9_.]*)\s*\}\}`)
+
+// sections are the placeholders that repeat the HTML they enclose.
+var sections = map[string]bool{"items": true, "taxes": true}
+
+type values map[string]string
+
+func (v values) with(other values) values {
+	merged := values{}
+	for name, value := range v {
+		merged[name] = value
+	}
+	for name, value := range other {
+		merged[name] = value
+	}
+	return merged
+}
+
+func addressValues(v values, prefix string, address *pb.Address) {
+	if address == nil {
+		address = &pb.Address{}
+	}
+	v[prefix+".line1"] = address.Line1
+	v[prefix+".line2"] = address.Line2
+	v[prefix+".city"] = address.City
+	v[prefix+".postalCode"] = address.PostalCode
+	v[prefix+".region"] = address.Region
+	v[prefix+".country"] = address.Country
+}
+
+func primaryContact(c *pb.Client) *pb.Contact {
+	for _, contact := range c.Contacts {
+		if contact.Primary {
+			return contact
+		}
+	}
+	if len(c.Contacts) > 0 {
+		return c.Contacts[0]
+	}
+	return &pb.Contact{}
+}
+
+// displayAmount formats an amount of the invoice for readers of its
+// language, e.g. "1.234,50" for EUR in German. Values that aren't amounts
+// are left as they are.
+func displayAmount(value string, currency string, lang string) string {
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return value
+	}
+	return money.FormatNumber(amount, currency, lang)
+}
+
+func discountText(invoice *pb.Invoice, discount *pb.Discount) string {
+	switch {
+	case discount == nil:
+		return ""
+	case discount.Percent != "" && discount.Percent != "0":
+		return discount.Percent + "%"
+	case discount.Amount != "" && discount.Amount != "0":
+		return displayAmount(discount.Amount, invoice.Currency, invoice.Language)
+	}
+	return ""
+}
+
+func invoiceValues(invoice *pb.Invoice, c *pb.Client) values {
+	amount := func(value string) string {
+		return displayAmount(value, invoice.Currency, invoice.Language)
+	}
+
+	v := values{
+		"invoice.number":           invoice.Number,
+		"invoice.status":           invoice.Status,
+		"invoice.kind":             invoice.Kind,
+		"invoice.originalNumber":   invoice.OriginalNumber,
+		"invoice.share":            invoice.Share,
+		"invoice.issueDate":        invoice.IssueDate,
+		"invoice.dueDate":          invoice.DueDate,
+		"invoice.serviceDate":      invoice.ServiceDate,
+		"invoice.currency":         invoice.Currency,
+		"invoice.currencySymbol":   money.Symbol(invoice.Currency, invoice.Language),
+		"invoice.notes":            invoice.Notes,
+		"invoice.subtotal":         amount(invoice.Totals.Subtotal),
+		"invoice.discount":         amount(invoice.Totals.Discount),
+		"invoice.net":              amount(invoice.Totals.Net),
+		"invoice.tax":              amount(invoice.Totals.Tax),
+		"invoice.gross":            amount(invoice.Totals.Gross),
+		"invoice.baseCurrency":     invoice.BaseCurrency,
+		"invoice.exchangeRate":     invoice.ExchangeRate,
+		"invoice.baseGross":        displayAmount(invoice.BaseGross, invoice.BaseCurrency, invoice.Language),
+		"invoice.exemptionReasons": exemptionReasons(invoice),
+		"client.legalName":         c.LegalName,
+		"client.taxId":             c.TaxId,
+	}
+	if v["invoice.serviceDate"] == "" {
+		v["invoice.serviceDate"] = invoice.IssueDate
+	}
+	// Quotes are valid until their due date, other documents leave it empty.
+	v["invoice.validUntil"] = ""
+	if invoice.Kind == KIND_QUOTE {
+		v["invoice.validUntil"] = invoice.DueDate
+	}
+	addressValues(v, "client.billingAddress", c.BillingAddress)
+	addressValues(v, "client.shippingAddress", c.ShippingAddress)
+
+	contact := primaryContact(c)
+	v["client.contact.name"] = contact.Name
+	v["client.contact.email"] = contact.Email
+	v["client.contact.phone"] = contact.Phone
+	return v
+}
+
+func itemValues(invoice *pb.Invoice) []values {
+	rows := []values{}
+	for i, item := range invoice.Items {
+		unit_price := item.UnitPrice
+		if price, err := parseDecimal(item.UnitPrice); err == nil {
+			unit_price = money.FormatPrice(price, invoice.Currency, invoice.Language)
+		}
+
+		rows = append(rows, values{
+			"item.position":        fmt.Sprint(i + 1),
+			"item.sku":             item.Sku,
+			"item.description":     item.Description,
+			"item.quantity":        item.Quantity,
+			"item.unit":            item.Unit,
+			"item.unitPrice":       unit_price,
+			"item.discount":        discountText(invoice, item.Discount),
+			"item.taxRate":         item.TaxRate,
+			"item.taxCategory":     item.TaxCategory,
+			"item.exemptionReason": item.ExemptionReason,
+			"item.net":             displayAmount(item.Net, invoice.Currency, invoice.Language),
+		})
+	}
+	return rows
+}
+
+// exemptionReasons lists why lines aren't taxed, each reason once, for the
+// footer of the invoice.
+func exemptionReasons(invoice *pb.Invoice) string {
+	reasons := []string{}
+	for _, tax := range invoice.Totals.Taxes {
+		if tax.ExemptionReason != "" && !slices.Contains(reasons, tax.ExemptionReason) {
+			reasons = append(reasons, tax.ExemptionReason)
+		}
+	}
+	return strings.Join(reasons, "\n")
+}
+
+func taxValues(invoice *pb.Invoice) []values {
+	rows := []values{}
+	for _, tax := range invoice.Totals.Taxes {
+		rows = append(rows, values{
+			"tax.rate":            tax.Rate,
+			"tax.category":        tax.Category,
+			"tax.exemptionReason": tax.ExemptionReason,
+			"tax.base":            displayAmount(tax.Base, invoice.Currency, invoice.Language),
+			"tax.amount":          displayAmount(tax.Amount, invoice.Currency, invoice.Language),
+		})
+	}
+	return rows
+}
+
+type filler struct {
+	rows    map[string][]values
+	unknown map[string]bool
+}
+
+// escape keeps line breaks of multi-line values like notes.
+func escape(value string) string {
+	return strings.ReplaceAll(html.EscapeString(value), "\n", "<br>")
+}
+
+func (f *filler) fill(source string, v values, section string) (string, error) {
+	var out strings.Builder
+	for {
+		loc := placeholderPattern.FindStringSubmatchIndex(source)
+		if loc == nil {
+			out.WriteString(source)
+			return out.String(), nil
+		}
+
+		out.WriteString(source[:loc[0]])
+		kind, name := source[loc[2]:loc[3]], source[loc[4]:loc[5]]
+		rest := source[loc[1]:]
+
+		switch kind {
+		case "#":
+			if !sections[name] || section != "" {
+				return "", fmt.Errorf("%w: section {{#%s}} is unknown or nested", ErrPlaceholder, name)
+			}
+
+			end := placeholderPattern.FindAllStringSubmatchIndex(rest, -1)
+			closing := -1
+			for _, m := range end {
+				if rest[m[2]:m[3]] == "/" && rest[m[4]:m[5]] == name {
+					closing = m[0]
+					rest, source = rest[:m[0]], rest[m[1]:]
+					break
+				}
+			}
+			if closing < 0 {
+				return "", fmt.Errorf("%w: {{#%s}} is never closed", ErrPlaceholder, name)
+			}
+
+			for _, row := range f.rows[name] {
+				filled, err := f.fill(rest, v.with(row), name)
+				if err != nil {
+					return "", err
+				}
+				out.WriteString(filled)
+			}
+			continue
+
+		case "/":
+			return "", fmt.Errorf("%w: {{/%s}} closes no section", ErrPlaceholder, name)
+		}
+
+		value, ok := v[name]
+		if !ok {
+			f.unknown[name] = true
+		}
+		out.WriteString(escape(value))
+		source = rest
+	}
+}
+
+// Fill replaces the placeholders in the HTML of a template with the data of
+// the invoice, its client and issuer. Values are HTML escaped.
+func Fill(source string, invoice *pb.Invoice, c *pb.Client, iss *pb.Issuer) (string, error) {
+	f := &filler{
+		rows:    map[string][]values{"items": itemValues(invoice), "taxes": taxValues(invoice)},
+		unknown: map[string]bool{},
+	}
+
+	v := invoiceValues(invoice, c)
+	if err := issuerValues(v, iss); err != nil {
+		return "", err
+	}
+
+	filled, err := f.fill(source, v, "")
+	if err != nil {
+		return "", err
+	}
+
+	if len(f.unknown) > 0 {
+		names := []string{}
+		for name := range f.unknown {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("%w: unknown placeholders %s", ErrPlaceholder, strings.Join(names, ", "))
+	}
+
+	return filled, nil
+}
+
+// Render fills the template of a draft, which counts against the monthly
+// renders quota of the workspace. Issued invoices return the HTML stored
+// when they were issued.
+func (is *Invoices) Render(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (_ string, err error) {
+	ctx, end := telemetry.Start(ctx, "Invoices.Render")
+	defer end(&err)
+
+	invoice, err := is.Retrieve(ctx, workspace_id, id)
+	if err != nil {
+		return "", err
+	}
+	if invoice.Status != STATUS_DRAFT {
+		html_path, _, err := is.documents(ctx, workspace_id, id)
+		if err != nil {
+			return "", err
+		}
+		stored, err := os.ReadFile(html_path)
+		return string(stored), err
+	}
+	if invoice.TemplateId == 0 {
+		return "", ErrNoTemplate
+	}
+
+	c, err := is.clients.Retrieve(ctx, workspace_id, invoice.ClientId)
+	if err != nil {
+		return "", err
+	}
+
+	iss, err := is.retrieveIssuer(ctx, workspace_id, invoice.IssuerId)
+	if err != nil {
+		return "", err
+	}
+	if iss, err = is.renderedIssuer(ctx, workspace_id, iss); err != nil {
+		return "", err
+	}
+
+	t, err := is.templates.Retrieve(ctx, workspace_id, int(invoice.TemplateId))
+	if err == template.ErrIDNotFound {
+		return "", ErrNoTemplate
+	}
+	if err != nil {
+		return "", err
+	}
+
+	source, err := os.ReadFile(t.Data().Path)
+	if err != nil {
+		return "", err
+	}
+
+	filled, err := Fill(string(source), invoice, c, iss)
+	if err != nil {
+		return "", err
+	}
+
+	if err = is.quotas.Reserve(workspace_id, actor.UserId, quota.KIND_RENDER); err != nil {
+		return "", err
+	}
+
+	return filled, nil
+}
@@ -0,0 +1,80 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"invoice-manager/main/internal/audit"
+	pb "invoice-manager/main/proto"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RetrieveTx reads an invoice within tx, e.g. one that allocates payments to
+// it.
+func (is *Invoices) RetrieveTx(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.Invoice, error) {
+	return is.retrieve(ctx, tx, workspace_id, id)
+}
+
+// unpaidStatus is the status an invoice goes back to once nothing is paid
+// anymore, i.e. the one it had before the first payment.
+func (is *Invoices) unpaidStatus(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice) (string, error) {
+	if invoice.DueDate != "" && invoice.DueDate < time.Now().Format(time.DateOnly) {
+		return STATUS_OVERDUE, nil
+	}
+
+	var sent bool
+	if err := tx.Stmt(is.reached_stmt).QueryRowContext(ctx, invoice.Id, STATUS_SENT).Scan(&sent); err != nil {
+		return "", err
+	}
+	if sent {
+		return STATUS_SENT, nil
+	}
+	return STATUS_ISSUED, nil
+}
+
+// Settle stores what has been paid of the invoice within tx and settles it.
+// The invoice is updated in place.
+func (is *Invoices) Settle(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, paid decimal.Decimal, note string, actor *audit.Actor) error {
+	_, err := tx.Stmt(is.paid_stmt).ExecContext(ctx, paid.String(), invoice.Id, invoice.WorkspaceId)
+	if err != nil {
+		return err
+	}
+	invoice.Paid = formatAmount(paid, invoice.Currency)
+
+	return is.settle(ctx, tx, invoice, note, actor)
+}
+
+// settle moves the invoice to the status its paid and credited amounts call
+// for: void once it is credited in full, paid once nothing is left to pay,
+// partially paid or back to unpaid.
+func (is *Invoices) settle(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, note string, actor *audit.Actor) error {
+	if err := computeBalance(invoice); err != nil {
+		return err
+	}
+
+	paid := decimal.RequireFromString(invoice.Paid)
+	balance := decimal.RequireFromString(invoice.Balance)
+
+	var status string
+	switch {
+	case !balance.IsPositive() && paid.IsZero():
+		status = STATUS_VOID
+	case !balance.IsPositive():
+		status = STATUS_PAID
+	case paid.IsPositive():
+		status = STATUS_PARTIALLY_PAID
+	case invoice.Status == STATUS_PAID || invoice.Status == STATUS_PARTIALLY_PAID:
+		var err error
+		if status, err = is.unpaidStatus(ctx, tx, invoice); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	if status == invoice.Status {
+		return nil
+	}
+	return is.transition(ctx, tx, invoice, status, note, actor)
+}
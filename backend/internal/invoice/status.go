@@ -0,0 +1,283 @@
+package invoice
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	STATUS_DRAFT          = "draft"
+	STATUS_ISSUED         = "issued"
+	STATUS_SENT           = "sent"
+	STATUS_PARTIALLY_PAID = "partially_paid"
+	STATUS_PAID           = "paid"
+	STATUS_OVERDUE        = "overdue"
+	STATUS_VOID           = "void"
+	STATUS_ACCEPTED       = "accepted"
+	STATUS_DECLINED       = "declined"
+	STATUS_EXPIRED        = "expired"
+)
+
+var Statuses = []string{
+	STATUS_DRAFT,
+	STATUS_ISSUED,
+	STATUS_SENT,
+	STATUS_PARTIALLY_PAID,
+	STATUS_PAID,
+	STATUS_OVERDUE,
+	STATUS_VOID,
+	STATUS_ACCEPTED,
+	STATUS_DECLINED,
+	STATUS_EXPIRED,
+}
+
+// transitions lists the statuses an invoice can move to from each status.
+// Void invoices are final. Paid invoices only move back when a payment is
+// reversed, and are voided once credited in full, which refunds what was
+// paid as credit of the client.
+var transitions = map[string][]string{
+	STATUS_DRAFT:          {STATUS_ISSUED},
+	STATUS_ISSUED:         {STATUS_SENT, STATUS_PARTIALLY_PAID, STATUS_PAID, STATUS_OVERDUE, STATUS_VOID},
+	STATUS_SENT:           {STATUS_PARTIALLY_PAID, STATUS_PAID, STATUS_OVERDUE, STATUS_VOID},
+	STATUS_OVERDUE:        {STATUS_PARTIALLY_PAID, STATUS_PAID, STATUS_VOID},
+	STATUS_PARTIALLY_PAID: {STATUS_PAID, STATUS_OVERDUE, STATUS_ISSUED, STATUS_SENT, STATUS_VOID},
+	STATUS_PAID:           {STATUS_PARTIALLY_PAID, STATUS_ISSUED, STATUS_SENT, STATUS_OVERDUE, STATUS_VOID},
+}
+
+// quoteTransitions lists the statuses a quote can move to from each status.
+// Quotes expire once their validity date has passed, and accepted,
+// declined and expired quotes are final.
+var quoteTransitions = map[string][]string{
+	STATUS_DRAFT:  {STATUS_ISSUED},
+	STATUS_ISSUED: {STATUS_SENT, STATUS_ACCEPTED, STATUS_DECLINED, STATUS_EXPIRED},
+	STATUS_SENT:   {STATUS_ACCEPTED, STATUS_DECLINED, STATUS_EXPIRED},
+}
+
+// payable are the statuses of invoices payments can be allocated to.
+var payable = []string{STATUS_ISSUED, STATUS_SENT, STATUS_OVERDUE, STATUS_PARTIALLY_PAID}
+
+var ErrInvalidTransition = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice can't move to that status")
+
+const (
+	AUDIT_TRANSITION = "invoice.transition"
+
+	MAX_EVENT_NOTE_BYTES = 1000
+)
+
+// Payable tells whether payments can be allocated to invoices in the status.
+func Payable(status string) bool {
+	return slices.Contains(payable, status)
+}
+
+// CanTransition tells whether a document of the kind in status from may
+// move to to.
+func CanTransition(kind string, from string, to string) bool {
+	if kind == KIND_QUOTE {
+		return slices.Contains(quoteTransitions[from], to)
+	}
+	return slices.Contains(transitions[from], to)
+}
+
+// transition moves the invoice to another status within tx, emitting an
+// event and an audit log entry. The invoice is updated in place.
+func (is *Invoices) transition(ctx context.Context, tx *sql.Tx, invoice *pb.Invoice, to string, note string, actor *audit.Actor) error {
+	from := invoice.Status
+	if !CanTransition(invoice.Kind, from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	if len(note) > MAX_EVENT_NOTE_BYTES {
+		return apperr.Invalid("Note is too long", apperr.Field("note", fmt.Sprintf("must be at most %d bytes", MAX_EVENT_NOTE_BYTES)))
+	}
+
+	now := time.Now().Unix()
+	_, err := tx.Stmt(is.status_stmt).ExecContext(ctx, to, now, helpers.NullableId(actor.UserId), invoice.Id, invoice.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Stmt(is.insert_event_stmt).ExecContext(ctx, invoice.Id, from, to, note, helpers.NullableId(actor.UserId), now)
+	if err != nil {
+		return err
+	}
+
+	changes := audit.Diff(map[string]string{"status": from}, map[string]string{"status": to, "note": note})
+	if err = is.audit.Record(tx, actor.Entry(invoice.WorkspaceId, AUDIT_TRANSITION, AUDIT_TARGET, invoice.Id, changes)); err != nil {
+		return err
+	}
+
+	invoice.Status = to
+	invoice.UpdatedAt = now
+	invoice.UpdatedBy = actor.UserId
+	return nil
+}
+
+// Transition moves an issued invoice to sent or void, or an issued quote to
+// sent, accepted or declined. Drafts can only be issued with Issue, which
+// freezes them, and payments move invoices with Settle. Voiding an invoice
+// issues its cancellation, which credits it in full; credit notes and
+// cancellations can't be voided themselves.
+func (is *Invoices) Transition(ctx context.Context, workspace_id uint32, id uint32, to string, note string, actor *audit.Actor) (_ *pb.Invoice, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Transition")
+	defer end(&err)
+
+	if !slices.Contains([]string{STATUS_SENT, STATUS_VOID, STATUS_ACCEPTED, STATUS_DECLINED}, to) {
+		return nil, fmt.Errorf("%w: %s is set by issuing or paying the invoice", ErrInvalidTransition, to)
+	}
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if to != STATUS_VOID {
+		// Paid invoices move back to sent only when payments are reversed.
+		if to == STATUS_SENT && invoice.Status != STATUS_ISSUED {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, invoice.Status, to)
+		}
+		if err = is.transition(ctx, tx, invoice, to, note, actor); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+		return invoice, nil
+	}
+
+	if invoice.Kind != KIND_INVOICE || !CanTransition(invoice.Kind, invoice.Status, STATUS_VOID) {
+		return nil, fmt.Errorf("%w: %s %s to %s", ErrInvalidTransition, strings.ReplaceAll(invoice.Kind, "_", " "), invoice.Status, to)
+	}
+	if decimal.RequireFromString(invoice.Credited).IsPositive() {
+		return nil, fmt.Errorf("%w: %s of %s %s", ErrPartiallyCredited, invoice.Credited, invoice.Totals.Gross, invoice.Currency)
+	}
+	if len(note) > MAX_EVENT_NOTE_BYTES {
+		return nil, apperr.Invalid("Note is too long", apperr.Field("note", fmt.Sprintf("must be at most %d bytes", MAX_EVENT_NOTE_BYTES)))
+	}
+
+	// Settling the credited invoice voids it.
+	cancellation, html_path, err := is.credit(ctx, tx, invoice, KIND_CANCELLATION, nil, note, note, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		os.Remove(html_path)
+		return nil, err
+	}
+
+	is.afterIssue(ctx, cancellation, html_path, actor)
+	return invoice, nil
+}
+
+// Events returns the status changes of the invoice, oldest first.
+func (is *Invoices) Events(ctx context.Context, workspace_id uint32, id uint32) (_ []*pb.InvoiceEvent, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.Events")
+	defer end(&err)
+
+	rows, err := is.events_stmt.QueryContext(ctx, id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*pb.InvoiceEvent{}
+	for rows.Next() {
+		event := &pb.InvoiceEvent{}
+		err = rows.Scan(
+			&event.Id,
+			&event.InvoiceId,
+			&event.FromStatus,
+			&event.ToStatus,
+			&event.Note,
+			&event.ActorUserId,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		if _, err = is.Retrieve(ctx, workspace_id, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// MarkOverdue moves the issued, sent and partially paid invoices of all
+// workspaces that were due before today to overdue, returning how many it
+// moved.
+func (is *Invoices) MarkOverdue(ctx context.Context, today time.Time) (_ int, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.MarkOverdue")
+	defer end(&err)
+
+	return is.moveDue(ctx, KIND_INVOICE, today, STATUS_OVERDUE)
+}
+
+// ExpireQuotes moves the issued and sent quotes of all workspaces that were
+// valid until before today to expired, returning how many it moved.
+func (is *Invoices) ExpireQuotes(ctx context.Context, today time.Time) (_ int, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Invoices.ExpireQuotes")
+	defer end(&err)
+
+	return is.moveDue(ctx, KIND_QUOTE, today, STATUS_EXPIRED)
+}
+
+// moveDue moves the issued, sent and partially paid documents of the kind
+// that were due before today to the status. Only invoices are ever paid.
+func (is *Invoices) moveDue(ctx context.Context, kind string, today time.Time, to string) (int, error) {
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Stmt(is.due_stmt).QueryContext(ctx, kind, today.Format(time.DateOnly), STATUS_ISSUED, STATUS_SENT, STATUS_PARTIALLY_PAID)
+	if err != nil {
+		return 0, err
+	}
+
+	due := []*pb.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, invoice)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range due {
+		if err = is.transition(ctx, tx, invoice, to, "", audit.SystemActor); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(due), tx.Commit()
+}
@@ -0,0 +1,194 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/audit"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	// The statuses documents of each kind can move to, from each status.
+	tests := map[string]map[string]string{
+		KIND_INVOICE: {
+			STATUS_DRAFT:          "issued",
+			STATUS_ISSUED:         "sent partially_paid paid overdue void",
+			STATUS_SENT:           "partially_paid paid overdue void",
+			STATUS_OVERDUE:        "partially_paid paid void",
+			STATUS_PARTIALLY_PAID: "issued sent paid overdue void",
+			STATUS_PAID:           "issued sent partially_paid overdue void",
+		},
+		KIND_QUOTE: {
+			STATUS_DRAFT:  "issued",
+			STATUS_ISSUED: "sent accepted declined expired",
+			STATUS_SENT:   "accepted declined expired",
+		},
+	}
+
+	for kind, allowed := range tests {
+		for _, from := range Statuses {
+			for _, to := range Statuses {
+				want := slices.Contains(strings.Fields(allowed[from]), to)
+				if got := CanTransition(kind, from, to); got != want {
+					t.Errorf("CanTransition(%s, %s, %s) = %t, want %t", kind, from, to, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestPayable(t *testing.T) {
+	payable := "issued sent overdue partially_paid"
+	for _, status := range Statuses {
+		want := slices.Contains(strings.Fields(payable), status)
+		if got := Payable(status); got != want {
+			t.Errorf("Payable(%s) = %t, want %t", status, got, want)
+		}
+	}
+}
+
+func TestTransition(t *testing.T) {
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		kind   string
+		paid   string
+		to     string
+		want   string
+		errors error
+	}{
+		{"send an invoice", KIND_INVOICE, "", STATUS_SENT, STATUS_SENT, nil},
+		{"pay by hand", KIND_INVOICE, "", STATUS_PAID, "", ErrInvalidTransition},
+		{"send a paid invoice", KIND_INVOICE, "119", STATUS_SENT, "", ErrInvalidTransition},
+		{"send a partially paid invoice", KIND_INVOICE, "50", STATUS_SENT, "", ErrInvalidTransition},
+		{"accept an invoice", KIND_INVOICE, "", STATUS_ACCEPTED, "", ErrInvalidTransition},
+		{"accept a quote", KIND_QUOTE, "", STATUS_ACCEPTED, STATUS_ACCEPTED, nil},
+		{"expire a quote by hand", KIND_QUOTE, "", STATUS_EXPIRED, "", ErrInvalidTransition},
+		{"void a quote", KIND_QUOTE, "", STATUS_VOID, "", ErrInvalidTransition},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			invoice := ti.issue(t, test.kind, [2]string{"1", "100"})
+			if test.paid != "" {
+				ti.pay(t, invoice.Id, test.paid)
+			}
+
+			got, err := ti.Transition(ctx, 1, invoice.Id, test.to, "", audit.CliActor)
+			if !errors.Is(err, test.errors) {
+				t.Fatalf("Transition() error = %v, want %v", err, test.errors)
+			}
+			if err == nil && got.Status != test.want {
+				t.Errorf("Transition() status = %s, want %s", got.Status, test.want)
+			}
+		})
+	}
+}
+
+func TestTransitionVoid(t *testing.T) {
+	ti := newTestInvoices(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// Paid of the invoice before it is voided, and what that refunds.
+		paid     string
+		refunded string
+	}{
+		{"unpaid", "", "0"},
+		{"partially paid", "50", "50"},
+		{"paid", "238", "238"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			invoice := ti.issue(t, KIND_INVOICE, [2]string{"2", "100"})
+			if test.paid != "" {
+				ti.pay(t, invoice.Id, test.paid)
+			}
+			refunded := ti.refunds.released
+
+			voided, err := ti.Transition(ctx, 1, invoice.Id, STATUS_VOID, "Wrong client", audit.CliActor)
+			if err != nil {
+				t.Fatalf("Transition() error = %v", err)
+			}
+			if voided.Status != STATUS_VOID || voided.Balance != "0.00" {
+				t.Errorf("voided invoice is %s with %s left", voided.Status, voided.Balance)
+			}
+			if got := ti.refunds.released.Sub(refunded).String(); got != test.refunded {
+				t.Errorf("refunded %s, want %s", got, test.refunded)
+			}
+
+			derived, err := ti.derived(ctx, 1, invoice.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(derived) != 1 {
+				t.Fatalf("voided invoice has %d documents, want its cancellation", len(derived))
+			}
+			cancellation := ti.retrieve(t, derived[0].Id)
+			if cancellation.Kind != KIND_CANCELLATION || cancellation.Status != STATUS_ISSUED || cancellation.Number == "" {
+				t.Errorf("cancellation is a %s %s numbered %q", cancellation.Status, cancellation.Kind, cancellation.Number)
+			}
+			if cancellation.Totals.Gross != "-238.00" || cancellation.Notes != "Wrong client" {
+				t.Errorf("cancellation of %s with notes %q, want -238.00 with the reason", cancellation.Totals.Gross, cancellation.Notes)
+			}
+
+			if _, err = ti.Transition(ctx, 1, invoice.Id, STATUS_VOID, "", audit.CliActor); !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("Transition() of a void invoice error = %v, want %v", err, ErrInvalidTransition)
+			}
+			if _, err = ti.Transition(ctx, 1, cancellation.Id, STATUS_VOID, "", audit.CliActor); !errors.Is(err, ErrInvalidTransition) {
+				t.Errorf("Transition() of a cancellation error = %v, want %v", err, ErrInvalidTransition)
+			}
+		})
+	}
+}
+
+func TestTransitionVoidCredited(t *testing.T) {
+	ti := newTestInvoices(t)
+
+	invoice := ti.issue(t, KIND_INVOICE, [2]string{"2", "100"})
+	if _, err := ti.creditNote(t, invoice.Id, &pb.CreditNoteLine{LineItemId: invoice.Items[0].Id, Quantity: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ti.Transition(context.Background(), 1, invoice.Id, STATUS_VOID, "", audit.CliActor); !errors.Is(err, ErrPartiallyCredited) {
+		t.Errorf("Transition() of a credited invoice error = %v, want %v", err, ErrPartiallyCredited)
+	}
+}
+
+func TestMarkOverdue(t *testing.T) {
+	ti := newTestInvoices(t)
+
+	issued := ti.issue(t, KIND_INVOICE, [2]string{"1", "100"})
+	partially_paid := ti.issue(t, KIND_INVOICE, [2]string{"1", "100"})
+	ti.pay(t, partially_paid.Id, "50")
+	paid := ti.issue(t, KIND_INVOICE, [2]string{"1", "100"})
+	ti.pay(t, paid.Id, "119")
+	quote := ti.issue(t, KIND_QUOTE, [2]string{"1", "100"})
+
+	marked, err := ti.MarkOverdue(context.Background(), time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marked != 2 {
+		t.Errorf("MarkOverdue() marked %d invoices, want 2", marked)
+	}
+
+	want := map[uint32]string{
+		issued.Id:         STATUS_OVERDUE,
+		partially_paid.Id: STATUS_OVERDUE,
+		paid.Id:           STATUS_PAID,
+		quote.Id:          STATUS_ISSUED,
+	}
+	for id, status := range want {
+		if got := ti.retrieve(t, id); got.Status != status {
+			t.Errorf("%s %d is %s, want %s", got.Kind, id, got.Status, status)
+		}
+	}
+}
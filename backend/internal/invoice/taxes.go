@@ -0,0 +1,144 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// reverseCharged tells whether the client owes the VAT of any of the lines.
+func reverseCharged(items []*pb.LineItem) bool {
+	return slices.ContainsFunc(items, func(item *pb.LineItem) bool {
+		return item.TaxCategory == tax.CATEGORY_REVERSE_CHARGE
+	})
+}
+
+// categoryRate looks up the rate of the category of the line in force in
+// the country of the seller, when the line has a category without a rate,
+// e.g. from a catalog item. The category needs to have a single rate
+// there, otherwise the problem is returned.
+func (is *Invoices) categoryRate(ctx context.Context, workspace_id uint32, country string, item *pb.LineItem, date string) (decimal.Decimal, string, error) {
+	rates, err := is.taxes.List(ctx, workspace_id, country, date)
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	rates = slices.DeleteFunc(rates, func(rate *pb.TaxRate) bool {
+		return rate.Category != item.TaxCategory
+	})
+
+	switch len(rates) {
+	case 0:
+		return decimal.Zero, fmt.Sprintf("is required, no %s rate is in force on %s", item.TaxCategory, date), nil
+	case 1:
+		item.TaxRateId = rates[0].Id
+		item.TaxRate = rates[0].Rate
+		rate, err := decimal.NewFromString(rates[0].Rate)
+		return rate, "", err
+	}
+	return decimal.Zero, fmt.Sprintf("is required, %d %s rates are in force on %s", len(rates), item.TaxCategory, date), nil
+}
+
+// resolveTaxes sets the rate, category and exemption reason of the lines of
+// a validated request. Lines referencing a tax rate take it as it is in
+// force on the service date, or the issue date without one. If issuer and
+// client are businesses in different EU member states, all lines that
+// aren't exempt are reverse charged.
+func (is *Invoices) resolveTaxes(ctx context.Context, workspace_id uint32, iss *pb.Issuer, c *pb.Client, req *pb.SaveInvoiceRequest) error {
+	date := req.ServiceDate
+	if date == "" {
+		date = req.IssueDate
+	}
+
+	seller, err := is.seller(ctx, workspace_id, iss)
+	if err != nil {
+		return err
+	}
+	buyer := tax.Party{Country: c.BillingAddress.GetCountry(), VatId: c.TaxId}
+	reverse_charge := tax.ReverseCharge(seller, buyer)
+
+	fields := []apperr.FieldError{}
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		item.TaxCategory = strings.TrimSpace(item.TaxCategory)
+		item.ExemptionReason = strings.TrimSpace(item.ExemptionReason)
+
+		if item.TaxRateId != 0 {
+			rate, err := is.taxes.Resolve(ctx, workspace_id, item.TaxRateId, date)
+			if err == tax.ErrIDNotFound {
+				fields = append(fields, apperr.Field(field+".taxRateId", "no such tax rate in this workspace"))
+				continue
+			}
+			if errors.Is(err, tax.ErrNotInForce) {
+				fields = append(fields, apperr.Field(field+".taxRateId", "is not in force on "+date))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			item.TaxRate = rate.Rate
+			item.TaxCategory = rate.Category
+			if item.ExemptionReason == "" {
+				item.ExemptionReason = rate.ExemptionReason
+			}
+		}
+
+		rate, err := parseDecimal(item.TaxRate)
+		if err != nil {
+			// Validate has reported it already.
+			continue
+		}
+		if item.TaxRateId == 0 && tax.Rated(item.TaxCategory) && rate.IsZero() && !reverse_charge {
+			var problem string
+			rate, problem, err = is.categoryRate(ctx, workspace_id, seller.Country, item, date)
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				fields = append(fields, apperr.Field(field+".taxRateId", problem))
+				continue
+			}
+		}
+		item.TaxCategory = lineCategory(item, rate)
+
+		if reverse_charge && item.TaxCategory != tax.CATEGORY_EXEMPT {
+			item.TaxCategory = tax.CATEGORY_REVERSE_CHARGE
+			item.TaxRate = "0"
+			rate = decimal.Zero
+			if item.ExemptionReason == "" {
+				item.ExemptionReason = tax.REVERSE_CHARGE_REASON
+			}
+		}
+
+		switch {
+		case !slices.Contains(tax.Categories, item.TaxCategory):
+			fields = append(fields, apperr.Field(field+".taxCategory", "must be one of "+strings.Join(tax.Categories, ", ")))
+		case tax.Rated(item.TaxCategory) && rate.IsZero():
+			fields = append(fields, apperr.Field(field+".taxRate", "must be greater than zero for "+item.TaxCategory+" lines"))
+		case !tax.Rated(item.TaxCategory) && !rate.IsZero():
+			fields = append(fields, apperr.Field(field+".taxRate", "must be 0 for "+strings.ReplaceAll(item.TaxCategory, "_", " ")+" lines"))
+		case item.TaxCategory == tax.CATEGORY_EXEMPT && item.ExemptionReason == "":
+			fields = append(fields, apperr.Field(field+".exemptionReason", "is required for exempt lines"))
+		case len(item.ExemptionReason) > tax.MAX_REASON_LENGTH:
+			fields = append(fields, apperr.Field(field+".exemptionReason", fmt.Sprintf("must not be longer than %d bytes", tax.MAX_REASON_LENGTH)))
+		}
+
+		if item.TaxCategory == tax.CATEGORY_REVERSE_CHARGE && item.ExemptionReason == "" {
+			item.ExemptionReason = tax.REVERSE_CHARGE_REASON
+		}
+		if tax.Rated(item.TaxCategory) {
+			item.ExemptionReason = ""
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Invoice is invalid", fields...)
+	}
+	return nil
+}
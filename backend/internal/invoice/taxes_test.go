@@ -0,0 +1,258 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const EXEMPT_REASON = "Article 135(1)(g) of Council Directive 2006/112/EC"
+
+// newTaxedInvoices returns invoices of a German workspace with tax rates
+// and their IDs by name. The 5% reduced rate only applied in the second
+// half of 2020, next to the 7% one.
+func newTaxedInvoices(t *testing.T) (*Invoices, map[string]uint32) {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec("UPDATE workspaces SET workspace_country = 'DE', workspace_vat_id = 'DE123456789' WHERE workspace_id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxes, err := tax.NewTaxRates(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { taxes.Close() })
+
+	ids := map[string]uint32{}
+	for _, req := range []*pb.SaveTaxRateRequest{
+		{Name: "standard", Country: "DE", Category: tax.CATEGORY_STANDARD, Rate: "19", ValidFrom: "2007-01-01"},
+		{Name: "reduced", Country: "DE", Category: tax.CATEGORY_REDUCED, Rate: "7", ValidFrom: "2007-01-01"},
+		{Name: "reduced 2020", Country: "DE", Category: tax.CATEGORY_REDUCED, Rate: "5", ValidFrom: "2020-07-01", ValidTo: "2020-12-31"},
+		{Name: "exempt", Country: "DE", Category: tax.CATEGORY_EXEMPT, ValidFrom: "2007-01-01", ExemptionReason: EXEMPT_REASON},
+		{Name: "Austrian standard", Country: "AT", Category: tax.CATEGORY_STANDARD, Rate: "20", ValidFrom: "2016-01-01"},
+	} {
+		rate, err := taxes.Create(context.Background(), 1, req, audit.CliActor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[req.Name] = rate.Id
+	}
+
+	return &Invoices{taxes: taxes}, ids
+}
+
+func TestReverseCharged(t *testing.T) {
+	tests := []struct {
+		categories []string
+		want       bool
+	}{
+		{[]string{}, false},
+		{[]string{tax.CATEGORY_STANDARD, tax.CATEGORY_EXEMPT}, false},
+		{[]string{tax.CATEGORY_EXEMPT, tax.CATEGORY_REVERSE_CHARGE}, true},
+	}
+
+	for _, test := range tests {
+		items := []*pb.LineItem{}
+		for _, category := range test.categories {
+			items = append(items, &pb.LineItem{TaxCategory: category})
+		}
+		if got := reverseCharged(items); got != test.want {
+			t.Errorf("reverseCharged(%v) = %t, want %t", test.categories, got, test.want)
+		}
+	}
+}
+
+func TestResolveTaxes(t *testing.T) {
+	is, ids := newTaxedInvoices(t)
+
+	consumer := &pb.Client{BillingAddress: &pb.Address{Country: "DE"}}
+	austrian_business := &pb.Client{BillingAddress: &pb.Address{Country: "AT"}, TaxId: "ATU12345678"}
+	austrian_consumer := &pb.Client{BillingAddress: &pb.Address{Country: "AT"}}
+	swiss_business := &pb.Client{BillingAddress: &pb.Address{Country: "CH"}, TaxId: "CHE-123.456.789"}
+	austrian_issuer := &pb.Issuer{Address: &pb.Address{Country: "AT"}, VatId: "ATU87654321"}
+
+	type line struct {
+		category string
+		rate     string
+		reason   string
+	}
+
+	tests := []struct {
+		name   string
+		issuer *pb.Issuer
+		client *pb.Client
+		date   string
+		items  []*pb.LineItem
+		// What the lines are resolved to, or the fields reported as
+		// invalid.
+		want   []line
+		fields []string
+	}{
+		{
+			name:   "categories by rate",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRate: "19"}, {TaxRate: "0"}},
+			want:   []line{{tax.CATEGORY_STANDARD, "19", ""}, {tax.CATEGORY_ZERO_RATED, "0", ""}},
+		},
+		{
+			name:   "referenced rates",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRateId: ids["reduced"]}, {TaxRateId: ids["exempt"]}},
+			want:   []line{{tax.CATEGORY_REDUCED, "7", ""}, {tax.CATEGORY_EXEMPT, "0", EXEMPT_REASON}},
+		},
+		{
+			name:   "rate of the category in the country of the seller",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxCategory: tax.CATEGORY_STANDARD}, {TaxCategory: " reduced "}},
+			want:   []line{{tax.CATEGORY_STANDARD, "19", ""}, {tax.CATEGORY_REDUCED, "7", ""}},
+		},
+		{
+			name:   "rate of the category in the country of the issuer",
+			issuer: austrian_issuer,
+			client: austrian_consumer,
+			items:  []*pb.LineItem{{TaxCategory: tax.CATEGORY_STANDARD}},
+			want:   []line{{tax.CATEGORY_STANDARD, "20", ""}},
+		},
+		{
+			name:   "rated lines have no exemption reason",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRate: "19", ExemptionReason: "Small business"}},
+			want:   []line{{tax.CATEGORY_STANDARD, "19", ""}},
+		},
+		{
+			name:   "reverse charge between businesses in the EU",
+			client: austrian_business,
+			items: []*pb.LineItem{
+				{TaxRateId: ids["standard"]},
+				{TaxCategory: tax.CATEGORY_REDUCED},
+				{TaxRateId: ids["exempt"]},
+			},
+			want: []line{
+				{tax.CATEGORY_REVERSE_CHARGE, "0", tax.REVERSE_CHARGE_REASON},
+				{tax.CATEGORY_REVERSE_CHARGE, "0", tax.REVERSE_CHARGE_REASON},
+				{tax.CATEGORY_EXEMPT, "0", EXEMPT_REASON},
+			},
+		},
+		{
+			name:   "reverse charge from an issuer abroad",
+			issuer: austrian_issuer,
+			client: &pb.Client{BillingAddress: &pb.Address{Country: "DE"}, TaxId: "DE999999999"},
+			items:  []*pb.LineItem{{TaxRate: "20"}},
+			want:   []line{{tax.CATEGORY_REVERSE_CHARGE, "0", tax.REVERSE_CHARGE_REASON}},
+		},
+		{
+			name:   "no reverse charge for consumers",
+			client: austrian_consumer,
+			items:  []*pb.LineItem{{TaxRate: "19"}},
+			want:   []line{{tax.CATEGORY_STANDARD, "19", ""}},
+		},
+		{
+			name:   "no reverse charge outside the EU",
+			client: swiss_business,
+			items:  []*pb.LineItem{{TaxRate: "0", TaxCategory: tax.CATEGORY_ZERO_RATED}},
+			want:   []line{{tax.CATEGORY_ZERO_RATED, "0", ""}},
+		},
+		{
+			name:   "reverse charge by hand keeps its reason",
+			client: swiss_business,
+			items:  []*pb.LineItem{{TaxRate: "0", TaxCategory: tax.CATEGORY_REVERSE_CHARGE}, {TaxRate: "0", TaxCategory: tax.CATEGORY_REVERSE_CHARGE, ExemptionReason: "Section 13b UStG"}},
+			want:   []line{{tax.CATEGORY_REVERSE_CHARGE, "0", tax.REVERSE_CHARGE_REASON}, {tax.CATEGORY_REVERSE_CHARGE, "0", "Section 13b UStG"}},
+		},
+		{
+			name:   "unknown rate",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRateId: 999}},
+			fields: []string{"items[0].taxRateId"},
+		},
+		{
+			name:   "rate not in force",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRateId: ids["reduced 2020"]}},
+			fields: []string{"items[0].taxRateId"},
+		},
+		{
+			name:   "two rates of the category in force",
+			client: consumer,
+			date:   "2020-08-01",
+			items:  []*pb.LineItem{{TaxCategory: tax.CATEGORY_REDUCED}},
+			fields: []string{"items[0].taxRateId"},
+		},
+		{
+			name:   "no rate of the category in force",
+			issuer: austrian_issuer,
+			client: austrian_consumer,
+			items:  []*pb.LineItem{{TaxCategory: tax.CATEGORY_REDUCED}},
+			fields: []string{"items[0].taxRateId"},
+		},
+		{
+			name:   "unknown category",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRate: "19", TaxCategory: "luxury"}},
+			fields: []string{"items[0].taxCategory"},
+		},
+		{
+			name:   "zero rated line with a rate",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRate: "7", TaxCategory: tax.CATEGORY_ZERO_RATED}},
+			fields: []string{"items[0].taxRate"},
+		},
+		{
+			name:   "exempt line without a reason",
+			client: consumer,
+			items:  []*pb.LineItem{{TaxRate: "19"}, {TaxCategory: tax.CATEGORY_EXEMPT}},
+			fields: []string{"items[1].exemptionReason"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := &pb.SaveInvoiceRequest{IssueDate: "2026-03-07", ServiceDate: test.date, Items: test.items}
+			err := is.resolveTaxes(context.Background(), 1, test.issuer, test.client, req)
+
+			if len(test.fields) > 0 {
+				var app_err *apperr.Error
+				if !errors.As(err, &app_err) {
+					t.Fatalf("resolveTaxes() error = %v, want a field error", err)
+				}
+				fields := []string{}
+				for _, field := range app_err.Fields {
+					fields = append(fields, field.Field)
+				}
+				if !slices.Equal(fields, test.fields) {
+					t.Errorf("invalid fields = %v, want %v", fields, test.fields)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveTaxes() error = %v", err)
+			}
+			for i, item := range req.Items {
+				got := line{item.TaxCategory, item.TaxRate, item.ExemptionReason}
+				if got != test.want[i] {
+					t.Errorf("line %d = %+v, want %+v", i+1, got, test.want[i])
+				}
+			}
+		})
+	}
+}
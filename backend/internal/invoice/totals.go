@@ -0,0 +1,184 @@
+package invoice
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	hundred = decimal.NewFromInt(100)
+
+	ErrDiscountTooLarge = apperr.Invalid("Discounts are larger than the subtotal", apperr.Field("discounts", "must not exceed the sum of the line items"))
+)
+
+func parseDecimal(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
+
+// formatAmount formats the amount with the minor units of the currency,
+// which amounts are rounded to half away from zero.
+func formatAmount(amount decimal.Decimal, currency string) string {
+	return money.String(amount, currency)
+}
+
+// discountOf returns what the discount takes off amount.
+func discountOf(discount *pb.Discount, amount decimal.Decimal) (decimal.Decimal, error) {
+	if discount == nil {
+		return decimal.Zero, nil
+	}
+
+	if discount.Percent != "" {
+		percent, err := parseDecimal(discount.Percent)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return amount.Mul(percent).Div(hundred), nil
+	}
+
+	return parseDecimal(discount.Amount)
+}
+
+// lineNet is quantity times unit price, less the discount of the line.
+func lineNet(item *pb.LineItem, currency string) (decimal.Decimal, error) {
+	quantity, err := parseDecimal(item.Quantity)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	unit_price, err := parseDecimal(item.UnitPrice)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	amount := quantity.Mul(unit_price)
+	discount, err := discountOf(item.Discount, amount)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return money.Round(amount.Sub(discount), currency), nil
+}
+
+type taxGroup struct {
+	category string
+	rate     decimal.Decimal
+	reason   string
+	net      decimal.Decimal
+}
+
+// lineCategory is the tax category of the line, lines saved without one
+// are standard or zero rated by their rate.
+func lineCategory(item *pb.LineItem, rate decimal.Decimal) string {
+	if item.TaxCategory != "" {
+		return item.TaxCategory
+	}
+	if rate.IsZero() {
+		return tax.CATEGORY_ZERO_RATED
+	}
+	return tax.CATEGORY_STANDARD
+}
+
+// Compute sets the net amount of every line item and returns the totals of
+// the invoice. Each line is rounded on its own, and tax is computed once
+// per category, rate and exemption reason on the sum of its lines, all in
+// the minor units of the currency. If prices include tax, the tax of each
+// group is taken out of its amount instead of added to it.
+func Compute(items []*pb.LineItem, discounts []*pb.Discount, currency string, prices_include_tax bool) (*pb.Totals, error) {
+	subtotal := decimal.Zero
+	groups := map[string]*taxGroup{}
+
+	for i, item := range items {
+		net, err := lineNet(item, currency)
+		if err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		item.Net = formatAmount(net, currency)
+		subtotal = subtotal.Add(net)
+
+		rate, err := parseDecimal(item.TaxRate)
+		if err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		category := lineCategory(item, rate)
+		key := category + "\x00" + rate.String() + "\x00" + item.ExemptionReason
+		if groups[key] == nil {
+			groups[key] = &taxGroup{category: category, rate: rate, reason: item.ExemptionReason, net: decimal.Zero}
+		}
+		groups[key].net = groups[key].net.Add(net)
+	}
+
+	discount := decimal.Zero
+	for _, d := range discounts {
+		amount, err := discountOf(d, subtotal)
+		if err != nil {
+			return nil, err
+		}
+		discount = discount.Add(amount)
+	}
+	discount = money.Round(discount, currency)
+	if discount.GreaterThan(subtotal) && discount.IsPositive() {
+		return nil, ErrDiscountTooLarge
+	}
+
+	sorted := []*taxGroup{}
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].rate.Equal(sorted[j].rate) {
+			return sorted[i].rate.LessThan(sorted[j].rate)
+		}
+		if sorted[i].category != sorted[j].category {
+			return sorted[i].category < sorted[j].category
+		}
+		return sorted[i].reason < sorted[j].reason
+	})
+
+	totals := &pb.Totals{Taxes: []*pb.TaxAmount{}}
+	net := decimal.Zero
+	tax := decimal.Zero
+	allocated := decimal.Zero
+	for i, group := range sorted {
+		// The last group gets what is left of the discount, so that the
+		// shares add up exactly.
+		share := discount.Sub(allocated)
+		if i < len(sorted)-1 && !subtotal.IsZero() {
+			share = money.Round(discount.Mul(group.net).Div(subtotal), currency)
+		}
+		allocated = allocated.Add(share)
+
+		base := group.net.Sub(share)
+		var amount decimal.Decimal
+		if prices_include_tax {
+			amount = money.Round(base.Mul(group.rate).Div(hundred.Add(group.rate)), currency)
+			base = base.Sub(amount)
+		} else {
+			amount = money.Round(base.Mul(group.rate).Div(hundred), currency)
+		}
+		net = net.Add(base)
+		tax = tax.Add(amount)
+
+		totals.Taxes = append(totals.Taxes, &pb.TaxAmount{
+			Rate:            group.rate.String(),
+			Base:            formatAmount(base, currency),
+			Amount:          formatAmount(amount, currency),
+			Category:        group.category,
+			ExemptionReason: group.reason,
+		})
+	}
+
+	totals.Subtotal = formatAmount(subtotal, currency)
+	totals.Discount = formatAmount(discount, currency)
+	totals.Net = formatAmount(net, currency)
+	totals.Tax = formatAmount(tax, currency)
+	totals.Gross = formatAmount(net.Add(tax), currency)
+	return totals, nil
+}
@@ -0,0 +1,194 @@
+package invoice
+
+import (
+	"errors"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name               string
+		items              []*pb.LineItem
+		discounts          []*pb.Discount
+		currency           string
+		prices_include_tax bool
+		// Net of every line, then subtotal, discount, net, tax and gross.
+		lines  []string
+		totals [5]string
+		// Base and amount of every tax group, in order.
+		taxes [][2]string
+	}{
+		{
+			name: "single line",
+			items: []*pb.LineItem{
+				{Quantity: "2", UnitPrice: "50", TaxRate: "19"},
+			},
+			currency: "EUR",
+			lines:    []string{"100.00"},
+			totals:   [5]string{"100.00", "0.00", "100.00", "19.00", "119.00"},
+			taxes:    [][2]string{{"100.00", "19.00"}},
+		},
+		{
+			name: "lines are rounded, tax once per rate",
+			items: []*pb.LineItem{
+				{Quantity: "3", UnitPrice: "0.333", TaxRate: "19"},
+				{Quantity: "3", UnitPrice: "0.333", TaxRate: "19"},
+				{Quantity: "1", UnitPrice: "10.005", TaxRate: "7"},
+			},
+			currency: "EUR",
+			lines:    []string{"1.00", "1.00", "10.01"},
+			totals:   [5]string{"12.01", "0.00", "12.01", "1.08", "13.09"},
+			taxes:    [][2]string{{"10.01", "0.70"}, {"2.00", "0.38"}},
+		},
+		{
+			name: "line discounts",
+			items: []*pb.LineItem{
+				{Quantity: "4", UnitPrice: "25", TaxRate: "19", Discount: &pb.Discount{Percent: "10"}},
+				{Quantity: "1", UnitPrice: "100", TaxRate: "19", Discount: &pb.Discount{Amount: "15.50"}},
+			},
+			currency: "EUR",
+			lines:    []string{"90.00", "84.50"},
+			totals:   [5]string{"174.50", "0.00", "174.50", "33.16", "207.66"},
+			taxes:    [][2]string{{"174.50", "33.16"}},
+		},
+		{
+			name: "invoice discount is shared by the groups",
+			items: []*pb.LineItem{
+				{Quantity: "1", UnitPrice: "100", TaxRate: "19"},
+				{Quantity: "1", UnitPrice: "200", TaxRate: "7"},
+			},
+			discounts: []*pb.Discount{{Percent: "10"}},
+			currency:  "EUR",
+			lines:     []string{"100.00", "200.00"},
+			totals:    [5]string{"300.00", "30.00", "270.00", "29.70", "299.70"},
+			taxes:     [][2]string{{"180.00", "12.60"}, {"90.00", "17.10"}},
+		},
+		{
+			name: "last group gets the rest of the discount",
+			items: []*pb.LineItem{
+				{Quantity: "1", UnitPrice: "10", TaxRate: "7"},
+				{Quantity: "1", UnitPrice: "10", TaxRate: "19"},
+				{Quantity: "1", UnitPrice: "10", TaxRate: "0"},
+			},
+			discounts: []*pb.Discount{{Amount: "1"}},
+			currency:  "EUR",
+			lines:     []string{"10.00", "10.00", "10.00"},
+			totals:    [5]string{"30.00", "1.00", "29.00", "2.52", "31.52"},
+			taxes:     [][2]string{{"9.67", "0.00"}, {"9.67", "0.68"}, {"9.66", "1.84"}},
+		},
+		{
+			name: "prices include tax",
+			items: []*pb.LineItem{
+				{Quantity: "1", UnitPrice: "119", TaxRate: "19"},
+				{Quantity: "2", UnitPrice: "10", TaxRate: "19"},
+			},
+			currency:           "EUR",
+			prices_include_tax: true,
+			lines:              []string{"119.00", "20.00"},
+			totals:             [5]string{"139.00", "0.00", "116.81", "22.19", "139.00"},
+			taxes:              [][2]string{{"116.81", "22.19"}},
+		},
+		{
+			name: "categories at the same rate are kept apart",
+			items: []*pb.LineItem{
+				{Quantity: "1", UnitPrice: "100", TaxRate: "0", TaxCategory: tax.CATEGORY_EXEMPT, ExemptionReason: "Art. 132 VAT Directive"},
+				{Quantity: "1", UnitPrice: "50", TaxRate: "0", TaxCategory: tax.CATEGORY_REVERSE_CHARGE, ExemptionReason: "Reverse charge"},
+				{Quantity: "1", UnitPrice: "25", TaxRate: "0"},
+			},
+			currency: "EUR",
+			lines:    []string{"100.00", "50.00", "25.00"},
+			totals:   [5]string{"175.00", "0.00", "175.00", "0.00", "175.00"},
+			taxes:    [][2]string{{"100.00", "0.00"}, {"50.00", "0.00"}, {"25.00", "0.00"}},
+		},
+		{
+			name: "currencies without minor units",
+			items: []*pb.LineItem{
+				{Quantity: "3", UnitPrice: "333.5", TaxRate: "10"},
+			},
+			currency: "JPY",
+			lines:    []string{"1001"},
+			totals:   [5]string{"1001", "0", "1001", "100", "1101"},
+			taxes:    [][2]string{{"1001", "100"}},
+		},
+		{
+			name:     "no lines",
+			currency: "EUR",
+			lines:    []string{},
+			totals:   [5]string{"0.00", "0.00", "0.00", "0.00", "0.00"},
+			taxes:    [][2]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			totals, err := Compute(test.items, test.discounts, test.currency, test.prices_include_tax)
+			if err != nil {
+				t.Fatalf("Compute() error = %v", err)
+			}
+
+			for i, item := range test.items {
+				if item.Net != test.lines[i] {
+					t.Errorf("net of line %d = %s, want %s", i+1, item.Net, test.lines[i])
+				}
+			}
+
+			got := [5]string{totals.Subtotal, totals.Discount, totals.Net, totals.Tax, totals.Gross}
+			if got != test.totals {
+				t.Errorf("subtotal, discount, net, tax, gross = %v, want %v", got, test.totals)
+			}
+
+			if len(totals.Taxes) != len(test.taxes) {
+				t.Fatalf("got %d tax groups, want %d", len(totals.Taxes), len(test.taxes))
+			}
+			for i, group := range totals.Taxes {
+				if [2]string{group.Base, group.Amount} != test.taxes[i] {
+					t.Errorf("tax group %d (%s %s%%) = %s/%s, want %v", i+1, group.Category, group.Rate, group.Base, group.Amount, test.taxes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []*pb.LineItem
+		discounts []*pb.Discount
+		want      error
+	}{
+		{
+			name:      "discount larger than the subtotal",
+			items:     []*pb.LineItem{{Quantity: "1", UnitPrice: "10", TaxRate: "19"}},
+			discounts: []*pb.Discount{{Amount: "10.01"}},
+			want:      ErrDiscountTooLarge,
+		},
+		{
+			name:      "discounts adding up to more than the subtotal",
+			items:     []*pb.LineItem{{Quantity: "1", UnitPrice: "10", TaxRate: "19"}},
+			discounts: []*pb.Discount{{Percent: "60"}, {Percent: "50"}},
+			want:      ErrDiscountTooLarge,
+		},
+		{
+			name:  "quantity isn't a number",
+			items: []*pb.LineItem{{Quantity: "two", UnitPrice: "10"}},
+		},
+		{
+			name:  "tax rate isn't a number",
+			items: []*pb.LineItem{{Quantity: "1", UnitPrice: "10", TaxRate: "19%"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Compute(test.items, test.discounts, "EUR", false)
+			if err == nil {
+				t.Fatal("Compute() succeeded, want an error")
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("Compute() error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package invoice
+
+import (
+	"context"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/telemetry"
+	"invoice-manager/main/internal/ubl"
+)
+
+var ErrQuoteNotInvoice = apperr.New(apperr.CODE_FAILED_PRECONDITION, "quotes can't be exported as e-invoices")
+
+// Ubl exports an issued invoice, credit note or cancellation as a UBL 2.1
+// document following Peppol BIS Billing 3.0. It is built from the snapshot
+// taken when the document was issued, and checked against the business
+// rules of EN 16931 first, which are returned by ID if it breaks any.
+// Documents the workspace issued itself have no issuer to take the legal
+// name and address of the seller from.
+func (is *Invoices) Ubl(ctx context.Context, workspace_id uint32, id uint32) (_ []byte, err error) {
+	ctx, end := telemetry.Start(ctx, "Invoices.Ubl")
+	defer end(&err)
+
+	invoice, err := is.Retrieve(ctx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Kind == KIND_QUOTE {
+		return nil, ErrQuoteNotInvoice
+	}
+	if invoice.Status == STATUS_DRAFT {
+		return nil, ErrNotIssued
+	}
+
+	seller, err := is.renderedIssuer(ctx, workspace_id, invoice.Snapshot.GetIssuer())
+	if err != nil {
+		return nil, err
+	}
+	buyer := invoice.Snapshot.GetClient()
+	if buyer == nil {
+		if buyer, err = is.clients.Retrieve(ctx, workspace_id, invoice.ClientId); err != nil {
+			return nil, err
+		}
+	}
+
+	credit := invoice.Kind == KIND_CREDIT_NOTE || invoice.Kind == KIND_CANCELLATION
+	doc, err := ubl.Build(invoice, seller, buyer, credit)
+	if err != nil {
+		return nil, err
+	}
+	if err = ubl.Check(doc); err != nil {
+		return nil, err
+	}
+
+	return doc.Marshal()
+}
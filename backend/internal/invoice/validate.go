@@ -0,0 +1,191 @@
+package invoice
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/language"
+)
+
+const (
+	MAX_LINE_ITEMS  = 500
+	MAX_DISCOUNTS   = 10
+	MAX_NOTES_BYTES = 10_000
+
+	MAX_BUYER_REFERENCE_LENGTH = 200
+)
+
+// checkDecimal parses the value of the field and normalizes it, e.g. " 1.50"
+// becomes "1.5". Empty values are zero.
+func checkDecimal(fields *[]apperr.FieldError, field string, value *string, check func(decimal.Decimal) string) decimal.Decimal {
+	*value = strings.TrimSpace(*value)
+	if *value == "" {
+		*value = "0"
+	}
+
+	d, err := decimal.NewFromString(*value)
+	if err != nil {
+		*fields = append(*fields, apperr.Field(field, "must be a decimal number"))
+		return decimal.Zero
+	}
+	*value = d.String()
+
+	if description := check(d); description != "" {
+		*fields = append(*fields, apperr.Field(field, description))
+	}
+	return d
+}
+
+func anyDecimal(decimal.Decimal) string {
+	return ""
+}
+
+func positive(d decimal.Decimal) string {
+	if !d.IsPositive() {
+		return "must be greater than zero"
+	}
+	return ""
+}
+
+func notNegative(d decimal.Decimal) string {
+	if d.IsNegative() {
+		return "must not be negative"
+	}
+	return ""
+}
+
+func percentage(d decimal.Decimal) string {
+	if d.IsNegative() || d.GreaterThan(hundred) {
+		return "must be between 0 and 100"
+	}
+	return ""
+}
+
+func validateDiscount(fields *[]apperr.FieldError, field string, discount *pb.Discount) {
+	if discount == nil {
+		return
+	}
+
+	discount.Description = strings.TrimSpace(discount.Description)
+	if discount.Percent != "" && discount.Amount != "" {
+		*fields = append(*fields, apperr.Field(field, "must have either a percent or an amount"))
+		return
+	}
+
+	if discount.Percent != "" {
+		checkDecimal(fields, field+".percent", &discount.Percent, percentage)
+	} else if discount.Amount != "" {
+		checkDecimal(fields, field+".amount", &discount.Amount, notNegative)
+	}
+}
+
+func validateItems(fields *[]apperr.FieldError, items []*pb.LineItem) {
+	if len(items) > MAX_LINE_ITEMS {
+		*fields = append(*fields, apperr.Field("items", fmt.Sprintf("must not be more than %d", MAX_LINE_ITEMS)))
+		return
+	}
+
+	for i, item := range items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item == nil {
+			*fields = append(*fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+		item.Description = strings.TrimSpace(item.Description)
+		item.Unit = strings.TrimSpace(item.Unit)
+		item.Sku = strings.TrimSpace(item.Sku)
+		item.OriginalLineItemId = 0
+		if item.Description == "" {
+			*fields = append(*fields, apperr.Field(field+".description", "must not be empty"))
+		}
+
+		checkDecimal(fields, field+".quantity", &item.Quantity, positive)
+		checkDecimal(fields, field+".unitPrice", &item.UnitPrice, anyDecimal)
+		checkDecimal(fields, field+".taxRate", &item.TaxRate, percentage)
+		validateDiscount(fields, field+".discount", item.Discount)
+	}
+}
+
+func checkDate(fields *[]apperr.FieldError, field string, value string) time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		*fields = append(*fields, apperr.Field(field, "must be a date formatted as YYYY-MM-DD"))
+	}
+	return date
+}
+
+// Validate checks the request, once the defaults of the client have been
+// applied, and normalizes it in place.
+func Validate(req *pb.SaveInvoiceRequest) error {
+	fields := []apperr.FieldError{}
+
+	if req.ClientId == 0 {
+		fields = append(fields, apperr.Field("clientId", "is required"))
+	}
+
+	if req.Kind == "" {
+		req.Kind = KIND_INVOICE
+	}
+	if req.Kind != KIND_INVOICE && req.Kind != KIND_QUOTE {
+		fields = append(fields, apperr.Field("kind", "must be invoice or quote"))
+	}
+
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !money.Valid(req.Currency) {
+		fields = append(fields, apperr.Field("currency", "must be an ISO 4217 code"))
+	}
+
+	if req.ExchangeRate = strings.TrimSpace(req.ExchangeRate); req.ExchangeRate != "" {
+		checkDecimal(&fields, "exchangeRate", &req.ExchangeRate, positive)
+	}
+
+	if req.Language = strings.TrimSpace(req.Language); req.Language != "" {
+		tag, err := language.Parse(req.Language)
+		if err != nil {
+			fields = append(fields, apperr.Field("language", "must be a BCP 47 language tag"))
+		} else {
+			req.Language = tag.String()
+		}
+	}
+
+	issue_date := checkDate(&fields, "issueDate", req.IssueDate)
+	due_date := checkDate(&fields, "dueDate", req.DueDate)
+	if due_date.Before(issue_date) {
+		fields = append(fields, apperr.Field("dueDate", "must not be before the issue date"))
+	}
+	if req.ServiceDate != "" {
+		checkDate(&fields, "serviceDate", req.ServiceDate)
+	}
+
+	if len(req.Notes) > MAX_NOTES_BYTES {
+		fields = append(fields, apperr.Field("notes", fmt.Sprintf("must not be longer than %d bytes", MAX_NOTES_BYTES)))
+	}
+
+	req.BuyerReference = strings.TrimSpace(req.BuyerReference)
+	if len(req.BuyerReference) > MAX_BUYER_REFERENCE_LENGTH {
+		fields = append(fields, apperr.Field("buyerReference", fmt.Sprintf("must not be longer than %d bytes", MAX_BUYER_REFERENCE_LENGTH)))
+	}
+
+	validateItems(&fields, req.Items)
+
+	if len(req.Discounts) > MAX_DISCOUNTS {
+		fields = append(fields, apperr.Field("discounts", fmt.Sprintf("must not be more than %d", MAX_DISCOUNTS)))
+	}
+	for i, discount := range req.Discounts {
+		if discount == nil {
+			fields = append(fields, apperr.Field(fmt.Sprintf("discounts[%d]", i), "must not be null"))
+			continue
+		}
+		validateDiscount(&fields, fmt.Sprintf("discounts[%d]", i), discount)
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Invoice is invalid", fields...)
+	}
+	return nil
+}
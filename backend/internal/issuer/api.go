@@ -0,0 +1,158 @@
+package issuer
+
+import (
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"io"
+	"net/http"
+)
+
+type IssuerApi struct {
+	issuers *Issuers
+}
+
+func (ia *IssuerApi) GetIssuersList(w http.ResponseWriter, req *http.Request) {
+	issuers, err := ia.issuers.List(req.Context(), workspace.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading issuers"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetIssuersResponse{Issuers: issuers})
+}
+
+func (ia *IssuerApi) GetIssuer(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	issuer, err := ia.issuers.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading issuer"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.IssuerResponse{Issuer: issuer})
+}
+
+func (ia *IssuerApi) CreateIssuer(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveIssuerRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	issuer, err := ia.issuers.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Issuer couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.IssuerResponse{Issuer: issuer})
+}
+
+func (ia *IssuerApi) UpdateIssuer(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveIssuerRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	issuer, err := ia.issuers.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Issuer couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.IssuerResponse{Issuer: issuer})
+}
+
+func (ia *IssuerApi) DeleteIssuer(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ia.issuers.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the issuer"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UploadLogo takes the image as the form file "file", the way templates
+// are uploaded.
+func (ia *IssuerApi) UploadLogo(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	// The multipart encoding adds a little on top of the file itself.
+	req.Body = http.MaxBytesReader(w, req.Body, MAX_LOGO_BYTES+1<<20)
+	form_file, _, err := req.FormFile("file")
+	var max_bytes_err *http.MaxBytesError
+	if errors.As(err, &max_bytes_err) {
+		apperr.Write(w, req, apperr.Invalid("Logo is too large", apperr.Field("file", fmt.Sprintf("must not be larger than %d bytes", MAX_LOGO_BYTES))))
+		return
+	}
+	if err != nil {
+		apperr.Write(w, req, apperr.Invalid("No file uploaded", apperr.Field("file", "an image is required")))
+		return
+	}
+	defer form_file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(form_file, MAX_LOGO_BYTES+1))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading the logo from request"))
+		return
+	}
+	if len(data) > MAX_LOGO_BYTES {
+		apperr.Write(w, req, apperr.Invalid("Logo is too large", apperr.Field("file", fmt.Sprintf("must not be larger than %d bytes", MAX_LOGO_BYTES))))
+		return
+	}
+
+	issuer, err := ia.issuers.UploadLogo(req.Context(), workspace.IdFromContext(req.Context()), id, data, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Logo couldn't be uploaded"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.IssuerResponse{Issuer: issuer})
+}
+
+func (ia *IssuerApi) DeleteLogo(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	issuer, err := ia.issuers.DeleteLogo(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Logo couldn't be removed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.IssuerResponse{Issuer: issuer})
+}
+
+func NewIssuerApi(is *Issuers) *IssuerApi {
+	return &IssuerApi{issuers: is}
+}
@@ -0,0 +1,521 @@
+package issuer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound       = apperr.New(apperr.CODE_NOT_FOUND, "issuer not found")
+	ErrHasInvoices      = apperr.New(apperr.CODE_FAILED_PRECONDITION, "issuer has invoices")
+	ErrTemplateNotFound = apperr.Invalid("Default template doesn't exist", apperr.Field("defaultTemplateId", "no such template in this workspace"))
+)
+
+const (
+	AUDIT_TARGET = "issuer"
+	AUDIT_CREATE = "issuer.create"
+	AUDIT_UPDATE = "issuer.update"
+	AUDIT_LOGO   = "issuer.logo"
+	AUDIT_DELETE = "issuer.delete"
+)
+
+// Issuers are the legal entities a workspace issues invoices from, for
+// workspaces that invoice as more than one.
+type Issuers struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	insert_stmt, retrieve_stmt, list_stmt, update_stmt, logo_stmt, delete_stmt *sql.Stmt
+	delete_sequences_stmt, template_exists_stmt                                *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIssuer(row scanner) (*pb.Issuer, error) {
+	issuer := &pb.Issuer{Address: &pb.Address{}}
+	var address, registration_numbers, bank_accounts string
+	err := row.Scan(
+		&issuer.Id,
+		&issuer.Name,
+		&issuer.LegalName,
+		&address,
+		&issuer.TaxId,
+		&issuer.VatId,
+		&registration_numbers,
+		&bank_accounts,
+		&issuer.Logo,
+		&issuer.DefaultTemplateId,
+		&issuer.Footer,
+		&issuer.CreatedAt,
+		&issuer.UpdatedAt,
+		&issuer.CreatedBy,
+		&issuer.UpdatedBy,
+		&issuer.WorkspaceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal([]byte(address), issuer.Address); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(registration_numbers), &issuer.RegistrationNumbers); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal([]byte(bank_accounts), &issuer.BankAccounts); err != nil {
+		return nil, err
+	}
+	return issuer, nil
+}
+
+// marshalJson stores lists that were left out as empty ones.
+func marshalJson[T any](values []T) string {
+	if values == nil {
+		values = []T{}
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+func marshalAddress(address *pb.Address) string {
+	if address == nil {
+		return "{}"
+	}
+	b, _ := json.Marshal(address)
+	return string(b)
+}
+
+// auditFields is what the audit log records of an issuer.
+func auditFields(i *pb.Issuer) map[string]string {
+	numbers := []string{}
+	for _, number := range i.RegistrationNumbers {
+		numbers = append(numbers, number.Label+" "+number.Value)
+	}
+	accounts := []string{}
+	for _, account := range i.BankAccounts {
+		accounts = append(accounts, account.Iban)
+	}
+
+	return map[string]string{
+		"name":                 i.Name,
+		"legal_name":           i.LegalName,
+		"address":              marshalAddress(i.Address),
+		"tax_id":               i.TaxId,
+		"vat_id":               i.VatId,
+		"registration_numbers": strings.Join(numbers, ", "),
+		"bank_accounts":        strings.Join(accounts, ", "),
+		"logo":                 i.Logo,
+		"default_template_id":  fmt.Sprint(i.DefaultTemplateId),
+		"footer":               i.Footer,
+	}
+}
+
+func (is *Issuers) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.Issuer, error) {
+	issuer, err := scanIssuer(tx.Stmt(is.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return issuer, err
+}
+
+func (is *Issuers) checkTemplate(ctx context.Context, workspace_id uint32, template_id uint32) error {
+	if template_id == 0 {
+		return nil
+	}
+
+	var exists int
+	err := is.template_exists_stmt.QueryRowContext(ctx, template_id, workspace_id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+func (is *Issuers) Create(ctx context.Context, workspace_id uint32, req *pb.SaveIssuerRequest, actor *audit.Actor) (_ *pb.Issuer, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Issuers.Create")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+	if err = is.checkTemplate(ctx, workspace_id, req.DefaultTemplateId); err != nil {
+		return nil, err
+	}
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(is.insert_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.LegalName,
+		marshalAddress(req.Address),
+		req.TaxId,
+		req.VatId,
+		marshalJson(req.RegistrationNumbers),
+		marshalJson(req.BankAccounts),
+		helpers.NullableId(req.DefaultTemplateId),
+		req.Footer,
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := is.retrieve(ctx, tx, workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, issuer.Id, audit.Diff(nil, auditFields(issuer)))
+	if err = is.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return issuer, nil
+}
+
+func (is *Issuers) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.Issuer, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Issuers.Retrieve")
+	defer end(&err)
+
+	issuer, err := scanIssuer(is.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return issuer, err
+}
+
+// List returns all issuers of the workspace ordered by their name, a
+// workspace rarely has more than a handful.
+func (is *Issuers) List(ctx context.Context, workspace_id uint32) (_ []*pb.Issuer, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Issuers.List")
+	defer end(&err)
+
+	rows, err := is.list_stmt.QueryContext(ctx, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	issuers := []*pb.Issuer{}
+	for rows.Next() {
+		issuer, err := scanIssuer(rows)
+		if err != nil {
+			return nil, err
+		}
+		issuers = append(issuers, issuer)
+	}
+
+	return issuers, rows.Err()
+}
+
+// Update replaces all fields of the issuer but its logo. Invoices issued
+// already keep the details they were issued with.
+func (is *Issuers) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveIssuerRequest, actor *audit.Actor) (_ *pb.Issuer, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Issuers.Update")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+	if err = is.checkTemplate(ctx, workspace_id, req.DefaultTemplateId); err != nil {
+		return nil, err
+	}
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Stmt(is.update_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.LegalName,
+		marshalAddress(req.Address),
+		req.TaxId,
+		req.VatId,
+		marshalJson(req.RegistrationNumbers),
+		marshalJson(req.BankAccounts),
+		helpers.NullableId(req.DefaultTemplateId),
+		req.Footer,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		id,
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = is.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// setLogo points the issuer to the logo at path, or to none if it is empty,
+// and returns it along with the path of the logo it had before.
+func (is *Issuers) setLogo(ctx context.Context, workspace_id uint32, id uint32, path string, actor *audit.Actor) (*pb.Issuer, string, error) {
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	before, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, err = tx.Stmt(is.logo_stmt).ExecContext(ctx, path, time.Now().Unix(), helpers.NullableId(actor.UserId), id, workspace_id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	after, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	changes := audit.Diff(map[string]string{"logo": before.Logo}, map[string]string{"logo": after.Logo})
+	if err = is.audit.Record(tx, actor.Entry(workspace_id, AUDIT_LOGO, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, "", err
+	}
+
+	return after, before.Logo, nil
+}
+
+// Delete removes an issuer no invoice has been made out from, along with
+// the sequences saved for it.
+func (is *Issuers) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Issuers.Delete")
+	defer end(&err)
+
+	tx, err := is.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	issuer, err := is.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	// Invoices keep their issuer, SQLite reports a plain constraint error
+	// for the foreign key.
+	_, err = tx.Stmt(is.delete_stmt).ExecContext(ctx, id, workspace_id)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.Code == sqlite3.ErrConstraint {
+		return ErrHasInvoices
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Stmt(is.delete_sequences_stmt).ExecContext(ctx, workspace_id, id); err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(issuer), nil))
+	if err = is.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	removeLogo(issuer.Logo)
+	return nil
+}
+
+func (is *Issuers) Close() error {
+	stmts := []*sql.Stmt{
+		is.insert_stmt,
+		is.retrieve_stmt,
+		is.list_stmt,
+		is.update_stmt,
+		is.logo_stmt,
+		is.delete_stmt,
+		is.delete_sequences_stmt,
+		is.template_exists_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const ISSUER_COLUMNS = `
+	issuer_id,
+	issuer_name,
+	issuer_legal_name,
+	issuer_address,
+	issuer_tax_id,
+	issuer_vat_id,
+	issuer_registration_numbers,
+	issuer_bank_accounts,
+	issuer_logo,
+	COALESCE(issuer_default_template_id, 0),
+	issuer_footer,
+	issuer_created_at,
+	issuer_updated_at,
+	COALESCE(issuer_created_by, 0),
+	COALESCE(issuer_updated_by, 0),
+	issuer_workspace_id
+`
+
+func NewIssuers(db *sql.DB, audit_log *audit.Log) (*Issuers, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO issuers (
+			issuer_name,
+			issuer_legal_name,
+			issuer_address,
+			issuer_tax_id,
+			issuer_vat_id,
+			issuer_registration_numbers,
+			issuer_bank_accounts,
+			issuer_default_template_id,
+			issuer_footer,
+			issuer_created_at,
+			issuer_updated_at,
+			issuer_created_by,
+			issuer_updated_by,
+			issuer_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + ISSUER_COLUMNS + `
+		FROM issuers
+		WHERE issuer_id = ? AND issuer_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(`
+		SELECT ` + ISSUER_COLUMNS + `
+		FROM issuers
+		WHERE issuer_workspace_id = ?
+		ORDER BY issuer_name COLLATE NOCASE, issuer_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE issuers
+		SET issuer_name = ?,
+			issuer_legal_name = ?,
+			issuer_address = ?,
+			issuer_tax_id = ?,
+			issuer_vat_id = ?,
+			issuer_registration_numbers = ?,
+			issuer_bank_accounts = ?,
+			issuer_default_template_id = ?,
+			issuer_footer = ?,
+			issuer_updated_at = ?,
+			issuer_updated_by = ?
+		WHERE issuer_id = ? AND issuer_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	logo_stmt, err := db.Prepare(`
+		UPDATE issuers
+		SET issuer_logo = ?, issuer_updated_at = ?, issuer_updated_by = ?
+		WHERE issuer_id = ? AND issuer_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM issuers WHERE issuer_id = ? AND issuer_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	delete_sequences_stmt, err := db.Prepare("DELETE FROM sequences WHERE sequence_workspace_id = ? AND sequence_issuer_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	template_exists_stmt, err := db.Prepare("SELECT 1 FROM templates WHERE template_id = ? AND template_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Issuers{
+		db:                    db,
+		audit:                 audit_log,
+		insert_stmt:           insert_stmt,
+		retrieve_stmt:         retrieve_stmt,
+		list_stmt:             list_stmt,
+		update_stmt:           update_stmt,
+		logo_stmt:             logo_stmt,
+		delete_stmt:           delete_stmt,
+		delete_sequences_stmt: delete_sequences_stmt,
+		template_exists_stmt:  template_exists_stmt,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package issuer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/h2non/bimg"
+)
+
+const (
+	MAX_LOGO_BYTES = 5 << 20
+
+	// Logos are scaled down to fit, keeping their aspect ratio. That is
+	// plenty for print, and keeps the HTML they are embedded in small.
+	LOGO_MAX_WIDTH  = 800
+	LOGO_MAX_HEIGHT = 400
+	LOGO_NAME       = "logo.png"
+)
+
+var ErrInvalidLogo = apperr.New(apperr.CODE_INVALID_ARGUMENT, "logo must be a PNG, JPEG, GIF or WebP image")
+
+var logoTypes = []bimg.ImageType{bimg.PNG, bimg.JPEG, bimg.GIF, bimg.WEBP}
+
+// logoWidth is the width an image of the given size is scaled down to.
+func logoWidth(size bimg.ImageSize) int {
+	width := min(size.Width, LOGO_MAX_WIDTH)
+	if size.Height*width > LOGO_MAX_HEIGHT*size.Width {
+		width = size.Width * LOGO_MAX_HEIGHT / size.Height
+	}
+	return max(width, 1)
+}
+
+// ResizeLogo scales the image down to fit the bounds of logos and stores it
+// as PNG in dest_dir, which keeps transparent backgrounds.
+func ResizeLogo(ctx context.Context, data []byte, dest_dir string) (_ string, err error) {
+	_, end := telemetry.StartConversion(ctx, "ResizeLogo", telemetry.CONVERTER_THUMBNAIL)
+	defer end(&err)
+
+	image := bimg.NewImage(data)
+	size, err := image.Size()
+	if err != nil {
+		return "", err
+	}
+
+	logo, err := image.Process(bimg.Options{Width: logoWidth(size), Type: bimg.PNG})
+	if err != nil {
+		return "", err
+	}
+
+	logo_path := filepath.Join(dest_dir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), LOGO_NAME))
+	if err = bimg.Write(logo_path, logo); err != nil {
+		return "", err
+	}
+
+	return logo_path, nil
+}
+
+func removeLogo(logo_path string) {
+	if logo_path == "" {
+		return
+	}
+	if err := os.Remove(logo_path); err != nil && !os.IsNotExist(err) {
+		log.Println("Error removing logo", logo_path, err)
+	}
+}
+
+// UploadLogo replaces the logo of the issuer. It is stored along with the
+// thumbnails of templates, below the static directory of the workspace.
+func (is *Issuers) UploadLogo(ctx context.Context, workspace_id uint32, id uint32, data []byte, actor *audit.Actor) (_ *pb.Issuer, err error) {
+	ctx, end := telemetry.Start(ctx, "Issuers.UploadLogo")
+	defer end(&err)
+
+	if !slices.Contains(logoTypes, bimg.DetermineImageType(data)) {
+		return nil, ErrInvalidLogo
+	}
+
+	if _, err = is.Retrieve(ctx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	logos_dir, err := storage.EnsureWorkspaceDir(workspace_id, storage.LOGOS)
+	if err != nil {
+		return nil, err
+	}
+
+	logo_path, err := ResizeLogo(ctx, data, logos_dir)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, previous, err := is.setLogo(ctx, workspace_id, id, logo_path, actor)
+	if err != nil {
+		removeLogo(logo_path)
+		return nil, err
+	}
+
+	removeLogo(previous)
+	return issuer, nil
+}
+
+// DeleteLogo leaves the issuer without a logo.
+func (is *Issuers) DeleteLogo(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (_ *pb.Issuer, err error) {
+	ctx, end := telemetry.Start(ctx, "Issuers.DeleteLogo")
+	defer end(&err)
+
+	issuer, previous, err := is.setLogo(ctx, workspace_id, id, "", actor)
+	if err != nil {
+		return nil, err
+	}
+
+	removeLogo(previous)
+	return issuer, nil
+}
+
+// LogoDataUri returns the logo as a data URI, e.g. for the src of an img.
+// Documents embed it, so that they print without access to the static
+// directory and keep their logo when it is replaced.
+func LogoDataUri(logo_path string) (string, error) {
+	if logo_path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(logo_path)
+	if err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
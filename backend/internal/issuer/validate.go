@@ -0,0 +1,194 @@
+package issuer
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/client"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+const (
+	MAX_NAME_LENGTH          = 256
+	MAX_FOOTER_LENGTH        = 4_000
+	MAX_REGISTRATION_NUMBERS = 10
+	MAX_BANK_ACCOUNTS        = 10
+)
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	vatIdPattern   = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9+*]{2,13}$`)
+	ibanPattern    = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}$`)
+	bicPattern     = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+)
+
+// ibanLengths are the lengths of IBANs by country, as in the SWIFT IBAN
+// registry. IBANs of other countries don't exist.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+// ValidIban checks the format, the length for its country and the ISO 7064
+// check digits of an IBAN without spaces.
+func ValidIban(iban string) bool {
+	if !ibanPattern.MatchString(iban) || len(iban) != ibanLengths[iban[:2]] {
+		return false
+	}
+
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprint(&digits, r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	return n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validateAddress(address *pb.Address) []apperr.FieldError {
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+	address.Region = strings.TrimSpace(address.Region)
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+
+	if address.Country != "" && !countryPattern.MatchString(address.Country) {
+		return []apperr.FieldError{apperr.Field("address.country", "must be an ISO 3166-1 alpha-2 code")}
+	}
+	return nil
+}
+
+func validateRegistrationNumbers(numbers []*pb.RegistrationNumber) []apperr.FieldError {
+	if len(numbers) > MAX_REGISTRATION_NUMBERS {
+		return []apperr.FieldError{apperr.Field("registrationNumbers", fmt.Sprintf("must not be more than %d", MAX_REGISTRATION_NUMBERS))}
+	}
+
+	fields := []apperr.FieldError{}
+	for i, number := range numbers {
+		field := fmt.Sprintf("registrationNumbers[%d]", i)
+		if number == nil {
+			fields = append(fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+		number.Label = strings.TrimSpace(number.Label)
+		number.Value = strings.TrimSpace(number.Value)
+		if number.Value == "" {
+			fields = append(fields, apperr.Field(field+".value", "must not be empty"))
+		}
+	}
+	return fields
+}
+
+func validateBankAccounts(accounts []*pb.BankAccount) []apperr.FieldError {
+	if len(accounts) > MAX_BANK_ACCOUNTS {
+		return []apperr.FieldError{apperr.Field("bankAccounts", fmt.Sprintf("must not be more than %d", MAX_BANK_ACCOUNTS))}
+	}
+
+	fields := []apperr.FieldError{}
+	primary := 0
+	for i, account := range accounts {
+		field := fmt.Sprintf("bankAccounts[%d]", i)
+		if account == nil {
+			fields = append(fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+		account.BankName = strings.TrimSpace(account.BankName)
+		account.AccountHolder = strings.TrimSpace(account.AccountHolder)
+		account.Iban = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(account.Iban), " ", ""))
+		account.Bic = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(account.Bic), " ", ""))
+
+		if !ValidIban(account.Iban) {
+			fields = append(fields, apperr.Field(field+".iban", "must be a valid IBAN"))
+		}
+		if account.Bic != "" && !bicPattern.MatchString(account.Bic) {
+			fields = append(fields, apperr.Field(field+".bic", "must be a BIC of 8 or 11 letters and digits"))
+		}
+		if account.Primary {
+			primary++
+		}
+	}
+
+	if primary > 1 {
+		fields = append(fields, apperr.Field("bankAccounts", "only one account can be primary"))
+	}
+	return fields
+}
+
+// Validate checks the request and normalizes it in place. Issuers without
+// a name are named after their legal name.
+func Validate(req *pb.SaveIssuerRequest) error {
+	fields := []apperr.FieldError{}
+
+	req.LegalName = strings.TrimSpace(req.LegalName)
+	if req.LegalName == "" {
+		fields = append(fields, apperr.Field("legalName", "must not be empty"))
+	} else if len(req.LegalName) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("legalName", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	if req.Name = strings.TrimSpace(req.Name); req.Name == "" {
+		req.Name = req.LegalName
+	}
+	if len(req.Name) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("name", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	if req.Address == nil {
+		req.Address = &pb.Address{}
+	}
+	fields = append(fields, validateAddress(req.Address)...)
+
+	req.TaxId = strings.TrimSpace(req.TaxId)
+	if len(req.TaxId) > client.MAX_TAX_ID_LENGTH {
+		fields = append(fields, apperr.Field("taxId", fmt.Sprintf("must not be longer than %d bytes", client.MAX_TAX_ID_LENGTH)))
+	}
+
+	req.VatId = workspace.NormalizeVatId(req.VatId)
+	if req.VatId != "" && !vatIdPattern.MatchString(req.VatId) {
+		fields = append(fields, apperr.Field("vatId", "must start with a country prefix followed by 2 to 13 letters or digits"))
+	} else if req.VatId != "" && req.Address.Country == "" {
+		fields = append(fields, apperr.Field("address.country", "is required with a VAT ID"))
+	}
+
+	fields = append(fields, validateRegistrationNumbers(req.RegistrationNumbers)...)
+	fields = append(fields, validateBankAccounts(req.BankAccounts)...)
+
+	req.Footer = strings.TrimSpace(req.Footer)
+	if len(req.Footer) > MAX_FOOTER_LENGTH {
+		fields = append(fields, apperr.Field("footer", fmt.Sprintf("must not be longer than %d bytes", MAX_FOOTER_LENGTH)))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Issuer is invalid", fields...)
+	}
+	return nil
+}
+
+// PrimaryAccount is the bank account invoices of the issuer print, nil if
+// it has none.
+func PrimaryAccount(issuer *pb.Issuer) *pb.BankAccount {
+	for _, account := range issuer.GetBankAccounts() {
+		if account.Primary {
+			return account
+		}
+	}
+	if len(issuer.GetBankAccounts()) > 0 {
+		return issuer.BankAccounts[0]
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package issuer
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"testing"
+)
+
+func TestValidIban(t *testing.T) {
+	tests := []struct {
+		name string
+		iban string
+		want bool
+	}{
+		{"Germany", "DE89370400440532013000", true},
+		{"United Kingdom", "GB29NWBK60161331926819", true},
+		{"France with a letter", "FR1420041010050500013M02606", true},
+		{"Norway, the shortest", "NO9386011117947", true},
+		{"Malta with letters", "MT84MALT011000012345MTLCAST001S", true},
+		{"wrong check digits", "DE89370400440532013001", false},
+		{"swapped digits", "DE89370400440532031000", false},
+		{"too long for its country", "DE543704004405320130001", false},
+		{"too short for its country", "AT61190430023457320", false},
+		{"country without IBANs", "XX113704004405320130001", false},
+		{"lowercase", "de89370400440532013000", false},
+		{"spaces", "DE89 3704 0044 0532 0130 00", false},
+		{"letters as check digits", "DEAB370400440532013000", false},
+		{"empty", "", false},
+	}
+
+	for _, test := range tests {
+		if got := ValidIban(test.iban); got != test.want {
+			t.Errorf("%s: ValidIban(%q) = %t, want %t", test.name, test.iban, got, test.want)
+		}
+	}
+}
+
+func TestValidateBankAccounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		account *pb.BankAccount
+		// Fields reported as invalid, none if the account is valid.
+		fields []string
+	}{
+		{name: "IBAN and BIC", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBADEFFXXX"}},
+		{name: "BIC of 8", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBADEFF"}},
+		{name: "no BIC", account: &pb.BankAccount{Iban: "DE89370400440532013000"}},
+		{name: "spaced and lowercase", account: &pb.BankAccount{Iban: " de89 3704 0044 0532 0130 00 ", Bic: "coba de ff"}},
+		{name: "no IBAN", account: &pb.BankAccount{Bic: "COBADEFF"}, fields: []string{"bankAccounts[0].iban"}},
+		{name: "invalid IBAN", account: &pb.BankAccount{Iban: "DE89370400440532013001"}, fields: []string{"bankAccounts[0].iban"}},
+		{name: "BIC of 7", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBADEF"}, fields: []string{"bankAccounts[0].bic"}},
+		{name: "BIC of 9", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBADEFF1"}, fields: []string{"bankAccounts[0].bic"}},
+		{name: "BIC with a digit in the country", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBAD1FF"}, fields: []string{"bankAccounts[0].bic"}},
+		{name: "BIC with punctuation", account: &pb.BankAccount{Iban: "DE89370400440532013000", Bic: "COBA-DEFF"}, fields: []string{"bankAccounts[0].bic"}},
+		{name: "both invalid", account: &pb.BankAccount{Iban: "DE8937040044", Bic: "COBA"}, fields: []string{"bankAccounts[0].iban", "bankAccounts[0].bic"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(&pb.SaveIssuerRequest{LegalName: "Acme GmbH", BankAccounts: []*pb.BankAccount{test.account}})
+			if len(test.fields) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				if test.account.Iban != "DE89370400440532013000" {
+					t.Errorf("IBAN is normalized to %q", test.account.Iban)
+				}
+				return
+			}
+
+			var app_err *apperr.Error
+			if !errors.As(err, &app_err) {
+				t.Fatalf("Validate() error = %v, want a field error", err)
+			}
+			fields := []string{}
+			for _, field := range app_err.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestValidatePrimaryAccount(t *testing.T) {
+	accounts := []*pb.BankAccount{
+		{Iban: "DE89370400440532013000", Primary: true},
+		{Iban: "GB29NWBK60161331926819", Primary: true},
+	}
+	err := Validate(&pb.SaveIssuerRequest{LegalName: "Acme GmbH", BankAccounts: accounts})
+
+	var app_err *apperr.Error
+	if !errors.As(err, &app_err) || len(app_err.Fields) != 1 || app_err.Fields[0].Field != "bankAccounts" {
+		t.Errorf("Validate() of two primary accounts error = %v, want a bankAccounts field error", err)
+	}
+}
@@ -0,0 +1,81 @@
+package lifecycle
+
+import (
+	"context"
+	"invoice-manager/main/internal/apperr"
+	"sync"
+	"time"
+)
+
+// ABORT_GRACE is how long aborted jobs get to clean up after themselves.
+const ABORT_GRACE = 5 * time.Second
+
+var (
+	ErrShuttingDown = apperr.New(apperr.CODE_UNAVAILABLE, "server is shutting down")
+	ErrJobsAborted  = apperr.New(apperr.CODE_UNAVAILABLE, "running jobs were aborted")
+)
+
+// Jobs tracks long running work, like conversions, that has to be finished
+// or aborted before the database is closed.
+type Jobs struct {
+	mu     sync.Mutex
+	wg     sync.WaitGroup
+	closed bool
+
+	abort  context.Context
+	cancel context.CancelFunc
+}
+
+func NewJobs() *Jobs {
+	abort, cancel := context.WithCancel(context.Background())
+	return &Jobs{abort: abort, cancel: cancel}
+}
+
+// Begin registers a job, which must call done once it is over. The context
+// of the job keeps the values of ctx, but is only canceled when the jobs
+// are aborted, not when the client goes away.
+func (j *Jobs) Begin(ctx context.Context) (context.Context, func(), error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil, nil, ErrShuttingDown
+	}
+
+	j.wg.Add(1)
+	job_ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(j.abort, cancel)
+
+	return job_ctx, func() {
+		stop()
+		cancel()
+		j.wg.Done()
+	}, nil
+}
+
+// Drain refuses new jobs and waits for the running ones until ctx is done.
+// Jobs still running then are aborted.
+func (j *Jobs) Drain(ctx context.Context) error {
+	j.mu.Lock()
+	j.closed = true
+	j.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	j.cancel()
+	select {
+	case <-done:
+	case <-time.After(ABORT_GRACE):
+	}
+	return ErrJobsAborted
+}
@@ -0,0 +1,135 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/helpers"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const DEFAULT_SHUTDOWN_TIMEOUT = 30 * time.Second
+
+// Worker runs in the background until its context is canceled.
+type Worker func(ctx context.Context) error
+
+type named[T any] struct {
+	name string
+	fn   T
+}
+
+// App runs the HTTP server along with the background workers. On SIGINT or
+// SIGTERM it stops taking requests, waits for the running ones, stops the
+// workers, drains jobs and closes resources, in that order and all within
+// the shutdown timeout.
+type App struct {
+	server  *http.Server
+	timeout time.Duration
+
+	workers []named[Worker]
+	drains  []named[func(context.Context) error]
+	closers []named[func() error]
+}
+
+func New(server *http.Server, timeout time.Duration) *App {
+	return &App{server: server, timeout: timeout}
+}
+
+// TimeoutFromEnv reads the shutdown timeout in seconds from
+// INVOICER_SHUTDOWN_TIMEOUT.
+func TimeoutFromEnv() time.Duration {
+	return time.Duration(helpers.EnvInt("INVOICER_SHUTDOWN_TIMEOUT", int64(DEFAULT_SHUTDOWN_TIMEOUT/time.Second))) * time.Second
+}
+
+func (a *App) Go(name string, worker Worker) {
+	a.workers = append(a.workers, named[Worker]{name, worker})
+}
+
+// OnDrain registers work to finish once no more requests come in, e.g.
+// Jobs.Drain.
+func (a *App) OnDrain(name string, drain func(context.Context) error) {
+	a.drains = append(a.drains, named[func(context.Context) error]{name, drain})
+}
+
+// OnClose registers resources to close last, in reverse order.
+func (a *App) OnClose(name string, close func() error) {
+	a.closers = append(a.closers, named[func() error]{name, close})
+}
+
+// Run blocks until a signal arrives or the server or a worker fails.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, len(a.workers)+1)
+
+	workers_ctx, stop_workers := context.WithCancel(context.Background())
+	defer stop_workers()
+
+	var workers sync.WaitGroup
+	for _, worker := range a.workers {
+		workers.Add(1)
+		go func(worker named[Worker]) {
+			defer workers.Done()
+			if err := worker.fn(workers_ctx); err != nil && !errors.Is(err, context.Canceled) {
+				failed <- fmt.Errorf("%s: %w", worker.name, err)
+			}
+		}(worker)
+	}
+
+	go func() {
+		if err := a.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- fmt.Errorf("failed to start a HTTP server: %w", err)
+		}
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down, waiting up to", a.timeout)
+	case err = <-failed:
+		log.Println("Shutting down:", err)
+	}
+	// A second signal kills the process right away.
+	stop()
+
+	shutdown_ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	if shutdown_err := a.server.Shutdown(shutdown_ctx); shutdown_err != nil {
+		log.Println("Requests still running, closing connections:", shutdown_err)
+		a.server.Close()
+	}
+
+	stop_workers()
+	stopped := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(ABORT_GRACE):
+		log.Println("Workers didn't stop in time")
+	}
+
+	for _, drain := range a.drains {
+		if drain_err := drain.fn(shutdown_ctx); drain_err != nil {
+			log.Printf("%s: %s", drain.name, drain_err)
+		}
+	}
+
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		if close_err := a.closers[i].fn(); close_err != nil {
+			log.Printf("closing %s: %s", a.closers[i].name, close_err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,119 @@
+package money
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Amounts are decimals in an ISO 4217 currency. They are rounded to the
+// minor units of their currency, half away from zero, e.g. to cents for EUR
+// and to whole yen for JPY.
+
+// DEFAULT_SCALE is the number of minor units of currencies x/text doesn't
+// know.
+const DEFAULT_SCALE = 2
+
+// DEFAULT_LANGUAGE formats amounts of documents without a language.
+var DEFAULT_LANGUAGE = language.English
+
+// Valid tells whether the code is a known ISO 4217 currency, e.g. EUR.
+func Valid(code string) bool {
+	if len(code) != 3 || strings.ToUpper(code) != code {
+		return false
+	}
+	_, err := currency.ParseISO(code)
+	return err == nil
+}
+
+// Scale returns the number of minor units of the currency.
+func Scale(code string) int32 {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return DEFAULT_SCALE
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return int32(scale)
+}
+
+// Round rounds the amount to the minor units of the currency.
+func Round(amount decimal.Decimal, code string) decimal.Decimal {
+	return amount.Round(Scale(code))
+}
+
+// String formats the amount with exactly the minor units of the currency,
+// e.g. "1234.50" for EUR, the way amounts are stored and sent.
+func String(amount decimal.Decimal, code string) string {
+	return amount.StringFixed(Scale(code))
+}
+
+func printer(lang string) *message.Printer {
+	tag, err := language.Parse(lang)
+	if err != nil || lang == "" {
+		tag = DEFAULT_LANGUAGE
+	}
+	return message.NewPrinter(tag)
+}
+
+// FormatNumber formats the amount for readers of the language, with its
+// digit grouping and decimal separator and the minor units of the currency,
+// e.g. "1.234,50" for EUR in German.
+func FormatNumber(amount decimal.Decimal, code string, lang string) string {
+	return format(Round(amount, code), Scale(code), lang)
+}
+
+// FormatPrice formats a unit price like FormatNumber, keeping the decimal
+// places it has beyond the minor units of the currency, e.g. "0,125" for EUR
+// in German.
+func FormatPrice(price decimal.Decimal, code string, lang string) string {
+	return format(price, max(Scale(code), -price.Exponent()), lang)
+}
+
+// format writes the digits of the amount itself, as floats would lose some
+// of them, and only takes the grouping, digits and separators of the
+// language from x/text.
+func format(amount decimal.Decimal, scale int32, lang string) string {
+	p := printer(lang)
+
+	// The zero of the language, its other digits follow it in Unicode.
+	zero, _ := utf8.DecodeRuneInString(p.Sprint(number.Decimal(0)))
+	sample := p.Sprint(number.Decimal(-1.5, number.Scale(1)))
+	one := strings.IndexRune(sample, zero+1)
+	five := strings.LastIndex(sample, string(zero+5))
+	minus, separator := sample[:one], sample[one+utf8.RuneLen(zero+1):five]
+
+	whole, fraction, _ := strings.Cut(amount.Abs().StringFixed(scale), ".")
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return amount.StringFixed(scale)
+	}
+
+	var b strings.Builder
+	if amount.Round(scale).IsNegative() {
+		b.WriteString(minus)
+	}
+	b.WriteString(p.Sprint(number.Decimal(units)))
+	if fraction != "" {
+		b.WriteString(separator)
+		for _, digit := range fraction {
+			b.WriteRune(zero + digit - '0')
+		}
+	}
+	return b.String()
+}
+
+// Symbol returns the symbol of the currency in the language, e.g. "€" or
+// "CHF", or the code if x/text doesn't know it.
+func Symbol(code string, lang string) string {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return code
+	}
+	return printer(lang).Sprint(currency.Symbol(unit))
+}
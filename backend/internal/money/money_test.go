@@ -0,0 +1,58 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		amount string
+		code   string
+		lang   string
+		want   string
+	}{
+		{"1234.5", "EUR", "de", "1.234,50"},
+		{"1234.5", "EUR", "en", "1,234.50"},
+		{"1234.5", "EUR", "", "1,234.50"},
+		{"1234.5", "CHF", "de-CH", "1’234.50"},
+		{"1234567.5", "INR", "en-IN", "12,34,567.50"},
+		{"1234.5", "EUR", "fr", "1\u00a0234,50"},
+		{"-1234.505", "EUR", "de", "-1.234,51"},
+		{"-0.001", "EUR", "en", "0.00"},
+		{"1234.5", "JPY", "en", "1,235"},
+		{"1234.5", "KWD", "en", "1,234.500"},
+		// Floats would already have lost the cents of this one.
+		{"12345678901234567.89", "EUR", "en", "12,345,678,901,234,567.89"},
+	}
+
+	for _, test := range tests {
+		got := FormatNumber(decimal.RequireFromString(test.amount), test.code, test.lang)
+		if got != test.want {
+			t.Errorf("FormatNumber(%s, %s, %q) = %q, want %q", test.amount, test.code, test.lang, got, test.want)
+		}
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		price string
+		code  string
+		lang  string
+		want  string
+	}{
+		{"0.125", "EUR", "de", "0,125"},
+		{"12", "EUR", "de", "12,00"},
+		{"-0.001", "EUR", "en", "-0.001"},
+		{"1000.5", "JPY", "en", "1,000.5"},
+		{"0.1", "EUR", "ar", "٠٫١٠"},
+	}
+
+	for _, test := range tests {
+		got := FormatPrice(decimal.RequireFromString(test.price), test.code, test.lang)
+		if got != test.want {
+			t.Errorf("FormatPrice(%s, %s, %q) = %q, want %q", test.price, test.code, test.lang, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+type PaymentApi struct {
+	payments *Payments
+}
+
+func (pa *PaymentApi) CreatePayment(w http.ResponseWriter, req *http.Request) {
+	var body pb.SavePaymentRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	payment, err := pa.payments.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Payment couldn't be recorded"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.PaymentResponse{Payment: payment})
+}
+
+func (pa *PaymentApi) GetPayment(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	payment, err := pa.payments.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading payment"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.PaymentResponse{Payment: payment})
+}
+
+func (pa *PaymentApi) ReversePayment(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.ReversePaymentRequest
+	if req.ContentLength != 0 {
+		if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	payment, err := pa.payments.Reverse(req.Context(), workspace.IdFromContext(req.Context()), id, body.Reason, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Payment couldn't be reversed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.PaymentResponse{Payment: payment})
+}
+
+func (pa *PaymentApi) GetInvoicePayments(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	payments, err := pa.payments.ListByInvoice(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading payments"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetPaymentsResponse{Payments: payments})
+}
+
+func (pa *PaymentApi) GetClientPayments(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	payments, err := pa.payments.ListByClient(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading payments"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetPaymentsResponse{Payments: payments})
+}
+
+func (pa *PaymentApi) GetClientCredit(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	balances, err := pa.payments.Credit(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading credit"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCreditResponse{Balances: balances})
+}
+
+func (pa *PaymentApi) ApplyClientCredit(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.ApplyCreditRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	invoice, allocations, err := pa.payments.ApplyCredit(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Credit couldn't be applied"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.ApplyCreditResponse{Invoice: invoice, Allocations: allocations})
+}
+
+func NewPaymentApi(ps *Payments) *PaymentApi {
+	return &PaymentApi{payments: ps}
+}
@@ -0,0 +1,815 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/client"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/invoice"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrIDNotFound         = apperr.New(apperr.CODE_NOT_FOUND, "payment not found")
+	ErrClientNotFound     = apperr.Invalid("Client doesn't exist", apperr.Field("clientId", "no such client in this workspace"))
+	ErrInvoiceNotFound    = apperr.New(apperr.CODE_INVALID_ARGUMENT, "invoice doesn't exist")
+	ErrOtherClient        = apperr.New(apperr.CODE_INVALID_ARGUMENT, "invoice is made out to another client")
+	ErrOtherCurrency      = apperr.New(apperr.CODE_INVALID_ARGUMENT, "invoice is in another currency")
+	ErrNotPayable         = apperr.New(apperr.CODE_FAILED_PRECONDITION, "invoice can't be paid")
+	ErrExceedsBalance     = apperr.New(apperr.CODE_FAILED_PRECONDITION, "amount exceeds the balance of the invoice")
+	ErrReversed           = apperr.New(apperr.CODE_FAILED_PRECONDITION, "payment has already been reversed")
+	ErrInsufficientCredit = apperr.New(apperr.CODE_FAILED_PRECONDITION, "client doesn't have enough credit")
+)
+
+const (
+	AUDIT_TARGET       = "payment"
+	AUDIT_CREATE       = "payment.create"
+	AUDIT_REVERSE      = "payment.reverse"
+	AUDIT_APPLY_CREDIT = "payment.apply_credit"
+	AUDIT_RELEASE      = "payment.release"
+
+	MAX_REASON_BYTES = 1000
+)
+
+// Payments are recorded per client and split across their invoices. What
+// isn't allocated to an invoice is credit of the client, which can be
+// applied to later invoices. Payments are never changed, a mistaken one is
+// reversed, which takes back all of its allocations.
+type Payments struct {
+	db       *sql.DB
+	audit    *audit.Log
+	clients  *client.Clients
+	invoices *invoice.Invoices
+
+	insert_stmt, retrieve_stmt, by_invoice_stmt, by_client_stmt, reverse_stmt *sql.Stmt
+	allocations_stmt, insert_allocation_stmt, paid_stmt, invoice_paid_stmt    *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPayment(row scanner) (*pb.Payment, error) {
+	payment := &pb.Payment{}
+	err := row.Scan(
+		&payment.Id,
+		&payment.ClientId,
+		&payment.Amount,
+		&payment.Currency,
+		&payment.Date,
+		&payment.Method,
+		&payment.Reference,
+		&payment.Notes,
+		&payment.ReversedAt,
+		&payment.ReversedBy,
+		&payment.ReversalReason,
+		&payment.CreatedAt,
+		&payment.CreatedBy,
+		&payment.WorkspaceId,
+	)
+	return payment, err
+}
+
+func marshalJson(value any) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func auditFields(p *pb.Payment) map[string]string {
+	return map[string]string{
+		"client_id":   fmt.Sprint(p.ClientId),
+		"amount":      p.Amount,
+		"currency":    p.Currency,
+		"date":        p.Date,
+		"method":      p.Method,
+		"reference":   p.Reference,
+		"allocations": marshalJson(p.Allocations),
+	}
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, args ...any) (*sql.Rows, error)
+}
+
+// loadAllocations reads the allocations of the payment and computes what is
+// left unallocated.
+func (ps *Payments) loadAllocations(ctx context.Context, stmt querier, payment *pb.Payment) error {
+	rows, err := stmt.QueryContext(ctx, payment.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	unallocated, err := decimal.NewFromString(payment.Amount)
+	if err != nil {
+		return err
+	}
+
+	payment.Allocations = []*pb.PaymentAllocation{}
+	for rows.Next() {
+		allocation := &pb.PaymentAllocation{}
+		err = rows.Scan(
+			&allocation.Id,
+			&allocation.PaymentId,
+			&allocation.InvoiceId,
+			&allocation.Amount,
+			&allocation.FromCredit,
+			&allocation.CreatedAt,
+			&allocation.CreatedBy,
+		)
+		if err != nil {
+			return err
+		}
+
+		amount, err := decimal.NewFromString(allocation.Amount)
+		if err != nil {
+			return err
+		}
+		unallocated = unallocated.Sub(amount)
+		payment.Allocations = append(payment.Allocations, allocation)
+	}
+
+	payment.Unallocated = formatAmount(unallocated, payment.Currency)
+	return rows.Err()
+}
+
+func (ps *Payments) list(ctx context.Context, stmt querier, allocations_stmt querier, args ...any) ([]*pb.Payment, error) {
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := []*pb.Payment{}
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, payment := range payments {
+		if err = ps.loadAllocations(ctx, allocations_stmt, payment); err != nil {
+			return nil, err
+		}
+	}
+	return payments, nil
+}
+
+func (ps *Payments) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.Payment, error) {
+	payment, err := scanPayment(tx.Stmt(ps.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, ps.loadAllocations(ctx, tx.Stmt(ps.allocations_stmt), payment)
+}
+
+// paid sums what the payments that haven't been reversed allocate to the
+// invoice.
+func (ps *Payments) paid(ctx context.Context, tx *sql.Tx, invoice_id uint32) (decimal.Decimal, error) {
+	rows, err := tx.Stmt(ps.paid_stmt).QueryContext(ctx, invoice_id)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	defer rows.Close()
+
+	paid := decimal.Zero
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return decimal.Zero, err
+		}
+
+		amount, err := decimal.NewFromString(value)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		paid = paid.Add(amount)
+	}
+
+	return paid, rows.Err()
+}
+
+// settle stores what is paid of the invoice, moving it to paid, partially
+// paid or back to unpaid.
+func (ps *Payments) settle(ctx context.Context, tx *sql.Tx, inv *pb.Invoice, note string, actor *audit.Actor) error {
+	paid, err := ps.paid(ctx, tx, inv.Id)
+	if err != nil {
+		return err
+	}
+	return ps.invoices.Settle(ctx, tx, inv, paid, note, actor)
+}
+
+// allocate settles amount of the invoice with the payment.
+func (ps *Payments) allocate(ctx context.Context, tx *sql.Tx, payment *pb.Payment, invoice_id uint32, amount decimal.Decimal, from_credit bool, actor *audit.Actor) (*pb.PaymentAllocation, error) {
+	inv, err := ps.invoices.RetrieveTx(ctx, tx, payment.WorkspaceId, invoice_id)
+	if err == invoice.ErrIDNotFound {
+		return nil, fmt.Errorf("%w: %d", ErrInvoiceNotFound, invoice_id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case inv.ClientId != payment.ClientId:
+		return nil, fmt.Errorf("%w: %d", ErrOtherClient, invoice_id)
+	case inv.Currency != payment.Currency:
+		return nil, fmt.Errorf("%w: %s is in %s", ErrOtherCurrency, inv.Number, inv.Currency)
+	case inv.Kind != invoice.KIND_INVOICE:
+		return nil, fmt.Errorf("%w: %s is a %s", ErrNotPayable, inv.Number, strings.ReplaceAll(inv.Kind, "_", " "))
+	case !invoice.Payable(inv.Status):
+		return nil, fmt.Errorf("%w: %d is %s", ErrNotPayable, invoice_id, inv.Status)
+	}
+
+	balance, err := decimal.NewFromString(inv.Balance)
+	if err != nil {
+		return nil, err
+	}
+	if amount.GreaterThan(balance) {
+		return nil, fmt.Errorf("%w: %s has %s %s left to pay", ErrExceedsBalance, inv.Number, inv.Balance, inv.Currency)
+	}
+
+	allocation := &pb.PaymentAllocation{
+		PaymentId:  payment.Id,
+		InvoiceId:  invoice_id,
+		Amount:     formatAmount(amount, payment.Currency),
+		FromCredit: from_credit,
+		CreatedAt:  time.Now().Unix(),
+		CreatedBy:  actor.UserId,
+	}
+
+	res, err := tx.Stmt(ps.insert_allocation_stmt).ExecContext(
+		ctx,
+		allocation.PaymentId,
+		allocation.InvoiceId,
+		allocation.Amount,
+		allocation.FromCredit,
+		allocation.CreatedAt,
+		helpers.NullableId(actor.UserId),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	allocation.Id = uint32(id)
+
+	if err = ps.settle(ctx, tx, inv, fmt.Sprintf("payment %d", payment.Id), actor); err != nil {
+		return nil, err
+	}
+
+	return allocation, nil
+}
+
+// Create records a payment of a client and allocates it to their invoices.
+// What is left over becomes credit of the client.
+func (ps *Payments) Create(ctx context.Context, workspace_id uint32, req *pb.SavePaymentRequest, actor *audit.Actor) (_ *pb.Payment, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.Create")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+
+	if _, err = ps.clients.Retrieve(ctx, workspace_id, req.ClientId); err == client.ErrIDNotFound {
+		return nil, ErrClientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Stmt(ps.insert_stmt).ExecContext(
+		ctx,
+		req.ClientId,
+		req.Amount,
+		req.Currency,
+		req.Date,
+		req.Method,
+		req.Reference,
+		req.Notes,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &pb.Payment{Id: uint32(id), ClientId: req.ClientId, Currency: req.Currency, WorkspaceId: workspace_id}
+	for _, allocation := range req.Allocations {
+		if _, err = ps.allocate(ctx, tx, payment, allocation.InvoiceId, decimal.RequireFromString(allocation.Amount), false, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	if payment, err = ps.retrieve(ctx, tx, workspace_id, uint32(id)); err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, payment.Id, audit.Diff(nil, auditFields(payment)))
+	if err = ps.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (ps *Payments) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.Payment, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.Retrieve")
+	defer end(&err)
+
+	payment, err := scanPayment(ps.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, ps.loadAllocations(ctx, ps.allocations_stmt, payment)
+}
+
+// ListByInvoice returns the payments allocated to the invoice, including
+// reversed ones, oldest first.
+func (ps *Payments) ListByInvoice(ctx context.Context, workspace_id uint32, invoice_id uint32) (_ []*pb.Payment, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.ListByInvoice")
+	defer end(&err)
+
+	if _, err = ps.invoices.Retrieve(ctx, workspace_id, invoice_id); err != nil {
+		return nil, err
+	}
+
+	return ps.list(ctx, ps.by_invoice_stmt, ps.allocations_stmt, workspace_id, invoice_id)
+}
+
+// ListByClient returns the payments of the client, including reversed ones,
+// oldest first.
+func (ps *Payments) ListByClient(ctx context.Context, workspace_id uint32, client_id uint32) (_ []*pb.Payment, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.ListByClient")
+	defer end(&err)
+
+	if _, err = ps.clients.Retrieve(ctx, workspace_id, client_id); err != nil {
+		return nil, err
+	}
+
+	return ps.list(ctx, ps.by_client_stmt, ps.allocations_stmt, workspace_id, client_id)
+}
+
+// credit sums the unallocated amounts of the payments that haven't been
+// reversed by currency.
+func credit(payments []*pb.Payment) map[string]decimal.Decimal {
+	balances := map[string]decimal.Decimal{}
+	for _, payment := range payments {
+		unallocated, err := decimal.NewFromString(payment.Unallocated)
+		if err != nil || payment.ReversedAt != 0 || !unallocated.IsPositive() {
+			continue
+		}
+		balances[payment.Currency] = balances[payment.Currency].Add(unallocated)
+	}
+	return balances
+}
+
+// Credit returns the credit balances of the client, one per currency it has
+// credit in.
+func (ps *Payments) Credit(ctx context.Context, workspace_id uint32, client_id uint32) (_ []*pb.CreditBalance, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.Credit")
+	defer end(&err)
+
+	payments, err := ps.ListByClient(ctx, workspace_id, client_id)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []*pb.CreditBalance{}
+	for currency, amount := range credit(payments) {
+		balances = append(balances, &pb.CreditBalance{Currency: currency, Amount: formatAmount(amount, currency)})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Currency < balances[j].Currency
+	})
+
+	return balances, nil
+}
+
+// ApplyCredit settles the invoice with credit of the client, taking it from
+// the oldest payments first. Without an amount, as much of the balance is
+// settled as the credit covers.
+func (ps *Payments) ApplyCredit(ctx context.Context, workspace_id uint32, client_id uint32, req *pb.ApplyCreditRequest, actor *audit.Actor) (_ *pb.Invoice, _ []*pb.PaymentAllocation, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.ApplyCredit")
+	defer end(&err)
+
+	fields := []apperr.FieldError{}
+	if req.InvoiceId == 0 {
+		fields = append(fields, apperr.Field("invoiceId", "is required"))
+	}
+	if len(fields) > 0 {
+		return nil, nil, apperr.Invalid("Credit can't be applied", fields...)
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	inv, err := ps.invoices.RetrieveTx(ctx, tx, workspace_id, req.InvoiceId)
+	if err == invoice.ErrIDNotFound {
+		return nil, nil, fmt.Errorf("%w: %d", ErrInvoiceNotFound, req.InvoiceId)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if inv.ClientId != client_id {
+		return nil, nil, fmt.Errorf("%w: %d", ErrOtherClient, inv.Id)
+	}
+
+	// Amounts are checked in the minor units of the invoice's currency.
+	var amount decimal.Decimal
+	all := strings.TrimSpace(req.Amount) == ""
+	if !all {
+		amount = checkAmount(&fields, "amount", &req.Amount, inv.Currency)
+	}
+	if len(fields) > 0 {
+		return nil, nil, apperr.Invalid("Credit can't be applied", fields...)
+	}
+
+	payments, err := ps.list(ctx, tx.Stmt(ps.by_client_stmt), tx.Stmt(ps.allocations_stmt), workspace_id, client_id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if all {
+		balance, err := decimal.NewFromString(inv.Balance)
+		if err != nil {
+			return nil, nil, err
+		}
+		amount = decimal.Min(balance, credit(payments)[inv.Currency])
+	}
+	if !amount.IsPositive() || amount.GreaterThan(credit(payments)[inv.Currency]) {
+		return nil, nil, fmt.Errorf("%w: %s %s available", ErrInsufficientCredit, formatAmount(credit(payments)[inv.Currency], inv.Currency), inv.Currency)
+	}
+
+	allocations := []*pb.PaymentAllocation{}
+	remaining := amount
+	for _, payment := range payments {
+		unallocated, err := decimal.NewFromString(payment.Unallocated)
+		if err != nil {
+			return nil, nil, err
+		}
+		if payment.ReversedAt != 0 || payment.Currency != inv.Currency || !unallocated.IsPositive() {
+			continue
+		}
+
+		take := decimal.Min(remaining, unallocated)
+		allocation, err := ps.allocate(ctx, tx, payment, inv.Id, take, true, actor)
+		if err != nil {
+			return nil, nil, err
+		}
+		allocations = append(allocations, allocation)
+
+		changes := audit.Diff(nil, map[string]string{"invoice_id": fmt.Sprint(inv.Id), "amount": allocation.Amount})
+		if err = ps.audit.Record(tx, actor.Entry(workspace_id, AUDIT_APPLY_CREDIT, AUDIT_TARGET, payment.Id, changes)); err != nil {
+			return nil, nil, err
+		}
+
+		if remaining = remaining.Sub(take); remaining.IsZero() {
+			break
+		}
+	}
+
+	if inv, err = ps.invoices.RetrieveTx(ctx, tx, workspace_id, inv.Id); err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return inv, allocations, nil
+}
+
+// Release takes amount of what the payments allocate to the invoice back,
+// from the newest payment first, once a credit note leaves it overpaid. The
+// payments keep their allocations and get one of the negated amount, which
+// leaves it as credit of the client.
+func (ps *Payments) Release(ctx context.Context, tx *sql.Tx, inv *pb.Invoice, amount decimal.Decimal, actor *audit.Actor) error {
+	rows, err := tx.Stmt(ps.invoice_paid_stmt).QueryContext(ctx, inv.Id)
+	if err != nil {
+		return err
+	}
+
+	order := []uint32{}
+	allocated := map[uint32]decimal.Decimal{}
+	for rows.Next() {
+		var payment_id uint32
+		var value string
+		if err = rows.Scan(&payment_id, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		allocation, err := decimal.NewFromString(value)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if _, ok := allocated[payment_id]; !ok {
+			order = append(order, payment_id)
+		}
+		allocated[payment_id] = allocated[payment_id].Add(allocation)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	remaining := amount
+	for _, payment_id := range order {
+		take := decimal.Min(remaining, allocated[payment_id])
+		if !take.IsPositive() {
+			continue
+		}
+
+		released := formatAmount(take.Neg(), inv.Currency)
+		_, err = tx.Stmt(ps.insert_allocation_stmt).ExecContext(ctx, payment_id, inv.Id, released, false, time.Now().Unix(), helpers.NullableId(actor.UserId))
+		if err != nil {
+			return err
+		}
+
+		changes := audit.Diff(nil, map[string]string{"invoice_id": fmt.Sprint(inv.Id), "amount": released})
+		if err = ps.audit.Record(tx, actor.Entry(inv.WorkspaceId, AUDIT_RELEASE, AUDIT_TARGET, payment_id, changes)); err != nil {
+			return err
+		}
+
+		if remaining = remaining.Sub(take); remaining.IsZero() {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s of %s %s isn't paid", ErrExceedsBalance, formatAmount(remaining, inv.Currency), formatAmount(amount, inv.Currency), inv.Currency)
+}
+
+// Reverse takes back all allocations of the payment, the invoices it paid
+// move back to partially paid or unpaid, and its credit is gone.
+func (ps *Payments) Reverse(ctx context.Context, workspace_id uint32, id uint32, reason string, actor *audit.Actor) (_ *pb.Payment, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Payments.Reverse")
+	defer end(&err)
+
+	if len(reason) > MAX_REASON_BYTES {
+		return nil, apperr.Invalid("Reason is too long", apperr.Field("reason", fmt.Sprintf("must be at most %d bytes", MAX_REASON_BYTES)))
+	}
+
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	payment, err := ps.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.ReversedAt != 0 {
+		return nil, ErrReversed
+	}
+
+	_, err = tx.Stmt(ps.reverse_stmt).ExecContext(ctx, time.Now().Unix(), helpers.NullableId(actor.UserId), reason, id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	settled := map[uint32]bool{}
+	for _, allocation := range payment.Allocations {
+		if settled[allocation.InvoiceId] {
+			continue
+		}
+		settled[allocation.InvoiceId] = true
+
+		inv, err := ps.invoices.RetrieveTx(ctx, tx, workspace_id, allocation.InvoiceId)
+		if err != nil {
+			return nil, err
+		}
+		if err = ps.settle(ctx, tx, inv, fmt.Sprintf("payment %d reversed", id), actor); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := audit.Diff(nil, map[string]string{"reversal_reason": reason})
+	if err = ps.audit.Record(tx, actor.Entry(workspace_id, AUDIT_REVERSE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if payment, err = ps.retrieve(ctx, tx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (ps *Payments) Close() error {
+	stmts := []*sql.Stmt{
+		ps.insert_stmt,
+		ps.retrieve_stmt,
+		ps.by_invoice_stmt,
+		ps.by_client_stmt,
+		ps.reverse_stmt,
+		ps.allocations_stmt,
+		ps.insert_allocation_stmt,
+		ps.paid_stmt,
+		ps.invoice_paid_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const PAYMENT_COLUMNS = `
+	payment_id,
+	payment_client_id,
+	payment_amount,
+	payment_currency,
+	payment_date,
+	payment_method,
+	payment_reference,
+	payment_notes,
+	COALESCE(payment_reversed_at, 0),
+	COALESCE(payment_reversed_by, 0),
+	payment_reversal_reason,
+	payment_created_at,
+	COALESCE(payment_created_by, 0),
+	payment_workspace_id
+`
+
+func NewPayments(db *sql.DB, audit_log *audit.Log, cs *client.Clients, is *invoice.Invoices) (*Payments, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO payments (
+			payment_client_id,
+			payment_amount,
+			payment_currency,
+			payment_date,
+			payment_method,
+			payment_reference,
+			payment_notes,
+			payment_created_at,
+			payment_created_by,
+			payment_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + PAYMENT_COLUMNS + `
+		FROM payments
+		WHERE payment_id = ? AND payment_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	by_invoice_stmt, err := db.Prepare(`
+		SELECT ` + PAYMENT_COLUMNS + `
+		FROM payments
+		WHERE payment_workspace_id = ? AND payment_id IN (
+			SELECT payment_allocation_payment_id FROM payment_allocations WHERE payment_allocation_invoice_id = ?
+		)
+		ORDER BY payment_date, payment_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	by_client_stmt, err := db.Prepare(`
+		SELECT ` + PAYMENT_COLUMNS + `
+		FROM payments
+		WHERE payment_workspace_id = ? AND payment_client_id = ?
+		ORDER BY payment_date, payment_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	reverse_stmt, err := db.Prepare(`
+		UPDATE payments
+		SET payment_reversed_at = ?,
+			payment_reversed_by = ?,
+			payment_reversal_reason = ?
+		WHERE payment_id = ? AND payment_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations_stmt, err := db.Prepare(`
+		SELECT
+			payment_allocation_id,
+			payment_allocation_payment_id,
+			payment_allocation_invoice_id,
+			payment_allocation_amount,
+			payment_allocation_from_credit,
+			payment_allocation_created_at,
+			COALESCE(payment_allocation_created_by, 0)
+		FROM payment_allocations
+		WHERE payment_allocation_payment_id = ?
+		ORDER BY payment_allocation_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_allocation_stmt, err := db.Prepare(`
+		INSERT INTO payment_allocations (
+			payment_allocation_payment_id,
+			payment_allocation_invoice_id,
+			payment_allocation_amount,
+			payment_allocation_from_credit,
+			payment_allocation_created_at,
+			payment_allocation_created_by
+		) VALUES(?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	paid_stmt, err := db.Prepare(`
+		SELECT payment_allocation_amount
+		FROM payment_allocations
+		JOIN payments ON payment_id = payment_allocation_payment_id
+		WHERE payment_allocation_invoice_id = ? AND payment_reversed_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice_paid_stmt, err := db.Prepare(`
+		SELECT payment_allocation_payment_id, payment_allocation_amount
+		FROM payment_allocations
+		JOIN payments ON payment_id = payment_allocation_payment_id
+		WHERE payment_allocation_invoice_id = ? AND payment_reversed_at IS NULL
+		ORDER BY payment_date DESC, payment_id DESC, payment_allocation_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Payments{
+		db:                     db,
+		audit:                  audit_log,
+		clients:                cs,
+		invoices:               is,
+		insert_stmt:            insert_stmt,
+		retrieve_stmt:          retrieve_stmt,
+		by_invoice_stmt:        by_invoice_stmt,
+		by_client_stmt:         by_client_stmt,
+		reverse_stmt:           reverse_stmt,
+		allocations_stmt:       allocations_stmt,
+		insert_allocation_stmt: insert_allocation_stmt,
+		paid_stmt:              paid_stmt,
+		invoice_paid_stmt:      invoice_paid_stmt,
+	}, nil
+}
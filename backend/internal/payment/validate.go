@@ -0,0 +1,124 @@
+package payment
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	METHOD_BANK_TRANSFER = "bank_transfer"
+	METHOD_CARD          = "card"
+	METHOD_CASH          = "cash"
+	METHOD_CHEQUE        = "cheque"
+	METHOD_DIRECT_DEBIT  = "direct_debit"
+	METHOD_PAYPAL        = "paypal"
+	METHOD_OTHER         = "other"
+
+	MAX_ALLOCATIONS      = 100
+	MAX_REFERENCE_LENGTH = 256
+	MAX_NOTES_BYTES      = 10_000
+)
+
+var Methods = []string{
+	METHOD_BANK_TRANSFER,
+	METHOD_CARD,
+	METHOD_CASH,
+	METHOD_CHEQUE,
+	METHOD_DIRECT_DEBIT,
+	METHOD_PAYPAL,
+	METHOD_OTHER,
+}
+
+func formatAmount(amount decimal.Decimal, currency string) string {
+	return money.String(amount, currency)
+}
+
+// checkAmount parses a positive amount in the minor units of the currency
+// and normalizes it, payments can't be split into fractions of a cent like
+// invoice totals can't.
+func checkAmount(fields *[]apperr.FieldError, field string, value *string, currency string) decimal.Decimal {
+	amount, err := decimal.NewFromString(strings.TrimSpace(*value))
+	switch {
+	case err != nil:
+		*fields = append(*fields, apperr.Field(field, "must be a decimal number"))
+		return decimal.Zero
+	case !amount.IsPositive():
+		*fields = append(*fields, apperr.Field(field, "must be greater than zero"))
+	case !amount.Equal(money.Round(amount, currency)) && money.Scale(currency) == 0:
+		*fields = append(*fields, apperr.Field(field, "must be a whole number of "+currency))
+	case !amount.Equal(money.Round(amount, currency)):
+		*fields = append(*fields, apperr.Field(field, fmt.Sprintf("must not have more than %d decimal places in %s", money.Scale(currency), currency)))
+	}
+	*value = formatAmount(amount, currency)
+	return amount
+}
+
+// Validate checks the request and normalizes it in place.
+func Validate(req *pb.SavePaymentRequest) error {
+	fields := []apperr.FieldError{}
+
+	if req.ClientId == 0 {
+		fields = append(fields, apperr.Field("clientId", "is required"))
+	}
+
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !money.Valid(req.Currency) {
+		fields = append(fields, apperr.Field("currency", "must be an ISO 4217 code"))
+	}
+
+	amount := checkAmount(&fields, "amount", &req.Amount, req.Currency)
+
+	if req.Date == "" {
+		req.Date = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, req.Date); err != nil {
+		fields = append(fields, apperr.Field("date", "must be a date formatted as YYYY-MM-DD"))
+	}
+
+	if !slices.Contains(Methods, req.Method) {
+		fields = append(fields, apperr.Field("method", "must be one of "+strings.Join(Methods, ", ")))
+	}
+
+	if req.Reference = strings.TrimSpace(req.Reference); len(req.Reference) > MAX_REFERENCE_LENGTH {
+		fields = append(fields, apperr.Field("reference", fmt.Sprintf("must not be longer than %d characters", MAX_REFERENCE_LENGTH)))
+	}
+	if len(req.Notes) > MAX_NOTES_BYTES {
+		fields = append(fields, apperr.Field("notes", fmt.Sprintf("must not be longer than %d bytes", MAX_NOTES_BYTES)))
+	}
+
+	if len(req.Allocations) > MAX_ALLOCATIONS {
+		fields = append(fields, apperr.Field("allocations", fmt.Sprintf("must not be more than %d", MAX_ALLOCATIONS)))
+	}
+
+	allocated := decimal.Zero
+	invoices := map[uint32]bool{}
+	for i, allocation := range req.Allocations {
+		field := fmt.Sprintf("allocations[%d]", i)
+		if allocation == nil {
+			fields = append(fields, apperr.Field(field, "must not be null"))
+			continue
+		}
+
+		if invoices[allocation.InvoiceId] {
+			fields = append(fields, apperr.Field(field+".invoiceId", "must not repeat"))
+		}
+		invoices[allocation.InvoiceId] = true
+
+		allocated = allocated.Add(checkAmount(&fields, field+".amount", &allocation.Amount, req.Currency))
+	}
+	if amount.IsPositive() && allocated.GreaterThan(amount) {
+		fields = append(fields, apperr.Field("allocations", "must not add up to more than the amount"))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Payment is invalid", fields...)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package payment
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func validRequest() *pb.SavePaymentRequest {
+	return &pb.SavePaymentRequest{
+		ClientId: 1,
+		Amount:   "100",
+		Currency: "EUR",
+		Date:     "2026-03-07",
+		Method:   METHOD_BANK_TRANSFER,
+		Allocations: []*pb.AllocationRequest{
+			{InvoiceId: 1, Amount: "60"},
+			{InvoiceId: 2, Amount: "40.00"},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(req *pb.SavePaymentRequest)
+		// Fields reported as invalid, none if the request is valid.
+		fields []string
+	}{
+		{name: "valid", change: func(req *pb.SavePaymentRequest) {}},
+		{name: "nothing allocated", change: func(req *pb.SavePaymentRequest) { req.Allocations = nil }},
+		{name: "part of it allocated", change: func(req *pb.SavePaymentRequest) { req.Allocations = req.Allocations[:1] }},
+		{name: "no client", change: func(req *pb.SavePaymentRequest) { req.ClientId = 0 }, fields: []string{"clientId"}},
+		{name: "unknown currency", change: func(req *pb.SavePaymentRequest) { req.Currency = "EURO" }, fields: []string{"currency"}},
+		{name: "amount isn't a number", change: func(req *pb.SavePaymentRequest) { req.Amount = "100 EUR"; req.Allocations = nil }, fields: []string{"amount"}},
+		{name: "zero amount", change: func(req *pb.SavePaymentRequest) { req.Amount = "0"; req.Allocations = nil }, fields: []string{"amount"}},
+		{name: "negative amount", change: func(req *pb.SavePaymentRequest) { req.Amount = "-5"; req.Allocations = nil }, fields: []string{"amount"}},
+		{name: "fractions of a cent", change: func(req *pb.SavePaymentRequest) { req.Amount = "100.001" }, fields: []string{"amount"}},
+		{name: "fractions of a yen", change: func(req *pb.SavePaymentRequest) {
+			req.Currency = "JPY"
+			req.Amount = "100.5"
+			req.Allocations = nil
+		}, fields: []string{"amount"}},
+		{name: "invalid date", change: func(req *pb.SavePaymentRequest) { req.Date = "07.03.2026" }, fields: []string{"date"}},
+		{name: "unknown method", change: func(req *pb.SavePaymentRequest) { req.Method = "barter" }, fields: []string{"method"}},
+		{name: "reference too long", change: func(req *pb.SavePaymentRequest) { req.Reference = strings.Repeat("x", MAX_REFERENCE_LENGTH+1) }, fields: []string{"reference"}},
+		{name: "notes too long", change: func(req *pb.SavePaymentRequest) { req.Notes = strings.Repeat("x", MAX_NOTES_BYTES+1) }, fields: []string{"notes"}},
+		{name: "null allocation", change: func(req *pb.SavePaymentRequest) { req.Allocations[1] = nil }, fields: []string{"allocations[1]"}},
+		{name: "invoice allocated twice", change: func(req *pb.SavePaymentRequest) { req.Allocations[1].InvoiceId = 1 }, fields: []string{"allocations[1].invoiceId"}},
+		{name: "allocation isn't positive", change: func(req *pb.SavePaymentRequest) { req.Allocations[1].Amount = "0" }, fields: []string{"allocations[1].amount"}},
+		{name: "more allocated than paid", change: func(req *pb.SavePaymentRequest) { req.Allocations[1].Amount = "40.01" }, fields: []string{"allocations"}},
+		{name: "too many allocations", change: func(req *pb.SavePaymentRequest) {
+			req.Allocations = make([]*pb.AllocationRequest, MAX_ALLOCATIONS+1)
+			for i := range req.Allocations {
+				req.Allocations[i] = &pb.AllocationRequest{InvoiceId: uint32(i + 1), Amount: "0.01"}
+			}
+			req.Amount = "1.01"
+		}, fields: []string{"allocations"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := validRequest()
+			test.change(req)
+
+			err := Validate(req)
+			if len(test.fields) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+
+			var app_err *apperr.Error
+			if !errors.As(err, &app_err) {
+				t.Fatalf("Validate() error = %v, want a field error", err)
+			}
+			fields := []string{}
+			for _, field := range app_err.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
+
+func TestValidateNormalizes(t *testing.T) {
+	req := validRequest()
+	req.Currency = " eur "
+	req.Amount = " 100.5 "
+	req.Date = ""
+	req.Reference = "  RF18 5390 0754 7034  "
+	req.Allocations = []*pb.AllocationRequest{{InvoiceId: 1, Amount: "60.5"}}
+
+	if err := Validate(req); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	if req.Currency != "EUR" {
+		t.Errorf("currency = %q, want EUR", req.Currency)
+	}
+	if req.Amount != "100.50" {
+		t.Errorf("amount = %q, want 100.50", req.Amount)
+	}
+	if req.Allocations[0].Amount != "60.50" {
+		t.Errorf("allocated amount = %q, want 60.50", req.Allocations[0].Amount)
+	}
+	if req.Date == "" {
+		t.Error("date is empty, want today")
+	}
+	if req.Reference != "RF18 5390 0754 7034" {
+		t.Errorf("reference = %q, want it trimmed", req.Reference)
+	}
+}
@@ -0,0 +1,234 @@
+package pdfcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	"io"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Reasons of a Rejection, meant for the frontend to tell them apart.
+const (
+	REASON_NOT_PDF          = "pdf_not_pdf"
+	REASON_TOO_LARGE        = "pdf_too_large"
+	REASON_CORRUPTED        = "pdf_corrupted"
+	REASON_ENCRYPTED        = "pdf_encrypted"
+	REASON_TOO_MANY_PAGES   = "pdf_too_many_pages"
+	REASON_PAGE_TOO_LARGE   = "pdf_page_too_large"
+	REASON_TOO_MANY_OBJECTS = "pdf_too_many_objects"
+	REASON_JAVASCRIPT       = "pdf_javascript"
+	REASON_LAUNCH_ACTION    = "pdf_launch_action"
+)
+
+// HEADER_WINDOW is how far into the file the %PDF- header may start, as
+// readers tolerate leading garbage.
+const HEADER_WINDOW = 1024
+
+var PDF_HEADER = []byte("%PDF-")
+
+// Limits bound what an uploaded PDF may contain. Page sizes are in points.
+type Limits struct {
+	MaxBytes      int64
+	MaxPages      int64
+	MaxPageWidth  int64
+	MaxPageHeight int64
+	MaxObjects    int64
+}
+
+var DefaultLimits = Limits{
+	MaxBytes:      10 << 20,
+	MaxPages:      50,
+	MaxPageWidth:  5000,
+	MaxPageHeight: 5000,
+	MaxObjects:    20000,
+}
+
+// LimitsFromEnv reads the limits from INVOICER_PDF_* variables, falling back
+// to DefaultLimits.
+func LimitsFromEnv() Limits {
+	return Limits{
+		MaxBytes:      helpers.EnvInt("INVOICER_PDF_MAX_BYTES", DefaultLimits.MaxBytes),
+		MaxPages:      helpers.EnvInt("INVOICER_PDF_MAX_PAGES", DefaultLimits.MaxPages),
+		MaxPageWidth:  helpers.EnvInt("INVOICER_PDF_MAX_PAGE_WIDTH", DefaultLimits.MaxPageWidth),
+		MaxPageHeight: helpers.EnvInt("INVOICER_PDF_MAX_PAGE_HEIGHT", DefaultLimits.MaxPageHeight),
+		MaxObjects:    helpers.EnvInt("INVOICER_PDF_MAX_OBJECTS", DefaultLimits.MaxObjects),
+	}
+}
+
+// Rejection is the error returned for PDFs that must not be converted.
+type Rejection struct {
+	Reason string
+	Detail string
+}
+
+func (r *Rejection) Error() string {
+	return r.Detail
+}
+
+func (r *Rejection) AppError() *apperr.Error {
+	code := apperr.CODE_INVALID_ARGUMENT
+	if r.Reason == REASON_TOO_LARGE {
+		code = apperr.CODE_PAYLOAD_TOO_LARGE
+	}
+
+	return &apperr.Error{
+		Code:       code,
+		Message:    r.Detail,
+		Fields:     []apperr.FieldError{apperr.Field("file", r.Detail)},
+		Extensions: map[string]string{"reason": r.Reason},
+	}
+}
+
+func reject(reason, format string, args ...any) *Rejection {
+	return &Rejection{Reason: reason, Detail: fmt.Sprintf(format, args...)}
+}
+
+func init() {
+	// pdfcpu would otherwise create a configuration directory in $HOME.
+	api.DisableConfigDir()
+}
+
+// SniffHeader checks the magic bytes, before anything is written to disk.
+// It returns a reader yielding the whole file again.
+func SniffHeader(file io.Reader) (io.Reader, error) {
+	head := make([]byte, HEADER_WINDOW)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	if !bytes.Contains(head, PDF_HEADER) {
+		return nil, reject(REASON_NOT_PDF, "file is not a PDF")
+	}
+
+	return io.MultiReader(bytes.NewReader(head), file), nil
+}
+
+// CopyLimited writes the file to dst, rejecting it as soon as it exceeds
+// the size limit.
+func CopyLimited(dst io.Writer, file io.Reader, limits Limits) error {
+	n, err := io.Copy(dst, io.LimitReader(file, limits.MaxBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > limits.MaxBytes {
+		return reject(REASON_TOO_LARGE, "file is larger than %d bytes", limits.MaxBytes)
+	}
+	return nil
+}
+
+// Validate parses the structure of the PDF at path and rejects files that
+// are encrypted, corrupted, over the limits or contain active content.
+func Validate(path string, limits Limits) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := api.ReadContext(f, conf)
+	if errors.Is(err, pdfcpu.ErrWrongPassword) {
+		return reject(REASON_ENCRYPTED, "PDF is password protected")
+	}
+	if err != nil {
+		return reject(REASON_CORRUPTED, "PDF is corrupted: %s", err)
+	}
+
+	if ctx.Encrypt != nil {
+		return reject(REASON_ENCRYPTED, "PDF is encrypted")
+	}
+
+	if int64(len(ctx.Table)) > limits.MaxObjects {
+		return reject(REASON_TOO_MANY_OBJECTS, "PDF has %d objects, at most %d are allowed", len(ctx.Table), limits.MaxObjects)
+	}
+
+	if err = api.ValidateContext(ctx); err != nil {
+		return reject(REASON_CORRUPTED, "PDF is corrupted: %s", err)
+	}
+
+	if err = ctx.EnsurePageCount(); err != nil {
+		return reject(REASON_CORRUPTED, "PDF page tree is corrupted: %s", err)
+	}
+	if ctx.PageCount == 0 {
+		return reject(REASON_CORRUPTED, "PDF has no pages")
+	}
+	if int64(ctx.PageCount) > limits.MaxPages {
+		return reject(REASON_TOO_MANY_PAGES, "PDF has %d pages, at most %d are allowed", ctx.PageCount, limits.MaxPages)
+	}
+
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return reject(REASON_CORRUPTED, "PDF page sizes are corrupted: %s", err)
+	}
+	for i, dim := range dims {
+		if dim.Width > float64(limits.MaxPageWidth) || dim.Height > float64(limits.MaxPageHeight) {
+			return reject(
+				REASON_PAGE_TOO_LARGE,
+				"page %d is %.0fx%.0f points, at most %dx%d are allowed",
+				i+1, dim.Width, dim.Height, limits.MaxPageWidth, limits.MaxPageHeight,
+			)
+		}
+	}
+
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		if err = checkActions(entry.Object); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkActions looks for JavaScript and launch actions anywhere in the
+// object, including the document's JavaScript name tree.
+func checkActions(obj types.Object) error {
+	switch o := obj.(type) {
+	case types.StreamDict:
+		return checkActions(o.Dict)
+
+	case types.Dict:
+		if s, ok := o["S"].(types.Name); ok {
+			switch s {
+			case "JavaScript":
+				return reject(REASON_JAVASCRIPT, "PDF contains JavaScript")
+			case "Launch":
+				return reject(REASON_LAUNCH_ACTION, "PDF contains a launch action")
+			}
+		}
+		if _, ok := o["JS"]; ok {
+			return reject(REASON_JAVASCRIPT, "PDF contains JavaScript")
+		}
+		if _, ok := o["JavaScript"]; ok {
+			return reject(REASON_JAVASCRIPT, "PDF contains JavaScript")
+		}
+		for _, value := range o {
+			if err := checkActions(value); err != nil {
+				return err
+			}
+		}
+
+	case types.Array:
+		for _, value := range o {
+			if err := checkActions(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package pdfcheck
+
+import (
+	"bytes"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkRejection fails unless err is a rejection for the reason, which is
+// reported with the status naming the reason.
+func checkRejection(t *testing.T, err error, reason string, status int) {
+	t.Helper()
+
+	var rejection *Rejection
+	if !errors.As(err, &rejection) {
+		t.Fatalf("error = %v, want a %s rejection", err, reason)
+	}
+	if rejection.Reason != reason {
+		t.Errorf("rejected for %s (%s), want %s", rejection.Reason, rejection.Detail, reason)
+	}
+
+	app_err := apperr.From(err)
+	if got := app_err.Code.HttpStatus(); got != status {
+		t.Errorf("rejection is reported as %d, want %d", got, status)
+	}
+	if app_err.Extensions["reason"] != reason {
+		t.Errorf("rejection is reported with reason %q, want %q", app_err.Extensions["reason"], reason)
+	}
+}
+
+func TestSniffHeader(t *testing.T) {
+	valid, err := os.ReadFile(filepath.Join("testdata", "valid.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		file []byte
+		want string
+	}{
+		{"PDF", valid, ""},
+		{"PDF after leading garbage", append(bytes.Repeat([]byte{0}, HEADER_WINDOW-len(PDF_HEADER)), valid...), ""},
+		{"text", []byte("Invoice 2026-0001\nTotal: 119.00 EUR\n"), REASON_NOT_PDF},
+		{"empty", nil, REASON_NOT_PDF},
+		{"header past the window", append(bytes.Repeat([]byte{0}, HEADER_WINDOW), valid...), REASON_NOT_PDF},
+		{"PNG", []byte("\x89PNG\r\n\x1a\n"), REASON_NOT_PDF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := SniffHeader(bytes.NewReader(test.file))
+			if test.want != "" {
+				checkRejection(t, err, test.want, http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				t.Fatalf("SniffHeader() error = %v", err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.file) {
+				t.Errorf("SniffHeader() yields %d bytes, want the %d of the file", len(got), len(test.file))
+			}
+		})
+	}
+}
+
+func TestCopyLimited(t *testing.T) {
+	limits := Limits{MaxBytes: 16}
+
+	var dst bytes.Buffer
+	if err := CopyLimited(&dst, strings.NewReader(strings.Repeat("x", 16)), limits); err != nil || dst.Len() != 16 {
+		t.Errorf("CopyLimited() of the limit wrote %d bytes, error = %v", dst.Len(), err)
+	}
+
+	dst.Reset()
+	err := CopyLimited(&dst, strings.NewReader(strings.Repeat("x", 1<<20)), limits)
+	checkRejection(t, err, REASON_TOO_LARGE, http.StatusRequestEntityTooLarge)
+	if dst.Len() > 17 {
+		t.Errorf("CopyLimited() wrote %d bytes of a file over the limit", dst.Len())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	limited := func(change func(*Limits)) Limits {
+		limits := DefaultLimits
+		change(&limits)
+		return limits
+	}
+
+	tests := []struct {
+		file   string
+		limits Limits
+		want   string
+	}{
+		{"valid.pdf", DefaultLimits, ""},
+		{"three_pages.pdf", DefaultLimits, ""},
+		{"three_pages.pdf", limited(func(l *Limits) { l.MaxPages = 2 }), REASON_TOO_MANY_PAGES},
+		{"large_page.pdf", DefaultLimits, REASON_PAGE_TOO_LARGE},
+		{"large_page.pdf", limited(func(l *Limits) { l.MaxPageWidth = 6000 }), ""},
+		{"valid.pdf", limited(func(l *Limits) { l.MaxObjects = 2 }), REASON_TOO_MANY_OBJECTS},
+		{"encrypted.pdf", DefaultLimits, REASON_ENCRYPTED},
+		{"javascript.pdf", DefaultLimits, REASON_JAVASCRIPT},
+		{"javascript_names.pdf", DefaultLimits, REASON_JAVASCRIPT},
+		{"launch.pdf", DefaultLimits, REASON_LAUNCH_ACTION},
+		{"no_pages.pdf", DefaultLimits, REASON_CORRUPTED},
+		{"corrupted.pdf", DefaultLimits, REASON_CORRUPTED},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			err := Validate(filepath.Join("testdata", test.file), test.limits)
+			if test.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			checkRejection(t, err, test.want, http.StatusBadRequest)
+		})
+	}
+}
@@ -27,6 +27,6 @@ func (ps *PingServer) Ping(
 	return res, nil
 }
 
-func PingServiceHandler() (string, http.Handler) {
-	return pbconnect.NewPingServiceHandler(&PingServer{})
+func PingServiceHandler(opts ...connect.HandlerOption) (string, http.Handler) {
+	return pbconnect.NewPingServiceHandler(&PingServer{}, opts...)
 }
@@ -0,0 +1,71 @@
+package purge
+
+import (
+	"context"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/template"
+	"invoice-manager/main/internal/user"
+	"log"
+	"time"
+)
+
+const (
+	DEFAULT_INTERVAL     = time.Hour
+	DEFAULT_MAX_TEMP_AGE = time.Hour
+)
+
+// Purger periodically removes expired sessions and uploads left behind by
+// interrupted conversions. Orphaned files are left to the gc command, as
+// running conversions write theirs before the template is inserted.
+type Purger struct {
+	users        *user.Users
+	interval     time.Duration
+	max_temp_age time.Duration
+}
+
+func NewPurger(us *user.Users, interval time.Duration, max_temp_age time.Duration) *Purger {
+	return &Purger{users: us, interval: interval, max_temp_age: max_temp_age}
+}
+
+// PurgerFromEnv reads the interval in seconds from INVOICER_PURGE_INTERVAL.
+func PurgerFromEnv(us *user.Users) *Purger {
+	interval := helpers.EnvInt("INVOICER_PURGE_INTERVAL", int64(DEFAULT_INTERVAL/time.Second))
+	return NewPurger(us, time.Duration(interval)*time.Second, DEFAULT_MAX_TEMP_AGE)
+}
+
+func (p *Purger) purge() {
+	expired, err := p.users.DeleteExpiredSessions()
+	if err != nil {
+		log.Println("Error purging sessions:", err)
+	}
+
+	report, err := template.RemoveStaleTempFiles(p.max_temp_age)
+	if err != nil {
+		log.Println("Error purging temporary uploads:", err)
+		return
+	}
+
+	if expired > 0 || len(report.StaleTempFiles) > 0 {
+		log.Printf("Purged %d expired sessions and %d stale uploads", expired, len(report.StaleTempFiles))
+	}
+}
+
+// Run purges once right away and then every interval, until ctx is done.
+func (p *Purger) Run(ctx context.Context) error {
+	if p.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
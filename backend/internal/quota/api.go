@@ -0,0 +1,37 @@
+package quota
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/ratelimit"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+type UsageApi struct {
+	quotas  *Quotas
+	limiter *ratelimit.Limiter
+}
+
+// GetUsage shows the consumption of the current workspace and user against
+// their quotas and the rate limit bucket of the caller.
+func (ua *UsageApi) GetUsage(w http.ResponseWriter, req *http.Request) {
+	usage, err := ua.quotas.Usage(workspace.IdFromContext(req.Context()), user.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading usage"))
+		return
+	}
+
+	res := &pb.GetUsageResponse{Quotas: usage}
+	if ua.limiter.Enabled() {
+		res.RateLimit = ua.limiter.Peek(ratelimit.KeyOf(req)).Proto()
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, res)
+}
+
+func NewUsageApi(qs *Quotas, l *ratelimit.Limiter) *UsageApi {
+	return &UsageApi{quotas: qs, limiter: l}
+}
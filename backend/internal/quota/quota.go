@@ -0,0 +1,374 @@
+package quota
+
+import (
+	"database/sql"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	pb "invoice-manager/main/proto"
+	"os"
+	"time"
+)
+
+const (
+	QUOTA_TEMPLATES     = "templates"
+	QUOTA_STORAGE_BYTES = "storage_bytes"
+	QUOTA_CONVERSIONS   = "conversions_per_hour"
+	QUOTA_RENDERS       = "renders_per_month"
+
+	QUOTA_USER_CONVERSIONS = "user_conversions_per_hour"
+	QUOTA_USER_RENDERS     = "user_renders_per_month"
+
+	KIND_CONVERSION = "conversion"
+	KIND_RENDER     = "render"
+)
+
+// Limits are per workspace, the User ones per user across the workspaces
+// they are a member of. 0 means unlimited.
+type Limits struct {
+	MaxTemplates       int64
+	MaxStoredBytes     int64
+	ConversionsPerHour int64
+	RendersPerMonth    int64
+
+	UserConversionsPerHour int64
+	UserRendersPerMonth    int64
+}
+
+var DefaultLimits = Limits{
+	MaxTemplates:       500,
+	MaxStoredBytes:     1 << 30,
+	ConversionsPerHour: 30,
+	RendersPerMonth:    1000,
+
+	UserConversionsPerHour: 10,
+	UserRendersPerMonth:    500,
+}
+
+// LimitsFromEnv reads the limits from INVOICER_QUOTA_* variables, falling
+// back to DefaultLimits.
+func LimitsFromEnv() Limits {
+	return Limits{
+		MaxTemplates:       helpers.EnvInt("INVOICER_QUOTA_TEMPLATES", DefaultLimits.MaxTemplates),
+		MaxStoredBytes:     helpers.EnvInt("INVOICER_QUOTA_STORAGE_BYTES", DefaultLimits.MaxStoredBytes),
+		ConversionsPerHour: helpers.EnvInt("INVOICER_QUOTA_CONVERSIONS_PER_HOUR", DefaultLimits.ConversionsPerHour),
+		RendersPerMonth:    helpers.EnvInt("INVOICER_QUOTA_RENDERS_PER_MONTH", DefaultLimits.RendersPerMonth),
+
+		UserConversionsPerHour: helpers.EnvInt("INVOICER_QUOTA_USER_CONVERSIONS_PER_HOUR", DefaultLimits.UserConversionsPerHour),
+		UserRendersPerMonth:    helpers.EnvInt("INVOICER_QUOTA_USER_RENDERS_PER_MONTH", DefaultLimits.UserRendersPerMonth),
+	}
+}
+
+// Exceeded is the error returned when an action would go over a quota.
+type Exceeded struct {
+	Quota      string
+	Limit      int64
+	Used       int64
+	RetryAfter time.Duration
+}
+
+func (e *Exceeded) Error() string {
+	return fmt.Sprintf("%s quota of %d is used up", e.Quota, e.Limit)
+}
+
+func (e *Exceeded) AppError() *apperr.Error {
+	return &apperr.Error{
+		Code:    apperr.CODE_RESOURCE_EXHAUSTED,
+		Message: e.Error(),
+		Extensions: map[string]string{
+			"quota": e.Quota,
+			"limit": fmt.Sprint(e.Limit),
+			"used":  fmt.Sprint(e.Used),
+		},
+		RetryAfter: e.RetryAfter,
+	}
+}
+
+type Quotas struct {
+	db     *sql.DB
+	limits Limits
+
+	count_templates_stmt, template_files_stmt, record_stmt *sql.Stmt
+	count_events_stmt, oldest_event_stmt                   *sql.Stmt
+	count_user_events_stmt, oldest_user_event_stmt         *sql.Stmt
+}
+
+func (q *Quotas) Limits() Limits {
+	return q.limits
+}
+
+func hourWindow(now time.Time) time.Time {
+	return now.Add(-time.Hour)
+}
+
+func monthWindow(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (q *Quotas) TemplateCount(workspace_id uint32) (int64, error) {
+	var count int64
+	err := q.count_templates_stmt.QueryRow(workspace_id).Scan(&count)
+	return count, err
+}
+
+// StoredBytes sums up the files of the workspace's templates as they are
+// on disk.
+func (q *Quotas) StoredBytes(workspace_id uint32) (int64, error) {
+	rows, err := q.template_files_stmt.Query(workspace_id)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total int64
+	for rows.Next() {
+		var template_path, thumbnail_path string
+		if err = rows.Scan(&template_path, &thumbnail_path); err != nil {
+			return 0, err
+		}
+
+		for _, path := range []string{template_path, thumbnail_path} {
+			if info, err := os.Stat(path); err == nil {
+				total += info.Size()
+			}
+		}
+	}
+
+	return total, rows.Err()
+}
+
+// window returns when the window of events of the kind that count against
+// their quotas started.
+func window(kind string, now time.Time) time.Time {
+	if kind == KIND_CONVERSION {
+		return hourWindow(now)
+	}
+	return monthWindow(now)
+}
+
+// eventLimit is a quota on the usage events of a kind, of a workspace or a
+// user depending on the statements counting them.
+type eventLimit struct {
+	quota         string
+	limit         int64
+	id            uint32
+	count, oldest *sql.Stmt
+}
+
+// eventLimits returns the quotas of the kind the workspace and the user
+// are held to. Users are only limited when the action is theirs.
+func (q *Quotas) eventLimits(kind string, workspace_id uint32, user_id uint32) []eventLimit {
+	limits := []eventLimit{}
+	switch kind {
+	case KIND_CONVERSION:
+		limits = append(limits, eventLimit{QUOTA_CONVERSIONS, q.limits.ConversionsPerHour, workspace_id, q.count_events_stmt, q.oldest_event_stmt})
+		if user_id != 0 {
+			limits = append(limits, eventLimit{QUOTA_USER_CONVERSIONS, q.limits.UserConversionsPerHour, user_id, q.count_user_events_stmt, q.oldest_user_event_stmt})
+		}
+	case KIND_RENDER:
+		limits = append(limits, eventLimit{QUOTA_RENDERS, q.limits.RendersPerMonth, workspace_id, q.count_events_stmt, q.oldest_event_stmt})
+		if user_id != 0 {
+			limits = append(limits, eventLimit{QUOTA_USER_RENDERS, q.limits.UserRendersPerMonth, user_id, q.count_user_events_stmt, q.oldest_user_event_stmt})
+		}
+	}
+	return limits
+}
+
+func (q *Quotas) countEvents(stmt *sql.Stmt, id uint32, kind string, since time.Time) (int64, error) {
+	var count int64
+	err := stmt.QueryRow(id, kind, since.Unix()).Scan(&count)
+	return count, err
+}
+
+// Usage lists the consumption of the workspace against every quota, and
+// that of the user if there is one.
+func (q *Quotas) Usage(workspace_id uint32, user_id uint32) ([]*pb.QuotaUsage, error) {
+	now := time.Now()
+
+	templates, err := q.TemplateCount(workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	stored_bytes, err := q.StoredBytes(workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := []*pb.QuotaUsage{
+		{Quota: QUOTA_TEMPLATES, Used: templates, Limit: q.limits.MaxTemplates},
+		{Quota: QUOTA_STORAGE_BYTES, Used: stored_bytes, Limit: q.limits.MaxStoredBytes},
+	}
+	for _, kind := range []string{KIND_CONVERSION, KIND_RENDER} {
+		for _, limit := range q.eventLimits(kind, workspace_id, user_id) {
+			used, err := q.countEvents(limit.count, limit.id, kind, window(kind, now))
+			if err != nil {
+				return nil, err
+			}
+			usage = append(usage, &pb.QuotaUsage{Quota: limit.quota, Used: used, Limit: limit.limit, WindowStart: window(kind, now).Unix()})
+		}
+	}
+
+	return usage, nil
+}
+
+// CheckTemplate fails if another template of the given size doesn't fit
+// into the workspace.
+func (q *Quotas) CheckTemplate(workspace_id uint32, size int64) error {
+	if q.limits.MaxTemplates > 0 {
+		count, err := q.TemplateCount(workspace_id)
+		if err != nil {
+			return err
+		}
+		if count >= q.limits.MaxTemplates {
+			return &Exceeded{Quota: QUOTA_TEMPLATES, Limit: q.limits.MaxTemplates, Used: count}
+		}
+	}
+
+	if q.limits.MaxStoredBytes > 0 {
+		stored_bytes, err := q.StoredBytes(workspace_id)
+		if err != nil {
+			return err
+		}
+		if stored_bytes+size > q.limits.MaxStoredBytes {
+			return &Exceeded{Quota: QUOTA_STORAGE_BYTES, Limit: q.limits.MaxStoredBytes, Used: stored_bytes}
+		}
+	}
+
+	return nil
+}
+
+// Reserve counts a conversion or render of the user against the quotas of
+// the workspace and the user, see ReserveTx.
+func (q *Quotas) Reserve(workspace_id uint32, user_id uint32, kind string) error {
+	tx, err := q.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = q.ReserveTx(tx, workspace_id, user_id, kind); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReserveTx records a conversion or render of the user within tx and fails
+// if that takes the workspace or the user over a quota of the kind. The
+// event is counted along with those in the window, so that concurrent
+// reservations can't both slip in under the limit; callers roll tx back on
+// errors, which gives the reservation back.
+func (q *Quotas) ReserveTx(tx *sql.Tx, workspace_id uint32, user_id uint32, kind string) error {
+	now := time.Now()
+	_, err := tx.Stmt(q.record_stmt).Exec(workspace_id, helpers.NullableId(user_id), kind, now.Unix())
+	if err != nil {
+		return err
+	}
+
+	since := window(kind, now)
+	for _, limit := range q.eventLimits(kind, workspace_id, user_id) {
+		if limit.limit <= 0 {
+			continue
+		}
+
+		count, err := q.countEvents(tx.Stmt(limit.count), limit.id, kind, since)
+		if err != nil {
+			return err
+		}
+		if count <= limit.limit {
+			continue
+		}
+
+		// Conversions free up as the oldest one leaves the hour, renders
+		// once the month is over.
+		retry_after := monthWindow(now).AddDate(0, 1, 0).Sub(now)
+		if kind == KIND_CONVERSION {
+			var oldest int64
+			if err = tx.Stmt(limit.oldest).QueryRow(limit.id, kind, since.Unix()).Scan(&oldest); err != nil {
+				return err
+			}
+			retry_after = time.Unix(oldest, 0).Add(time.Hour).Sub(now)
+		}
+
+		return &Exceeded{Quota: limit.quota, Limit: limit.limit, Used: count - 1, RetryAfter: retry_after}
+	}
+
+	return nil
+}
+
+func NewQuotas(db *sql.DB, limits Limits) (*Quotas, error) {
+	count_templates_stmt, err := db.Prepare("SELECT COUNT(*) FROM templates WHERE template_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	template_files_stmt, err := db.Prepare(`
+		SELECT template_private_path, template_private_thumbnail_path
+		FROM templates
+		WHERE template_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_events_stmt, err := db.Prepare(`
+		SELECT COUNT(*)
+		FROM usage_events
+		WHERE usage_event_workspace_id = ? AND usage_event_kind = ? AND usage_event_created_at > ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest_event_stmt, err := db.Prepare(`
+		SELECT COALESCE(MIN(usage_event_created_at), 0)
+		FROM usage_events
+		WHERE usage_event_workspace_id = ? AND usage_event_kind = ? AND usage_event_created_at > ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_user_events_stmt, err := db.Prepare(`
+		SELECT COUNT(*)
+		FROM usage_events
+		WHERE usage_event_user_id = ? AND usage_event_kind = ? AND usage_event_created_at > ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	oldest_user_event_stmt, err := db.Prepare(`
+		SELECT COALESCE(MIN(usage_event_created_at), 0)
+		FROM usage_events
+		WHERE usage_event_user_id = ? AND usage_event_kind = ? AND usage_event_created_at > ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	record_stmt, err := db.Prepare(`
+		INSERT INTO usage_events (
+			usage_event_workspace_id,
+			usage_event_user_id,
+			usage_event_kind,
+			usage_event_created_at
+		) VALUES(?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Quotas{
+		db:                     db,
+		limits:                 limits,
+		count_templates_stmt:   count_templates_stmt,
+		template_files_stmt:    template_files_stmt,
+		record_stmt:            record_stmt,
+		count_events_stmt:      count_events_stmt,
+		oldest_event_stmt:      oldest_event_stmt,
+		count_user_events_stmt: count_user_events_stmt,
+		oldest_user_event_stmt: oldest_user_event_stmt,
+	}, nil
+}
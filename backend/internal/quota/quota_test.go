@@ -0,0 +1,113 @@
+package quota
+
+import (
+	"errors"
+	"invoice-manager/main/internal/database"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestQuotas(t *testing.T, limits Limits) *Quotas {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	for _, email := range []string{"ada@example.com", "grace@example.com"} {
+		if _, err = db.Exec("INSERT INTO users VALUES(NULL, ?, 'Test', '', unixepoch(), unixepoch())", email); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q, err := NewQuotas(db, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestReserve(t *testing.T) {
+	q := newTestQuotas(t, Limits{ConversionsPerHour: 3, UserConversionsPerHour: 2, RendersPerMonth: 5, UserRendersPerMonth: 1})
+
+	tests := []struct {
+		user_id uint32
+		kind    string
+		// Quota that is exceeded, if any.
+		want string
+	}{
+		{1, KIND_CONVERSION, ""},
+		{1, KIND_CONVERSION, ""},
+		{1, KIND_CONVERSION, QUOTA_USER_CONVERSIONS},
+		{2, KIND_CONVERSION, ""},
+		{2, KIND_CONVERSION, QUOTA_CONVERSIONS},
+		{0, KIND_CONVERSION, QUOTA_CONVERSIONS},
+		{2, KIND_RENDER, ""},
+		{2, KIND_RENDER, QUOTA_USER_RENDERS},
+		{0, KIND_RENDER, ""},
+	}
+
+	for i, test := range tests {
+		err := q.Reserve(1, test.user_id, test.kind)
+		var exceeded *Exceeded
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%d: Reserve(%d, %s) error = %v", i, test.user_id, test.kind, err)
+		case test.want != "" && (!errors.As(err, &exceeded) || exceeded.Quota != test.want):
+			t.Errorf("%d: Reserve(%d, %s) error = %v, want %s exceeded", i, test.user_id, test.kind, err, test.want)
+		case exceeded != nil && exceeded.RetryAfter <= 0:
+			t.Errorf("%d: Reserve(%d, %s) retry after %s", i, test.user_id, test.kind, exceeded.RetryAfter)
+		}
+	}
+
+	usage, err := q.Usage(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := map[string]int64{}
+	for _, u := range usage {
+		used[u.Quota] = u.Used
+	}
+	want := map[string]int64{QUOTA_CONVERSIONS: 3, QUOTA_USER_CONVERSIONS: 2, QUOTA_RENDERS: 2, QUOTA_USER_RENDERS: 0}
+	for quota, count := range want {
+		if used[quota] != count {
+			t.Errorf("used %d of %s, want %d", used[quota], quota, count)
+		}
+	}
+}
+
+func TestReserveConcurrently(t *testing.T) {
+	const LIMIT = 4
+
+	q := newTestQuotas(t, Limits{ConversionsPerHour: LIMIT})
+
+	var wg sync.WaitGroup
+	results := make(chan error, 4*LIMIT)
+	for range 4 * LIMIT {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- q.Reserve(1, 0, KIND_CONVERSION)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	reserved := 0
+	for err := range results {
+		var exceeded *Exceeded
+		if err == nil {
+			reserved++
+		} else if !errors.As(err, &exceeded) {
+			t.Errorf("Reserve() error = %v", err)
+		}
+	}
+	if reserved != LIMIT {
+		t.Errorf("reserved %d conversions, want %d", reserved, LIMIT)
+	}
+}
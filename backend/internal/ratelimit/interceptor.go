@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+
+	"connectrpc.com/connect"
+)
+
+type interceptor struct {
+	limiter *Limiter
+}
+
+func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient || !i.limiter.Enabled() {
+			return next(ctx, req)
+		}
+
+		status := i.limiter.Allow(Key(ctx, req.Peer().Addr))
+		if !status.Allowed {
+			connect_err := apperr.ToConnect(status.Error())
+			status.SetHeaders(connect_err.Meta())
+			return nil, connect_err
+		}
+
+		// Handlers return typed nil responses with their errors, whose
+		// headers can't be set.
+		res, err := next(ctx, req)
+		var connect_err *connect.Error
+		switch {
+		case err == nil:
+			status.SetHeaders(res.Header())
+		case errors.As(err, &connect_err):
+			status.SetHeaders(connect_err.Meta())
+		}
+		return res, err
+	}
+}
+
+func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if !i.limiter.Enabled() {
+			return next(ctx, conn)
+		}
+
+		status := i.limiter.Allow(Key(ctx, conn.Peer().Addr))
+		status.SetHeaders(conn.ResponseHeader())
+		if !status.Allowed {
+			return apperr.ToConnect(status.Error())
+		}
+		return next(ctx, conn)
+	}
+}
+
+// Interceptor rate limits Connect requests with the same buckets as
+// Middleware. It has to come after the auth interceptor.
+func (l *Limiter) Interceptor() connect.Interceptor {
+	return &interceptor{limiter: l}
+}
@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/auth"
+	"invoice-manager/main/internal/helpers"
+	pb "invoice-manager/main/proto"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	DEFAULT_PER_MINUTE = 120
+	DEFAULT_BURST      = 60
+
+	// Buckets of clients that haven't been seen for this long are full
+	// again anyway and can be dropped.
+	IDLE_TIMEOUT = 10 * time.Minute
+)
+
+var ErrRateLimited = apperr.New(apperr.CODE_RESOURCE_EXHAUSTED, "too many requests, slow down")
+
+type bucket struct {
+	limiter   *rate.Limiter
+	last_seen time.Time
+}
+
+// Limiter is a token bucket per client. Clients are told about their
+// bucket with the RateLimit-* headers of the IETF draft.
+type Limiter struct {
+	rate  rate.Limit
+	burst int
+
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	last_sweep time.Time
+}
+
+func NewLimiter(per_minute int64, burst int64) *Limiter {
+	return &Limiter{
+		rate:       rate.Limit(float64(per_minute) / 60),
+		burst:      int(burst),
+		buckets:    map[string]*bucket{},
+		last_sweep: time.Now(),
+	}
+}
+
+// LimiterFromEnv reads INVOICER_RATE_LIMIT_PER_MINUTE and
+// INVOICER_RATE_LIMIT_BURST. A rate of 0 turns rate limiting off.
+func LimiterFromEnv() *Limiter {
+	return NewLimiter(
+		helpers.EnvInt("INVOICER_RATE_LIMIT_PER_MINUTE", DEFAULT_PER_MINUTE),
+		helpers.EnvInt("INVOICER_RATE_LIMIT_BURST", DEFAULT_BURST),
+	)
+}
+
+func (l *Limiter) Enabled() bool {
+	return l.rate > 0
+}
+
+// Status is the state of a bucket after a request was counted.
+type Status struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprint(int64(math.Ceil(d.Seconds())))
+}
+
+func (s *Status) SetHeaders(h http.Header) {
+	h.Set("RateLimit-Limit", fmt.Sprint(s.Limit))
+	h.Set("RateLimit-Remaining", fmt.Sprint(s.Remaining))
+	h.Set("RateLimit-Reset", seconds(s.Reset))
+	if !s.Allowed {
+		h.Set("Retry-After", seconds(s.RetryAfter))
+	}
+}
+
+func (s *Status) Proto() *pb.RateLimitStatus {
+	return &pb.RateLimitStatus{
+		Limit:     int64(s.Limit),
+		Remaining: int64(s.Remaining),
+		ResetIn:   int64(math.Ceil(s.Reset.Seconds())),
+	}
+}
+
+func (s *Status) Error() error {
+	err := *ErrRateLimited
+	err.RetryAfter = s.RetryAfter
+	return &err
+}
+
+func (l *Limiter) bucket(key string, now time.Time) *bucket {
+	if now.Sub(l.last_sweep) > IDLE_TIMEOUT {
+		for k, b := range l.buckets {
+			if now.Sub(b.last_seen) > IDLE_TIMEOUT {
+				delete(l.buckets, k)
+			}
+		}
+		l.last_sweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.buckets[key] = b
+	}
+	b.last_seen = now
+	return b
+}
+
+func (l *Limiter) status(b *bucket, now time.Time, allowed bool) *Status {
+	tokens := b.limiter.TokensAt(now)
+	status := &Status{Allowed: allowed, Limit: l.burst, Remaining: int(math.Max(0, math.Floor(tokens)))}
+	status.Reset = time.Duration((float64(l.burst) - tokens) / float64(l.rate) * float64(time.Second))
+	if !allowed {
+		status.RetryAfter = time.Duration((1 - tokens) / float64(l.rate) * float64(time.Second))
+	}
+	return status
+}
+
+// Allow takes a token from the bucket of the key, if there is one.
+func (l *Limiter) Allow(key string) *Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b := l.bucket(key, now)
+	allowed := b.limiter.AllowN(now, 1)
+	return l.status(b, now, allowed)
+}
+
+// Peek returns the state of the bucket without taking a token.
+func (l *Limiter) Peek(key string) *Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	return l.status(l.bucket(key, now), now, true)
+}
+
+// Key identifies the client of a request: the API key or user of the
+// principal if there is one, the IP address otherwise.
+func Key(ctx context.Context, remote_addr string) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		if principal.ApiKey != nil {
+			return fmt.Sprintf("key:%d", principal.ApiKey.Id)
+		}
+		return fmt.Sprintf("user:%d", principal.User.Id)
+	}
+
+	if host, _, err := net.SplitHostPort(remote_addr); err == nil {
+		remote_addr = host
+	}
+	return "ip:" + remote_addr
+}
+
+func KeyOf(req *http.Request) string {
+	return Key(req.Context(), req.RemoteAddr)
+}
+
+// Middleware rate limits REST requests. It has to run after authentication
+// for requests to be counted per principal.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !l.Enabled() {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		status := l.Allow(KeyOf(req))
+		status.SetHeaders(w.Header())
+		if !status.Allowed {
+			apperr.Write(w, req, status.Error())
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (l *Limiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return l.Middleware(next).ServeHTTP
+}
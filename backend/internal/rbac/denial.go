@@ -0,0 +1,56 @@
+package rbac
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+)
+
+// Reasons of a Denial, meant for clients to tell them apart.
+const (
+	REASON_MISSING_PERMISSION = "missing_permission"
+	REASON_MISSING_SCOPE      = "missing_scope"
+	REASON_SESSION_REQUIRED   = "session_required"
+	REASON_NOT_MEMBER         = "not_member"
+	REASON_NO_WORKSPACE       = "no_workspace"
+	REASON_OWNER_REQUIRED     = "owner_required"
+)
+
+// Denial is the error returned when the authorization layer refuses an
+// action.
+type Denial struct {
+	Reason     string
+	Permission string
+	Role       string
+	Scope      string
+}
+
+func (d *Denial) Error() string {
+	switch d.Reason {
+	case REASON_MISSING_PERMISSION:
+		return fmt.Sprintf("role %q lacks the %q permission", d.Role, d.Permission)
+	case REASON_MISSING_SCOPE:
+		return fmt.Sprintf("API key is missing the %q scope", d.Scope)
+	case REASON_SESSION_REQUIRED:
+		return fmt.Sprintf("%q can't be used with an API key", d.Permission)
+	case REASON_NOT_MEMBER:
+		return "user is not a member of the workspace"
+	case REASON_NO_WORKSPACE:
+		return "no workspace selected"
+	case REASON_OWNER_REQUIRED:
+		return "only owners can grant or revoke the owner role"
+	}
+	return "access denied"
+}
+
+// AppError reports the denial as a permission error, its fields are added
+// as extension members so clients can tell denials apart.
+func (d *Denial) AppError() *apperr.Error {
+	extensions := map[string]string{"reason": d.Reason}
+	for key, value := range map[string]string{"permission": d.Permission, "role": d.Role, "scope": d.Scope} {
+		if value != "" {
+			extensions[key] = value
+		}
+	}
+
+	return &apperr.Error{Code: apperr.CODE_PERMISSION_DENIED, Message: d.Error(), Extensions: extensions}
+}
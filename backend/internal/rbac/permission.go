@@ -0,0 +1,163 @@
+package rbac
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"slices"
+)
+
+const (
+	PERM_TEMPLATES_READ   = "templates.read"
+	PERM_TEMPLATES_WRITE  = "templates.write"
+	PERM_TEMPLATES_DELETE = "templates.delete"
+
+	PERM_CLIENTS_READ  = "clients.read"
+	PERM_CLIENTS_WRITE = "clients.write"
+
+	PERM_CATALOG_READ  = "catalog.read"
+	PERM_CATALOG_WRITE = "catalog.write"
+
+	PERM_INVOICES_READ  = "invoices.read"
+	PERM_INVOICES_WRITE = "invoices.write"
+	PERM_INVOICES_ISSUE = "invoices.issue"
+	PERM_INVOICES_VOID  = "invoices.void"
+
+	PERM_PAYMENTS_READ  = "payments.read"
+	PERM_PAYMENTS_WRITE = "payments.write"
+
+	PERM_SEQUENCES_MANAGE = "sequences.manage"
+	PERM_ISSUERS_MANAGE   = "issuers.manage"
+
+	PERM_EXCHANGE_RATES_MANAGE = "exchange_rates.manage"
+	PERM_TAX_RATES_MANAGE      = "tax_rates.manage"
+
+	PERM_MEMBERS_READ     = "members.read"
+	PERM_MEMBERS_MANAGE   = "members.manage"
+	PERM_ROLES_MANAGE     = "roles.manage"
+	PERM_API_KEYS_MANAGE  = "api_keys.manage"
+	PERM_WORKSPACE_MANAGE = "workspace.manage"
+	PERM_AUDIT_READ       = "audit.read"
+)
+
+const (
+	ROLE_OWNER      = "owner"
+	ROLE_ADMIN      = "admin"
+	ROLE_EDITOR     = "editor"
+	ROLE_VIEWER     = "viewer"
+	ROLE_ACCOUNTANT = "accountant"
+
+	DEFAULT_ROLE = ROLE_VIEWER
+)
+
+var (
+	ErrUnknownPermission = apperr.New(apperr.CODE_INVALID_ARGUMENT, "unknown permission")
+	ErrNoPermissions     = apperr.New(apperr.CODE_INVALID_ARGUMENT, "at least one permission is required")
+)
+
+var Permissions = []string{
+	PERM_TEMPLATES_READ,
+	PERM_TEMPLATES_WRITE,
+	PERM_TEMPLATES_DELETE,
+	PERM_CLIENTS_READ,
+	PERM_CLIENTS_WRITE,
+	PERM_CATALOG_READ,
+	PERM_CATALOG_WRITE,
+	PERM_INVOICES_READ,
+	PERM_INVOICES_WRITE,
+	PERM_INVOICES_ISSUE,
+	PERM_INVOICES_VOID,
+	PERM_PAYMENTS_READ,
+	PERM_PAYMENTS_WRITE,
+	PERM_SEQUENCES_MANAGE,
+	PERM_ISSUERS_MANAGE,
+	PERM_EXCHANGE_RATES_MANAGE,
+	PERM_TAX_RATES_MANAGE,
+	PERM_MEMBERS_READ,
+	PERM_MEMBERS_MANAGE,
+	PERM_ROLES_MANAGE,
+	PERM_API_KEYS_MANAGE,
+	PERM_WORKSPACE_MANAGE,
+	PERM_AUDIT_READ,
+}
+
+// BuiltinRoles is the permission matrix of the roles every workspace has.
+// Owners can do everything, including handing out the owner role.
+var BuiltinRoles = map[string][]string{
+	ROLE_OWNER: Permissions,
+	ROLE_ADMIN: slices.DeleteFunc(slices.Clone(Permissions), func(p string) bool {
+		return p == PERM_WORKSPACE_MANAGE
+	}),
+	ROLE_EDITOR: {
+		PERM_TEMPLATES_READ,
+		PERM_TEMPLATES_WRITE,
+		PERM_TEMPLATES_DELETE,
+		PERM_CLIENTS_READ,
+		PERM_CLIENTS_WRITE,
+		PERM_CATALOG_READ,
+		PERM_CATALOG_WRITE,
+		PERM_INVOICES_READ,
+		PERM_INVOICES_WRITE,
+		PERM_PAYMENTS_READ,
+		PERM_MEMBERS_READ,
+		PERM_API_KEYS_MANAGE,
+	},
+	ROLE_VIEWER: {
+		PERM_TEMPLATES_READ,
+		PERM_CLIENTS_READ,
+		PERM_CATALOG_READ,
+		PERM_INVOICES_READ,
+		PERM_PAYMENTS_READ,
+		PERM_MEMBERS_READ,
+	},
+	ROLE_ACCOUNTANT: {
+		PERM_TEMPLATES_READ,
+		PERM_CLIENTS_READ,
+		PERM_CLIENTS_WRITE,
+		PERM_CATALOG_READ,
+		PERM_CATALOG_WRITE,
+		PERM_INVOICES_READ,
+		PERM_INVOICES_WRITE,
+		PERM_INVOICES_ISSUE,
+		PERM_INVOICES_VOID,
+		PERM_PAYMENTS_READ,
+		PERM_PAYMENTS_WRITE,
+		PERM_SEQUENCES_MANAGE,
+		PERM_ISSUERS_MANAGE,
+		PERM_EXCHANGE_RATES_MANAGE,
+		PERM_TAX_RATES_MANAGE,
+		PERM_MEMBERS_READ,
+		PERM_API_KEYS_MANAGE,
+		PERM_AUDIT_READ,
+	},
+}
+
+// BuiltinRoleNames lists the built-in roles from the most to the least
+// privileged one.
+var BuiltinRoleNames = []string{ROLE_OWNER, ROLE_ADMIN, ROLE_EDITOR, ROLE_ACCOUNTANT, ROLE_VIEWER}
+
+func IsBuiltin(role string) bool {
+	_, ok := BuiltinRoles[role]
+	return ok
+}
+
+// ValidatePermissions checks that all permissions are known and returns
+// them without duplicates, in the order of Permissions.
+func ValidatePermissions(permissions []string) ([]string, error) {
+	if len(permissions) == 0 {
+		return nil, ErrNoPermissions
+	}
+
+	for _, p := range permissions {
+		if !slices.Contains(Permissions, p) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPermission, p)
+		}
+	}
+
+	valid := []string{}
+	for _, p := range Permissions {
+		if slices.Contains(permissions, p) {
+			valid = append(valid, p)
+		}
+	}
+	return valid, nil
+}
@@ -0,0 +1,257 @@
+package rbac
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound  = apperr.New(apperr.CODE_NOT_FOUND, "role not found")
+	ErrUnknownRole = apperr.New(apperr.CODE_INVALID_ARGUMENT, "unknown role")
+	ErrInvalidName = apperr.New(apperr.CODE_INVALID_ARGUMENT, "role name must be 2-32 lowercase letters, digits, dashes or underscores")
+	ErrNameTaken   = apperr.New(apperr.CODE_ALREADY_EXISTS, "role name is already taken")
+	ErrRoleInUse   = apperr.New(apperr.CODE_FAILED_PRECONDITION, "role is still assigned to members")
+
+	role_name_pattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,31}$`)
+)
+
+// Roles stores the custom roles of workspaces. The built-in roles aren't
+// stored, they are the same for every workspace.
+type Roles struct {
+	db *sql.DB
+
+	insert_stmt, retrieve_stmt, retrieve_by_name_stmt, list_stmt, update_stmt, delete_stmt, count_members_stmt *sql.Stmt
+}
+
+func builtinRole(name string) *pb.Role {
+	return &pb.Role{Name: name, Permissions: BuiltinRoles[name], BuiltIn: true}
+}
+
+func scanRole(row interface{ Scan(...any) error }) (*pb.Role, error) {
+	role := &pb.Role{}
+	var permissions string
+	err := row.Scan(
+		&role.Id,
+		&role.WorkspaceId,
+		&role.Name,
+		&permissions,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = strings.Fields(permissions)
+	return role, nil
+}
+
+func (rs *Roles) Create(workspace_id uint32, name string, permissions []string) (*pb.Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !role_name_pattern.MatchString(name) {
+		return nil, ErrInvalidName
+	}
+	if IsBuiltin(name) {
+		return nil, ErrNameTaken
+	}
+
+	permissions, err := ValidatePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	res, err := rs.insert_stmt.Exec(workspace_id, name, strings.Join(permissions, " "), now, now)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, ErrNameTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.Retrieve(workspace_id, uint32(id))
+}
+
+func (rs *Roles) Retrieve(workspace_id uint32, id uint32) (*pb.Role, error) {
+	role, err := scanRole(rs.retrieve_stmt.QueryRow(id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return role, err
+}
+
+// RetrieveByName finds a built-in or custom role of the workspace.
+func (rs *Roles) RetrieveByName(workspace_id uint32, name string) (*pb.Role, error) {
+	if IsBuiltin(name) {
+		return builtinRole(name), nil
+	}
+
+	role, err := scanRole(rs.retrieve_by_name_stmt.QueryRow(workspace_id, name))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRole, name)
+	}
+	return role, err
+}
+
+// List returns the built-in roles followed by the custom roles of the
+// workspace.
+func (rs *Roles) List(workspace_id uint32) ([]*pb.Role, error) {
+	roles := []*pb.Role{}
+	for _, name := range BuiltinRoleNames {
+		roles = append(roles, builtinRole(name))
+	}
+
+	rows, err := rs.list_stmt.Query(workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (rs *Roles) Update(workspace_id uint32, id uint32, permissions []string) (*pb.Role, error) {
+	permissions, err := ValidatePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := rs.update_stmt.Exec(strings.Join(permissions, " "), time.Now().Unix(), id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrIDNotFound
+	}
+
+	return rs.Retrieve(workspace_id, id)
+}
+
+func (rs *Roles) Delete(workspace_id uint32, id uint32) error {
+	role, err := rs.Retrieve(workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	var members int
+	if err = rs.count_members_stmt.QueryRow(workspace_id, role.Name).Scan(&members); err != nil {
+		return err
+	}
+	if members > 0 {
+		return ErrRoleInUse
+	}
+
+	_, err = rs.delete_stmt.Exec(id, workspace_id)
+	return err
+}
+
+// HasPermission tells whether the role, built-in or custom, grants the
+// permission in the workspace. Unknown roles grant nothing.
+func (rs *Roles) HasPermission(workspace_id uint32, role string, permission string) (bool, error) {
+	found, err := rs.RetrieveByName(workspace_id, role)
+	if errors.Is(err, ErrUnknownRole) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(found.Permissions, permission), nil
+}
+
+const ROLE_COLUMNS = `
+	role_id,
+	role_workspace_id,
+	role_name,
+	role_permissions,
+	role_created_at,
+	role_updated_at
+`
+
+func NewRoles(db *sql.DB) (*Roles, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO roles (
+			role_workspace_id,
+			role_name,
+			role_permissions,
+			role_created_at,
+			role_updated_at
+		) VALUES(?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare("SELECT " + ROLE_COLUMNS + " FROM roles WHERE role_id = ? AND role_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_by_name_stmt, err := db.Prepare("SELECT " + ROLE_COLUMNS + " FROM roles WHERE role_workspace_id = ? AND role_name = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare("SELECT " + ROLE_COLUMNS + " FROM roles WHERE role_workspace_id = ? ORDER BY role_name ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE roles
+		SET role_permissions = ?, role_updated_at = ?
+		WHERE role_id = ? AND role_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM roles WHERE role_id = ? AND role_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	count_members_stmt, err := db.Prepare(`
+		SELECT COUNT(*)
+		FROM workspace_members
+		WHERE workspace_member_workspace_id = ? AND workspace_member_role = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Roles{
+		db:                    db,
+		insert_stmt:           insert_stmt,
+		retrieve_stmt:         retrieve_stmt,
+		retrieve_by_name_stmt: retrieve_by_name_stmt,
+		list_stmt:             list_stmt,
+		update_stmt:           update_stmt,
+		delete_stmt:           delete_stmt,
+		count_members_stmt:    count_members_stmt,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package rbac
+
+import (
+	"errors"
+	"invoice-manager/main/internal/database"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTestRoles(t *testing.T) *Roles {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec("INSERT INTO workspaces (workspace_id, workspace_slug, workspace_name, workspace_created_at, workspace_updated_at) VALUES(2, 'other', 'Other', unixepoch(), unixepoch())"); err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := NewRoles(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func TestHasPermission(t *testing.T) {
+	rs := newTestRoles(t)
+	if _, err := rs.Create(1, "bookkeeper", []string{PERM_PAYMENTS_WRITE, PERM_INVOICES_READ, PERM_PAYMENTS_READ}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs.Create(2, "auditor", []string{PERM_AUDIT_READ}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The permissions each role grants in workspace 1, or after a leading
+	// "-" the only ones it doesn't grant.
+	tests := map[string]string{
+		ROLE_OWNER: "-",
+		ROLE_ADMIN: "- workspace.manage",
+		ROLE_EDITOR: "templates.read templates.write templates.delete clients.read clients.write catalog.read catalog.write " +
+			"invoices.read invoices.write payments.read members.read api_keys.manage",
+		ROLE_ACCOUNTANT: "templates.read clients.read clients.write catalog.read catalog.write invoices.read invoices.write " +
+			"invoices.issue invoices.void payments.read payments.write sequences.manage issuers.manage exchange_rates.manage " +
+			"tax_rates.manage members.read api_keys.manage audit.read",
+		ROLE_VIEWER:  "templates.read clients.read catalog.read invoices.read payments.read members.read",
+		"bookkeeper": "invoices.read payments.read payments.write",
+		// Custom roles of other workspaces and unknown roles grant nothing.
+		"auditor": "",
+		"nobody":  "",
+	}
+
+	for role, granted := range tests {
+		fields := strings.Fields(granted)
+		for _, permission := range Permissions {
+			want := slices.Contains(fields, permission)
+			if len(fields) > 0 && fields[0] == "-" {
+				want = !slices.Contains(fields[1:], permission)
+			}
+
+			got, err := rs.HasPermission(1, role, permission)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("HasPermission(%s, %s) = %t, want %t", role, permission, got, want)
+			}
+		}
+	}
+
+	if got, err := rs.HasPermission(2, "auditor", PERM_AUDIT_READ); err != nil || !got {
+		t.Errorf("HasPermission(auditor, %s) in its workspace = %t, %v, want true", PERM_AUDIT_READ, got, err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	rs := newTestRoles(t)
+
+	role, err := rs.Create(1, " Bookkeeper ", []string{PERM_PAYMENTS_WRITE, PERM_INVOICES_READ, PERM_PAYMENTS_WRITE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{PERM_INVOICES_READ, PERM_PAYMENTS_WRITE}; role.Name != "bookkeeper" || !slices.Equal(role.Permissions, want) {
+		t.Errorf("Create() = %s with %v, want bookkeeper with %v", role.Name, role.Permissions, want)
+	}
+
+	tests := []struct {
+		name        string
+		role        string
+		permissions []string
+		want        error
+	}{
+		{"name taken", "bookkeeper", []string{PERM_INVOICES_READ}, ErrNameTaken},
+		{"built-in name", ROLE_ADMIN, []string{PERM_INVOICES_READ}, ErrNameTaken},
+		{"invalid name", "Book keeper", []string{PERM_INVOICES_READ}, ErrInvalidName},
+		{"too short", "b", []string{PERM_INVOICES_READ}, ErrInvalidName},
+		{"no permissions", "clerk", nil, ErrNoPermissions},
+		{"unknown permission", "clerk", []string{"invoices.delete"}, ErrUnknownPermission},
+	}
+
+	for _, test := range tests {
+		if _, err := rs.Create(1, test.role, test.permissions); !errors.Is(err, test.want) {
+			t.Errorf("%s: Create(%q) error = %v, want %v", test.name, test.role, err, test.want)
+		}
+	}
+
+	if _, err := rs.Create(2, "bookkeeper", []string{PERM_INVOICES_READ}); err != nil {
+		t.Errorf("Create() of a name taken in another workspace error = %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package recurring
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+type RecurringApi struct {
+	schedules *Schedules
+}
+
+func (ra *RecurringApi) GetSchedules(w http.ResponseWriter, req *http.Request) {
+	schedules, err := ra.schedules.List(req.Context(), workspace.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading recurring schedules"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetRecurringSchedulesResponse{Schedules: schedules})
+}
+
+func (ra *RecurringApi) CreateSchedule(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveRecurringScheduleRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	schedule, err := ra.schedules.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Recurring schedule couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.RecurringScheduleResponse{Schedule: schedule})
+}
+
+func (ra *RecurringApi) GetSchedule(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	schedule, err := ra.schedules.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading recurring schedule"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.RecurringScheduleResponse{Schedule: schedule})
+}
+
+func (ra *RecurringApi) UpdateSchedule(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveRecurringScheduleRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	schedule, err := ra.schedules.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Recurring schedule couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.RecurringScheduleResponse{Schedule: schedule})
+}
+
+func (ra *RecurringApi) DeleteSchedule(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ra.schedules.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the recurring schedule"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ra *RecurringApi) GetScheduleRuns(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	runs, err := ra.schedules.Runs(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading recurring runs"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetRecurringRunsResponse{Runs: runs})
+}
+
+func NewRecurringApi(ss *Schedules) *RecurringApi {
+	return &RecurringApi{schedules: ss}
+}
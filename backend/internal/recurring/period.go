@@ -0,0 +1,140 @@
+package recurring
+
+import (
+	"fmt"
+	pb "invoice-manager/main/proto"
+	"regexp"
+	"time"
+
+	"github.com/robfig/cron"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	CADENCE_WEEKLY    = "weekly"
+	CADENCE_MONTHLY   = "monthly"
+	CADENCE_QUARTERLY = "quarterly"
+	CADENCE_YEARLY    = "yearly"
+	CADENCE_CRON      = "cron"
+)
+
+var Cadences = []string{CADENCE_WEEKLY, CADENCE_MONTHLY, CADENCE_QUARTERLY, CADENCE_YEARLY, CADENCE_CRON}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*period\.([A-Za-z]+)\s*\}\}`)
+
+// placeholders are the names {{period.name}} can take.
+var placeholders = map[string]bool{"start": true, "end": true, "year": true, "month": true, "quarter": true}
+
+// day drops the time of t, cron schedules match on times and periods are
+// whole days.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths moves date by months, to the last day of the target month if it
+// is shorter, so that monthly periods starting on the 31st don't drift.
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), last)-1)
+}
+
+// firstStart returns the start of the first period of the schedule, which
+// is the zero time if a cron expression never matches.
+func firstStart(s *pb.RecurringSchedule) (time.Time, error) {
+	start, err := time.Parse(time.DateOnly, s.StartDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if s.Cadence != CADENCE_CRON {
+		return start, nil
+	}
+
+	schedule, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := schedule.Next(start.Add(-time.Second))
+	if next.IsZero() {
+		return next, nil
+	}
+	return day(next), nil
+}
+
+// nextStart returns the start of the period after the nth one, counting
+// from zero, which starts on current. Fixed cadences count from the start
+// date of the schedule.
+func nextStart(s *pb.RecurringSchedule, n uint32, current time.Time) (time.Time, error) {
+	start, err := time.Parse(time.DateOnly, s.StartDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	periods := int(n) + 1
+	switch s.Cadence {
+	case CADENCE_WEEKLY:
+		return start.AddDate(0, 0, 7*periods), nil
+	case CADENCE_MONTHLY:
+		return addMonths(start, periods), nil
+	case CADENCE_QUARTERLY:
+		return addMonths(start, 3*periods), nil
+	case CADENCE_YEARLY:
+		return addMonths(start, 12*periods), nil
+	case CADENCE_CRON:
+		schedule, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		// The first match after the day of current, matches later on the
+		// same day would start an empty period.
+		next := schedule.Next(current.AddDate(0, 0, 1).Add(-time.Second))
+		if next.IsZero() {
+			return next, nil
+		}
+		return day(next), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown cadence %q", s.Cadence)
+}
+
+// periodValues are what the placeholders resolve to for the period from
+// start to end, both included.
+func periodValues(start time.Time, end time.Time) map[string]string {
+	return map[string]string{
+		"start":   start.Format(time.DateOnly),
+		"end":     end.Format(time.DateOnly),
+		"year":    start.Format("2006"),
+		"month":   start.Format("01"),
+		"quarter": fmt.Sprintf("Q%d", (int(start.Month())+2)/3),
+	}
+}
+
+func resolve(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if value, ok := values[placeholderPattern.FindStringSubmatch(placeholder)[1]]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// periodInvoice returns the invoice of the schedule for the period, with
+// its placeholders resolved and issued on the first day of the period.
+func periodInvoice(template *pb.SaveInvoiceRequest, start time.Time, end time.Time) *pb.SaveInvoiceRequest {
+	req := proto.Clone(template).(*pb.SaveInvoiceRequest)
+	values := periodValues(start, end)
+
+	req.IssueDate = start.Format(time.DateOnly)
+	req.DueDate = ""
+	req.Notes = resolve(req.Notes, values)
+	for _, item := range req.Items {
+		if item != nil {
+			item.Description = resolve(item.Description, values)
+		}
+	}
+	for _, discount := range req.Discounts {
+		if discount != nil {
+			discount.Description = resolve(discount.Description, values)
+		}
+	}
+	return req
+}
@@ -0,0 +1,135 @@
+package recurring
+
+import (
+	pb "invoice-manager/main/proto"
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		date   string
+		months int
+		want   string
+	}{
+		{"2026-01-15", 1, "2026-02-15"},
+		{"2026-01-31", 1, "2026-02-28"},
+		{"2028-01-31", 1, "2028-02-29"},
+		{"2026-01-31", 2, "2026-03-31"},
+		{"2026-08-31", 3, "2026-11-30"},
+		{"2026-11-30", 3, "2027-02-28"},
+		{"2028-02-29", 12, "2029-02-28"},
+	}
+
+	for _, test := range tests {
+		got := addMonths(date(test.date), test.months).Format(time.DateOnly)
+		if got != test.want {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", test.date, test.months, got, test.want)
+		}
+	}
+}
+
+func TestNextStart(t *testing.T) {
+	tests := []struct {
+		name    string
+		cadence string
+		cron    string
+		start   string
+		n       uint32
+		current string
+		want    string
+	}{
+		{name: "weekly", cadence: CADENCE_WEEKLY, start: "2026-03-02", n: 2, current: "2026-03-16", want: "2026-03-23"},
+		{name: "monthly", cadence: CADENCE_MONTHLY, start: "2026-01-15", n: 0, current: "2026-01-15", want: "2026-02-15"},
+		// Counting from the start date keeps the 31st after February.
+		{name: "monthly on the 31st", cadence: CADENCE_MONTHLY, start: "2026-01-31", n: 1, current: "2026-02-28", want: "2026-03-31"},
+		{name: "quarterly", cadence: CADENCE_QUARTERLY, start: "2026-01-01", n: 3, current: "2026-10-01", want: "2027-01-01"},
+		{name: "yearly on a leap day", cadence: CADENCE_YEARLY, start: "2028-02-29", n: 3, current: "2031-02-28", want: "2032-02-29"},
+		{name: "cron", cadence: CADENCE_CRON, cron: "0 9 1,15 * *", start: "2026-03-01", n: 0, current: "2026-03-01", want: "2026-03-15"},
+		{name: "cron matching twice a day", cadence: CADENCE_CRON, cron: "0 9,17 * * 1", start: "2026-03-02", n: 0, current: "2026-03-02", want: "2026-03-09"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &pb.RecurringSchedule{Cadence: test.cadence, Cron: test.cron, StartDate: test.start}
+			got, err := nextStart(s, test.n, date(test.current))
+			if err != nil {
+				t.Fatalf("nextStart() error = %v", err)
+			}
+			if got.Format(time.DateOnly) != test.want {
+				t.Errorf("nextStart() = %s, want %s", got.Format(time.DateOnly), test.want)
+			}
+		})
+	}
+}
+
+func TestFirstStart(t *testing.T) {
+	tests := []struct {
+		cadence string
+		cron    string
+		start   string
+		want    string
+	}{
+		{CADENCE_MONTHLY, "", "2026-03-07", "2026-03-07"},
+		{CADENCE_CRON, "0 0 1 * *", "2026-03-01", "2026-03-01"},
+		{CADENCE_CRON, "0 0 1 * *", "2026-03-07", "2026-04-01"},
+		{CADENCE_CRON, "30 8 * * 5", "2026-03-07", "2026-03-13"},
+	}
+
+	for _, test := range tests {
+		s := &pb.RecurringSchedule{Cadence: test.cadence, Cron: test.cron, StartDate: test.start}
+		got, err := firstStart(s)
+		if err != nil {
+			t.Fatalf("firstStart(%s %q) error = %v", test.cadence, test.cron, err)
+		}
+		if got.Format(time.DateOnly) != test.want {
+			t.Errorf("firstStart(%s %q from %s) = %s, want %s", test.cadence, test.cron, test.start, got.Format(time.DateOnly), test.want)
+		}
+	}
+}
+
+func TestPeriodInvoice(t *testing.T) {
+	template := &pb.SaveInvoiceRequest{
+		IssueDate: "2026-01-01",
+		DueDate:   "2026-01-15",
+		Notes:     "Services {{period.start}} to {{ period.end }}",
+		Items: []*pb.LineItem{
+			{Description: "Hosting {{period.month}}/{{period.year}}"},
+			nil,
+			{Description: "Support {{period.quarter}}, {{period.week}}"},
+		},
+		Discounts: []*pb.Discount{{Description: "Loyalty {{period.year}}"}},
+	}
+
+	req := periodInvoice(template, date("2026-08-01"), date("2026-08-31"))
+
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"issueDate", req.IssueDate, "2026-08-01"},
+		{"dueDate", req.DueDate, ""},
+		{"notes", req.Notes, "Services 2026-08-01 to 2026-08-31"},
+		{"items[0].description", req.Items[0].Description, "Hosting 08/2026"},
+		{"items[2].description", req.Items[2].Description, "Support Q3, {{period.week}}"},
+		{"discounts[0].description", req.Discounts[0].Description, "Loyalty 2026"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %q, want %q", test.field, test.got, test.want)
+		}
+	}
+
+	if template.Notes != "Services {{period.start}} to {{ period.end }}" {
+		t.Errorf("template notes changed to %q", template.Notes)
+	}
+}
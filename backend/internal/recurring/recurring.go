@@ -0,0 +1,641 @@
+package recurring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/invoice"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"os"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound = apperr.New(apperr.CODE_NOT_FOUND, "recurring schedule not found")
+	ErrStarted    = apperr.New(apperr.CODE_FAILED_PRECONDITION, "schedule has generated invoices, its cadence and start date can't change")
+)
+
+const (
+	AUDIT_TARGET = "recurring_schedule"
+	AUDIT_CREATE = "recurring_schedule.create"
+	AUDIT_UPDATE = "recurring_schedule.update"
+	AUDIT_DELETE = "recurring_schedule.delete"
+	AUDIT_RUN    = "recurring_schedule.run"
+)
+
+// Schedules generate an invoice once per billing period. Every period is
+// generated in a transaction that records it as a run, which can only
+// exist once per period, and advances the schedule only if no one else
+// did, so that restarts and several instances never generate it twice.
+type Schedules struct {
+	db       *sql.DB
+	audit    *audit.Log
+	invoices *invoice.Invoices
+
+	insert_stmt, retrieve_stmt, list_stmt, update_stmt, delete_stmt *sql.Stmt
+	due_stmt, advance_stmt, insert_run_stmt, runs_stmt              *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSchedule(row scanner) (*pb.RecurringSchedule, error) {
+	s := &pb.RecurringSchedule{Invoice: &pb.SaveInvoiceRequest{}}
+	var template string
+	err := row.Scan(
+		&s.Id,
+		&s.Name,
+		&template,
+		&s.Cadence,
+		&s.Cron,
+		&s.StartDate,
+		&s.EndDate,
+		&s.AutoIssue,
+		&s.Paused,
+		&s.Periods,
+		&s.NextDate,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.CreatedBy,
+		&s.UpdatedBy,
+		&s.WorkspaceId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, json.Unmarshal([]byte(template), s.Invoice)
+}
+
+func marshalJson(value any) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func auditFields(s *pb.RecurringSchedule) map[string]string {
+	return map[string]string{
+		"name":       s.Name,
+		"invoice":    marshalJson(s.Invoice),
+		"cadence":    s.Cadence,
+		"cron":       s.Cron,
+		"start_date": s.StartDate,
+		"end_date":   s.EndDate,
+		"auto_issue": fmt.Sprint(s.AutoIssue),
+		"paused":     fmt.Sprint(s.Paused),
+	}
+}
+
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
+
+// check validates the request, including its invoice for the first period
+// with the defaults of its client.
+func (ss *Schedules) check(ctx context.Context, workspace_id uint32, req *pb.SaveRecurringScheduleRequest) error {
+	if err := Validate(req); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.DateOnly, req.StartDate)
+	if err != nil {
+		return err
+	}
+	return ss.invoices.Check(ctx, workspace_id, periodInvoice(req.Invoice, start, start))
+}
+
+func (ss *Schedules) retrieve(ctx context.Context, tx *sql.Tx, workspace_id uint32, id uint32) (*pb.RecurringSchedule, error) {
+	s, err := scanSchedule(tx.Stmt(ss.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return s, err
+}
+
+func (ss *Schedules) Create(ctx context.Context, workspace_id uint32, req *pb.SaveRecurringScheduleRequest, actor *audit.Actor) (_ *pb.RecurringSchedule, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Create")
+	defer end(&err)
+
+	if err = ss.check(ctx, workspace_id, req); err != nil {
+		return nil, err
+	}
+
+	first, err := firstStart(&pb.RecurringSchedule{Cadence: req.Cadence, Cron: req.Cron, StartDate: req.StartDate})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(ss.insert_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.Invoice.ClientId,
+		marshalJson(req.Invoice),
+		req.Cadence,
+		req.Cron,
+		req.StartDate,
+		req.EndDate,
+		req.AutoIssue,
+		req.Paused,
+		formatDate(first),
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ss.retrieve(ctx, tx, workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, s.Id, audit.Diff(nil, auditFields(s)))
+	if err = ss.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (ss *Schedules) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.RecurringSchedule, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Retrieve")
+	defer end(&err)
+
+	s, err := scanSchedule(ss.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return s, err
+}
+
+// List returns the schedules of the workspace by name.
+func (ss *Schedules) List(ctx context.Context, workspace_id uint32) (_ []*pb.RecurringSchedule, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.List")
+	defer end(&err)
+
+	rows, err := ss.list_stmt.QueryContext(ctx, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*pb.RecurringSchedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// Update replaces the schedule. Once it has generated invoices, its
+// cadence and start date are fixed, as they determine the periods.
+func (ss *Schedules) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveRecurringScheduleRequest, actor *audit.Actor) (_ *pb.RecurringSchedule, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Update")
+	defer end(&err)
+
+	if err = ss.check(ctx, workspace_id, req); err != nil {
+		return nil, err
+	}
+
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := ss.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	next_date := before.NextDate
+	if before.Cadence != req.Cadence || before.Cron != req.Cron || before.StartDate != req.StartDate {
+		if before.Periods > 0 {
+			return nil, ErrStarted
+		}
+		first, err := firstStart(&pb.RecurringSchedule{Cadence: req.Cadence, Cron: req.Cron, StartDate: req.StartDate})
+		if err != nil {
+			return nil, err
+		}
+		next_date = formatDate(first)
+	}
+
+	_, err = tx.Stmt(ss.update_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.Invoice.ClientId,
+		marshalJson(req.Invoice),
+		req.Cadence,
+		req.Cron,
+		req.StartDate,
+		req.EndDate,
+		req.AutoIssue,
+		req.Paused,
+		next_date,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		id,
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := ss.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = ss.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Delete removes the schedule along with its runs, the invoices it
+// generated stay.
+func (ss *Schedules) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Delete")
+	defer end(&err)
+
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	s, err := ss.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Stmt(ss.delete_stmt).ExecContext(ctx, id, workspace_id); err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(s), nil))
+	if err = ss.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Runs returns the periods the schedule generated, oldest first.
+func (ss *Schedules) Runs(ctx context.Context, workspace_id uint32, id uint32) (_ []*pb.RecurringRun, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Runs")
+	defer end(&err)
+
+	if _, err = ss.Retrieve(ctx, workspace_id, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := ss.runs_stmt.QueryContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*pb.RecurringRun{}
+	for rows.Next() {
+		run := &pb.RecurringRun{}
+		err = rows.Scan(&run.Id, &run.ScheduleId, &run.PeriodStart, &run.PeriodEnd, &run.InvoiceId, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// Due returns the schedules of all workspaces whose next period has
+// started by today.
+func (ss *Schedules) Due(ctx context.Context, today time.Time) (_ []*pb.RecurringSchedule, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Schedules.Due")
+	defer end(&err)
+
+	rows, err := ss.due_stmt.QueryContext(ctx, today.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*pb.RecurringSchedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+// Generate creates the invoice of the next period of the schedule if it
+// has started by today, issuing it if the schedule says so. It tells
+// whether it generated one; it doesn't if the period isn't due, or if
+// another instance got to it first.
+func (ss *Schedules) Generate(ctx context.Context, workspace_id uint32, id uint32, today time.Time) (_ bool, err error) {
+	ctx, end := telemetry.Start(ctx, "Schedules.Generate")
+	defer end(&err)
+
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	s, err := ss.retrieve(ctx, tx, workspace_id, id)
+	if err != nil {
+		return false, err
+	}
+	if s.Paused || s.NextDate == "" || s.NextDate > today.Format(time.DateOnly) || (s.EndDate != "" && s.NextDate > s.EndDate) {
+		return false, nil
+	}
+
+	start, err := time.Parse(time.DateOnly, s.NextDate)
+	if err != nil {
+		return false, err
+	}
+	next, err := nextStart(s, s.Periods, start)
+	if err != nil {
+		return false, err
+	}
+	// A cron expression that stops matching ends the schedule, its last
+	// period lasts for a day.
+	period_end := start
+	if !next.IsZero() {
+		period_end = next.AddDate(0, 0, -1)
+	}
+
+	actor := audit.SystemActor
+	inv, err := ss.invoices.CreateTx(ctx, tx, workspace_id, periodInvoice(s.Invoice, start, period_end), actor)
+	if err != nil {
+		return false, err
+	}
+
+	var html_path string
+	committed := false
+	if s.AutoIssue {
+		if html_path, err = ss.invoices.IssueTx(ctx, tx, inv, actor); err != nil {
+			return false, err
+		}
+		defer func() {
+			if !committed {
+				os.Remove(html_path)
+			}
+		}()
+	}
+
+	_, err = tx.Stmt(ss.insert_run_stmt).ExecContext(ctx, id, s.NextDate, formatDate(period_end), inv.Id, time.Now().Unix())
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.Code == sqlite3.ErrConstraint {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Stmt(ss.advance_stmt).ExecContext(ctx, formatDate(next), id, s.NextDate)
+	if err != nil {
+		return false, err
+	}
+	if advanced, err := res.RowsAffected(); err != nil || advanced == 0 {
+		return false, err
+	}
+
+	changes := audit.Diff(nil, map[string]string{
+		"period_start": s.NextDate,
+		"period_end":   formatDate(period_end),
+		"invoice_id":   fmt.Sprint(inv.Id),
+	})
+	if err = ss.audit.Record(tx, actor.Entry(workspace_id, AUDIT_RUN, AUDIT_TARGET, id, changes)); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	committed = true
+
+	if s.AutoIssue {
+		ss.invoices.AfterIssue(ctx, inv, html_path, actor)
+	}
+	return true, nil
+}
+
+func (ss *Schedules) Close() error {
+	stmts := []*sql.Stmt{
+		ss.insert_stmt,
+		ss.retrieve_stmt,
+		ss.list_stmt,
+		ss.update_stmt,
+		ss.delete_stmt,
+		ss.due_stmt,
+		ss.advance_stmt,
+		ss.insert_run_stmt,
+		ss.runs_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const SCHEDULE_COLUMNS = `
+	schedule_id,
+	schedule_name,
+	schedule_invoice,
+	schedule_cadence,
+	schedule_cron,
+	schedule_start_date,
+	schedule_end_date,
+	schedule_auto_issue,
+	schedule_paused,
+	schedule_periods,
+	schedule_next_date,
+	schedule_created_at,
+	schedule_updated_at,
+	COALESCE(schedule_created_by, 0),
+	COALESCE(schedule_updated_by, 0),
+	schedule_workspace_id
+`
+
+func NewSchedules(db *sql.DB, audit_log *audit.Log, is *invoice.Invoices) (*Schedules, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO recurring_schedules (
+			schedule_name,
+			schedule_client_id,
+			schedule_invoice,
+			schedule_cadence,
+			schedule_cron,
+			schedule_start_date,
+			schedule_end_date,
+			schedule_auto_issue,
+			schedule_paused,
+			schedule_next_date,
+			schedule_created_at,
+			schedule_updated_at,
+			schedule_created_by,
+			schedule_updated_by,
+			schedule_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + SCHEDULE_COLUMNS + `
+		FROM recurring_schedules
+		WHERE schedule_id = ? AND schedule_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(`
+		SELECT ` + SCHEDULE_COLUMNS + `
+		FROM recurring_schedules
+		WHERE schedule_workspace_id = ?
+		ORDER BY schedule_name COLLATE NOCASE, schedule_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE recurring_schedules
+		SET schedule_name = ?,
+			schedule_client_id = ?,
+			schedule_invoice = ?,
+			schedule_cadence = ?,
+			schedule_cron = ?,
+			schedule_start_date = ?,
+			schedule_end_date = ?,
+			schedule_auto_issue = ?,
+			schedule_paused = ?,
+			schedule_next_date = ?,
+			schedule_updated_at = ?,
+			schedule_updated_by = ?
+		WHERE schedule_id = ? AND schedule_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM recurring_schedules WHERE schedule_id = ? AND schedule_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	due_stmt, err := db.Prepare(`
+		SELECT ` + SCHEDULE_COLUMNS + `
+		FROM recurring_schedules
+		WHERE schedule_paused = 0
+			AND schedule_next_date != ''
+			AND schedule_next_date <= ?1
+			AND (schedule_end_date = '' OR schedule_next_date <= schedule_end_date)
+		ORDER BY schedule_next_date, schedule_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	advance_stmt, err := db.Prepare(`
+		UPDATE recurring_schedules
+		SET schedule_periods = schedule_periods + 1,
+			schedule_next_date = ?
+		WHERE schedule_id = ? AND schedule_next_date = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_run_stmt, err := db.Prepare(`
+		INSERT INTO recurring_runs (
+			run_schedule_id,
+			run_period_start,
+			run_period_end,
+			run_invoice_id,
+			run_created_at
+		) VALUES(?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	runs_stmt, err := db.Prepare(`
+		SELECT
+			run_id,
+			run_schedule_id,
+			run_period_start,
+			run_period_end,
+			COALESCE(run_invoice_id, 0),
+			run_created_at
+		FROM recurring_runs
+		WHERE run_schedule_id = ?
+		ORDER BY run_period_start
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedules{
+		db:              db,
+		audit:           audit_log,
+		invoices:        is,
+		insert_stmt:     insert_stmt,
+		retrieve_stmt:   retrieve_stmt,
+		list_stmt:       list_stmt,
+		update_stmt:     update_stmt,
+		delete_stmt:     delete_stmt,
+		due_stmt:        due_stmt,
+		advance_stmt:    advance_stmt,
+		insert_run_stmt: insert_run_stmt,
+		runs_stmt:       runs_stmt,
+	}, nil
+}
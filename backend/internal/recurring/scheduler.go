@@ -0,0 +1,84 @@
+package recurring
+
+import (
+	"context"
+	"invoice-manager/main/internal/helpers"
+	"log"
+	"time"
+)
+
+const (
+	DEFAULT_SCHEDULER_INTERVAL = 15 * time.Minute
+
+	// MAX_BACKFILL_PERIODS bounds how many missed periods of a schedule are
+	// generated at once, the rest follow on the next tick.
+	MAX_BACKFILL_PERIODS = 100
+)
+
+// Scheduler periodically generates the invoices of the periods that have
+// started. Periods missed while the server was down are generated in
+// order once it is back.
+type Scheduler struct {
+	schedules *Schedules
+	interval  time.Duration
+}
+
+func NewScheduler(ss *Schedules, interval time.Duration) *Scheduler {
+	return &Scheduler{schedules: ss, interval: interval}
+}
+
+// SchedulerFromEnv reads the interval in seconds from
+// INVOICER_RECURRING_INTERVAL.
+func SchedulerFromEnv(ss *Schedules) *Scheduler {
+	interval := helpers.EnvInt("INVOICER_RECURRING_INTERVAL", int64(DEFAULT_SCHEDULER_INTERVAL/time.Second))
+	return NewScheduler(ss, time.Duration(interval)*time.Second)
+}
+
+func (s *Scheduler) generate(ctx context.Context) {
+	today := time.Now()
+	due, err := s.schedules.Due(ctx, today)
+	if err != nil {
+		log.Println("Error reading due recurring schedules:", err)
+		return
+	}
+
+	for _, schedule := range due {
+		generated := 0
+		for generated < MAX_BACKFILL_PERIODS {
+			ok, err := s.schedules.Generate(ctx, schedule.WorkspaceId, schedule.Id, today)
+			if err != nil {
+				log.Printf("Error generating an invoice of recurring schedule %d: %v", schedule.Id, err)
+				break
+			}
+			if !ok {
+				break
+			}
+			generated++
+		}
+
+		if generated > 0 {
+			log.Printf("Generated %d invoices of recurring schedule %d", generated, schedule.Id)
+		}
+	}
+}
+
+// Run generates due invoices once right away and then every interval,
+// until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.interval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.generate(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package recurring
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/invoice"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+const MAX_NAME_LENGTH = 256
+
+// checkPlaceholders reports placeholders of the text that don't name a
+// part of the period.
+func checkPlaceholders(fields *[]apperr.FieldError, field string, text string) {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !placeholders[match[1]] {
+			*fields = append(*fields, apperr.Field(field, fmt.Sprintf("{{period.%s}} is unknown, use start, end, year, month or quarter", match[1])))
+		}
+	}
+}
+
+// Validate checks the request and normalizes it in place. The invoice is
+// checked with the client defaults by the store, its dates are set per
+// period and cleared here.
+func Validate(req *pb.SaveRecurringScheduleRequest) error {
+	fields := []apperr.FieldError{}
+
+	if req.Name = strings.TrimSpace(req.Name); req.Name == "" {
+		fields = append(fields, apperr.Field("name", "must not be empty"))
+	} else if len(req.Name) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("name", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	if !slices.Contains(Cadences, req.Cadence) {
+		fields = append(fields, apperr.Field("cadence", "must be one of "+strings.Join(Cadences, ", ")))
+	}
+	req.Cron = strings.TrimSpace(req.Cron)
+	if req.Cadence == CADENCE_CRON {
+		if _, err := cron.ParseStandard(req.Cron); err != nil {
+			fields = append(fields, apperr.Field("cron", "must be a cron expression with 5 fields"))
+		}
+	} else if req.Cron != "" {
+		fields = append(fields, apperr.Field("cron", "must be empty unless the cadence is cron"))
+	}
+
+	start, err := time.Parse(time.DateOnly, req.StartDate)
+	if err != nil {
+		fields = append(fields, apperr.Field("startDate", "must be a date formatted as YYYY-MM-DD"))
+	}
+	if req.EndDate != "" {
+		end, err := time.Parse(time.DateOnly, req.EndDate)
+		if err != nil {
+			fields = append(fields, apperr.Field("endDate", "must be a date formatted as YYYY-MM-DD"))
+		} else if end.Before(start) {
+			fields = append(fields, apperr.Field("endDate", "must not be before the start date"))
+		}
+	}
+
+	if req.Invoice == nil {
+		fields = append(fields, apperr.Field("invoice", "is required"))
+	} else {
+		req.Invoice.IssueDate = ""
+		req.Invoice.DueDate = ""
+		if req.Invoice.Kind != "" && req.Invoice.Kind != invoice.KIND_INVOICE {
+			fields = append(fields, apperr.Field("invoice.kind", "must be invoice"))
+		}
+
+		checkPlaceholders(&fields, "invoice.notes", req.Invoice.Notes)
+		for i, item := range req.Invoice.Items {
+			if item != nil {
+				checkPlaceholders(&fields, fmt.Sprintf("invoice.items[%d].description", i), item.Description)
+			}
+		}
+		for i, discount := range req.Invoice.Discounts {
+			if discount != nil {
+				checkPlaceholders(&fields, fmt.Sprintf("invoice.discounts[%d].description", i), discount.Description)
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Recurring schedule is invalid", fields...)
+	}
+	return nil
+}
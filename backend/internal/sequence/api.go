@@ -0,0 +1,84 @@
+package sequence
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SequenceApi struct {
+	sequences *Sequences
+}
+
+func (sa *SequenceApi) GetSequencesList(w http.ResponseWriter, req *http.Request) {
+	sequences, err := sa.sequences.List(req.Context(), workspace.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading sequences"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetSequencesResponse{Sequences: sequences})
+}
+
+func (sa *SequenceApi) SaveSequence(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveSequenceRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	sequence, err := sa.sequences.Save(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Sequence couldn't be saved"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.SequenceResponse{Sequence: sequence})
+}
+
+// PreviewNumber takes the document type, issuer and date from the query
+// parameters type, issuer and date, which default to invoice, the
+// workspace's own sequence and today.
+func (sa *SequenceApi) PreviewNumber(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	document_type := query.Get("type")
+	if document_type == "" {
+		document_type = DOC_INVOICE
+	}
+
+	var issuer_id uint64
+	if issuer := query.Get("issuer"); issuer != "" {
+		var err error
+		if issuer_id, err = strconv.ParseUint(issuer, 10, 32); err != nil {
+			apperr.Write(w, req, apperr.Invalid("Invalid issuer", apperr.Field("issuer", "must be an ID")))
+			return
+		}
+	}
+
+	date := time.Now()
+	if value := query.Get("date"); value != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, value); err != nil {
+			apperr.Write(w, req, apperr.Invalid("Invalid date", apperr.Field("date", "must be a date formatted as YYYY-MM-DD")))
+			return
+		}
+	}
+
+	number, sequence, err := sa.sequences.Preview(req.Context(), workspace.IdFromContext(req.Context()), document_type, uint32(issuer_id), date)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error previewing the next number"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.SequencePreviewResponse{Number: number, Sequence: sequence})
+}
+
+func NewSequenceApi(ss *Sequences) *SequenceApi {
+	return &SequenceApi{sequences: ss}
+}
@@ -0,0 +1,114 @@
+package sequence
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RESET_NEVER   = "never"
+	RESET_YEARLY  = "yearly"
+	RESET_MONTHLY = "monthly"
+
+	MAX_PATTERN_LENGTH = 64
+	MAX_PREFIX_LENGTH  = 16
+	MAX_SEQ_WIDTH      = 12
+)
+
+var tokenPattern = regexp.MustCompile(`\{([A-Za-z]+)(?::(\d+))?\}`)
+
+// Period names the span of dates that share a counter, e.g. 2024 for
+// yearly resets. Sequences that never reset have a single, empty period.
+func Period(reset string, date time.Time) string {
+	switch reset {
+	case RESET_YEARLY:
+		return date.Format("2006")
+	case RESET_MONTHLY:
+		return date.Format("2006-01")
+	}
+	return ""
+}
+
+// Format renders the number of the value-th document dated date.
+func Format(pattern string, prefix string, date time.Time, value int64) string {
+	return tokenPattern.ReplaceAllStringFunc(pattern, func(token string) string {
+		match := tokenPattern.FindStringSubmatch(token)
+		switch match[1] {
+		case "PREFIX":
+			return prefix
+		case "YYYY":
+			return date.Format("2006")
+		case "YY":
+			return date.Format("06")
+		case "MM":
+			return date.Format("01")
+		case "DD":
+			return date.Format("02")
+		case "seq":
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, value)
+		}
+		return token
+	})
+}
+
+// ValidatePattern checks that numbers of the pattern can't repeat: it needs
+// exactly one {seq}, and the year and month of the period it resets in.
+// Sequences of an issuer share the numbers of the workspace, so theirs also
+// need a prefix to tell them apart.
+func ValidatePattern(pattern string, prefix string, reset string, per_issuer bool) error {
+	fields := []apperr.FieldError{}
+
+	if reset != RESET_NEVER && reset != RESET_YEARLY && reset != RESET_MONTHLY {
+		fields = append(fields, apperr.Field("resetPeriod", "must be never, yearly or monthly"))
+	}
+
+	switch {
+	case len(prefix) > MAX_PREFIX_LENGTH || strings.ContainsAny(prefix, "{}"):
+		fields = append(fields, apperr.Field("prefix", fmt.Sprintf("must be at most %d characters, without braces", MAX_PREFIX_LENGTH)))
+	case per_issuer && strings.TrimSpace(prefix) == "":
+		fields = append(fields, apperr.Field("prefix", "must not be empty for the sequence of an issuer"))
+	}
+
+	tokens := map[string]int{}
+	for _, match := range tokenPattern.FindAllStringSubmatch(pattern, -1) {
+		switch match[1] {
+		case "PREFIX", "YYYY", "YY", "MM", "DD":
+			if match[2] != "" {
+				fields = append(fields, apperr.Field("pattern", fmt.Sprintf("{%s} takes no width", match[1])))
+			}
+		case "seq":
+			if width, _ := strconv.Atoi(match[2]); width > MAX_SEQ_WIDTH {
+				fields = append(fields, apperr.Field("pattern", fmt.Sprintf("{seq} must not be wider than %d digits", MAX_SEQ_WIDTH)))
+			}
+		default:
+			fields = append(fields, apperr.Field("pattern", fmt.Sprintf("{%s} is not a known placeholder", match[1])))
+		}
+		tokens[match[1]]++
+	}
+
+	literal := tokenPattern.ReplaceAllString(pattern, "")
+	switch {
+	case pattern == "" || len(pattern) > MAX_PATTERN_LENGTH:
+		fields = append(fields, apperr.Field("pattern", fmt.Sprintf("must be between 1 and %d characters", MAX_PATTERN_LENGTH)))
+	case strings.ContainsAny(literal, "{}"):
+		fields = append(fields, apperr.Field("pattern", "has unbalanced braces"))
+	case tokens["seq"] != 1:
+		fields = append(fields, apperr.Field("pattern", "must contain {seq} exactly once"))
+	case per_issuer && tokens["PREFIX"] == 0:
+		fields = append(fields, apperr.Field("pattern", "must contain {PREFIX}, as the issuer shares the numbers of the workspace"))
+	case reset != RESET_NEVER && tokens["YYYY"]+tokens["YY"] == 0:
+		fields = append(fields, apperr.Field("pattern", "must contain the year, as the counter starts over every year"))
+	case reset == RESET_MONTHLY && tokens["MM"] == 0:
+		fields = append(fields, apperr.Field("pattern", "must contain the month, as the counter starts over every month"))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Sequence is invalid", fields...)
+	}
+	return nil
+}
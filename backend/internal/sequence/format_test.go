@@ -0,0 +1,109 @@
+package sequence
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPeriod(t *testing.T) {
+	date := time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		reset string
+		want  string
+	}{
+		{RESET_NEVER, ""},
+		{RESET_YEARLY, "2026"},
+		{RESET_MONTHLY, "2026-03"},
+	}
+
+	for _, test := range tests {
+		if got := Period(test.reset, date); got != test.want {
+			t.Errorf("Period(%q) = %q, want %q", test.reset, got, test.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern string
+		prefix  string
+		value   int64
+		want    string
+	}{
+		{"INV-{YYYY}-{seq:05}", "", 42, "INV-2026-00042"},
+		{"{PREFIX}-{YY}{MM}-{seq:03}", "ACME", 7, "ACME-2603-007"},
+		{"{YYYY}/{MM}/{DD}/{seq}", "", 12345, "2026/03/07/12345"},
+		{"{seq:02}", "", 123, "123"},
+		{"{PREFIX}{seq}", "", 1, "1"},
+		{"{unknown}-{seq}", "", 1, "{unknown}-1"},
+	}
+
+	for _, test := range tests {
+		if got := Format(test.pattern, test.prefix, date, test.value); got != test.want {
+			t.Errorf("Format(%q, %q, %d) = %q, want %q", test.pattern, test.prefix, test.value, got, test.want)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		prefix     string
+		reset      string
+		per_issuer bool
+		// Fields reported as invalid, none if the pattern is valid.
+		fields []string
+	}{
+		{name: "default", pattern: "INV-{YYYY}-{seq:05}", reset: RESET_YEARLY},
+		{name: "never reset", pattern: "INV-{seq}", reset: RESET_NEVER},
+		{name: "monthly", pattern: "{YY}{MM}-{seq:04}", reset: RESET_MONTHLY},
+		{name: "issuer", pattern: "{PREFIX}-{YYYY}-{seq}", prefix: "AT", reset: RESET_YEARLY, per_issuer: true},
+		{name: "unknown reset", pattern: "INV-{YYYY}-{seq}", reset: "weekly", fields: []string{"resetPeriod"}},
+		{name: "empty", pattern: "", reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "too long", pattern: "INV-{seq}-" + strings.Repeat("0", MAX_PATTERN_LENGTH), reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "no seq", pattern: "INV-{YYYY}", reset: RESET_YEARLY, fields: []string{"pattern"}},
+		{name: "two seqs", pattern: "{seq}-{seq}", reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "seq too wide", pattern: "{seq:13}", reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "width on a date", pattern: "{YYYY:4}-{seq}", reset: RESET_YEARLY, fields: []string{"pattern"}},
+		{name: "unknown placeholder", pattern: "{NAME}-{seq}", reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "unbalanced braces", pattern: "INV-{seq}}", reset: RESET_NEVER, fields: []string{"pattern"}},
+		{name: "yearly without year", pattern: "INV-{seq}", reset: RESET_YEARLY, fields: []string{"pattern"}},
+		{name: "monthly without month", pattern: "INV-{YYYY}-{seq}", reset: RESET_MONTHLY, fields: []string{"pattern"}},
+		{name: "prefix too long", pattern: "{PREFIX}-{seq}", prefix: "ABCDEFGHIJKLMNOPQ", reset: RESET_NEVER, fields: []string{"prefix"}},
+		{name: "prefix with braces", pattern: "{PREFIX}-{seq}", prefix: "{seq}", reset: RESET_NEVER, fields: []string{"prefix"}},
+		{name: "issuer without prefix", pattern: "{PREFIX}-{YYYY}-{seq}", prefix: " ", reset: RESET_YEARLY, per_issuer: true, fields: []string{"prefix"}},
+		{name: "issuer without {PREFIX}", pattern: "AT-{YYYY}-{seq}", prefix: "AT", reset: RESET_YEARLY, per_issuer: true, fields: []string{"pattern"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidatePattern(test.pattern, test.prefix, test.reset, test.per_issuer)
+			if len(test.fields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidatePattern() error = %v, want none", err)
+				}
+				return
+			}
+
+			var app_err *apperr.Error
+			if !errors.As(err, &app_err) {
+				t.Fatalf("ValidatePattern() error = %v, want a field error", err)
+			}
+			fields := []string{}
+			for _, field := range app_err.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, test.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
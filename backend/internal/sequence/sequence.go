@@ -0,0 +1,447 @@
+package sequence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	DOC_INVOICE     = "invoice"
+	DOC_CREDIT_NOTE = "credit_note"
+	DOC_QUOTE       = "quote"
+)
+
+var DocumentTypes = []string{DOC_INVOICE, DOC_CREDIT_NOTE, DOC_QUOTE}
+
+// Numbering of document types whose workspace hasn't configured any.
+var defaultPatterns = map[string]string{
+	DOC_INVOICE:     "INV-{YYYY}-{seq:05}",
+	DOC_CREDIT_NOTE: "CN-{YYYY}-{seq:05}",
+	DOC_QUOTE:       "Q-{YYYY}-{seq:05}",
+}
+
+const DEFAULT_RESET = RESET_YEARLY
+
+var (
+	ErrUnknownDocumentType = apperr.Invalid("Unknown document type", apperr.Field("documentType", "must be one of the known document types"))
+	ErrIssuerNotFound      = apperr.Invalid("Issuer doesn't exist", apperr.Field("issuerId", "no such issuer in this workspace"))
+	ErrPrefixTaken         = apperr.Invalid("Prefix is taken", apperr.Field("prefix", "another issuer already numbers with this prefix"))
+)
+
+const (
+	AUDIT_TARGET = "sequence"
+	AUDIT_SAVE   = "sequence.save"
+)
+
+// Sequences hand out gapless document numbers. A number is allocated in the
+// transaction that stores the document, so that numbers of transactions
+// rolled back are handed out again.
+type Sequences struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	list_stmt, retrieve_stmt, insert_stmt, update_stmt, current_stmt, counter_stmt, next_stmt, issuer_exists_stmt, prefix_taken_stmt *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSequence(row scanner) (*pb.Sequence, error) {
+	sequence := &pb.Sequence{}
+	err := row.Scan(
+		&sequence.Id,
+		&sequence.DocumentType,
+		&sequence.IssuerId,
+		&sequence.Pattern,
+		&sequence.Prefix,
+		&sequence.ResetPeriod,
+		&sequence.CreatedAt,
+		&sequence.UpdatedAt,
+		&sequence.CreatedBy,
+		&sequence.UpdatedBy,
+		&sequence.WorkspaceId,
+	)
+	return sequence, err
+}
+
+func auditFields(s *pb.Sequence) map[string]string {
+	return map[string]string{
+		"document_type": s.DocumentType,
+		"issuer_id":     fmt.Sprint(s.IssuerId),
+		"pattern":       s.Pattern,
+		"prefix":        s.Prefix,
+		"reset_period":  s.ResetPeriod,
+	}
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, args ...any) *sql.Row
+}
+
+// resolve finds the sequence of the issuer, falling back to that of the
+// workspace and then to the default one, which has no ID until it is used.
+func (ss *Sequences) resolve(ctx context.Context, stmt queryRower, workspace_id uint32, document_type string, issuer_id uint32) (*pb.Sequence, error) {
+	if !slices.Contains(DocumentTypes, document_type) {
+		return nil, ErrUnknownDocumentType
+	}
+
+	for _, id := range []uint32{issuer_id, 0} {
+		sequence, err := scanSequence(stmt.QueryRowContext(ctx, workspace_id, document_type, id))
+		if err == nil {
+			return sequence, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	return &pb.Sequence{
+		DocumentType: document_type,
+		Pattern:      defaultPatterns[document_type],
+		ResetPeriod:  DEFAULT_RESET,
+		WorkspaceId:  workspace_id,
+	}, nil
+}
+
+func (ss *Sequences) List(ctx context.Context, workspace_id uint32) (_ []*pb.Sequence, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Sequences.List")
+	defer end(&err)
+
+	rows, err := ss.list_stmt.QueryContext(ctx, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sequences := []*pb.Sequence{}
+	for rows.Next() {
+		sequence, err := scanSequence(rows)
+		if err != nil {
+			return nil, err
+		}
+		sequences = append(sequences, sequence)
+	}
+
+	return sequences, rows.Err()
+}
+
+// Save configures the sequence of a document type and issuer. Counters are
+// kept, so changing the pattern mid-year carries on where the old one
+// stopped.
+func (ss *Sequences) Save(ctx context.Context, workspace_id uint32, req *pb.SaveSequenceRequest, actor *audit.Actor) (_ *pb.Sequence, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Sequences.Save")
+	defer end(&err)
+
+	if !slices.Contains(DocumentTypes, req.DocumentType) {
+		return nil, ErrUnknownDocumentType
+	}
+	req.Prefix = strings.TrimSpace(req.Prefix)
+	if err = ValidatePattern(req.Pattern, req.Prefix, req.ResetPeriod, req.IssuerId != 0); err != nil {
+		return nil, err
+	}
+	if req.IssuerId != 0 {
+		var exists int
+		err = ss.issuer_exists_stmt.QueryRowContext(ctx, req.IssuerId, workspace_id).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return nil, ErrIssuerNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := ss.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if req.IssuerId != 0 {
+		var taken int
+		err = tx.Stmt(ss.prefix_taken_stmt).QueryRowContext(ctx, workspace_id, req.Prefix, req.IssuerId).Scan(&taken)
+		if err == nil {
+			return nil, ErrPrefixTaken
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	now := time.Now().Unix()
+	var before map[string]string
+	previous, err := scanSequence(tx.Stmt(ss.retrieve_stmt).QueryRowContext(ctx, workspace_id, req.DocumentType, req.IssuerId))
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Stmt(ss.insert_stmt).ExecContext(
+			ctx,
+			req.DocumentType,
+			req.IssuerId,
+			req.Pattern,
+			req.Prefix,
+			req.ResetPeriod,
+			now,
+			now,
+			helpers.NullableId(actor.UserId),
+			helpers.NullableId(actor.UserId),
+			workspace_id,
+		)
+	case err == nil:
+		before = auditFields(previous)
+		_, err = tx.Stmt(ss.update_stmt).ExecContext(
+			ctx,
+			req.Pattern,
+			req.Prefix,
+			req.ResetPeriod,
+			now,
+			helpers.NullableId(actor.UserId),
+			previous.Id,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := scanSequence(tx.Stmt(ss.retrieve_stmt).QueryRowContext(ctx, workspace_id, req.DocumentType, req.IssuerId))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_SAVE, AUDIT_TARGET, sequence.Id, audit.Diff(before, auditFields(sequence)))
+	if err = ss.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sequence, nil
+}
+
+// Preview returns the number the next document dated date would get, if
+// no other one is issued first. Nothing is allocated.
+func (ss *Sequences) Preview(ctx context.Context, workspace_id uint32, document_type string, issuer_id uint32, date time.Time) (_ string, _ *pb.Sequence, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Sequences.Preview")
+	defer end(&err)
+
+	sequence, err := ss.resolve(ctx, ss.retrieve_stmt, workspace_id, document_type, issuer_id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var current int64
+	err = ss.current_stmt.QueryRowContext(ctx, sequence.Id, Period(sequence.ResetPeriod, date)).Scan(&current)
+	if err != nil && err != sql.ErrNoRows {
+		return "", nil, err
+	}
+
+	return Format(sequence.Pattern, sequence.Prefix, date, current+1), sequence, nil
+}
+
+// Allocate hands out the next number of the sequence within tx, which has
+// to store the document as well. The counter row of the period is created
+// if it is missing and then bumped by an update, which locks the row until
+// tx ends: concurrent allocations wait for each other and a rollback gives
+// the number back.
+func (ss *Sequences) Allocate(ctx context.Context, tx *sql.Tx, workspace_id uint32, document_type string, issuer_id uint32, date time.Time) (_ string, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Sequences.Allocate")
+	defer end(&err)
+
+	sequence, err := ss.resolve(ctx, tx.Stmt(ss.retrieve_stmt), workspace_id, document_type, issuer_id)
+	if err != nil {
+		return "", err
+	}
+
+	if sequence.Id == 0 {
+		now := time.Now().Unix()
+		res, err := tx.Stmt(ss.insert_stmt).ExecContext(
+			ctx,
+			sequence.DocumentType,
+			0,
+			sequence.Pattern,
+			sequence.Prefix,
+			sequence.ResetPeriod,
+			now,
+			now,
+			nil,
+			nil,
+			workspace_id,
+		)
+		if err != nil {
+			return "", err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return "", err
+		}
+		sequence.Id = uint32(id)
+	}
+
+	period := Period(sequence.ResetPeriod, date)
+	if _, err = tx.Stmt(ss.counter_stmt).ExecContext(ctx, sequence.Id, period); err != nil {
+		return "", err
+	}
+
+	var value int64
+	err = tx.Stmt(ss.next_stmt).QueryRowContext(ctx, sequence.Id, period).Scan(&value)
+	if err != nil {
+		return "", err
+	}
+
+	return Format(sequence.Pattern, sequence.Prefix, date, value), nil
+}
+
+func (ss *Sequences) Close() error {
+	stmts := []*sql.Stmt{
+		ss.list_stmt,
+		ss.retrieve_stmt,
+		ss.insert_stmt,
+		ss.update_stmt,
+		ss.current_stmt,
+		ss.counter_stmt,
+		ss.next_stmt,
+		ss.issuer_exists_stmt,
+		ss.prefix_taken_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const SEQUENCE_COLUMNS = `
+	sequence_id,
+	sequence_document_type,
+	sequence_issuer_id,
+	sequence_pattern,
+	sequence_prefix,
+	sequence_reset_period,
+	sequence_created_at,
+	sequence_updated_at,
+	COALESCE(sequence_created_by, 0),
+	COALESCE(sequence_updated_by, 0),
+	sequence_workspace_id
+`
+
+func NewSequences(db *sql.DB, audit_log *audit.Log) (*Sequences, error) {
+	list_stmt, err := db.Prepare(`
+		SELECT ` + SEQUENCE_COLUMNS + `
+		FROM sequences
+		WHERE sequence_workspace_id = ?
+		ORDER BY sequence_document_type, sequence_issuer_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + SEQUENCE_COLUMNS + `
+		FROM sequences
+		WHERE sequence_workspace_id = ? AND sequence_document_type = ? AND sequence_issuer_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO sequences (
+			sequence_document_type,
+			sequence_issuer_id,
+			sequence_pattern,
+			sequence_prefix,
+			sequence_reset_period,
+			sequence_created_at,
+			sequence_updated_at,
+			sequence_created_by,
+			sequence_updated_by,
+			sequence_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE sequences
+		SET sequence_pattern = ?,
+			sequence_prefix = ?,
+			sequence_reset_period = ?,
+			sequence_updated_at = ?,
+			sequence_updated_by = ?
+		WHERE sequence_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	current_stmt, err := db.Prepare(`
+		SELECT sequence_counter_value
+		FROM sequence_counters
+		WHERE sequence_counter_sequence_id = ? AND sequence_counter_period = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	counter_stmt, err := db.Prepare(`
+		INSERT INTO sequence_counters (sequence_counter_sequence_id, sequence_counter_period, sequence_counter_value)
+		VALUES(?, ?, 0)
+		ON CONFLICT (sequence_counter_sequence_id, sequence_counter_period) DO NOTHING
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	next_stmt, err := db.Prepare(`
+		UPDATE sequence_counters
+		SET sequence_counter_value = sequence_counter_value + 1
+		WHERE sequence_counter_sequence_id = ? AND sequence_counter_period = ?
+		RETURNING sequence_counter_value
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer_exists_stmt, err := db.Prepare("SELECT 1 FROM issuers WHERE issuer_id = ? AND issuer_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	prefix_taken_stmt, err := db.Prepare(`
+		SELECT 1
+		FROM sequences
+		WHERE sequence_workspace_id = ? AND sequence_prefix = ? AND sequence_issuer_id NOT IN (0, ?)
+		LIMIT 1
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sequences{
+		db:                 db,
+		audit:              audit_log,
+		list_stmt:          list_stmt,
+		retrieve_stmt:      retrieve_stmt,
+		insert_stmt:        insert_stmt,
+		update_stmt:        update_stmt,
+		current_stmt:       current_stmt,
+		counter_stmt:       counter_stmt,
+		next_stmt:          next_stmt,
+		issuer_exists_stmt: issuer_exists_stmt,
+		prefix_taken_stmt:  prefix_taken_stmt,
+	}, nil
+}
@@ -0,0 +1,137 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/database"
+	pb "invoice-manager/main/proto"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSequences returns sequences on a database of its own, with issuers
+// 1 and 2 in the default workspace and issuer 3 in workspace 2.
+func newTestSequences(t *testing.T) *Sequences {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec("INSERT INTO workspaces (workspace_id, workspace_slug, workspace_name, workspace_created_at, workspace_updated_at) VALUES(2, 'other', 'Other', unixepoch(), unixepoch())"); err != nil {
+		t.Fatal(err)
+	}
+	for _, workspace_id := range []int{1, 1, 2} {
+		_, err = db.Exec("INSERT INTO issuers (issuer_name, issuer_legal_name, issuer_created_at, issuer_updated_at, issuer_workspace_id) VALUES('Acme', 'Acme GmbH', unixepoch(), unixepoch(), ?)", workspace_id)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := NewSequences(db, audit_log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	return ss
+}
+
+func TestSavePrefix(t *testing.T) {
+	ss := newTestSequences(t)
+
+	tests := []struct {
+		name         string
+		workspace_id uint32
+		req          *pb.SaveSequenceRequest
+		want         error
+		// Fields reported as invalid, for errors that aren't sentinels.
+		fields []string
+	}{
+		{
+			name:         "issuer",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, IssuerId: 1, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "AT", ResetPeriod: RESET_YEARLY},
+		},
+		{
+			name:         "same prefix for another document type of the issuer",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_CREDIT_NOTE, IssuerId: 1, Pattern: "{PREFIX}-CN-{YYYY}-{seq}", Prefix: "AT", ResetPeriod: RESET_YEARLY},
+		},
+		{
+			name:         "prefix of another issuer",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, IssuerId: 2, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "AT", ResetPeriod: RESET_YEARLY},
+			want:         ErrPrefixTaken,
+		},
+		{
+			name:         "prefix of another issuer with spaces",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_QUOTE, IssuerId: 2, Pattern: "{PREFIX}-Q-{seq}", Prefix: " AT ", ResetPeriod: RESET_NEVER},
+			want:         ErrPrefixTaken,
+		},
+		{
+			name:         "prefix of its own",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, IssuerId: 2, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "DE", ResetPeriod: RESET_YEARLY},
+		},
+		{
+			name:         "workspace sequence with the prefix of an issuer",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "AT", ResetPeriod: RESET_YEARLY},
+		},
+		{
+			name:         "prefix of an issuer in another workspace",
+			workspace_id: 2,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, IssuerId: 3, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "AT", ResetPeriod: RESET_YEARLY},
+		},
+		{
+			name:         "issuer of another workspace",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_INVOICE, IssuerId: 3, Pattern: "{PREFIX}-{YYYY}-{seq}", Prefix: "CH", ResetPeriod: RESET_YEARLY},
+			want:         ErrIssuerNotFound,
+		},
+		{
+			name:         "issuer without prefix",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_QUOTE, IssuerId: 1, Pattern: "{PREFIX}-Q-{seq}", ResetPeriod: RESET_NEVER},
+			fields:       []string{"prefix"},
+		},
+		{
+			name:         "issuer without {PREFIX}",
+			workspace_id: 1,
+			req:          &pb.SaveSequenceRequest{DocumentType: DOC_QUOTE, IssuerId: 1, Pattern: "AT-Q-{seq}", Prefix: "AT", ResetPeriod: RESET_NEVER},
+			fields:       []string{"pattern"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sequence, err := ss.Save(context.Background(), test.workspace_id, test.req, audit.CliActor)
+			switch {
+			case test.want != nil:
+				if !errors.Is(err, test.want) {
+					t.Errorf("Save() error = %v, want %v", err, test.want)
+				}
+			case len(test.fields) > 0:
+				var app_err *apperr.Error
+				if !errors.As(err, &app_err) || len(app_err.Fields) != len(test.fields) || app_err.Fields[0].Field != test.fields[0] {
+					t.Errorf("Save() error = %v, want %v invalid", err, test.fields)
+				}
+			case err != nil:
+				t.Errorf("Save() error = %v", err)
+			case sequence.Prefix != test.req.Prefix || sequence.IssuerId != test.req.IssuerId:
+				t.Errorf("Save() = issuer %d with prefix %q, want issuer %d with %q", sequence.IssuerId, sequence.Prefix, test.req.IssuerId, test.req.Prefix)
+			}
+		})
+	}
+}
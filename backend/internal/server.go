@@ -1,13 +1,45 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"invoice-manager/main/internal/apikey"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/auth"
+	"invoice-manager/main/internal/catalog"
+	"invoice-manager/main/internal/cli"
+	"invoice-manager/main/internal/client"
 	"invoice-manager/main/internal/constants"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/exchange"
+	"invoice-manager/main/internal/health"
+	"invoice-manager/main/internal/invoice"
+	"invoice-manager/main/internal/issuer"
+	"invoice-manager/main/internal/lifecycle"
+	"invoice-manager/main/internal/payment"
 	"invoice-manager/main/internal/ping"
+	"invoice-manager/main/internal/purge"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/ratelimit"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/recurring"
+	"invoice-manager/main/internal/sequence"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/tax"
+	"invoice-manager/main/internal/telemetry"
 	"invoice-manager/main/internal/template"
+	"invoice-manager/main/internal/user"
+	"invoice-manager/main/internal/workspace"
+	pbconnect "invoice-manager/main/proto/protoconnect"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	"golang.org/x/net/http2"
@@ -32,6 +64,8 @@ func handleCors() *cors.Cors {
 		},
 		AllowedHeaders: []string{
 			"Content-Type",             // for all protocols
+			"Authorization",            // for API keys
+			"X-Workspace",              // for selecting the workspace
 			"Connect-Protocol-Version", // for Connect
 			"Connect-Timeout-Ms",       // for Connect
 			"Grpc-Timeout",             // for gRPC-web
@@ -42,40 +76,347 @@ func handleCors() *cors.Cors {
 			"Grpc-Status",             // for gRPC-web
 			"Grpc-Message",            // for gRPC-web
 			"Grpc-Status-Details-Bin", // for gRPC-web
+			"RateLimit-Limit",         // for rate limiting
+			"RateLimit-Remaining",     // for rate limiting
+			"RateLimit-Reset",         // for rate limiting
+			"Retry-After",             // for rate limiting and quotas
 		},
-		MaxAge: 7200, // 2 hours in seconds
+		AllowCredentials: true, // for the session cookie
+		MaxAge:           7200, // 2 hours in seconds
 	})
 }
 
 type Api struct {
 	TemplatesApi *template.TemplateApi
+	UsersApi     *user.UserApi
+	ApiKeysApi   *apikey.ApiKeyApi
+	WorkspaceApi *workspace.WorkspaceApi
+	AuditApi     *audit.AuditApi
+	UsageApi     *quota.UsageApi
+	ClientsApi   *client.ClientApi
+	InvoicesApi  *invoice.InvoiceApi
+	SequencesApi *sequence.SequenceApi
+	PaymentsApi  *payment.PaymentApi
+	RecurringApi *recurring.RecurringApi
+	ExchangeApi  *exchange.ExchangeApi
+	TaxApi       *tax.TaxApi
+	CatalogApi   *catalog.CatalogApi
+	IssuersApi   *issuer.IssuerApi
 }
 
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+func serve() error {
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err = database.Migrate(db); err != nil {
+		return err
+	}
+
+	shutdown_tracing, err := telemetry.InitTracing(context.Background())
+	if err != nil {
+		return err
+	}
+	defer shutdown_tracing(context.Background())
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		return err
+	}
+
+	qs, err := quota.NewQuotas(db, quota.LimitsFromEnv())
+	if err != nil {
+		return err
+	}
+
+	ts, err := template.NewTemplates(db, audit_log, qs)
+	if err != nil {
+		return err
+	}
+
+	us, err := user.NewUsers(db)
+	if err != nil {
+		return err
+	}
+
+	ks, err := apikey.NewApiKeys(db)
+	if err != nil {
+		return err
+	}
+
+	ws, err := workspace.NewWorkspaces(db)
+	if err != nil {
+		return err
+	}
+
+	rs, err := rbac.NewRoles(db)
+	if err != nil {
+		return err
+	}
+
+	cs, err := client.NewClients(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	ss, err := sequence.NewSequences(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	xs, err := exchange.NewRates(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	taxes, err := tax.NewTaxRates(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	items, err := catalog.NewCatalog(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	issuers, err := issuer.NewIssuers(db, audit_log)
+	if err != nil {
+		return err
+	}
+
+	is, err := invoice.NewInvoices(db, audit_log, qs, cs, ts, ss, xs, taxes, items, issuers)
+	if err != nil {
+		return err
+	}
+
+	ps, err := payment.NewPayments(db, audit_log, cs, is)
+	if err != nil {
+		return err
+	}
+	is.SetRefunds(ps)
+
+	schedules, err := recurring.NewSchedules(db, audit_log, is)
+	if err != nil {
+		return err
+	}
+
+	limiter := ratelimit.LimiterFromEnv()
+
 	api := &Api{
-		TemplatesApi: template.NewTemplateApi(),
+		TemplatesApi: template.NewTemplateApi(ts),
+		UsersApi:     user.NewUserApi(us),
+		ApiKeysApi:   apikey.NewApiKeyApi(ks),
+		WorkspaceApi: workspace.NewWorkspaceApi(ws, us, rs),
+		AuditApi:     audit.NewAuditApi(audit_log),
+		UsageApi:     quota.NewUsageApi(qs, limiter),
+		ClientsApi:   client.NewClientApi(cs),
+		InvoicesApi:  invoice.NewInvoiceApi(is),
+		SequencesApi: sequence.NewSequenceApi(ss),
+		PaymentsApi:  payment.NewPaymentApi(ps),
+		RecurringApi: recurring.NewRecurringApi(schedules),
+		ExchangeApi:  exchange.NewExchangeApi(xs),
+		TaxApi:       tax.NewTaxApi(taxes),
+		CatalogApi:   catalog.NewCatalogApi(items),
+		IssuersApi:   issuer.NewIssuerApi(issuers),
 	}
+	authenticator := auth.NewAuthenticator(us, ks, ws, rs)
+
+	checker := health.NewChecker(pbconnect.PingServiceName, pbconnect.ClientServiceName)
+	checker.AddLiveness("database", health.Database(db))
+	checker.Add("disk", health.DiskSpaceFromEnv(storage.STATIC_DIR))
+	checker.Add("converter", template.CheckConverter)
+	checker.Add("queue", health.QueueBacklogFromEnv())
 
 	r := mux.NewRouter()
+	r.Use(telemetry.Middleware)
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		apperr.Write(w, req, apperr.New(apperr.CODE_NOT_FOUND, "no such route"))
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		apperr.Write(w, req, apperr.New(apperr.CODE_METHOD_NOT_ALLOWED, req.Method+" is not allowed on this route"))
+	})
+	r.HandleFunc("/auth/login", limiter.Limit(api.UsersApi.Login)).Methods("POST")
+	r.HandleFunc("/auth/logout", limiter.Limit(api.UsersApi.Logout)).Methods("POST")
+	r.Handle("/metrics", telemetry.MetricsHandler()).Methods("GET")
+	r.HandleFunc("/healthz", checker.Live).Methods("GET")
+	r.HandleFunc("/readyz", checker.Ready).Methods("GET")
 
 	// gRPC services
-	r.Handle(ping.PingServiceHandler())
+	interceptors := connect.WithInterceptors(telemetry.Interceptor(), authenticator.Interceptor(nil), limiter.Interceptor())
+	ping_path, ping_handler := ping.PingServiceHandler(interceptors)
+	r.PathPrefix(ping_path).Handler(auth.WithRequestHost(ping_handler))
+	client_path, client_handler := client.ClientServiceHandler(cs, connect.WithInterceptors(telemetry.Interceptor(), authenticator.Interceptor(client.Permissions), limiter.Interceptor()))
+	r.PathPrefix(client_path).Handler(auth.WithRequestHost(client_handler))
+
+	// Health checks are for orchestrators and load balancers, which don't
+	// authenticate
+	health_path, health_handler := grpchealth.NewHandler(checker, connect.WithInterceptors(telemetry.Interceptor()))
+	r.PathPrefix(health_path).Handler(health_handler)
 
 	// Rest API
-	r.PathPrefix("/" + template.STATIC_DIR).Handler(http.FileServer(http.Dir(".")))
-	r.HandleFunc("/templates", api.TemplatesApi.GetTemplatesList).Methods("GET")
-	r.HandleFunc("/templates", api.TemplatesApi.UploadFile).Methods("POST")
-	r.HandleFunc("/templates/{id:[0-9]+}", api.TemplatesApi.UpdateTemplate).Methods("PATCH")
-	r.HandleFunc("/templates/{id:[0-9]+}", api.TemplatesApi.DeleteTemplate).Methods("DELETE")
-	r.HandleFunc("/templates/{id:[0-9]+}/html", api.TemplatesApi.UpdateTemplateHtml).Methods("PUT")
+	authenticated := r.NewRoute().Subrouter()
+	authenticated.Use(authenticator.Middleware, limiter.Middleware)
+
+	authenticated.HandleFunc("/auth/me", api.UsersApi.GetCurrentUser).Methods("GET")
+	authenticated.HandleFunc("/workspaces", api.WorkspaceApi.GetWorkspacesList).Methods("GET")
+	authenticated.HandleFunc("/workspaces", auth.RequireSession(api.WorkspaceApi.CreateWorkspace)).Methods("POST")
+	authenticated.HandleFunc("/workspaces/{id:[0-9]+}/switch", auth.RequireSession(api.WorkspaceApi.SwitchWorkspace)).Methods("POST")
+
+	// Everything below works on the data of the resolved workspace
+	in_workspace := authenticated.NewRoute().Subrouter()
+	in_workspace.Use(authenticator.WorkspaceMiddleware)
+
+	// Every handler below is wrapped with the permission it needs
+	can := authenticator.Require
+
+	in_workspace.HandleFunc("/workspace", api.WorkspaceApi.GetCurrentWorkspace).Methods("GET")
+	in_workspace.HandleFunc("/workspace/permissions", api.WorkspaceApi.GetPermissions).Methods("GET")
+	in_workspace.HandleFunc("/workspace/base-currency", can(rbac.PERM_WORKSPACE_MANAGE, api.WorkspaceApi.SetBaseCurrency)).Methods("PUT")
+	in_workspace.HandleFunc("/workspace/tax-details", can(rbac.PERM_WORKSPACE_MANAGE, api.WorkspaceApi.SetTaxDetails)).Methods("PUT")
+	in_workspace.HandleFunc("/workspace/usage", api.UsageApi.GetUsage).Methods("GET")
+	in_workspace.HandleFunc("/workspace/members", can(rbac.PERM_MEMBERS_READ, api.WorkspaceApi.GetMembersList)).Methods("GET")
+	in_workspace.HandleFunc("/workspace/members", can(rbac.PERM_MEMBERS_MANAGE, api.WorkspaceApi.AddMember)).Methods("POST")
+	in_workspace.HandleFunc("/workspace/members/{user_id:[0-9]+}", can(rbac.PERM_MEMBERS_MANAGE, api.WorkspaceApi.RemoveMember)).Methods("DELETE")
+	in_workspace.HandleFunc("/workspace/members/{user_id:[0-9]+}/role", can(rbac.PERM_MEMBERS_MANAGE, api.WorkspaceApi.SetMemberRole)).Methods("PUT")
+	in_workspace.HandleFunc("/workspace/roles", can(rbac.PERM_MEMBERS_READ, api.WorkspaceApi.GetRolesList)).Methods("GET")
+	in_workspace.HandleFunc("/workspace/roles", can(rbac.PERM_ROLES_MANAGE, api.WorkspaceApi.CreateRole)).Methods("POST")
+	in_workspace.HandleFunc("/workspace/roles/{id:[0-9]+}", can(rbac.PERM_ROLES_MANAGE, api.WorkspaceApi.UpdateRole)).Methods("PUT")
+	in_workspace.HandleFunc("/workspace/roles/{id:[0-9]+}", can(rbac.PERM_ROLES_MANAGE, api.WorkspaceApi.DeleteRole)).Methods("DELETE")
+
+	in_workspace.HandleFunc("/audit", can(rbac.PERM_AUDIT_READ, api.AuditApi.GetAuditLog)).Methods("GET")
+	in_workspace.HandleFunc("/audit/export", can(rbac.PERM_AUDIT_READ, api.AuditApi.ExportAuditLog)).Methods("GET")
+
+	in_workspace.HandleFunc("/api-keys", can(rbac.PERM_API_KEYS_MANAGE, api.ApiKeysApi.GetApiKeysList)).Methods("GET")
+	in_workspace.HandleFunc("/api-keys", can(rbac.PERM_API_KEYS_MANAGE, api.ApiKeysApi.CreateApiKey)).Methods("POST")
+	in_workspace.HandleFunc("/api-keys/{id:[0-9]+}", can(rbac.PERM_API_KEYS_MANAGE, api.ApiKeysApi.RevokeApiKey)).Methods("DELETE")
+
+	in_workspace.PathPrefix("/" + storage.STATIC_DIR).Handler(can(rbac.PERM_TEMPLATES_READ, storage.FileServer().ServeHTTP))
+	in_workspace.HandleFunc("/templates", can(rbac.PERM_TEMPLATES_READ, api.TemplatesApi.GetTemplatesList)).Methods("GET")
+	in_workspace.HandleFunc("/templates", can(rbac.PERM_TEMPLATES_WRITE, api.TemplatesApi.UploadFile)).Methods("POST")
+	in_workspace.HandleFunc("/templates/{id:[0-9]+}", can(rbac.PERM_TEMPLATES_WRITE, api.TemplatesApi.UpdateTemplate)).Methods("PATCH")
+	in_workspace.HandleFunc("/templates/{id:[0-9]+}", can(rbac.PERM_TEMPLATES_DELETE, api.TemplatesApi.DeleteTemplate)).Methods("DELETE")
+	in_workspace.HandleFunc("/templates/{id:[0-9]+}/html", can(rbac.PERM_TEMPLATES_WRITE, api.TemplatesApi.UpdateTemplateHtml)).Methods("PUT")
+
+	in_workspace.HandleFunc("/clients", can(rbac.PERM_CLIENTS_READ, api.ClientsApi.GetClientsList)).Methods("GET")
+	in_workspace.HandleFunc("/clients", can(rbac.PERM_CLIENTS_WRITE, api.ClientsApi.CreateClient)).Methods("POST")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}", can(rbac.PERM_CLIENTS_READ, api.ClientsApi.GetClient)).Methods("GET")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}", can(rbac.PERM_CLIENTS_WRITE, api.ClientsApi.UpdateClient)).Methods("PUT")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}", can(rbac.PERM_CLIENTS_WRITE, api.ClientsApi.DeleteClient)).Methods("DELETE")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}/archive", can(rbac.PERM_CLIENTS_WRITE, api.ClientsApi.ArchiveClient)).Methods("POST")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}/unarchive", can(rbac.PERM_CLIENTS_WRITE, api.ClientsApi.UnarchiveClient)).Methods("POST")
+
+	in_workspace.HandleFunc("/catalog", can(rbac.PERM_CATALOG_READ, api.CatalogApi.GetCatalogItemsList)).Methods("GET")
+	in_workspace.HandleFunc("/catalog", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.CreateCatalogItem)).Methods("POST")
+	in_workspace.HandleFunc("/catalog/suggest", can(rbac.PERM_CATALOG_READ, api.CatalogApi.SuggestCatalogItems)).Methods("GET")
+	in_workspace.HandleFunc("/catalog/import", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.ImportCatalog)).Methods("POST")
+	in_workspace.HandleFunc("/catalog/{id:[0-9]+}", can(rbac.PERM_CATALOG_READ, api.CatalogApi.GetCatalogItem)).Methods("GET")
+	in_workspace.HandleFunc("/catalog/{id:[0-9]+}", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.UpdateCatalogItem)).Methods("PUT")
+	in_workspace.HandleFunc("/catalog/{id:[0-9]+}", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.DeleteCatalogItem)).Methods("DELETE")
+	in_workspace.HandleFunc("/catalog/{id:[0-9]+}/archive", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.ArchiveCatalogItem)).Methods("POST")
+	in_workspace.HandleFunc("/catalog/{id:[0-9]+}/unarchive", can(rbac.PERM_CATALOG_WRITE, api.CatalogApi.UnarchiveCatalogItem)).Methods("POST")
+
+	in_workspace.HandleFunc("/invoices", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.GetInvoicesList)).Methods("GET")
+	in_workspace.HandleFunc("/invoices", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.CreateInvoice)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.GetInvoice)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.UpdateInvoice)).Methods("PUT")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.DeleteInvoice)).Methods("DELETE")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/render", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.RenderInvoice)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/pdf", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.DownloadInvoicePdf)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/ubl", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.DownloadInvoiceUbl)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/events", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.GetInvoiceEvents)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/issue", can(rbac.PERM_INVOICES_ISSUE, api.InvoicesApi.IssueInvoice)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/send", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.SendInvoice)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/void", can(rbac.PERM_INVOICES_VOID, api.InvoicesApi.VoidInvoice)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/credit-notes", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.GetCreditNotes)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/credit-notes", can(rbac.PERM_INVOICES_ISSUE, api.InvoicesApi.CreateCreditNote)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/accept", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.AcceptQuote)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/decline", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.DeclineQuote)).Methods("POST")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/conversions", can(rbac.PERM_INVOICES_READ, api.InvoicesApi.GetConversions)).Methods("GET")
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/conversions", can(rbac.PERM_INVOICES_WRITE, api.InvoicesApi.ConvertQuote)).Methods("POST")
+
+	in_workspace.HandleFunc("/invoices/{id:[0-9]+}/payments", can(rbac.PERM_PAYMENTS_READ, api.PaymentsApi.GetInvoicePayments)).Methods("GET")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}/payments", can(rbac.PERM_PAYMENTS_READ, api.PaymentsApi.GetClientPayments)).Methods("GET")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}/credit", can(rbac.PERM_PAYMENTS_READ, api.PaymentsApi.GetClientCredit)).Methods("GET")
+	in_workspace.HandleFunc("/clients/{id:[0-9]+}/credit/apply", can(rbac.PERM_PAYMENTS_WRITE, api.PaymentsApi.ApplyClientCredit)).Methods("POST")
+	in_workspace.HandleFunc("/payments", can(rbac.PERM_PAYMENTS_WRITE, api.PaymentsApi.CreatePayment)).Methods("POST")
+	in_workspace.HandleFunc("/payments/{id:[0-9]+}", can(rbac.PERM_PAYMENTS_READ, api.PaymentsApi.GetPayment)).Methods("GET")
+	in_workspace.HandleFunc("/payments/{id:[0-9]+}/reverse", can(rbac.PERM_PAYMENTS_WRITE, api.PaymentsApi.ReversePayment)).Methods("POST")
+
+	// Schedules may issue invoices on their own, so changing them takes the
+	// permission to issue.
+	in_workspace.HandleFunc("/recurring-schedules", can(rbac.PERM_INVOICES_READ, api.RecurringApi.GetSchedules)).Methods("GET")
+	in_workspace.HandleFunc("/recurring-schedules", can(rbac.PERM_INVOICES_ISSUE, api.RecurringApi.CreateSchedule)).Methods("POST")
+	in_workspace.HandleFunc("/recurring-schedules/{id:[0-9]+}", can(rbac.PERM_INVOICES_READ, api.RecurringApi.GetSchedule)).Methods("GET")
+	in_workspace.HandleFunc("/recurring-schedules/{id:[0-9]+}", can(rbac.PERM_INVOICES_ISSUE, api.RecurringApi.UpdateSchedule)).Methods("PUT")
+	in_workspace.HandleFunc("/recurring-schedules/{id:[0-9]+}", can(rbac.PERM_INVOICES_ISSUE, api.RecurringApi.DeleteSchedule)).Methods("DELETE")
+	in_workspace.HandleFunc("/recurring-schedules/{id:[0-9]+}/runs", can(rbac.PERM_INVOICES_READ, api.RecurringApi.GetScheduleRuns)).Methods("GET")
+
+	in_workspace.HandleFunc("/issuers", can(rbac.PERM_INVOICES_READ, api.IssuersApi.GetIssuersList)).Methods("GET")
+	in_workspace.HandleFunc("/issuers", can(rbac.PERM_ISSUERS_MANAGE, api.IssuersApi.CreateIssuer)).Methods("POST")
+	in_workspace.HandleFunc("/issuers/{id:[0-9]+}", can(rbac.PERM_INVOICES_READ, api.IssuersApi.GetIssuer)).Methods("GET")
+	in_workspace.HandleFunc("/issuers/{id:[0-9]+}", can(rbac.PERM_ISSUERS_MANAGE, api.IssuersApi.UpdateIssuer)).Methods("PUT")
+	in_workspace.HandleFunc("/issuers/{id:[0-9]+}", can(rbac.PERM_ISSUERS_MANAGE, api.IssuersApi.DeleteIssuer)).Methods("DELETE")
+	in_workspace.HandleFunc("/issuers/{id:[0-9]+}/logo", can(rbac.PERM_ISSUERS_MANAGE, api.IssuersApi.UploadLogo)).Methods("POST")
+	in_workspace.HandleFunc("/issuers/{id:[0-9]+}/logo", can(rbac.PERM_ISSUERS_MANAGE, api.IssuersApi.DeleteLogo)).Methods("DELETE")
+
+	in_workspace.HandleFunc("/sequences", can(rbac.PERM_INVOICES_READ, api.SequencesApi.GetSequencesList)).Methods("GET")
+	in_workspace.HandleFunc("/sequences", can(rbac.PERM_SEQUENCES_MANAGE, api.SequencesApi.SaveSequence)).Methods("PUT")
+	in_workspace.HandleFunc("/sequences/preview", can(rbac.PERM_INVOICES_READ, api.SequencesApi.PreviewNumber)).Methods("GET")
+
+	in_workspace.HandleFunc("/exchange-rates", can(rbac.PERM_INVOICES_READ, api.ExchangeApi.GetRatesList)).Methods("GET")
+	in_workspace.HandleFunc("/exchange-rates/import", can(rbac.PERM_EXCHANGE_RATES_MANAGE, api.ExchangeApi.ImportRates)).Methods("POST")
+	in_workspace.HandleFunc("/exchange-rates/lookup", can(rbac.PERM_INVOICES_READ, api.ExchangeApi.LookupRate)).Methods("GET")
+
+	in_workspace.HandleFunc("/tax-rates", can(rbac.PERM_INVOICES_READ, api.TaxApi.GetTaxRatesList)).Methods("GET")
+	in_workspace.HandleFunc("/tax-rates", can(rbac.PERM_TAX_RATES_MANAGE, api.TaxApi.CreateTaxRate)).Methods("POST")
+	in_workspace.HandleFunc("/tax-rates/{id:[0-9]+}", can(rbac.PERM_INVOICES_READ, api.TaxApi.GetTaxRate)).Methods("GET")
+	in_workspace.HandleFunc("/tax-rates/{id:[0-9]+}", can(rbac.PERM_TAX_RATES_MANAGE, api.TaxApi.UpdateTaxRate)).Methods("PUT")
+	in_workspace.HandleFunc("/tax-rates/{id:[0-9]+}", can(rbac.PERM_TAX_RATES_MANAGE, api.TaxApi.DeleteTaxRate)).Methods("DELETE")
 
 	handler := h2c.NewHandler(r, &http2.Server{})
 	handler = handleCors().Handler(handler)
 
+	server := &http.Server{
+		Addr:              constants.HTTP_ADDR,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// The database and tracing are closed by the defers above, once the app
+	// has shut down.
+	app := lifecycle.New(server, lifecycle.TimeoutFromEnv())
+	app.Go("purger", purge.PurgerFromEnv(us).Run)
+	app.Go("overdue", invoice.OverdueMarkerFromEnv(is).Run)
+	app.Go("recurring", recurring.SchedulerFromEnv(schedules).Run)
+	app.OnDrain("conversions", ts.Jobs().Drain)
+	app.OnClose("templates", ts.Close)
+	app.OnClose("clients", cs.Close)
+	app.OnClose("catalog", items.Close)
+	app.OnClose("issuers", issuers.Close)
+	app.OnClose("invoices", is.Close)
+	app.OnClose("sequences", ss.Close)
+	app.OnClose("exchange rates", xs.Close)
+	app.OnClose("tax rates", taxes.Close)
+	app.OnClose("payments", ps.Close)
+	app.OnClose("schedules", schedules.Close)
+
 	fmt.Println("HTTP server listening on", constants.HTTP_ADDR)
-	err := http.ListenAndServe(constants.HTTP_ADDR, handler)
+	return app.Run(context.Background())
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	err := cli.Run(cli.DefaultEnv(serve), os.Args[1:])
+	if errors.Is(err, cli.ErrUsage) {
+		if err != cli.ErrUsage {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(2)
+	}
 	if err != nil {
-		log.Fatal("Failed to start a HTTP server:", err)
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/workspace"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	STATIC_DIR     = "static"
+	WORKSPACES_DIR = "workspaces"
+
+	// Issued documents are kept out of STATIC_DIR and only served by the
+	// API, with the permissions of their kind.
+	DOCUMENTS_DIR = "documents"
+
+	TEMPLATES  = "templates"
+	THUMBNAILS = "thumbnails"
+	LOGOS      = "logos"
+	INVOICES   = "invoices"
+)
+
+// WorkspaceDir is where files of the given kind are kept for a workspace,
+// e.g. static/workspaces/3/templates.
+func WorkspaceDir(workspace_id uint32, kind string) string {
+	return filepath.Join(STATIC_DIR, WORKSPACES_DIR, fmt.Sprint(workspace_id), kind)
+}
+
+func EnsureWorkspaceDir(workspace_id uint32, kind string) (string, error) {
+	dir := WorkspaceDir(workspace_id, kind)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// EnsureDocumentDir creates the directory issued documents of the given
+// kind are kept in for a workspace, e.g. documents/workspaces/3/invoices.
+func EnsureDocumentDir(workspace_id uint32, kind string) (string, error) {
+	dir := filepath.Join(DOCUMENTS_DIR, WORKSPACES_DIR, fmt.Sprint(workspace_id), kind)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// WorkspaceDirs lists the directories of the given kind of all workspaces
+// that have stored files.
+func WorkspaceDirs(kind string) ([]string, error) {
+	return filepath.Glob(filepath.Join(STATIC_DIR, WORKSPACES_DIR, "*", kind))
+}
+
+// WorkspaceOf returns the workspace a path below STATIC_DIR belongs to.
+// Files stored before workspaces existed live directly in STATIC_DIR and
+// belong to the default workspace.
+func WorkspaceOf(file_path string) uint32 {
+	parts := strings.Split(path.Clean(filepath.ToSlash(file_path)), "/")
+	for i := 0; i+2 < len(parts); i++ {
+		if parts[i] == STATIC_DIR && parts[i+1] == WORKSPACES_DIR {
+			id, err := strconv.ParseUint(parts[i+2], 10, 32)
+			if err != nil {
+				return 0
+			}
+			return uint32(id)
+		}
+	}
+	return workspace.DEFAULT_WORKSPACE_ID
+}
+
+// FileServer serves STATIC_DIR, but only the files of the workspace the
+// request was resolved to.
+func FileServer() http.Handler {
+	files := http.FileServer(http.Dir("."))
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if WorkspaceOf(req.URL.Path) != workspace.IdFromContext(req.Context()) {
+			http.NotFound(w, req)
+			return
+		}
+		files.ServeHTTP(w, req)
+	})
+}
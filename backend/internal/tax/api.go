@@ -0,0 +1,113 @@
+package tax
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type TaxApi struct {
+	rates *TaxRates
+}
+
+// GetTaxRatesList lists the rates, optionally of one country and in force on
+// a date.
+func (ta *TaxApi) GetTaxRatesList(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	country := strings.ToUpper(query.Get("country"))
+	if country != "" && !countryPattern.MatchString(country) {
+		apperr.Write(w, req, apperr.Invalid("Invalid tax rate filter", apperr.Field("country", "must be an ISO 3166-1 alpha-2 code")))
+		return
+	}
+	date := query.Get("date")
+	if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+		apperr.Write(w, req, apperr.Invalid("Invalid tax rate filter", apperr.Field("date", "must be a date formatted as YYYY-MM-DD")))
+		return
+	}
+
+	rates, err := ta.rates.List(req.Context(), workspace.IdFromContext(req.Context()), country, date)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading tax rates"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetTaxRatesResponse{TaxRates: rates})
+}
+
+func (ta *TaxApi) GetTaxRate(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	rate, err := ta.rates.Retrieve(req.Context(), workspace.IdFromContext(req.Context()), id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading tax rate"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.TaxRateResponse{TaxRate: rate})
+}
+
+func (ta *TaxApi) CreateTaxRate(w http.ResponseWriter, req *http.Request) {
+	var body pb.SaveTaxRateRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	rate, err := ta.rates.Create(req.Context(), workspace.IdFromContext(req.Context()), &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Tax rate couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.TaxRateResponse{TaxRate: rate})
+}
+
+func (ta *TaxApi) UpdateTaxRate(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SaveTaxRateRequest
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	rate, err := ta.rates.Update(req.Context(), workspace.IdFromContext(req.Context()), id, &body, audit.ActorFromRequest(req))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Tax rate couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.TaxRateResponse{TaxRate: rate})
+}
+
+func (ta *TaxApi) DeleteTaxRate(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = ta.rates.Delete(req.Context(), workspace.IdFromContext(req.Context()), id, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the tax rate"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewTaxApi(ts *TaxRates) *TaxApi {
+	return &TaxApi{rates: ts}
+}
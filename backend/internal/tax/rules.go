@@ -0,0 +1,57 @@
+package tax
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	CATEGORY_STANDARD       = "standard"
+	CATEGORY_REDUCED        = "reduced"
+	CATEGORY_ZERO_RATED     = "zero_rated"
+	CATEGORY_EXEMPT         = "exempt"
+	CATEGORY_REVERSE_CHARGE = "reverse_charge"
+)
+
+var Categories = []string{CATEGORY_STANDARD, CATEGORY_REDUCED, CATEGORY_ZERO_RATED, CATEGORY_EXEMPT, CATEGORY_REVERSE_CHARGE}
+
+// REVERSE_CHARGE_REASON is printed on invoices the client owes the VAT of.
+const REVERSE_CHARGE_REASON = "Reverse charge: VAT to be accounted for by the recipient, Article 196 of Council Directive 2006/112/EC"
+
+// euMembers are the ISO 3166-1 codes of the EU member states. Greece uses
+// EL in its VAT IDs.
+var euMembers = []string{
+	"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU",
+	"IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK",
+}
+
+func InEU(country string) bool {
+	return slices.Contains(euMembers, strings.ToUpper(country))
+}
+
+// Rated tells whether lines of the category carry a percentage, the others
+// are taxed at 0%.
+func Rated(category string) bool {
+	return category == CATEGORY_STANDARD || category == CATEGORY_REDUCED
+}
+
+// NeedsReason tells whether lines of the category must say why they aren't
+// taxed.
+func NeedsReason(category string) bool {
+	return category == CATEGORY_EXEMPT || category == CATEGORY_REVERSE_CHARGE
+}
+
+// Party is the seller or buyer of an invoice, as far as VAT is concerned.
+type Party struct {
+	Country string
+	VatId   string
+}
+
+// ReverseCharge tells whether the buyer owes the VAT instead of the seller:
+// both are businesses registered for VAT in different EU member states.
+// Having a VAT ID is what makes a buyer a business.
+func ReverseCharge(seller Party, buyer Party) bool {
+	return seller.VatId != "" && buyer.VatId != "" &&
+		InEU(seller.Country) && InEU(buyer.Country) &&
+		!strings.EqualFold(seller.Country, buyer.Country)
+}
@@ -0,0 +1,408 @@
+package tax
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrIDNotFound = apperr.New(apperr.CODE_NOT_FOUND, "tax rate not found")
+	ErrNotInForce = apperr.New(apperr.CODE_FAILED_PRECONDITION, "tax rate is not in force")
+	ErrInUse      = apperr.New(apperr.CODE_FAILED_PRECONDITION, "tax rate is used by invoices, end its validity instead")
+)
+
+const (
+	AUDIT_TARGET = "tax_rate"
+	AUDIT_CREATE = "tax_rate.create"
+	AUDIT_UPDATE = "tax_rate.update"
+	AUDIT_DELETE = "tax_rate.delete"
+)
+
+// TaxRates are the named rates of a workspace. Line items copy the rate
+// they reference when they are saved, so that changing it later doesn't
+// change issued invoices.
+type TaxRates struct {
+	db    *sql.DB
+	audit *audit.Log
+
+	insert_stmt, retrieve_stmt, update_stmt, delete_stmt, list_stmt, seller_stmt *sql.Stmt
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTaxRate(row scanner) (*pb.TaxRate, error) {
+	rate := &pb.TaxRate{}
+	err := row.Scan(
+		&rate.Id,
+		&rate.Name,
+		&rate.Country,
+		&rate.Category,
+		&rate.Rate,
+		&rate.ValidFrom,
+		&rate.ValidTo,
+		&rate.ExemptionReason,
+		&rate.CreatedAt,
+		&rate.UpdatedAt,
+		&rate.CreatedBy,
+		&rate.UpdatedBy,
+		&rate.WorkspaceId,
+	)
+	return rate, err
+}
+
+func auditFields(r *pb.TaxRate) map[string]string {
+	return map[string]string{
+		"name":             r.Name,
+		"country":          r.Country,
+		"category":         r.Category,
+		"rate":             r.Rate,
+		"valid_from":       r.ValidFrom,
+		"valid_to":         r.ValidTo,
+		"exemption_reason": r.ExemptionReason,
+	}
+}
+
+// InForce tells whether the rate applies on the date, formatted as
+// YYYY-MM-DD.
+func InForce(rate *pb.TaxRate, date string) bool {
+	return rate.ValidFrom <= date && (rate.ValidTo == "" || date <= rate.ValidTo)
+}
+
+func (ts *TaxRates) retrieve(ctx context.Context, stmt *sql.Stmt, workspace_id uint32, id uint32) (*pb.TaxRate, error) {
+	rate, err := scanTaxRate(stmt.QueryRowContext(ctx, id, workspace_id))
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	return rate, err
+}
+
+func (ts *TaxRates) Retrieve(ctx context.Context, workspace_id uint32, id uint32) (_ *pb.TaxRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Retrieve")
+	defer end(&err)
+
+	return ts.retrieve(ctx, ts.retrieve_stmt, workspace_id, id)
+}
+
+// Resolve returns the rate if it is in force on the date.
+func (ts *TaxRates) Resolve(ctx context.Context, workspace_id uint32, id uint32, date string) (_ *pb.TaxRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Resolve")
+	defer end(&err)
+
+	rate, err := ts.retrieve(ctx, ts.retrieve_stmt, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+	if !InForce(rate, date) {
+		return nil, fmt.Errorf("%w: %s on %s", ErrNotInForce, rate.Name, date)
+	}
+	return rate, nil
+}
+
+// Seller returns the country and VAT ID of the workspace, which issues the
+// invoices.
+func (ts *TaxRates) Seller(ctx context.Context, workspace_id uint32) (_ Party, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Seller")
+	defer end(&err)
+
+	var seller Party
+	err = ts.seller_stmt.QueryRowContext(ctx, workspace_id).Scan(&seller.Country, &seller.VatId)
+	return seller, err
+}
+
+// List returns the rates of the workspace ordered by country, category and
+// start of validity. An empty country matches all of them, a date only the
+// rates in force on it.
+func (ts *TaxRates) List(ctx context.Context, workspace_id uint32, country string, date string) (_ []*pb.TaxRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.List")
+	defer end(&err)
+
+	rows, err := ts.list_stmt.QueryContext(ctx, workspace_id, country, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []*pb.TaxRate{}
+	for rows.Next() {
+		rate, err := scanTaxRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (ts *TaxRates) Create(ctx context.Context, workspace_id uint32, req *pb.SaveTaxRateRequest, actor *audit.Actor) (_ *pb.TaxRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Create")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := ts.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(ts.insert_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.Country,
+		req.Category,
+		req.Rate,
+		req.ValidFrom,
+		req.ValidTo,
+		req.ExemptionReason,
+		now,
+		now,
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := ts.retrieve(ctx, tx.Stmt(ts.retrieve_stmt), workspace_id, uint32(id))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, rate.Id, audit.Diff(nil, auditFields(rate)))
+	if err = ts.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+// Update replaces all fields of the rate. Drafts pick up the change when
+// they are saved again, issued invoices keep what they were issued with.
+func (ts *TaxRates) Update(ctx context.Context, workspace_id uint32, id uint32, req *pb.SaveTaxRateRequest, actor *audit.Actor) (_ *pb.TaxRate, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Update")
+	defer end(&err)
+
+	if err = Validate(req); err != nil {
+		return nil, err
+	}
+
+	tx, err := ts.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := ts.retrieve(ctx, tx.Stmt(ts.retrieve_stmt), workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Stmt(ts.update_stmt).ExecContext(
+		ctx,
+		req.Name,
+		req.Country,
+		req.Category,
+		req.Rate,
+		req.ValidFrom,
+		req.ValidTo,
+		req.ExemptionReason,
+		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		id,
+		workspace_id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := ts.retrieve(ctx, tx.Stmt(ts.retrieve_stmt), workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(auditFields(before), auditFields(after))
+	if err = ts.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE, AUDIT_TARGET, id, changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// Delete removes a rate no line item references.
+func (ts *TaxRates) Delete(ctx context.Context, workspace_id uint32, id uint32, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "TaxRates.Delete")
+	defer end(&err)
+
+	tx, err := ts.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rate, err := ts.retrieve(ctx, tx.Stmt(ts.retrieve_stmt), workspace_id, id)
+	if err != nil {
+		return err
+	}
+
+	// Line items keep their rate, SQLite reports a plain constraint error
+	// for the foreign key.
+	_, err = tx.Stmt(ts.delete_stmt).ExecContext(ctx, id, workspace_id)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.Code == sqlite3.ErrConstraint {
+		return ErrInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, id, audit.Diff(auditFields(rate), nil))
+	if err = ts.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (ts *TaxRates) Close() error {
+	stmts := []*sql.Stmt{
+		ts.insert_stmt,
+		ts.retrieve_stmt,
+		ts.update_stmt,
+		ts.delete_stmt,
+		ts.list_stmt,
+		ts.seller_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const TAX_RATE_COLUMNS = `
+	tax_rate_id,
+	tax_rate_name,
+	tax_rate_country,
+	tax_rate_category,
+	tax_rate_value,
+	tax_rate_valid_from,
+	tax_rate_valid_to,
+	tax_rate_exemption_reason,
+	tax_rate_created_at,
+	tax_rate_updated_at,
+	COALESCE(tax_rate_created_by, 0),
+	COALESCE(tax_rate_updated_by, 0),
+	tax_rate_workspace_id
+`
+
+func NewTaxRates(db *sql.DB, audit_log *audit.Log) (*TaxRates, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO tax_rates (
+			tax_rate_name,
+			tax_rate_country,
+			tax_rate_category,
+			tax_rate_value,
+			tax_rate_valid_from,
+			tax_rate_valid_to,
+			tax_rate_exemption_reason,
+			tax_rate_created_at,
+			tax_rate_updated_at,
+			tax_rate_created_by,
+			tax_rate_updated_by,
+			tax_rate_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + TAX_RATE_COLUMNS + `
+		FROM tax_rates
+		WHERE tax_rate_id = ? AND tax_rate_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_stmt, err := db.Prepare(`
+		UPDATE tax_rates
+		SET tax_rate_name = ?,
+			tax_rate_country = ?,
+			tax_rate_category = ?,
+			tax_rate_value = ?,
+			tax_rate_valid_from = ?,
+			tax_rate_valid_to = ?,
+			tax_rate_exemption_reason = ?,
+			tax_rate_updated_at = ?,
+			tax_rate_updated_by = ?
+		WHERE tax_rate_id = ? AND tax_rate_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM tax_rates WHERE tax_rate_id = ? AND tax_rate_workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(`
+		SELECT ` + TAX_RATE_COLUMNS + `
+		FROM tax_rates
+		WHERE tax_rate_workspace_id = ?1
+			AND (?2 = '' OR tax_rate_country = ?2)
+			AND (?3 = '' OR (tax_rate_valid_from <= ?3 AND (tax_rate_valid_to = '' OR tax_rate_valid_to >= ?3)))
+		ORDER BY tax_rate_country, tax_rate_category, tax_rate_valid_from, tax_rate_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	seller_stmt, err := db.Prepare("SELECT workspace_country, workspace_vat_id FROM workspaces WHERE workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaxRates{
+		db:            db,
+		audit:         audit_log,
+		insert_stmt:   insert_stmt,
+		retrieve_stmt: retrieve_stmt,
+		update_stmt:   update_stmt,
+		delete_stmt:   delete_stmt,
+		list_stmt:     list_stmt,
+		seller_stmt:   seller_stmt,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package tax
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MAX_NAME_LENGTH   = 128
+	MAX_REASON_LENGTH = 1024
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+var hundred = decimal.NewFromInt(100)
+
+// Validate checks the request and normalizes it in place. Rates of the
+// categories without a percentage are 0.
+func Validate(req *pb.SaveTaxRateRequest) error {
+	fields := []apperr.FieldError{}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		fields = append(fields, apperr.Field("name", "must not be empty"))
+	} else if len(req.Name) > MAX_NAME_LENGTH {
+		fields = append(fields, apperr.Field("name", fmt.Sprintf("must not be longer than %d bytes", MAX_NAME_LENGTH)))
+	}
+
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	if !countryPattern.MatchString(req.Country) {
+		fields = append(fields, apperr.Field("country", "must be an ISO 3166-1 alpha-2 code"))
+	}
+
+	req.Category = strings.TrimSpace(req.Category)
+	if !slices.Contains(Categories, req.Category) {
+		fields = append(fields, apperr.Field("category", "must be one of "+strings.Join(Categories, ", ")))
+	}
+
+	req.Rate = strings.TrimSpace(req.Rate)
+	if req.Rate == "" {
+		req.Rate = "0"
+	}
+	rate, err := decimal.NewFromString(req.Rate)
+	switch {
+	case err != nil:
+		fields = append(fields, apperr.Field("rate", "must be a decimal number"))
+	case Rated(req.Category) && (!rate.IsPositive() || rate.GreaterThan(hundred)):
+		fields = append(fields, apperr.Field("rate", "must be more than 0 and at most 100"))
+	case !Rated(req.Category) && !rate.IsZero():
+		fields = append(fields, apperr.Field("rate", "must be 0 for "+strings.ReplaceAll(req.Category, "_", " ")+" rates"))
+	default:
+		req.Rate = rate.String()
+	}
+
+	valid_from, err := time.Parse(time.DateOnly, req.ValidFrom)
+	if err != nil {
+		fields = append(fields, apperr.Field("validFrom", "must be a date formatted as YYYY-MM-DD"))
+	}
+	if req.ValidTo != "" {
+		valid_to, err := time.Parse(time.DateOnly, req.ValidTo)
+		if err != nil {
+			fields = append(fields, apperr.Field("validTo", "must be a date formatted as YYYY-MM-DD"))
+		} else if valid_to.Before(valid_from) {
+			fields = append(fields, apperr.Field("validTo", "must not be before validFrom"))
+		}
+	}
+
+	req.ExemptionReason = strings.TrimSpace(req.ExemptionReason)
+	if len(req.ExemptionReason) > MAX_REASON_LENGTH {
+		fields = append(fields, apperr.Field("exemptionReason", fmt.Sprintf("must not be longer than %d bytes", MAX_REASON_LENGTH)))
+	}
+	if req.Category == CATEGORY_EXEMPT && req.ExemptionReason == "" {
+		fields = append(fields, apperr.Field("exemptionReason", "is required for exempt rates"))
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("Tax rate is invalid", fields...)
+	}
+	return nil
+}
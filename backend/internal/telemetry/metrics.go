@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"invoice-manager/main/internal/storage"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const NAMESPACE = "invoicer"
+
+// Converters, as labelled in the conversion metrics.
+const (
+	CONVERTER_PDF2HTMLEX = "pdf2htmlex"
+	CONVERTER_THUMBNAIL  = "bimg"
+	CONVERTER_CHROMIUM   = "chromium"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of HTTP requests by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	ConnectRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "connect_request_duration_seconds",
+		Help:      "Duration of Connect calls by procedure and Connect code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"procedure", "code"})
+
+	ConversionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "conversion_duration_seconds",
+		Help:      "Duration of PDF conversions by converter.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"converter"})
+
+	ConversionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "conversion_failures_total",
+		Help:      "Failed PDF conversions by converter.",
+	}, []string{"converter"})
+
+	conversion_queue     atomic.Int64
+	ConversionQueueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: NAMESPACE,
+		Name:      "conversion_queue_depth",
+		Help:      "Uploads waiting for or in the middle of a conversion.",
+	}, func() float64 {
+		return float64(conversion_queue.Load())
+	})
+
+	DbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database operations by store method.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1},
+	}, []string{"query"})
+)
+
+// storageCollector reports the size of the files below STATIC_DIR. The
+// directory is walked on every scrape, as files are changed by the CLI too.
+type storageCollector struct {
+	desc *prometheus.Desc
+}
+
+func (c *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	sizes := map[string]int64{storage.TEMPLATES: 0, storage.THUMBNAILS: 0, "other": 0}
+	filepath.WalkDir(storage.STATIC_DIR, func(file_path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		kind := "other"
+		for _, dir := range []string{storage.TEMPLATES, storage.THUMBNAILS} {
+			if strings.Contains(filepath.ToSlash(file_path), "/"+dir+"/") {
+				kind = dir
+			}
+		}
+		sizes[kind] += info.Size()
+		return nil
+	})
+
+	for kind, size := range sizes {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(size), kind)
+	}
+}
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequestDuration,
+		ConnectRequestDuration,
+		ConversionDuration,
+		ConversionFailures,
+		ConversionQueueDepth,
+		DbQueryDuration,
+		&storageCollector{desc: prometheus.NewDesc(
+			prometheus.BuildFQName(NAMESPACE, "storage", "bytes"),
+			"Size of the stored files by kind.",
+			[]string{"kind"}, nil,
+		)},
+	)
+}
+
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// EnterConversionQueue counts an upload in ConversionQueueDepth until the
+// returned function is called.
+func EnterConversionQueue() func() {
+	conversion_queue.Add(1)
+	return func() {
+		conversion_queue.Add(-1)
+	}
+}
+
+func QueueDepth() int64 {
+	return conversion_queue.Load()
+}
@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	SERVICE_NAME = "invoicer"
+	TRACER_NAME  = "invoice-manager/main"
+)
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// InitTracing exports spans to the OTLP/HTTP collector configured with the
+// standard OTEL_EXPORTER_OTLP_* variables, e.g.
+// OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318. Without an endpoint
+// spans are not recorded at all. The returned function flushes the spans
+// that are still buffered.
+func InitTracing(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(SERVICE_NAME)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start begins a span, to be ended with the error of the operation:
+//
+//	ctx, end := telemetry.Start(ctx, "ConvertPdfToHtml")
+//	defer end(&err)
+func Start(ctx context.Context, name string) (context.Context, func(*error)) {
+	ctx, span := Tracer().Start(ctx, name)
+	return ctx, func(err *error) {
+		if err != nil && *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
+
+// StartQuery is Start for store methods, which are also timed in
+// DbQueryDuration.
+func StartQuery(ctx context.Context, name string) (context.Context, func(*error)) {
+	start := time.Now()
+	ctx, end := Start(ctx, name)
+	return ctx, func(err *error) {
+		DbQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		end(err)
+	}
+}
+
+// StartConversion is Start for converters, which are also timed in
+// ConversionDuration and counted in ConversionFailures when they fail.
+func StartConversion(ctx context.Context, name string, converter string) (context.Context, func(*error)) {
+	start := time.Now()
+	ctx, end := Start(ctx, name)
+	return ctx, func(err *error) {
+		ConversionDuration.WithLabelValues(converter).Observe(time.Since(start).Seconds())
+		if err != nil && *err != nil {
+			ConversionFailures.WithLabelValues(converter).Inc()
+		}
+		end(err)
+	}
+}
@@ -1,23 +1,29 @@
 package template
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/health"
 	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/pdfcheck"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/telemetry"
+	"invoice-manager/main/internal/workspace"
 	pb "invoice-manager/main/proto"
 	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/h2non/bimg"
 	"golang.org/x/net/html"
 )
@@ -27,38 +33,49 @@ type TemplateApi struct {
 }
 
 const (
-	STATIC_DIR     = "static"
-	THUMBNAIL_NAME = "thumbnail.jpg"
+	STATIC_DIR        = storage.STATIC_DIR
+	THUMBNAIL_NAME    = "thumbnail.jpg"
+	TEMP_FILE_PATTERN = "tmp-uploaded-pdf-*.pdf"
+	PDF2HTMLEX_IMAGE  = "pdf2htmlex/pdf2htmlex:0.18.8.rc2-master-20200820-alpine-3.12.0-x86_64"
+	CHROMIUM_IMAGE    = "zenika/alpine-chrome:124"
 )
 
+// Files uploaded before workspaces existed. They belong to the default
+// workspace, new files are stored under storage.WorkspaceDir.
 var (
-	HTML_TEMPLATES_DIR = filepath.Join(STATIC_DIR, "templates")
-	THUMBNAILS_DIR     = filepath.Join(STATIC_DIR, "thumbnails")
+	HTML_TEMPLATES_DIR = filepath.Join(STATIC_DIR, storage.TEMPLATES)
+	THUMBNAILS_DIR     = filepath.Join(STATIC_DIR, storage.THUMBNAILS)
 )
 
-func UploadToTempFile(file multipart.File, handler *multipart.FileHeader) (*os.File, error) {
-	temp_file, err := os.CreateTemp(STATIC_DIR, "tmp-uploaded-pdf-*.pdf")
+// UploadToTempFile stores the upload after checking its magic bytes and
+// size, and rejects it unless its structure passes pdfcheck.Validate.
+func UploadToTempFile(file io.Reader, limits pdfcheck.Limits) (*os.File, error) {
+	file, err := pdfcheck.SniffHeader(file)
 	if err != nil {
 		return nil, err
 	}
 
-	if filepath.Ext(handler.Filename) != ".pdf" {
-		return nil, err
-	}
-
-	form_file_bytes, err := io.ReadAll(file)
+	temp_file, err := os.CreateTemp(STATIC_DIR, TEMP_FILE_PATTERN)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err = temp_file.Write(form_file_bytes); err != nil {
+	if err = pdfcheck.CopyLimited(temp_file, file, limits); err == nil {
+		err = pdfcheck.Validate(temp_file.Name(), limits)
+	}
+	if err != nil {
+		temp_file.Close()
+		os.Remove(temp_file.Name())
 		return nil, err
 	}
 
 	return temp_file, nil
 }
 
-func CreateThumbnail(temp_file *os.File) (string, error) {
+func CreateThumbnail(ctx context.Context, temp_file *os.File, dest_dir string) (_ string, err error) {
+	_, end := telemetry.StartConversion(ctx, "CreateThumbnail", telemetry.CONVERTER_THUMBNAIL)
+	defer end(&err)
+
 	uploaded_file_path := temp_file.Name()
 	thumbnail_buffer, err := bimg.Read(uploaded_file_path)
 	if err != nil {
@@ -71,7 +88,7 @@ func CreateThumbnail(temp_file *os.File) (string, error) {
 	}
 
 	thumbnail_id := fmt.Sprint(time.Now().UnixNano())
-	thumbnail_path := filepath.Join(THUMBNAILS_DIR, thumbnail_id+"_"+THUMBNAIL_NAME)
+	thumbnail_path := filepath.Join(dest_dir, thumbnail_id+"_"+THUMBNAIL_NAME)
 	if err = bimg.Write(thumbnail_path, thumbnail); err != nil {
 		return "", err
 	}
@@ -79,163 +96,296 @@ func CreateThumbnail(temp_file *os.File) (string, error) {
 	return thumbnail_path, nil
 }
 
-func ConvertPdfToHtml(temp_file *os.File) (template_path string, err error) {
+func ConvertPdfToHtml(ctx context.Context, temp_file *os.File, dest_dir string) (template_path string, err error) {
+	ctx, end := telemetry.StartConversion(ctx, "ConvertPdfToHtml", telemetry.CONVERTER_PDF2HTMLEX)
+	defer end(&err)
+
 	cwd_root, err := os.Getwd()
 	if err != nil {
 		return "", err
 	}
 
 	template_name := fmt.Sprint(time.Now().UnixNano()) + ".html"
-	image := "pdf2htmlex/pdf2htmlex:0.18.8.rc2-master-20200820-alpine-3.12.0-x86_64"
 	cmd := fmt.Sprintf(
 		"docker run -t --rm -v %s:/backend -w /backend %s --zoom 1.8 --embed CFIJO --dest-dir %s %s %s --process-outline 0 --optimize-text 1",
 		cwd_root,
-		image,
-		HTML_TEMPLATES_DIR,
+		PDF2HTMLEX_IMAGE,
+		dest_dir,
 		temp_file.Name(),
 		template_name,
 	)
 
-	if err = exec.Command("/bin/sh", "-c", cmd).Run(); err != nil {
+	if err = exec.CommandContext(ctx, "/bin/sh", "-c", cmd).Run(); err != nil {
 		log.Println(cmd, err)
 		return
 	}
 
-	template_path = path.Join(HTML_TEMPLATES_DIR, template_name)
+	template_path = path.Join(dest_dir, template_name)
 	return
 }
 
-func (ta *TemplateApi) UploadFile(w http.ResponseWriter, req *http.Request) {
-	req.ParseMultipartForm(10 << 20) // 10mb
-	form_file, handler, err := req.FormFile("file")
+// ConvertHtmlToPdf prints the HTML file with headless Chromium. Both paths
+// are relative to the working directory, which is mounted into the
+// container.
+func ConvertHtmlToPdf(ctx context.Context, html_path string, pdf_path string) (err error) {
+	ctx, end := telemetry.StartConversion(ctx, "ConvertHtmlToPdf", telemetry.CONVERTER_CHROMIUM)
+	defer end(&err)
+
+	cwd_root, err := os.Getwd()
 	if err != nil {
-		fmt.Fprint(w, "Couldn't get req.FormFile(\"file\")")
+		return err
 	}
-	defer form_file.Close()
 
-	temp_file, err := UploadToTempFile(form_file, handler)
+	cmd := fmt.Sprintf(
+		"docker run --rm -v %s:/backend -w /backend %s --no-sandbox --headless --disable-gpu --no-pdf-header-footer --print-to-pdf=%s file:///backend/%s",
+		cwd_root,
+		CHROMIUM_IMAGE,
+		filepath.ToSlash(pdf_path),
+		filepath.ToSlash(html_path),
+	)
+
+	if err = exec.CommandContext(ctx, "/bin/sh", "-c", cmd).Run(); err != nil {
+		log.Println(cmd, err)
+		return err
+	}
+
+	if _, err = os.Stat(pdf_path); err != nil {
+		return fmt.Errorf("chromium didn't write the PDF: %w", err)
+	}
+	return nil
+}
+
+// CheckConverter fails if docker can't be run, and warns if the images of
+// pdf2htmlEX or Chromium have yet to be pulled, which the first conversion
+// would do.
+func CheckConverter(ctx context.Context) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("docker is not installed: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+	if ctx.Err() != nil {
+		return fmt.Errorf("docker didn't answer: %w", ctx.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("docker daemon is unavailable: %s", strings.TrimSpace(string(out)))
+	}
+
+	for _, image := range []string{PDF2HTMLEX_IMAGE, CHROMIUM_IMAGE} {
+		out, err = exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", image).CombinedOutput()
+		if err != nil {
+			return &health.Warning{Detail: fmt.Sprintf("image %s is not available: %s", image, strings.TrimSpace(string(out)))}
+		}
+	}
+
+	return nil
+}
+
+func (ts *Templates) CreateFromPdf(ctx context.Context, workspace_id uint32, filename string, size int64, file io.Reader, actor *audit.Actor) (_ *Template, err error) {
+	// The conversion carries on if the client goes away, but is aborted if
+	// it doesn't finish in time on shutdown.
+	ctx, done, err := ts.jobs.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx, end := telemetry.Start(ctx, "Templates.CreateFromPdf")
+	defer end(&err)
+
+	defer telemetry.EnterConversionQueue()()
+
+	templates_dir, err := storage.EnsureWorkspaceDir(workspace_id, storage.TEMPLATES)
 	if err != nil {
-		log.Println(err)
-		fmt.Fprint(w, "Count't create temp file")
+		return nil, err
+	}
+
+	thumbnails_dir, err := storage.EnsureWorkspaceDir(workspace_id, storage.THUMBNAILS)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ts.quotas.CheckTemplate(workspace_id, size); err != nil {
+		return nil, err
+	}
+
+	temp_file, err := UploadToTempFile(file, ts.limits)
+	if err != nil {
+		return nil, err
 	}
 	defer os.Remove(temp_file.Name())
 	defer temp_file.Close()
 
-	thumbnail_path, err := CreateThumbnail(temp_file)
+	if err = ts.quotas.Reserve(workspace_id, actor.UserId, quota.KIND_CONVERSION); err != nil {
+		return nil, err
+	}
+
+	thumbnail_path, err := CreateThumbnail(ctx, temp_file, thumbnails_dir)
 	if err != nil {
-		fmt.Fprint(w, "Error creating thumbnail")
+		return nil, err
 	}
 
-	template_path, err := ConvertPdfToHtml(temp_file)
+	template_path, err := ConvertPdfToHtml(ctx, temp_file, templates_dir)
 	if err != nil {
-		fmt.Fprint(w, "Error converting PDF to HTML")
+		os.Remove(thumbnail_path)
+		return nil, err
 	}
 
-	file_ext := filepath.Ext(handler.Filename)
-	new_template, err := ta.templates.Insert(&pb.Template{
-		Name:      strings.TrimSuffix(handler.Filename, file_ext),
+	file_ext := filepath.Ext(filename)
+	template, err := ts.Insert(ctx, &pb.Template{
+		Name:      strings.TrimSuffix(filepath.Base(filename), file_ext),
 		Ext:       file_ext,
-		Size:      uint32(handler.Size),
+		Size:      uint32(size),
 		Path:      template_path,
 		Thumbnail: thumbnail_path,
-	})
+	}, workspace_id, actor)
+	if err != nil {
+		os.Remove(template_path)
+		os.Remove(thumbnail_path)
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (ta *TemplateApi) UploadFile(w http.ResponseWriter, req *http.Request) {
+	// The multipart encoding adds a little on top of the file itself.
+	req.Body = http.MaxBytesReader(w, req.Body, ta.templates.limits.MaxBytes+1<<20)
+	req.ParseMultipartForm(10 << 20) // 10mb
+	form_file, handler, err := req.FormFile("file")
+	var max_bytes_err *http.MaxBytesError
+	if errors.As(err, &max_bytes_err) {
+		apperr.Write(w, req, &pdfcheck.Rejection{Reason: pdfcheck.REASON_TOO_LARGE, Detail: "file is too large"})
+		return
+	}
 	if err != nil {
-		fmt.Fprint(w, "Error inserting new template into the database")
+		apperr.Write(w, req, apperr.Invalid("No file uploaded", apperr.Field("file", "a PDF file is required")))
+		return
 	}
+	defer form_file.Close()
 
-	helpers.JsonResponse(w, http.StatusOK, pb.FileUploadResponse{Template: new_template.data})
+	new_template, err := ta.templates.CreateFromPdf(
+		req.Context(),
+		workspace.IdFromContext(req.Context()),
+		handler.Filename,
+		handler.Size,
+		form_file,
+		audit.ActorFromRequest(req),
+	)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error creating template from PDF"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.FileUploadResponse{Template: new_template.data})
 }
 
 func (ta *TemplateApi) GetTemplatesList(w http.ResponseWriter, req *http.Request) {
-	templates, err := ta.templates.List()
+	templates, err := ta.templates.List(req.Context(), workspace.IdFromContext(req.Context()))
 	if err != nil {
-		fmt.Fprint(w, "Error reading files")
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading templates"))
+		return
 	}
 
-	var data []pb.Template
+	data := []*pb.Template{}
 	for _, template := range templates {
-		data = append(data, pb.Template{
-			Id:        template.data.Id,
-			Name:      template.data.Name,
-			Ext:       template.data.Ext,
-			Size:      template.data.Size,
-			CreatedAt: template.data.CreatedAt,
-			UpdatedAt: template.data.UpdatedAt,
-			Path:      template.public_path,
-			Thumbnail: template.public_thumbnail_path,
-		})
+		data = append(data, template.Public())
 	}
 
 	helpers.JsonResponse(w, http.StatusOK, data)
 }
 
-func GetId(w http.ResponseWriter, req *http.Request) int {
-	vars := mux.Vars(req)
-	id, err := strconv.Atoi(vars["id"])
+func (ta *TemplateApi) UpdateTemplate(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
 	if err != nil {
-		fmt.Fprint(w, "Invalid template ID")
+		apperr.Write(w, req, err)
+		return
 	}
-	return id
-}
 
-func (ta *TemplateApi) UpdateTemplate(w http.ResponseWriter, req *http.Request) {
 	var body pb.UpdateTemplateRequest
-	err := json.NewDecoder(req.Body).Decode(&body)
-	id := GetId(w, req)
+	if err = helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
 
-	if body.GetName() == "" {
-		fmt.Fprintf(w, "Name field is empty")
+	name := strings.TrimSpace(body.GetName())
+	if name == "" {
+		apperr.Write(w, req, apperr.Invalid("Name field is empty", apperr.Field("name", "must not be empty")))
+		return
 	}
 
-	updated_template, err := ta.templates.UpdateName(id, body.GetName())
+	updated_template, err := ta.templates.UpdateName(
+		req.Context(),
+		workspace.IdFromContext(req.Context()),
+		int(id),
+		name,
+		audit.ActorFromRequest(req),
+	)
 	if err != nil {
-		fmt.Fprint(w, "Template couldn't be updated")
+		apperr.Write(w, req, apperr.Wrap(err, "Template couldn't be updated"))
+		return
 	}
 
-	helpers.JsonResponse(w, http.StatusOK, &updated_template)
+	helpers.JsonResponse(w, http.StatusOK, &pb.UpdateTemplateResponse{Template: updated_template.Public()})
 }
 
 func (ta *TemplateApi) UpdateTemplateHtml(w http.ResponseWriter, req *http.Request) {
-	req.Header.Set("Accept", "text/html; charset=utf-8")
-	b, err := io.ReadAll(req.Body)
-	html_string := string(b)
+	id, err := helpers.PathId(req, "id")
 	if err != nil {
-		fmt.Fprint(w, "Error reading HTML from request")
+		apperr.Write(w, req, err)
+		return
 	}
 
-	if _, err := html.Parse(strings.NewReader(html_string)); err != nil {
-		fmt.Fprint(w, "HTML seems to be invalid")
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading HTML from request"))
+		return
 	}
 
-	id := GetId(w, req)
-	template, err := ta.templates.Retrieve(id)
-	if err != nil {
-		fmt.Fprint(w, "Coudln't retrieve template")
+	html_string := string(b)
+	if _, err = html.Parse(strings.NewReader(html_string)); err != nil {
+		apperr.Write(w, req, apperr.Invalid("HTML seems to be invalid", apperr.Field("body", err.Error())))
+		return
 	}
 
-	if err := os.WriteFile(template.data.Path, []byte(html_string), 0660); err != nil {
-		fmt.Fprint(w, "Coudln't write into HTML file")
+	workspace_id := workspace.IdFromContext(req.Context())
+	if _, err = ta.templates.UpdateHtml(req.Context(), workspace_id, int(id), html_string, audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Couldn't write into HTML file"))
+		return
 	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (ta *TemplateApi) DeleteTemplate(w http.ResponseWriter, req *http.Request) {
-	id := GetId(w, req)
-	if err := ta.templates.Delete(id); err != nil {
-		fmt.Fprint(w, "Error while deleting the template")
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
 	}
+
+	if err = ta.templates.Delete(req.Context(), workspace.IdFromContext(req.Context()), int(id), audit.ActorFromRequest(req)); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error while deleting the template"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func NewTemplateApi() *TemplateApi {
+func CreateStaticDirs() error {
 	if err := os.MkdirAll(HTML_TEMPLATES_DIR, os.ModePerm); err != nil {
-		fmt.Print("Error creating HTML templates directory")
+		return fmt.Errorf("error creating HTML templates directory: %w", err)
 	}
 
 	if err := os.MkdirAll(THUMBNAILS_DIR, os.ModePerm); err != nil {
-		fmt.Print("Error creating thumbnails directory")
+		return fmt.Errorf("error creating thumbnails directory: %w", err)
 	}
 
-	ts, err := NewTemplates()
-	if err != nil {
+	return nil
+}
+
+func NewTemplateApi(ts *Templates) *TemplateApi {
+	if err := CreateStaticDirs(); err != nil {
 		fmt.Print(err)
 	}
 
@@ -0,0 +1,213 @@
+package template
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/telemetry"
+	pb "invoice-manager/main/proto"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const ARCHIVE_MANIFEST = "manifest.json"
+
+var (
+	ErrInvalidArchive = apperr.New(apperr.CODE_INVALID_ARGUMENT, "invalid templates archive")
+)
+
+type ArchiveEntry struct {
+	Name      string `json:"name"`
+	Ext       string `json:"ext"`
+	Size      uint32 `json:"size"`
+	Html      string `json:"html"`
+	Thumbnail string `json:"thumbnail"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+type ArchiveManifest struct {
+	ExportedAt int64          `json:"exportedAt"`
+	Templates  []ArchiveEntry `json:"templates"`
+}
+
+func addFileToArchive(zw *zip.Writer, name string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (ts *Templates) Export(ctx context.Context, w io.Writer, workspace_id uint32, ids []int) (err error) {
+	ctx, end := telemetry.Start(ctx, "Templates.Export")
+	defer end(&err)
+
+	templates := []Template{}
+	for _, id := range ids {
+		template, err := ts.Retrieve(ctx, workspace_id, id)
+		if err != nil {
+			return fmt.Errorf("template %d: %w", id, err)
+		}
+		templates = append(templates, template)
+	}
+
+	if len(ids) == 0 {
+		all, err := ts.List(ctx, workspace_id)
+		if err != nil {
+			return err
+		}
+		templates = all
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := ArchiveManifest{ExportedAt: time.Now().Unix()}
+
+	for _, template := range templates {
+		data := template.data
+		entry := ArchiveEntry{
+			Name:      data.Name,
+			Ext:       data.Ext,
+			Size:      data.Size,
+			Html:      fmt.Sprintf("templates/%d.html", data.Id),
+			Thumbnail: fmt.Sprintf("thumbnails/%d.jpg", data.Id),
+			CreatedAt: data.CreatedAt,
+			UpdatedAt: data.UpdatedAt,
+		}
+
+		if err := addFileToArchive(zw, entry.Html, data.Path); err != nil {
+			return err
+		}
+
+		if err := addFileToArchive(zw, entry.Thumbnail, data.Thumbnail); err != nil {
+			return err
+		}
+
+		manifest.Templates = append(manifest.Templates, entry)
+	}
+
+	mw, err := zw.Create(ARCHIVE_MANIFEST)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(mw)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(manifest); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func extractArchiveFile(zr *zip.Reader, name string, dest string) error {
+	src, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer src.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+
+	return out.Close()
+}
+
+func archiveFileSize(zr *zip.Reader, name string) int64 {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return int64(f.UncompressedSize64)
+		}
+	}
+	return 0
+}
+
+func (ts *Templates) Import(ctx context.Context, r io.ReaderAt, size int64, workspace_id uint32, actor *audit.Actor) (_ []*Template, err error) {
+	ctx, end := telemetry.Start(ctx, "Templates.Import")
+	defer end(&err)
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	mf, err := zr.Open(ARCHIVE_MANIFEST)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer mf.Close()
+
+	var manifest ArchiveManifest
+	if err = json.NewDecoder(mf).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+
+	templates_dir, err := storage.EnsureWorkspaceDir(workspace_id, storage.TEMPLATES)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails_dir, err := storage.EnsureWorkspaceDir(workspace_id, storage.THUMBNAILS)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := []*Template{}
+	for _, entry := range manifest.Templates {
+		id := fmt.Sprint(time.Now().UnixNano())
+		template_path := filepath.Join(templates_dir, id+".html")
+		thumbnail_path := filepath.Join(thumbnails_dir, id+"_"+THUMBNAIL_NAME)
+
+		if err = ts.quotas.CheckTemplate(workspace_id, archiveFileSize(zr, entry.Html)+archiveFileSize(zr, entry.Thumbnail)); err != nil {
+			return imported, err
+		}
+
+		if err = extractArchiveFile(zr, entry.Html, template_path); err != nil {
+			return imported, err
+		}
+
+		if err = extractArchiveFile(zr, entry.Thumbnail, thumbnail_path); err != nil {
+			os.Remove(template_path)
+			return imported, err
+		}
+
+		new_template, err := ts.Insert(ctx, &pb.Template{
+			Name:      entry.Name,
+			Ext:       entry.Ext,
+			Size:      entry.Size,
+			Path:      template_path,
+			Thumbnail: thumbnail_path,
+		}, workspace_id, actor)
+		if err != nil {
+			os.Remove(template_path)
+			os.Remove(thumbnail_path)
+			return imported, err
+		}
+
+		imported = append(imported, new_template)
+	}
+
+	return imported, nil
+}
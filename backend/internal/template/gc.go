@@ -0,0 +1,133 @@
+package template
+
+import (
+	"context"
+	"invoice-manager/main/internal/storage"
+	"invoice-manager/main/internal/telemetry"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type GcReport struct {
+	OrphanedFiles  []string `json:"orphanedFiles"`
+	StaleTempFiles []string `json:"staleTempFiles"`
+	MissingFiles   []string `json:"missingFiles"`
+	FreedBytes     int64    `json:"freedBytes"`
+}
+
+func (r *GcReport) remove(path string, size int64, dry_run bool) error {
+	if !dry_run {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	r.FreedBytes += size
+	return nil
+}
+
+// CollectGarbage removes files in the static directories that no template
+// references anymore and temporary uploads left behind by interrupted
+// conversions. Templates whose files are gone are only reported.
+func (ts *Templates) CollectGarbage(ctx context.Context, dry_run bool, max_temp_age time.Duration) (_ *GcReport, err error) {
+	ctx, end := telemetry.Start(ctx, "Templates.CollectGarbage")
+	defer end(&err)
+
+	templates, err := ts.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GcReport{
+		OrphanedFiles:  []string{},
+		StaleTempFiles: []string{},
+		MissingFiles:   []string{},
+	}
+
+	referenced := map[string]bool{}
+	for _, template := range templates {
+		for _, path := range []string{template.data.Path, template.data.Thumbnail} {
+			referenced[filepath.Clean(path)] = true
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				report.MissingFiles = append(report.MissingFiles, path)
+			}
+		}
+	}
+
+	dirs := []string{HTML_TEMPLATES_DIR, THUMBNAILS_DIR}
+	for _, kind := range []string{storage.TEMPLATES, storage.THUMBNAILS} {
+		workspace_dirs, err := storage.WorkspaceDirs(kind)
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, workspace_dirs...)
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || referenced[path] {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+
+			if err = report.remove(path, info.Size(), dry_run); err != nil {
+				return nil, err
+			}
+			report.OrphanedFiles = append(report.OrphanedFiles, path)
+		}
+	}
+
+	if err = report.removeStaleTempFiles(dry_run, max_temp_age); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r *GcReport) removeStaleTempFiles(dry_run bool, max_temp_age time.Duration) error {
+	temp_files, err := filepath.Glob(filepath.Join(STATIC_DIR, TEMP_FILE_PATTERN))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range temp_files {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if time.Since(info.ModTime()) < max_temp_age {
+			continue
+		}
+
+		if err = r.remove(path, info.Size(), dry_run); err != nil {
+			return err
+		}
+		r.StaleTempFiles = append(r.StaleTempFiles, path)
+	}
+
+	return nil
+}
+
+// RemoveStaleTempFiles only removes the uploads left behind by interrupted
+// conversions, which is safe to do while the server is running.
+func RemoveStaleTempFiles(max_temp_age time.Duration) (*GcReport, error) {
+	report := &GcReport{StaleTempFiles: []string{}}
+	if err := report.removeStaleTempFiles(false, max_temp_age); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
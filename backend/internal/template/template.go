@@ -1,20 +1,29 @@
 package template
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"errors"
 	"fmt"
-	"invoice-manager/main/internal/constants"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/audit"
 	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/lifecycle"
+	"invoice-manager/main/internal/pdfcheck"
+	"invoice-manager/main/internal/quota"
+	"invoice-manager/main/internal/telemetry"
 	pb "invoice-manager/main/proto"
 	"log"
 	"os"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 var (
-	ErrIDNotFound = fmt.Errorf("ID not found")
+	ErrIDNotFound = apperr.New(apperr.CODE_NOT_FOUND, "template not found")
+	ErrInUse      = apperr.New(apperr.CODE_FAILED_PRECONDITION, "template is used by issued invoices")
 )
 
 type Template struct {
@@ -31,14 +40,98 @@ func (t *Template) GetPublicThumbnailPath() string {
 	return helpers.PublicUrlToFile(t.data.Thumbnail)
 }
 
+func (t *Template) Data() *pb.Template {
+	return t.data
+}
+
+func (t *Template) Public() *pb.Template {
+	return &pb.Template{
+		Id:          t.data.Id,
+		Name:        t.data.Name,
+		Ext:         t.data.Ext,
+		Size:        t.data.Size,
+		CreatedAt:   t.data.CreatedAt,
+		UpdatedAt:   t.data.UpdatedAt,
+		CreatedBy:   t.data.CreatedBy,
+		UpdatedBy:   t.data.UpdatedBy,
+		WorkspaceId: t.data.WorkspaceId,
+		Path:        t.public_path,
+		Thumbnail:   t.public_thumbnail_path,
+	}
+}
+
 type Templates struct {
-	db *sql.DB
+	db     *sql.DB
+	audit  *audit.Log
+	limits pdfcheck.Limits
+	quotas *quota.Quotas
+	jobs   *lifecycle.Jobs
 
-	insert_stmt, retrieve_stmt, list_stmt, delete_stmt, update_name_stmt *sql.Stmt
+	insert_stmt, retrieve_stmt, list_stmt, list_all_stmt, delete_stmt, update_name_stmt, touch_stmt *sql.Stmt
 }
 
-func (ts *Templates) Insert(template *pb.Template) (*Template, error) {
-	res, err := ts.insert_stmt.Exec(
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplate(row scanner) (Template, error) {
+	template := Template{data: &pb.Template{}}
+	err := row.Scan(
+		&template.data.Id,
+		&template.data.Name,
+		&template.data.Ext,
+		&template.data.Size,
+		&template.data.Path,
+		&template.public_path,
+		&template.data.Thumbnail,
+		&template.public_thumbnail_path,
+		&template.data.CreatedAt,
+		&template.data.UpdatedAt,
+		&template.data.CreatedBy,
+		&template.data.UpdatedBy,
+		&template.data.WorkspaceId,
+	)
+	return template, err
+}
+
+const (
+	AUDIT_TARGET      = "template"
+	AUDIT_CREATE      = "template.create"
+	AUDIT_RENAME      = "template.rename"
+	AUDIT_UPDATE_HTML = "template.update_html"
+	AUDIT_DELETE      = "template.delete"
+)
+
+// auditFields is what the audit log records of a template.
+func auditFields(t *pb.Template) map[string]string {
+	return map[string]string{
+		"name":      t.Name,
+		"ext":       t.Ext,
+		"size":      fmt.Sprint(t.Size),
+		"path":      t.Path,
+		"thumbnail": t.Thumbnail,
+	}
+}
+
+func htmlFields(html []byte) map[string]string {
+	return map[string]string{
+		"html_sha256": fmt.Sprintf("%x", sha256.Sum256(html)),
+		"html_size":   fmt.Sprint(len(html)),
+	}
+}
+
+func (ts *Templates) Insert(ctx context.Context, template *pb.Template, workspace_id uint32, actor *audit.Actor) (_ *Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.Insert")
+	defer end(&err)
+
+	tx, err := ts.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Stmt(ts.insert_stmt).ExecContext(
+		ctx,
 		template.Name,
 		template.Ext,
 		template.Size,
@@ -48,6 +141,9 @@ func (ts *Templates) Insert(template *pb.Template) (*Template, error) {
 		helpers.PublicUrlToFile(template.Thumbnail),
 		time.Now().Unix(),
 		time.Now().Unix(),
+		helpers.NullableId(actor.UserId),
+		helpers.NullableId(actor.UserId),
+		workspace_id,
 	)
 	if err != nil {
 		return nil, err
@@ -58,29 +154,28 @@ func (ts *Templates) Insert(template *pb.Template) (*Template, error) {
 		return nil, err
 	}
 
-	new_template, err := ts.Retrieve(int(id))
+	new_template, err := scanTemplate(tx.Stmt(ts.retrieve_stmt).QueryRowContext(ctx, id, workspace_id))
 	if err != nil {
 		return nil, err
 	}
 
+	entry := actor.Entry(workspace_id, AUDIT_CREATE, AUDIT_TARGET, uint32(id), audit.Diff(nil, auditFields(new_template.data)))
+	if err = ts.audit.Record(tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return &new_template, nil
 }
 
-func (ts *Templates) Retrieve(id int) (Template, error) {
-	row := ts.retrieve_stmt.QueryRow(id)
-	template := Template{data: &pb.Template{}}
-	err := row.Scan(
-		&template.data.Id,
-		&template.data.Name,
-		&template.data.Ext,
-		&template.data.Size,
-		&template.data.Path,
-		&template.public_path,
-		&template.data.Thumbnail,
-		&template.public_thumbnail_path,
-		&template.data.CreatedAt,
-		&template.data.UpdatedAt,
-	)
+func (ts *Templates) Retrieve(ctx context.Context, workspace_id uint32, id int) (_ Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.Retrieve")
+	defer end(&err)
+
+	template, err := scanTemplate(ts.retrieve_stmt.QueryRowContext(ctx, id, workspace_id))
 	if err == sql.ErrNoRows {
 		return template, ErrIDNotFound
 	}
@@ -88,28 +183,12 @@ func (ts *Templates) Retrieve(id int) (Template, error) {
 	return template, err
 }
 
-func (ts *Templates) List() ([]Template, error) {
-	rows, err := ts.list_stmt.Query()
-	if err != nil {
-		return nil, err
-	}
+func (ts *Templates) scanRows(rows *sql.Rows) ([]Template, error) {
 	defer rows.Close()
 
 	data := []Template{}
 	for rows.Next() {
-		row := Template{data: &pb.Template{}}
-		err = rows.Scan(
-			&row.data.Id,
-			&row.data.Name,
-			&row.data.Ext,
-			&row.data.Size,
-			&row.data.Path,
-			&row.public_path,
-			&row.data.Thumbnail,
-			&row.public_thumbnail_path,
-			&row.data.CreatedAt,
-			&row.data.UpdatedAt,
-		)
+		row, err := scanTemplate(rows)
 		if err != nil {
 			log.Println(err)
 			return nil, err
@@ -118,19 +197,69 @@ func (ts *Templates) List() ([]Template, error) {
 		data = append(data, row)
 	}
 
-	return data, nil
+	return data, rows.Err()
+}
+
+func (ts *Templates) List(ctx context.Context, workspace_id uint32) (_ []Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.List")
+	defer end(&err)
+
+	rows, err := ts.list_stmt.QueryContext(ctx, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.scanRows(rows)
 }
 
-func (ts *Templates) Delete(id int) error {
-	template, err := ts.Retrieve(id)
+// ListAll returns the templates of every workspace. It is meant for
+// maintenance tasks only and must never back a request handler.
+func (ts *Templates) ListAll(ctx context.Context) (_ []Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.ListAll")
+	defer end(&err)
+
+	rows, err := ts.list_all_stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.scanRows(rows)
+}
+
+func (ts *Templates) Delete(ctx context.Context, workspace_id uint32, id int, actor *audit.Actor) (err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.Delete")
+	defer end(&err)
+
+	template, err := ts.Retrieve(ctx, workspace_id, id)
 	if err != nil {
 		return err
 	}
 
-	_, err = ts.delete_stmt.Exec(id)
+	tx, err := ts.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	// Drafts drop the template, issued invoices keep theirs, which the
+	// trigger guarding them refuses to clear.
+	_, err = tx.Stmt(ts.delete_stmt).ExecContext(ctx, id, workspace_id)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && (sqlite_err.ExtendedCode == sqlite3.ErrConstraintTrigger || sqlite_err.ExtendedCode == sqlite3.ErrConstraintForeignKey) {
+		return ErrInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	entry := actor.Entry(workspace_id, AUDIT_DELETE, AUDIT_TARGET, uint32(id), audit.Diff(auditFields(template.data), nil))
+	if err = ts.audit.Record(tx, entry); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
 
 	err = os.Remove(template.data.Path)
 	if err != nil {
@@ -145,13 +274,40 @@ func (ts *Templates) Delete(id int) error {
 	return nil
 }
 
-func (ts *Templates) UpdateName(id int, new_name string) (*Template, error) {
-	_, err := ts.update_name_stmt.Exec(new_name, id)
+func (ts *Templates) UpdateName(ctx context.Context, workspace_id uint32, id int, new_name string, actor *audit.Actor) (_ *Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.UpdateName")
+	defer end(&err)
+
+	template, err := ts.Retrieve(ctx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ts.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	updated_template, err := ts.Retrieve(id)
+	res, err := tx.Stmt(ts.update_name_stmt).ExecContext(ctx, new_name, time.Now().Unix(), helpers.NullableId(actor.UserId), id, workspace_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrIDNotFound
+	}
+
+	changes := audit.Diff(map[string]string{"name": template.data.Name}, map[string]string{"name": new_name})
+	if err = ts.audit.Record(tx, actor.Entry(workspace_id, AUDIT_RENAME, AUDIT_TARGET, uint32(id), changes)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	updated_template, err := ts.Retrieve(ctx, workspace_id, id)
 	if err != nil {
 		return nil, err
 	}
@@ -159,39 +315,96 @@ func (ts *Templates) UpdateName(id int, new_name string) (*Template, error) {
 	return &updated_template, nil
 }
 
-func NewTemplates() (*Templates, error) {
-	db, err := sql.Open("sqlite3", constants.DB_FILE)
+// UpdateHtml replaces the HTML file of the template. The file is written
+// last, so that a failed audit log entry leaves it untouched.
+func (ts *Templates) UpdateHtml(ctx context.Context, workspace_id uint32, id int, html string, actor *audit.Actor) (_ *Template, err error) {
+	ctx, end := telemetry.StartQuery(ctx, "Templates.UpdateHtml")
+	defer end(&err)
+
+	template, err := ts.Retrieve(ctx, workspace_id, id)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS templates (
-			template_id INTEGER NOT NULL PRIMARY KEY,
-			template_name VARCHAR NOT NULL,
-			template_ext VARCHAR(10) NOT NULL,
-			template_size INTEGER NOT NULL,
-			template_private_path TEXT NOT NULL,
-			template_public_path TEXT NOT NULL,
-			template_private_thumbnail_path TEXT NOT NULL,
-			template_public_thumbnail_path TEXT NOT NULL,
-			template_created_at INTEGER NOT NULL,
-			template_updated_at INTEGER NOT NULL
-		);
-	`)
+	previous_html, err := os.ReadFile(template.data.Path)
 	if err != nil {
-		log.Println(err)
 		return nil, err
 	}
 
-	insert_stmt, err := db.Prepare("INSERT INTO templates VALUES(NULL, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	tx, err := ts.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	retrieve_stmt, err := db.Prepare(`
-		SELECT 
-			template_id,
+	if _, err = tx.Stmt(ts.touch_stmt).ExecContext(ctx, time.Now().Unix(), helpers.NullableId(actor.UserId), id, workspace_id); err != nil {
+		return nil, err
+	}
+
+	changes := audit.Diff(htmlFields(previous_html), htmlFields([]byte(html)))
+	if err = ts.audit.Record(tx, actor.Entry(workspace_id, AUDIT_UPDATE_HTML, AUDIT_TARGET, uint32(id), changes)); err != nil {
+		return nil, err
+	}
+
+	if err = os.WriteFile(template.data.Path, []byte(html), 0660); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		os.WriteFile(template.data.Path, previous_html, 0660)
+		return nil, err
+	}
+
+	updated_template, err := ts.Retrieve(ctx, workspace_id, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated_template, nil
+}
+
+// Jobs are the conversions running right now.
+func (ts *Templates) Jobs() *lifecycle.Jobs {
+	return ts.jobs
+}
+
+func (ts *Templates) Close() error {
+	stmts := []*sql.Stmt{
+		ts.insert_stmt,
+		ts.retrieve_stmt,
+		ts.list_stmt,
+		ts.list_all_stmt,
+		ts.delete_stmt,
+		ts.update_name_stmt,
+		ts.touch_stmt,
+	}
+
+	var errs []error
+	for _, stmt := range stmts {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+const TEMPLATE_COLUMNS = `
+	template_id,
+	template_name,
+	template_ext,
+	template_size,
+	template_private_path,
+	template_public_path,
+	template_private_thumbnail_path,
+	template_public_thumbnail_path,
+	template_created_at,
+	template_updated_at,
+	COALESCE(template_created_by, 0),
+	COALESCE(template_updated_by, 0),
+	template_workspace_id
+`
+
+func NewTemplates(db *sql.DB, audit_log *audit.Log, quotas *quota.Quotas) (*Templates, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO templates (
 			template_name,
 			template_ext,
 			template_size,
@@ -200,38 +413,77 @@ func NewTemplates() (*Templates, error) {
 			template_private_thumbnail_path,
 			template_public_thumbnail_path,
 			template_created_at,
-			template_updated_at
+			template_updated_at,
+			template_created_by,
+			template_updated_by,
+			template_workspace_id
+		) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT ` + TEMPLATE_COLUMNS + `
 		FROM templates
-		WHERE template_id = ?
-		ORDER BY template_created_at ASC;
+		WHERE template_id = ? AND template_workspace_id = ?;
 	`)
 	if err != nil {
 		return nil, err
 	}
 
-	delete_stmt, err := db.Prepare("DELETE FROM templates WHERE template_id = ?")
+	delete_stmt, err := db.Prepare("DELETE FROM templates WHERE template_id = ? AND template_workspace_id = ?")
 	if err != nil {
 		return nil, err
 	}
 
-	list_stmt, err := db.Prepare("SELECT * FROM templates ORDER BY template_created_at ASC")
+	list_stmt, err := db.Prepare(`
+		SELECT ` + TEMPLATE_COLUMNS + `
+		FROM templates
+		WHERE template_workspace_id = ?
+		ORDER BY template_created_at ASC
+	`)
 	if err != nil {
 		return nil, err
 	}
 
-	update_name_stmt, err := db.Prepare(
-		"UPDATE templates SET template_name = ? WHERE template_id = ?",
+	list_all_stmt, err := db.Prepare(
+		"SELECT " + TEMPLATE_COLUMNS + " FROM templates ORDER BY template_created_at ASC",
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	update_name_stmt, err := db.Prepare(`
+		UPDATE templates
+		SET template_name = ?, template_updated_at = ?, template_updated_by = ?
+		WHERE template_id = ? AND template_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	touch_stmt, err := db.Prepare(`
+		UPDATE templates
+		SET template_updated_at = ?, template_updated_by = ?
+		WHERE template_id = ? AND template_workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Templates{
 		db:               db,
+		audit:            audit_log,
+		limits:           pdfcheck.LimitsFromEnv(),
+		quotas:           quotas,
+		jobs:             lifecycle.NewJobs(),
 		insert_stmt:      insert_stmt,
 		retrieve_stmt:    retrieve_stmt,
 		delete_stmt:      delete_stmt,
 		list_stmt:        list_stmt,
+		list_all_stmt:    list_all_stmt,
 		update_name_stmt: update_name_stmt,
+		touch_stmt:       touch_stmt,
 	}, nil
 }
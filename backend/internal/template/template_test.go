@@ -0,0 +1,109 @@
+package template
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"invoice-manager/main/internal/audit"
+	"invoice-manager/main/internal/database"
+	"invoice-manager/main/internal/quota"
+	pb "invoice-manager/main/proto"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestTemplates returns templates on a database of its own, along with
+// a client to use them for.
+func newTestTemplates(t *testing.T) (*Templates, *sql.DB) {
+	t.Helper()
+
+	db, err := database.OpenFile(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err = database.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO clients (client_id, client_legal_name, client_created_at, client_updated_at, client_workspace_id) VALUES(1, 'Acme GmbH', unixepoch(), unixepoch(), 1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit_log, err := audit.NewLog(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotas, err := quota.NewQuotas(db, quota.DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, err := NewTemplates(db, audit_log, quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts, db
+}
+
+// insertTemplate stores a template with its files in dir, and an invoice
+// of the status using it.
+func insertTemplate(t *testing.T, ts *Templates, db *sql.DB, dir string, status string) *Template {
+	t.Helper()
+
+	path, thumbnail := filepath.Join(dir, status+".html"), filepath.Join(dir, status+".png")
+	for _, file := range []string{path, thumbnail} {
+		if err := os.WriteFile(file, []byte(status), 0660); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	template, err := ts.Insert(context.Background(), &pb.Template{Name: status, Ext: ".pdf", Path: path, Thumbnail: thumbnail}, 1, audit.CliActor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO invoices (invoice_status, invoice_client_id, invoice_template_id, invoice_currency, invoice_issue_date, invoice_due_date, invoice_created_at, invoice_updated_at, invoice_workspace_id)
+		VALUES(?, 1, ?, 'EUR', '2026-03-07', '2026-03-21', unixepoch(), unixepoch(), 1)
+	`, status, template.Data().Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+func TestDelete(t *testing.T) {
+	ts, db := newTestTemplates(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	issued := insertTemplate(t, ts, db, dir, "issued")
+	if err := ts.Delete(ctx, 1, int(issued.Data().Id), audit.CliActor); !errors.Is(err, ErrInUse) {
+		t.Errorf("Delete() of a template of an issued invoice error = %v, want %v", err, ErrInUse)
+	}
+	if _, err := os.Stat(issued.Data().Path); err != nil {
+		t.Errorf("template of an issued invoice is gone: %v", err)
+	}
+
+	draft := insertTemplate(t, ts, db, dir, "draft")
+	if err := ts.Delete(ctx, 1, int(draft.Data().Id), audit.CliActor); err != nil {
+		t.Fatalf("Delete() of a template of a draft error = %v", err)
+	}
+	for _, path := range []string{draft.Data().Path, draft.Data().Thumbnail} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s of a deleted template is left: %v", path, err)
+		}
+	}
+	var template_id sql.NullInt64
+	if err := db.QueryRow("SELECT invoice_template_id FROM invoices WHERE invoice_status = 'draft'").Scan(&template_id); err != nil {
+		t.Fatal(err)
+	}
+	if template_id.Valid {
+		t.Errorf("draft still uses deleted template %d", template_id.Int64)
+	}
+
+	if err := ts.Delete(ctx, 1, int(draft.Data().Id), audit.CliActor); !errors.Is(err, ErrIDNotFound) {
+		t.Errorf("Delete() of a deleted template error = %v, want %v", err, ErrIDNotFound)
+	}
+}
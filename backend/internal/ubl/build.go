@@ -0,0 +1,447 @@
+package ubl
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/issuer"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/tax"
+	"invoice-manager/main/internal/workspace"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// DEFAULT_DISCOUNT_REASON names discounts without a description, as every
+// allowance needs a reason.
+const DEFAULT_DISCOUNT_REASON = "Discount"
+
+type builder struct {
+	currency string
+	// sign is -1 for credit notes, which are stored with negated amounts
+	// but credit positive ones in UBL.
+	sign decimal.Decimal
+}
+
+func (b *builder) parse(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(value)
+	return d.Mul(b.sign), err
+}
+
+func (b *builder) amount(value decimal.Decimal) Amount {
+	return Amount{Value: money.String(value, b.currency), Currency: b.currency}
+}
+
+// price keeps the decimals of unit prices finer than the minor units of the
+// currency.
+func (b *builder) price(value decimal.Decimal) Amount {
+	if value.Equal(money.Round(value, b.currency)) {
+		return b.amount(value)
+	}
+	return Amount{Value: value.String(), Currency: b.currency}
+}
+
+// allowanceCharge takes amount off as an allowance, or adds it as a charge
+// if it is negative.
+func (b *builder) allowanceCharge(amount decimal.Decimal, reason string, category *TaxCategory) AllowanceCharge {
+	return AllowanceCharge{
+		ChargeIndicator:       amount.IsNegative(),
+		AllowanceChargeReason: reason,
+		Amount:                b.amount(amount.Abs()),
+		TaxCategory:           category,
+	}
+}
+
+func taxCategory(code string, rate decimal.Decimal) TaxCategory {
+	return TaxCategory{Id: code, Percent: rate.String(), TaxScheme: TaxScheme{Id: TAX_SCHEME_VAT}}
+}
+
+// lineCategory is the tax category of the line, the way invoice totals
+// default it for lines saved without one.
+func lineCategory(item *pb.LineItem, rate decimal.Decimal) string {
+	if item.TaxCategory != "" {
+		return item.TaxCategory
+	}
+	if rate.IsZero() {
+		return tax.CATEGORY_ZERO_RATED
+	}
+	return tax.CATEGORY_STANDARD
+}
+
+func groupKey(category string, rate decimal.Decimal, reason string) string {
+	return category + "\x00" + rate.String() + "\x00" + reason
+}
+
+type line struct {
+	item     *pb.LineItem
+	quantity decimal.Decimal
+	price    decimal.Decimal
+	net      decimal.Decimal
+	rate     decimal.Decimal
+	code     string
+	// extension is the net amount of the line in the document, which
+	// differs from net if prices include tax.
+	extension decimal.Decimal
+}
+
+type group struct {
+	lines []*line
+	net   decimal.Decimal
+}
+
+func address(a *pb.Address) Address {
+	if a == nil {
+		a = &pb.Address{}
+	}
+	return Address{
+		StreetName:           a.Line1,
+		AdditionalStreetName: a.Line2,
+		CityName:             a.City,
+		PostalZone:           a.PostalCode,
+		CountrySubentity:     a.Region,
+		Country:              Country{IdentificationCode: strings.ToUpper(a.Country)},
+	}
+}
+
+func sellerParty(seller *pb.Issuer) Party {
+	party := Party{
+		EndpointId:       endpoint(seller.VatId),
+		PostalAddress:    address(seller.Address),
+		PartyTaxSchemes:  []PartyTaxScheme{},
+		PartyLegalEntity: PartyLegalEntity{RegistrationName: seller.LegalName},
+	}
+	if seller.Name != "" && seller.Name != seller.LegalName {
+		party.PartyName = &PartyName{Name: seller.Name}
+	}
+	if seller.VatId != "" {
+		party.PartyTaxSchemes = append(party.PartyTaxSchemes, PartyTaxScheme{CompanyId: seller.VatId, TaxScheme: TaxScheme{Id: TAX_SCHEME_VAT}})
+	}
+	if seller.TaxId != "" {
+		party.PartyTaxSchemes = append(party.PartyTaxSchemes, PartyTaxScheme{CompanyId: seller.TaxId, TaxScheme: TaxScheme{Id: TAX_SCHEME_FISCAL}})
+	}
+	if len(seller.RegistrationNumbers) > 0 {
+		party.PartyLegalEntity.CompanyId = seller.RegistrationNumbers[0].Value
+	}
+	return party
+}
+
+// buyerParty takes the tax ID of the client as its VAT ID if it looks like
+// one, otherwise as its legal registration.
+func buyerParty(c *pb.Client) Party {
+	party := Party{
+		PostalAddress:    address(c.BillingAddress),
+		PartyTaxSchemes:  []PartyTaxScheme{},
+		PartyLegalEntity: PartyLegalEntity{RegistrationName: c.LegalName},
+	}
+
+	vat_id := workspace.NormalizeVatId(c.TaxId)
+	if vatIdPattern.MatchString(vat_id) {
+		party.EndpointId = endpoint(vat_id)
+		party.PartyTaxSchemes = append(party.PartyTaxSchemes, PartyTaxScheme{CompanyId: vat_id, TaxScheme: TaxScheme{Id: TAX_SCHEME_VAT}})
+	} else {
+		party.PartyLegalEntity.CompanyId = strings.TrimSpace(c.TaxId)
+	}
+
+	contacts := slices.Clone(c.Contacts)
+	slices.SortStableFunc(contacts, func(a, b *pb.Contact) int {
+		switch {
+		case a.Primary == b.Primary:
+			return 0
+		case a.Primary:
+			return -1
+		}
+		return 1
+	})
+	if len(contacts) > 0 {
+		party.Contact = &Contact{Name: contacts[0].Name, Telephone: contacts[0].Phone, ElectronicMail: contacts[0].Email}
+	}
+	return party
+}
+
+func paymentMeans(invoice *pb.Invoice, seller *pb.Issuer) *PaymentMeans {
+	account := issuer.PrimaryAccount(seller)
+	if account == nil || account.Iban == "" {
+		return nil
+	}
+
+	holder := account.AccountHolder
+	if holder == "" {
+		holder = seller.LegalName
+	}
+	payee := &FinancialAccount{Id: account.Iban, Name: holder}
+	if account.Bic != "" {
+		payee.FinancialInstitutionBranch = &Branch{Id: account.Bic}
+	}
+	return &PaymentMeans{PaymentMeansCode: PAYMENT_SEPA_CREDIT_TRANSFER, PaymentId: invoice.Number, PayeeFinancialAccount: payee}
+}
+
+// nameAndDescription splits the description of a line into the name of the
+// item, its first line, and the rest.
+func nameAndDescription(description string) (string, string) {
+	name, rest, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	return strings.TrimSpace(name), strings.TrimSpace(rest)
+}
+
+func discountReason(discounts ...*pb.Discount) string {
+	reasons := []string{}
+	for _, discount := range discounts {
+		if discount != nil && discount.Description != "" {
+			reasons = append(reasons, discount.Description)
+		}
+	}
+	if len(reasons) == 0 {
+		return DEFAULT_DISCOUNT_REASON
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// Build maps an issued invoice to a UBL Invoice, or a CreditNote if credit
+// is set. Seller and buyer are the issuer and client as they were when it
+// was issued.
+//
+// The discounts of the invoice become one allowance per tax group, in the
+// amounts its totals took off each. If prices include tax, the lines and
+// allowances are converted to net amounts, with the rounding difference
+// put on the largest line of their group, so that they add up to the
+// taxable amounts of the totals. Those lines are priced per their whole
+// quantity.
+func Build(invoice *pb.Invoice, seller *pb.Issuer, buyer *pb.Client, credit bool) (*Document, error) {
+	b := &builder{currency: invoice.Currency, sign: decimal.NewFromInt(1)}
+	if credit {
+		b.sign = b.sign.Neg()
+	}
+
+	doc := &Document{
+		Xmlns:                   NS_INVOICE,
+		XmlnsCac:                NS_CAC,
+		XmlnsCbc:                NS_CBC,
+		CustomizationId:         CUSTOMIZATION_ID,
+		ProfileId:               PROFILE_ID,
+		Id:                      invoice.Number,
+		IssueDate:               invoice.IssueDate,
+		Notes:                   []string{},
+		DocumentCurrencyCode:    invoice.Currency,
+		BuyerReference:          invoice.BuyerReference,
+		AccountingSupplierParty: PartyWrapper{Party: sellerParty(seller)},
+		AccountingCustomerParty: PartyWrapper{Party: buyerParty(buyer)},
+		AllowanceCharges:        []AllowanceCharge{},
+	}
+	doc.XMLName.Local = "Invoice"
+	if credit {
+		doc.XMLName.Local = "CreditNote"
+		doc.Xmlns = NS_CREDIT_NOTE
+		doc.CreditNoteTypeCode = TYPE_CREDIT_NOTE
+		if invoice.OriginalNumber != "" {
+			doc.BillingReference = &BillingReference{InvoiceDocumentReference: DocumentReference{Id: invoice.OriginalNumber}}
+			doc.PaymentTerms = &PaymentTerms{Note: "Credits invoice " + invoice.OriginalNumber}
+		}
+	} else {
+		doc.InvoiceTypeCode = TYPE_INVOICE
+		doc.DueDate = invoice.DueDate
+		doc.PaymentMeans = paymentMeans(invoice, seller)
+	}
+	if invoice.Notes != "" {
+		doc.Notes = append(doc.Notes, invoice.Notes)
+	}
+	if invoice.ServiceDate != "" {
+		doc.Delivery = &Delivery{ActualDeliveryDate: invoice.ServiceDate}
+	}
+
+	lines := []*line{}
+	groups := map[string]*group{}
+	for i, item := range invoice.Items {
+		l := &line{item: item}
+		var err error
+		if l.quantity, err = b.parse(item.Quantity); err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		if l.price, err = decimal.NewFromString(item.UnitPrice); err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		if l.net, err = b.parse(item.Net); err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		if l.rate, err = decimal.NewFromString(item.TaxRate); err != nil {
+			return nil, fmt.Errorf("line item %d: %w", i+1, err)
+		}
+		category := lineCategory(item, l.rate)
+		l.code = categoryCodes[category]
+		l.extension = l.net
+		lines = append(lines, l)
+
+		key := groupKey(category, l.rate, item.ExemptionReason)
+		if groups[key] == nil {
+			groups[key] = &group{net: decimal.Zero}
+		}
+		groups[key].lines = append(groups[key].lines, l)
+		groups[key].net = groups[key].net.Add(l.net)
+	}
+
+	subtotals := []TaxSubtotal{}
+	reasons := map[int][]string{}
+	line_extension, allowances, charges := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, t := range invoice.Totals.GetTaxes() {
+		rate, err := decimal.NewFromString(t.Rate)
+		if err != nil {
+			return nil, err
+		}
+		base, err := b.parse(t.Base)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := b.parse(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+		g := groups[groupKey(t.Category, rate, t.ExemptionReason)]
+		if g == nil {
+			return nil, fmt.Errorf("no lines are taxed %s at %s%%", t.Category, t.Rate)
+		}
+
+		// What the discounts of the invoice took off the group.
+		share := g.net.Sub(base)
+		if invoice.PricesIncludeTax {
+			share = share.Sub(amount)
+			net_of := func(gross decimal.Decimal) decimal.Decimal {
+				return money.Round(gross.Mul(hundred).Div(hundred.Add(rate)), b.currency)
+			}
+
+			largest := g.lines[0]
+			sum := decimal.Zero
+			for _, l := range g.lines {
+				l.extension = net_of(l.net)
+				sum = sum.Add(l.extension)
+				if l.extension.Abs().GreaterThan(largest.extension.Abs()) {
+					largest = l
+				}
+			}
+			share = net_of(share)
+			largest.extension = largest.extension.Add(base.Sub(sum.Sub(share)))
+		}
+
+		code := categoryCodes[t.Category]
+		if !share.IsZero() {
+			category := taxCategory(code, rate)
+			doc.AllowanceCharges = append(doc.AllowanceCharges, b.allowanceCharge(share, discountReason(invoice.Discounts...), &category))
+			if share.IsNegative() {
+				charges = charges.Sub(share)
+			} else {
+				allowances = allowances.Add(share)
+			}
+		}
+
+		// Groups that only differ in their exemption reason share the
+		// breakdown of their category and rate.
+		i := slices.IndexFunc(subtotals, func(s TaxSubtotal) bool {
+			return s.TaxCategory.Id == code && s.TaxCategory.Percent == rate.String()
+		})
+		if i < 0 {
+			subtotals = append(subtotals, TaxSubtotal{
+				TaxableAmount: b.amount(decimal.Zero),
+				TaxAmount:     b.amount(decimal.Zero),
+				TaxCategory:   taxCategory(code, rate),
+			})
+			i = len(subtotals) - 1
+		}
+		s := &subtotals[i]
+		s.TaxableAmount = b.amount(decimal.RequireFromString(s.TaxableAmount.Value).Add(base))
+		s.TaxAmount = b.amount(decimal.RequireFromString(s.TaxAmount.Value).Add(amount))
+		if t.ExemptionReason != "" && !slices.Contains(reasons[i], t.ExemptionReason) {
+			reasons[i] = append(reasons[i], t.ExemptionReason)
+		}
+	}
+	for i := range subtotals {
+		subtotals[i].TaxCategory.TaxExemptionReason = strings.Join(reasons[i], "; ")
+	}
+
+	for i, l := range lines {
+		line_extension = line_extension.Add(l.extension)
+		name, description := nameAndDescription(l.item.Description)
+		quantity := &Quantity{UnitCode: UnitCode(l.item.Unit)}
+		out := Line{
+			Id:                  fmt.Sprint(i + 1),
+			LineExtensionAmount: b.amount(l.extension),
+			AllowanceCharges:    []AllowanceCharge{},
+			Item: Item{
+				Description:           description,
+				Name:                  name,
+				ClassifiedTaxCategory: taxCategory(l.code, l.rate),
+			},
+		}
+		if l.item.Sku != "" {
+			out.Item.SellersItemIdentification = &SellersItemIdentification{Id: l.item.Sku}
+		}
+
+		if invoice.PricesIncludeTax {
+			// The net price of a single unit would need more decimals than
+			// amounts have, so the line is priced as a whole.
+			quantity.Value = l.quantity.Abs().String()
+			out.Price.PriceAmount = b.amount(l.extension.Abs())
+			if !l.quantity.IsZero() {
+				out.Price.BaseQuantity = &Quantity{Value: l.quantity.Abs().String(), UnitCode: quantity.UnitCode}
+			}
+			if l.extension.IsNegative() {
+				quantity.Value = l.quantity.Abs().Neg().String()
+			}
+		} else {
+			// Prices must not be negative, credited lines of a negative
+			// price are a negative quantity instead.
+			q, price := l.quantity, l.price
+			if price.IsNegative() {
+				q, price = q.Neg(), price.Neg()
+			}
+			quantity.Value = q.String()
+			out.Price.PriceAmount = b.price(price)
+
+			discount := money.Round(q.Mul(price), b.currency).Sub(l.extension)
+			if !discount.IsZero() {
+				out.AllowanceCharges = append(out.AllowanceCharges, b.allowanceCharge(discount, discountReason(l.item.Discount), nil))
+			}
+		}
+
+		if credit {
+			out.CreditedQuantity = quantity
+			doc.CreditNoteLines = append(doc.CreditNoteLines, out)
+		} else {
+			out.InvoicedQuantity = quantity
+			doc.InvoiceLines = append(doc.InvoiceLines, out)
+		}
+	}
+
+	net, err := b.parse(invoice.Totals.GetNet())
+	if err != nil {
+		return nil, err
+	}
+	tax_amount, err := b.parse(invoice.Totals.GetTax())
+	if err != nil {
+		return nil, err
+	}
+	gross, err := b.parse(invoice.Totals.GetGross())
+	if err != nil {
+		return nil, err
+	}
+
+	doc.TaxTotal = TaxTotal{TaxAmount: b.amount(tax_amount), TaxSubtotals: subtotals}
+	doc.LegalMonetaryTotal = MonetaryTotal{
+		LineExtensionAmount: b.amount(line_extension),
+		TaxExclusiveAmount:  b.amount(net),
+		TaxInclusiveAmount:  b.amount(gross),
+		PayableAmount:       b.amount(gross),
+	}
+	if !allowances.IsZero() {
+		total := b.amount(allowances)
+		doc.LegalMonetaryTotal.AllowanceTotalAmount = &total
+	}
+	if !charges.IsZero() {
+		total := b.amount(charges)
+		doc.LegalMonetaryTotal.ChargeTotalAmount = &total
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,206 @@
+package ubl
+
+import (
+	"bytes"
+	"flag"
+	"invoice-manager/main/internal/tax"
+	pb "invoice-manager/main/proto"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const EXEMPT_REASON = "Article 135(1)(g) of Council Directive 2006/112/EC"
+
+func seller() *pb.Issuer {
+	return &pb.Issuer{
+		Name:      "Muster Consulting",
+		LegalName: "Muster Consulting GmbH",
+		Address:   &pb.Address{Line1: "Hauptstraße 1", City: "Berlin", PostalCode: "10115", Country: "DE"},
+		VatId:     "DE123456789",
+		TaxId:     "27/123/45678",
+		BankAccounts: []*pb.BankAccount{
+			{Iban: "DE02100100109307118603", Bic: "PBNKDEFF", Primary: true},
+		},
+	}
+}
+
+func buyer() *pb.Client {
+	return &pb.Client{
+		LegalName:      "Beispiel AG",
+		BillingAddress: &pb.Address{Line1: "Marienplatz 2", City: "München", PostalCode: "80331", Country: "de"},
+		TaxId:          "DE 987 654 321",
+		Contacts: []*pb.Contact{
+			{Name: "Max Mustermann", Email: "max@beispiel.de"},
+			{Name: "Erika Mustermann", Email: "erika@beispiel.de", Phone: "+49 89 123456", Primary: true},
+		},
+	}
+}
+
+func austrianBuyer() *pb.Client {
+	return &pb.Client{
+		LegalName:      "Beispiel GmbH",
+		BillingAddress: &pb.Address{Line1: "Stephansplatz 3", City: "Wien", PostalCode: "1010", Country: "AT"},
+		TaxId:          "ATU12345678",
+	}
+}
+
+func standardInvoice() *pb.Invoice {
+	return &pb.Invoice{
+		Number:         "INV-2026-0001",
+		Currency:       "EUR",
+		IssueDate:      "2026-03-07",
+		DueDate:        "2026-03-21",
+		ServiceDate:    "2026-02-28",
+		Notes:          "Thank you for your business.",
+		BuyerReference: "04011000-12345-03",
+		Items: []*pb.LineItem{
+			{Description: "Consulting\nArchitecture review", Quantity: "10", Unit: "h", UnitPrice: "120", TaxRate: "19", TaxCategory: tax.CATEGORY_STANDARD, Sku: "CONS-1", Net: "1200.00"},
+			{Description: "Travel expenses", Quantity: "1", UnitPrice: "250", Discount: &pb.Discount{Amount: "50", Description: "Flat rate"}, TaxRate: "19", Net: "200.00"},
+		},
+		Totals: &pb.Totals{
+			Subtotal: "1400.00",
+			Discount: "0.00",
+			Net:      "1400.00",
+			Tax:      "266.00",
+			Gross:    "1666.00",
+			Taxes:    []*pb.TaxAmount{{Rate: "19", Base: "1400.00", Amount: "266.00", Category: tax.CATEGORY_STANDARD}},
+		},
+	}
+}
+
+// creditNote credits two hours of the standard invoice. Credit notes are
+// stored with negated quantities and amounts.
+func creditNote() *pb.Invoice {
+	return &pb.Invoice{
+		Number:         "CN-2026-0001",
+		Currency:       "EUR",
+		IssueDate:      "2026-03-14",
+		BuyerReference: "04011000-12345-03",
+		OriginalNumber: "INV-2026-0001",
+		Items: []*pb.LineItem{
+			{Description: "Consulting\nArchitecture review", Quantity: "-2", Unit: "h", UnitPrice: "120", TaxRate: "19", TaxCategory: tax.CATEGORY_STANDARD, Sku: "CONS-1", Net: "-240.00"},
+		},
+		Totals: &pb.Totals{
+			Subtotal: "-240.00",
+			Discount: "0.00",
+			Net:      "-240.00",
+			Tax:      "-45.60",
+			Gross:    "-285.60",
+			Taxes:    []*pb.TaxAmount{{Rate: "19", Base: "-240.00", Amount: "-45.60", Category: tax.CATEGORY_STANDARD}},
+		},
+	}
+}
+
+func reverseChargeInvoice() *pb.Invoice {
+	return &pb.Invoice{
+		Number:         "INV-2026-0002",
+		Currency:       "EUR",
+		IssueDate:      "2026-03-07",
+		DueDate:        "2026-04-06",
+		BuyerReference: "PO-4711",
+		ReverseCharge:  true,
+		Items: []*pb.LineItem{
+			{Description: "Software licence", Quantity: "1", Unit: "year", UnitPrice: "5000", TaxRate: "0", TaxCategory: tax.CATEGORY_REVERSE_CHARGE, ExemptionReason: tax.REVERSE_CHARGE_REASON, Net: "5000.00"},
+		},
+		Totals: &pb.Totals{
+			Subtotal: "5000.00",
+			Discount: "0.00",
+			Net:      "5000.00",
+			Tax:      "0.00",
+			Gross:    "5000.00",
+			Taxes:    []*pb.TaxAmount{{Rate: "0", Base: "5000.00", Amount: "0.00", Category: tax.CATEGORY_REVERSE_CHARGE, ExemptionReason: tax.REVERSE_CHARGE_REASON}},
+		},
+	}
+}
+
+// mixedInvoice has standard, reduced and exempt lines and a discount of the
+// whole invoice, which takes 10% off each of them.
+func mixedInvoice() *pb.Invoice {
+	return &pb.Invoice{
+		Number:         "INV-2026-0003",
+		Currency:       "EUR",
+		IssueDate:      "2026-03-07",
+		DueDate:        "2026-03-21",
+		BuyerReference: "04011000-12345-03",
+		Items: []*pb.LineItem{
+			{Description: "Hosting", Quantity: "12", Unit: "month", UnitPrice: "50", TaxRate: "19", TaxCategory: tax.CATEGORY_STANDARD, Net: "600.00"},
+			{Description: "Handbook", Quantity: "3", Unit: "pcs", UnitPrice: "19.90", TaxRate: "7", TaxCategory: tax.CATEGORY_REDUCED, Net: "59.70"},
+			{Description: "Training", Quantity: "1", Unit: "DAY", UnitPrice: "800", TaxRate: "0", TaxCategory: tax.CATEGORY_EXEMPT, ExemptionReason: EXEMPT_REASON, Net: "800.00"},
+		},
+		Discounts: []*pb.Discount{{Percent: "10", Description: "Loyalty"}},
+		Totals: &pb.Totals{
+			Subtotal: "1459.70",
+			Discount: "145.97",
+			Net:      "1313.73",
+			Tax:      "106.36",
+			Gross:    "1420.09",
+			Taxes: []*pb.TaxAmount{
+				{Rate: "19", Base: "540.00", Amount: "102.60", Category: tax.CATEGORY_STANDARD},
+				{Rate: "7", Base: "53.73", Amount: "3.76", Category: tax.CATEGORY_REDUCED},
+				{Rate: "0", Base: "720.00", Amount: "0.00", Category: tax.CATEGORY_EXEMPT, ExemptionReason: EXEMPT_REASON},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		golden  string
+		invoice *pb.Invoice
+		buyer   *pb.Client
+		credit  bool
+	}{
+		{"standard invoice", "invoice.xml", standardInvoice(), buyer(), false},
+		{"credit note", "credit_note.xml", creditNote(), buyer(), true},
+		{"reverse charge", "reverse_charge.xml", reverseChargeInvoice(), austrianBuyer(), false},
+		{"multiple tax categories", "tax_categories.xml", mixedInvoice(), buyer(), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := Build(test.invoice, seller(), test.buyer, test.credit)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if err = Check(doc); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			got, err := doc.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", test.golden)
+			if *update {
+				if err = os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("document differs from %s, rerun with -update if the change is intended\n%s", path, got)
+			}
+		})
+	}
+}
+
+func TestBuildInvalidAmounts(t *testing.T) {
+	invoice := standardInvoice()
+	invoice.Items[1].Quantity = "one"
+	if _, err := Build(invoice, seller(), buyer(), false); err == nil {
+		t.Error("Build() of a line with an invalid quantity succeeded")
+	}
+
+	invoice = standardInvoice()
+	invoice.Totals.Taxes[0].Category = tax.CATEGORY_REDUCED
+	if _, err := Build(invoice, seller(), buyer(), false); err == nil {
+		t.Error("Build() of taxes no line is taxed at succeeded")
+	}
+}
@@ -0,0 +1,94 @@
+package ubl
+
+import (
+	"invoice-manager/main/internal/tax"
+	"regexp"
+	"strings"
+)
+
+const (
+	TAX_SCHEME_VAT = "VAT"
+	// TAX_SCHEME_FISCAL marks the tax number of the seller that isn't its
+	// VAT ID, e.g. the German Steuernummer.
+	TAX_SCHEME_FISCAL = "FC"
+
+	// UNCL5305 tax categories.
+	CATEGORY_STANDARD       = "S"
+	CATEGORY_ZERO_RATED     = "Z"
+	CATEGORY_EXEMPT         = "E"
+	CATEGORY_REVERSE_CHARGE = "AE"
+
+	// UNIT_ONE is the UN/ECE Recommendation 20 code of lines without a
+	// unit, or with one it doesn't know.
+	UNIT_ONE = "C62"
+)
+
+// categoryCodes map the tax categories of lines to UNCL5305. Reduced rates
+// are standard rated at another percentage.
+var categoryCodes = map[string]string{
+	tax.CATEGORY_STANDARD:       CATEGORY_STANDARD,
+	tax.CATEGORY_REDUCED:        CATEGORY_STANDARD,
+	tax.CATEGORY_ZERO_RATED:     CATEGORY_ZERO_RATED,
+	tax.CATEGORY_EXEMPT:         CATEGORY_EXEMPT,
+	tax.CATEGORY_REVERSE_CHARGE: CATEGORY_REVERSE_CHARGE,
+}
+
+// unitCodes map the units people write on lines to UN/ECE Recommendation 20
+// codes.
+var unitCodes = map[string]string{
+	"pc": "H87", "pcs": "H87", "piece": "H87", "pieces": "H87", "stk": "H87", "stück": "H87",
+	"min": "MIN", "minute": "MIN", "minutes": "MIN",
+	"h": "HUR", "hr": "HUR", "hrs": "HUR", "hour": "HUR", "hours": "HUR", "std": "HUR", "std.": "HUR", "stunde": "HUR", "stunden": "HUR",
+	"d": "DAY", "day": "DAY", "days": "DAY", "tag": "DAY", "tage": "DAY",
+	"wk": "WEE", "week": "WEE", "weeks": "WEE", "woche": "WEE", "wochen": "WEE",
+	"mo": "MON", "month": "MON", "months": "MON", "monat": "MON", "monate": "MON",
+	"yr": "ANN", "year": "ANN", "years": "ANN", "jahr": "ANN", "jahre": "ANN",
+	"g": "GRM", "kg": "KGM", "t": "TNE",
+	"m": "MTR", "km": "KMT", "m2": "MTK", "m²": "MTK", "sqm": "MTK", "m3": "MTQ", "m³": "MTQ",
+	"l": "LTR", "ltr": "LTR", "liter": "LTR", "litre": "LTR",
+	"kwh": "KWH",
+}
+
+var unitCodePattern = regexp.MustCompile(`^[A-Z0-9]{2,3}$`)
+
+// UnitCode returns the Recommendation 20 code of the unit of a line. Units
+// that already are codes, e.g. HUR, are kept.
+func UnitCode(unit string) string {
+	unit = strings.TrimSpace(unit)
+	if unitCodePattern.MatchString(unit) {
+		return unit
+	}
+	if code, ok := unitCodes[strings.ToLower(unit)]; ok {
+		return code
+	}
+	return UNIT_ONE
+}
+
+// vatSchemes are the Peppol electronic address schemes (EAS) of VAT IDs,
+// by the prefix of the VAT ID.
+var vatSchemes = map[string]string{
+	"AD": "9922", "AL": "9923", "AT": "9914", "BA": "9924", "BE": "9925", "BG": "9926",
+	"CH": "9927", "CY": "9928", "CZ": "9929", "DE": "9930", "EE": "9931", "EL": "9933",
+	"ES": "9920", "FR": "9957", "GB": "9932", "HR": "9934", "HU": "9910", "IE": "9935",
+	"IT": "9906", "LI": "9936", "LT": "9937", "LU": "9938", "LV": "9939", "MC": "9940",
+	"ME": "9941", "MK": "9942", "MT": "9943", "NL": "9944", "PL": "9945", "PT": "9946",
+	"RO": "9947", "RS": "9948", "SE": "9955", "SI": "9949", "SK": "9950", "SM": "9951",
+	"TR": "9952", "VA": "9953",
+}
+
+// vatIdPattern is the shape of VAT IDs, prefixed with the country code.
+var vatIdPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9+*]{2,13}$`)
+
+// endpoint is the electronic address a party is reached at in Peppol, its
+// VAT ID. Parties without one, or whose country has no scheme for it, have
+// none.
+func endpoint(vat_id string) *Identifier {
+	if !vatIdPattern.MatchString(vat_id) {
+		return nil
+	}
+	scheme, ok := vatSchemes[vat_id[:2]]
+	if !ok {
+		return nil
+	}
+	return &Identifier{Value: vat_id, SchemeId: scheme}
+}
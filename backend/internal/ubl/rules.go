@@ -0,0 +1,458 @@
+package ubl
+
+import (
+	"fmt"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Violation is a business rule of EN 16931, or of Peppol BIS on top of it,
+// that a document breaks, e.g. BR-06 for a seller without a name.
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// Violations is the error of documents that break business rules. Clients
+// get the rules as the fields of the error.
+type Violations []Violation
+
+func (v Violations) Error() string {
+	parts := []string{}
+	for _, violation := range v {
+		parts = append(parts, violation.Rule+": "+violation.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (v Violations) AppError() *apperr.Error {
+	fields := []apperr.FieldError{}
+	for _, violation := range v {
+		fields = append(fields, apperr.Field(violation.Rule, violation.Message))
+	}
+	return &apperr.Error{
+		Code:    apperr.CODE_FAILED_PRECONDITION,
+		Message: "invoice doesn't meet the EN 16931 business rules",
+		Fields:  fields,
+	}
+}
+
+// credit transfers need the account to pay to, UNCL4461 30 and 58.
+var creditTransfers = []string{"30", PAYMENT_SEPA_CREDIT_TRANSFER}
+
+var countryPrefixPattern = regexp.MustCompile(`^[A-Z]{2}`)
+
+// categoryRule tells what the rules of a tax category, e.g. BR-S-05 of
+// standard rated lines, demand.
+type categoryRule struct {
+	prefix string
+	name   string
+	// rated categories have a percentage above zero, the others zero.
+	rated bool
+	// reason is whether the breakdown must say why the category isn't
+	// taxed, categories that are must not.
+	reason bool
+	// buyerId is whether the buyer needs a VAT or legal registration
+	// identifier.
+	buyerId bool
+}
+
+var categoryRules = map[string]categoryRule{
+	CATEGORY_STANDARD:       {prefix: "BR-S", name: "standard rated", rated: true},
+	CATEGORY_ZERO_RATED:     {prefix: "BR-Z", name: "zero rated"},
+	CATEGORY_EXEMPT:         {prefix: "BR-E", name: "exempt", reason: true},
+	CATEGORY_REVERSE_CHARGE: {prefix: "BR-AE", name: "reverse charged", reason: true, buyerId: true},
+}
+
+type checker struct {
+	doc        *Document
+	currency   string
+	violations Violations
+}
+
+func (c *checker) fail(rule string, format string, args ...any) {
+	c.violations = append(c.violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// value parses an amount, quantity or percentage. Missing and malformed
+// values are zero, the rules requiring them report those.
+func value(v string) decimal.Decimal {
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return decimal.Zero
+	}
+	return d
+}
+
+func optional(a *Amount) decimal.Decimal {
+	if a == nil {
+		return decimal.Zero
+	}
+	return value(a.Value)
+}
+
+func (c *checker) round(d decimal.Decimal) decimal.Decimal {
+	return money.Round(d, c.currency)
+}
+
+func (c *checker) format(d decimal.Decimal) string {
+	return money.String(d, c.currency)
+}
+
+func categoryKey(category TaxCategory) string {
+	return category.Id + "\x00" + value(category.Percent).String()
+}
+
+func (c *checker) document() {
+	d := c.doc
+	if d.CustomizationId == "" {
+		c.fail("BR-01", "specification identifier (BT-24) is required")
+	}
+	if d.Id == "" {
+		c.fail("BR-02", "invoice number (BT-1) is required")
+	}
+	if d.IssueDate == "" {
+		c.fail("BR-03", "issue date (BT-2) is required")
+	}
+	if d.TypeCode() == "" {
+		c.fail("BR-04", "invoice type code (BT-3) is required")
+	}
+	if d.DocumentCurrencyCode == "" {
+		c.fail("BR-05", "currency code (BT-5) is required")
+	}
+	if d.BillingReference != nil && d.BillingReference.InvoiceDocumentReference.Id == "" {
+		c.fail("BR-55", "preceding invoice reference (BT-25) is required")
+	}
+}
+
+func (c *checker) parties() {
+	seller := &c.doc.AccountingSupplierParty.Party
+	buyer := &c.doc.AccountingCustomerParty.Party
+
+	if seller.PartyLegalEntity.RegistrationName == "" {
+		c.fail("BR-06", "seller name (BT-27) is required, the legal name of the issuer")
+	}
+	if seller.PostalAddress.Country.IdentificationCode == "" {
+		c.fail("BR-09", "seller country code (BT-40) is required")
+	}
+	if buyer.PartyLegalEntity.RegistrationName == "" {
+		c.fail("BR-07", "buyer name (BT-44) is required")
+	}
+	if buyer.PostalAddress.Country.IdentificationCode == "" {
+		c.fail("BR-11", "buyer country code (BT-55) is required, the country of the billing address of the client")
+	}
+	if seller.VatId() == "" && seller.PartyLegalEntity.CompanyId == "" {
+		c.fail("BR-CO-26", "seller VAT identifier (BT-31) or legal registration identifier (BT-30) is required")
+	}
+
+	for _, party := range []*Party{seller, buyer} {
+		if vat_id := party.VatId(); vat_id != "" && !countryPrefixPattern.MatchString(vat_id) {
+			c.fail("BR-CO-09", "VAT identifier %s must start with the country code", vat_id)
+		}
+	}
+}
+
+func (c *checker) allowanceCharges(allowances []AllowanceCharge, where string, document bool) (decimal.Decimal, decimal.Decimal) {
+	amount_rule, category_rule, reason_rule := "BR-41", "", "BR-42"
+	charge_amount_rule, charge_category_rule, charge_reason_rule := "BR-43", "", "BR-44"
+	if document {
+		amount_rule, category_rule, reason_rule = "BR-31", "BR-32", "BR-33"
+		charge_amount_rule, charge_category_rule, charge_reason_rule = "BR-36", "BR-37", "BR-38"
+	}
+
+	allowed, charged := decimal.Zero, decimal.Zero
+	for _, a := range allowances {
+		kind, amount, category, reason := "allowance", amount_rule, category_rule, reason_rule
+		if a.ChargeIndicator {
+			kind, amount, category, reason = "charge", charge_amount_rule, charge_category_rule, charge_reason_rule
+			charged = charged.Add(value(a.Amount.Value))
+		} else {
+			allowed = allowed.Add(value(a.Amount.Value))
+		}
+
+		if a.Amount.Value == "" {
+			c.fail(amount, "%s%s needs an amount", kind, where)
+		}
+		if document && (a.TaxCategory == nil || a.TaxCategory.Id == "") {
+			c.fail(category, "%s%s needs a VAT category", kind, where)
+		}
+		if a.AllowanceChargeReason == "" {
+			c.fail(reason, "%s%s needs a reason", kind, where)
+		}
+	}
+	return allowed, charged
+}
+
+func (c *checker) lines() decimal.Decimal {
+	lines := c.doc.Lines()
+	if len(lines) == 0 {
+		c.fail("BR-16", "at least one line is required")
+	}
+
+	sum := decimal.Zero
+	for i, l := range lines {
+		where := fmt.Sprintf(" of line %d", i+1)
+		sum = sum.Add(value(l.LineExtensionAmount.Value))
+
+		if l.Id == "" {
+			c.fail("BR-21", "line %d needs an identifier", i+1)
+		}
+		quantity := l.Quantity()
+		if quantity == nil || quantity.Value == "" {
+			c.fail("BR-22", "line %d needs a quantity", i+1)
+			quantity = &Quantity{}
+		}
+		if quantity.UnitCode == "" {
+			c.fail("BR-23", "line %d needs a unit of measure", i+1)
+		}
+		if l.LineExtensionAmount.Value == "" {
+			c.fail("BR-24", "line %d needs a net amount", i+1)
+		}
+		if l.Item.Name == "" {
+			c.fail("BR-25", "line %d needs an item name, the first line of its description", i+1)
+		}
+		if l.Price.PriceAmount.Value == "" {
+			c.fail("BR-26", "line %d needs a net price", i+1)
+		}
+		if value(l.Price.PriceAmount.Value).IsNegative() {
+			c.fail("BR-27", "net price%s must not be negative", where)
+		}
+		if l.Item.ClassifiedTaxCategory.Id == "" {
+			c.fail("BR-CO-04", "line %d needs a VAT category", i+1)
+		}
+
+		allowed, charged := c.allowanceCharges(l.AllowanceCharges, where, false)
+
+		base := decimal.NewFromInt(1)
+		if l.Price.BaseQuantity != nil {
+			base = value(l.Price.BaseQuantity.Value)
+			if !base.IsPositive() {
+				c.fail("PEPPOL-EN16931-R121", "base quantity%s must be above zero", where)
+				continue
+			}
+		}
+		expected := c.round(value(quantity.Value).Mul(value(l.Price.PriceAmount.Value)).Div(base)).Add(charged).Sub(allowed)
+		if !expected.Equal(value(l.LineExtensionAmount.Value)) {
+			c.fail("PEPPOL-EN16931-R120", "net amount%s must be quantity times price plus charges minus allowances, %s", where, c.format(expected))
+		}
+	}
+	return sum
+}
+
+func (c *checker) breakdown() decimal.Decimal {
+	subtotals := c.doc.TaxTotal.TaxSubtotals
+	if len(subtotals) == 0 {
+		c.fail("BR-CO-18", "at least one VAT breakdown is required")
+	}
+
+	sum := decimal.Zero
+	one_unit := decimal.New(1, -money.Scale(c.currency))
+	for _, s := range subtotals {
+		sum = sum.Add(value(s.TaxAmount.Value))
+		if s.TaxableAmount.Value == "" {
+			c.fail("BR-45", "VAT breakdown %s needs a taxable amount", s.TaxCategory.Id)
+		}
+		if s.TaxAmount.Value == "" {
+			c.fail("BR-46", "VAT breakdown %s needs a tax amount", s.TaxCategory.Id)
+		}
+		if s.TaxCategory.Id == "" {
+			c.fail("BR-47", "VAT breakdown needs a category code")
+		}
+		if s.TaxCategory.Percent == "" {
+			c.fail("BR-48", "VAT breakdown %s needs a rate", s.TaxCategory.Id)
+		}
+
+		// Totals of invoices with prices including tax take the tax out of
+		// the gross amount, which may round one minor unit the other way.
+		expected := c.round(value(s.TaxableAmount.Value).Mul(value(s.TaxCategory.Percent)).Div(hundred))
+		if expected.Sub(value(s.TaxAmount.Value)).Abs().GreaterThan(one_unit) {
+			c.fail("BR-CO-17", "tax amount of VAT breakdown %s at %s%% must be its taxable amount times the rate, %s", s.TaxCategory.Id, s.TaxCategory.Percent, c.format(expected))
+		}
+	}
+	return sum
+}
+
+func (c *checker) totals(line_sum decimal.Decimal, tax_sum decimal.Decimal) {
+	d := c.doc
+	t := d.LegalMonetaryTotal
+
+	allowed, charged := c.allowanceCharges(d.AllowanceCharges, " of the document", true)
+
+	if t.LineExtensionAmount.Value == "" {
+		c.fail("BR-12", "sum of line net amounts (BT-106) is required")
+	}
+	if t.TaxExclusiveAmount.Value == "" {
+		c.fail("BR-13", "total without VAT (BT-109) is required")
+	}
+	if t.TaxInclusiveAmount.Value == "" {
+		c.fail("BR-14", "total with VAT (BT-112) is required")
+	}
+	if t.PayableAmount.Value == "" {
+		c.fail("BR-15", "amount due (BT-115) is required")
+	}
+
+	line_extension := value(t.LineExtensionAmount.Value)
+	if !line_sum.Equal(line_extension) {
+		c.fail("BR-CO-10", "sum of line net amounts must be %s", c.format(line_sum))
+	}
+	if !allowed.Equal(optional(t.AllowanceTotalAmount)) {
+		c.fail("BR-CO-11", "sum of allowances must be %s", c.format(allowed))
+	}
+	if !charged.Equal(optional(t.ChargeTotalAmount)) {
+		c.fail("BR-CO-12", "sum of charges must be %s", c.format(charged))
+	}
+
+	net := line_extension.Sub(optional(t.AllowanceTotalAmount)).Add(optional(t.ChargeTotalAmount))
+	if !net.Equal(value(t.TaxExclusiveAmount.Value)) {
+		c.fail("BR-CO-13", "total without VAT must be %s", c.format(net))
+	}
+	if !tax_sum.Equal(value(d.TaxTotal.TaxAmount.Value)) {
+		c.fail("BR-CO-14", "total VAT amount must be the sum of the VAT breakdown, %s", c.format(tax_sum))
+	}
+	gross := value(t.TaxExclusiveAmount.Value).Add(value(d.TaxTotal.TaxAmount.Value))
+	if !gross.Equal(value(t.TaxInclusiveAmount.Value)) {
+		c.fail("BR-CO-15", "total with VAT must be %s", c.format(gross))
+	}
+	payable := value(t.TaxInclusiveAmount.Value).Add(optional(t.PayableRoundingAmount))
+	if !payable.Equal(value(t.PayableAmount.Value)) {
+		c.fail("BR-CO-16", "amount due must be %s", c.format(payable))
+	}
+	if payable.IsPositive() && d.DueDate == "" && d.PaymentTerms == nil {
+		c.fail("BR-CO-25", "payment due date (BT-9) or payment terms (BT-20) are required")
+	}
+}
+
+// categories checks the rules of each tax category used by lines or
+// allowances, e.g. that exempt amounts say why in their breakdown.
+func (c *checker) categories() {
+	d := c.doc
+	seller := &d.AccountingSupplierParty.Party
+	buyer := &d.AccountingCustomerParty.Party
+
+	used := []string{}
+	taxable := map[string]decimal.Decimal{}
+	use := func(category TaxCategory, amount decimal.Decimal, rule string, where string) {
+		rules, ok := categoryRules[category.Id]
+		if !ok {
+			return
+		}
+		if !slices.Contains(used, category.Id) {
+			used = append(used, category.Id)
+		}
+		key := categoryKey(category)
+		taxable[key] = taxable[key].Add(amount)
+
+		percent := value(category.Percent)
+		if rules.rated && !percent.IsPositive() {
+			c.fail(rules.prefix+rule, "%s%s must have a VAT rate above zero", rules.name, where)
+		}
+		if !rules.rated && !percent.IsZero() {
+			c.fail(rules.prefix+rule, "%s%s must have a VAT rate of zero", rules.name, where)
+		}
+	}
+
+	for i, l := range d.Lines() {
+		use(l.Item.ClassifiedTaxCategory, value(l.LineExtensionAmount.Value), "-05", fmt.Sprintf(" line %d", i+1))
+	}
+	for _, a := range d.AllowanceCharges {
+		if a.TaxCategory == nil {
+			continue
+		}
+		amount, rule, where := value(a.Amount.Value).Neg(), "-06", " allowance"
+		if a.ChargeIndicator {
+			amount, rule, where = amount.Neg(), "-07", " charge"
+		}
+		use(*a.TaxCategory, amount, rule, where)
+	}
+
+	for _, id := range used {
+		rules := categoryRules[id]
+
+		found := false
+		for _, s := range d.TaxTotal.TaxSubtotals {
+			if s.TaxCategory.Id != id {
+				continue
+			}
+			found = true
+
+			expected := taxable[categoryKey(s.TaxCategory)]
+			if !expected.Equal(value(s.TaxableAmount.Value)) {
+				c.fail(rules.prefix+"-08", "taxable amount of %s VAT breakdown at %s%% must be %s", rules.name, s.TaxCategory.Percent, c.format(expected))
+			}
+			if !rules.rated && !value(s.TaxAmount.Value).IsZero() {
+				c.fail(rules.prefix+"-09", "tax amount of %s VAT breakdown must be zero", rules.name)
+			}
+			if rules.reason && s.TaxCategory.TaxExemptionReason == "" {
+				c.fail(rules.prefix+"-10", "%s VAT breakdown needs an exemption reason", rules.name)
+			}
+			if !rules.reason && s.TaxCategory.TaxExemptionReason != "" {
+				c.fail(rules.prefix+"-10", "%s VAT breakdown must not have an exemption reason", rules.name)
+			}
+		}
+		if !found {
+			c.fail(rules.prefix+"-01", "%s amounts need a VAT breakdown of category %s", rules.name, id)
+		}
+
+		if len(seller.PartyTaxSchemes) == 0 {
+			c.fail(rules.prefix+"-02", "%s amounts need the seller VAT identifier (BT-31) or tax registration identifier (BT-32)", rules.name)
+		}
+		if rules.buyerId && buyer.VatId() == "" && buyer.PartyLegalEntity.CompanyId == "" {
+			c.fail(rules.prefix+"-02", "%s amounts need the buyer VAT identifier (BT-48) or legal registration identifier (BT-47)", rules.name)
+		}
+	}
+}
+
+func (c *checker) payment() {
+	means := c.doc.PaymentMeans
+	if means == nil {
+		return
+	}
+	if means.PaymentMeansCode == "" {
+		c.fail("BR-49", "payment means type code (BT-81) is required")
+	}
+	for _, code := range creditTransfers {
+		if means.PaymentMeansCode == code && (means.PayeeFinancialAccount == nil || means.PayeeFinancialAccount.Id == "") {
+			c.fail("BR-61", "credit transfers need the payment account identifier (BT-84), the IBAN of the issuer")
+		}
+	}
+}
+
+// peppol checks the rules Peppol BIS adds to EN 16931 for documents to be
+// delivered through its network.
+func (c *checker) peppol() {
+	d := c.doc
+	if d.BuyerReference == "" {
+		c.fail("PEPPOL-EN16931-R003", "buyer reference (BT-10) is required, the buyer reference of the invoice")
+	}
+	if d.AccountingCustomerParty.Party.EndpointId == nil {
+		c.fail("PEPPOL-EN16931-R010", "buyer electronic address (BT-49) is required, the VAT ID of the client")
+	}
+	if d.AccountingSupplierParty.Party.EndpointId == nil {
+		c.fail("PEPPOL-EN16931-R020", "seller electronic address (BT-34) is required, the VAT ID of the issuer")
+	}
+}
+
+// Check validates the document against the business rules of EN 16931 and
+// Peppol BIS Billing 3.0 that depend on its content. It returns the rules
+// it breaks as Violations.
+func Check(doc *Document) error {
+	c := &checker{doc: doc, currency: doc.DocumentCurrencyCode}
+	c.document()
+	c.parties()
+	line_sum := c.lines()
+	tax_sum := c.breakdown()
+	c.totals(line_sum, tax_sum)
+	c.categories()
+	c.payment()
+	c.peppol()
+
+	if len(c.violations) > 0 {
+		return c.violations
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package ubl
+
+import (
+	"errors"
+	pb "invoice-manager/main/proto"
+	"slices"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		invoice func() *pb.Invoice
+		buyer   *pb.Client
+		credit  bool
+		change  func(d *Document)
+		// Rules the document breaks, in the order they are checked.
+		want []string
+	}{
+		{
+			name:    "no specification identifier",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.CustomizationId = "" },
+			want:    []string{"BR-01"},
+		},
+		{
+			name:    "no invoice number",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.Id = "" },
+			want:    []string{"BR-02"},
+		},
+		{
+			name:    "no seller name",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.AccountingSupplierParty.Party.PartyLegalEntity.RegistrationName = "" },
+			want:    []string{"BR-06"},
+		},
+		{
+			name:    "no buyer country",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.AccountingCustomerParty.Party.PostalAddress.Country.IdentificationCode = "" },
+			want:    []string{"BR-11"},
+		},
+		{
+			name:    "seller without VAT or legal registration",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.AccountingSupplierParty.Party.PartyTaxSchemes = nil },
+			want:    []string{"BR-CO-26", "BR-S-02"},
+		},
+		{
+			name:    "VAT identifier without country code",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.AccountingCustomerParty.Party.PartyTaxSchemes[0].CompanyId = "987654321" },
+			want:    []string{"BR-CO-09"},
+		},
+		{
+			name:    "no lines",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.InvoiceLines = nil },
+			want:    []string{"BR-16", "BR-CO-10"},
+		},
+		{
+			name:    "line net amount isn't quantity times price",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.InvoiceLines[0].LineExtensionAmount.Value = "1100.00" },
+			want:    []string{"PEPPOL-EN16931-R120", "BR-CO-10", "BR-S-08"},
+		},
+		{
+			name:    "negative price",
+			invoice: standardInvoice,
+			change: func(d *Document) {
+				d.InvoiceLines[0].InvoicedQuantity.Value = "-10"
+				d.InvoiceLines[0].Price.PriceAmount.Value = "-120.00"
+			},
+			want: []string{"BR-27"},
+		},
+		{
+			name:    "standard rated line at zero percent",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.InvoiceLines[0].Item.ClassifiedTaxCategory.Percent = "0" },
+			want:    []string{"BR-S-05", "BR-S-08"},
+		},
+		{
+			name:    "breakdown tax amount isn't the rate of its taxable amount",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.TaxTotal.TaxSubtotals[0].TaxAmount.Value = "260.00" },
+			want:    []string{"BR-CO-17", "BR-CO-14"},
+		},
+		{
+			name:    "total VAT amount isn't the sum of the breakdown",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.TaxTotal.TaxAmount.Value = "200.00" },
+			want:    []string{"BR-CO-14", "BR-CO-15"},
+		},
+		{
+			name:    "no due date or payment terms",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.DueDate = "" },
+			want:    []string{"BR-CO-25"},
+		},
+		{
+			name:    "credit transfer without an account",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.PaymentMeans.PayeeFinancialAccount = nil },
+			want:    []string{"BR-61"},
+		},
+		{
+			name:    "no buyer reference",
+			invoice: standardInvoice,
+			change:  func(d *Document) { d.BuyerReference = "" },
+			want:    []string{"PEPPOL-EN16931-R003"},
+		},
+		{
+			name:    "credit note without the invoice it credits",
+			invoice: creditNote,
+			credit:  true,
+			change:  func(d *Document) { d.BillingReference.InvoiceDocumentReference.Id = "" },
+			want:    []string{"BR-55"},
+		},
+		{
+			name:    "reverse charge to a buyer without a VAT ID",
+			invoice: reverseChargeInvoice,
+			buyer:   &pb.Client{LegalName: "Beispiel GmbH", BillingAddress: &pb.Address{Country: "AT"}},
+			want:    []string{"BR-AE-02", "PEPPOL-EN16931-R010"},
+		},
+		{
+			name:    "reverse charge without an exemption reason",
+			invoice: reverseChargeInvoice,
+			buyer:   austrianBuyer(),
+			change:  func(d *Document) { d.TaxTotal.TaxSubtotals[0].TaxCategory.TaxExemptionReason = "" },
+			want:    []string{"BR-AE-10"},
+		},
+		{
+			name:    "exempt without an exemption reason",
+			invoice: mixedInvoice,
+			change:  func(d *Document) { d.TaxTotal.TaxSubtotals[2].TaxCategory.TaxExemptionReason = "" },
+			want:    []string{"BR-E-10"},
+		},
+		{
+			name:    "document allowance without a reason",
+			invoice: mixedInvoice,
+			change:  func(d *Document) { d.AllowanceCharges[0].AllowanceChargeReason = "" },
+			want:    []string{"BR-33"},
+		},
+		{
+			name:    "allowances missing from the totals",
+			invoice: mixedInvoice,
+			change:  func(d *Document) { d.LegalMonetaryTotal.AllowanceTotalAmount = nil },
+			want:    []string{"BR-CO-11", "BR-CO-13"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := test.buyer
+			if client == nil {
+				client = buyer()
+			}
+			doc, err := Build(test.invoice(), seller(), client, test.credit)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if test.change != nil {
+				test.change(doc)
+			}
+
+			var violations Violations
+			if !errors.As(Check(doc), &violations) {
+				t.Fatalf("Check() broke no rules, want %v", test.want)
+			}
+			rules := []string{}
+			for _, violation := range violations {
+				rules = append(rules, violation.Rule)
+			}
+			if !slices.Equal(rules, test.want) {
+				t.Errorf("rules = %v, want %v\n%v", rules, test.want, violations)
+			}
+
+			fields := []string{}
+			for _, field := range violations.AppError().Fields {
+				fields = append(fields, field.Field)
+			}
+			if !slices.Equal(fields, rules) {
+				t.Errorf("fields of the error = %v, want the rules %v", fields, rules)
+			}
+		})
+	}
+}
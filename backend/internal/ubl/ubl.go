@@ -0,0 +1,267 @@
+package ubl
+
+import (
+	"encoding/xml"
+)
+
+// Documents follow Peppol BIS Billing 3.0, the CIUS of EN 16931 most
+// access points and public authorities in Europe take.
+const (
+	CUSTOMIZATION_ID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	PROFILE_ID       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+
+	NS_INVOICE     = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	NS_CREDIT_NOTE = "urn:oasis:names:specification:ubl:schema:xsd:CreditNote-2"
+	NS_CAC         = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	NS_CBC         = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+
+	// UNCL1001 document types.
+	TYPE_INVOICE     = "380"
+	TYPE_CREDIT_NOTE = "381"
+
+	// UNCL4461 payment means.
+	PAYMENT_SEPA_CREDIT_TRANSFER = "58"
+
+	CONTENT_TYPE = "application/xml"
+)
+
+type Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"currencyID,attr"`
+}
+
+type Quantity struct {
+	Value    string `xml:",chardata"`
+	UnitCode string `xml:"unitCode,attr,omitempty"`
+}
+
+type Identifier struct {
+	Value    string `xml:",chardata"`
+	SchemeId string `xml:"schemeID,attr,omitempty"`
+}
+
+type Country struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+type Address struct {
+	StreetName           string  `xml:"cbc:StreetName,omitempty"`
+	AdditionalStreetName string  `xml:"cbc:AdditionalStreetName,omitempty"`
+	CityName             string  `xml:"cbc:CityName,omitempty"`
+	PostalZone           string  `xml:"cbc:PostalZone,omitempty"`
+	CountrySubentity     string  `xml:"cbc:CountrySubentity,omitempty"`
+	Country              Country `xml:"cac:Country"`
+}
+
+type TaxScheme struct {
+	Id string `xml:"cbc:ID"`
+}
+
+type PartyName struct {
+	Name string `xml:"cbc:Name"`
+}
+
+type PartyTaxScheme struct {
+	CompanyId string    `xml:"cbc:CompanyID"`
+	TaxScheme TaxScheme `xml:"cac:TaxScheme"`
+}
+
+type PartyLegalEntity struct {
+	RegistrationName string `xml:"cbc:RegistrationName"`
+	CompanyId        string `xml:"cbc:CompanyID,omitempty"`
+}
+
+type Contact struct {
+	Name           string `xml:"cbc:Name,omitempty"`
+	Telephone      string `xml:"cbc:Telephone,omitempty"`
+	ElectronicMail string `xml:"cbc:ElectronicMail,omitempty"`
+}
+
+type Party struct {
+	EndpointId       *Identifier      `xml:"cbc:EndpointID"`
+	PartyName        *PartyName       `xml:"cac:PartyName"`
+	PostalAddress    Address          `xml:"cac:PostalAddress"`
+	PartyTaxSchemes  []PartyTaxScheme `xml:"cac:PartyTaxScheme"`
+	PartyLegalEntity PartyLegalEntity `xml:"cac:PartyLegalEntity"`
+	Contact          *Contact         `xml:"cac:Contact"`
+}
+
+// VatId returns the VAT identifier of the party, empty if it has none.
+func (p *Party) VatId() string {
+	for _, scheme := range p.PartyTaxSchemes {
+		if scheme.TaxScheme.Id == TAX_SCHEME_VAT {
+			return scheme.CompanyId
+		}
+	}
+	return ""
+}
+
+type PartyWrapper struct {
+	Party Party `xml:"cac:Party"`
+}
+
+type DocumentReference struct {
+	Id string `xml:"cbc:ID"`
+}
+
+type BillingReference struct {
+	InvoiceDocumentReference DocumentReference `xml:"cac:InvoiceDocumentReference"`
+}
+
+type Delivery struct {
+	ActualDeliveryDate string `xml:"cbc:ActualDeliveryDate"`
+}
+
+type Branch struct {
+	Id string `xml:"cbc:ID"`
+}
+
+type FinancialAccount struct {
+	Id                         string  `xml:"cbc:ID"`
+	Name                       string  `xml:"cbc:Name,omitempty"`
+	FinancialInstitutionBranch *Branch `xml:"cac:FinancialInstitutionBranch"`
+}
+
+type PaymentMeans struct {
+	PaymentMeansCode      string            `xml:"cbc:PaymentMeansCode"`
+	PaymentId             string            `xml:"cbc:PaymentID,omitempty"`
+	PayeeFinancialAccount *FinancialAccount `xml:"cac:PayeeFinancialAccount"`
+}
+
+type PaymentTerms struct {
+	Note string `xml:"cbc:Note"`
+}
+
+type TaxCategory struct {
+	Id                 string    `xml:"cbc:ID"`
+	Percent            string    `xml:"cbc:Percent"`
+	TaxExemptionReason string    `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme          TaxScheme `xml:"cac:TaxScheme"`
+}
+
+// AllowanceCharge is a discount or surcharge, of the document if it has a
+// tax category, otherwise of the line it is part of.
+type AllowanceCharge struct {
+	ChargeIndicator       bool         `xml:"cbc:ChargeIndicator"`
+	AllowanceChargeReason string       `xml:"cbc:AllowanceChargeReason,omitempty"`
+	Amount                Amount       `xml:"cbc:Amount"`
+	TaxCategory           *TaxCategory `xml:"cac:TaxCategory"`
+}
+
+type TaxSubtotal struct {
+	TaxableAmount Amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     Amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   TaxCategory `xml:"cac:TaxCategory"`
+}
+
+type TaxTotal struct {
+	TaxAmount    Amount        `xml:"cbc:TaxAmount"`
+	TaxSubtotals []TaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type MonetaryTotal struct {
+	LineExtensionAmount   Amount  `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount    Amount  `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount    Amount  `xml:"cbc:TaxInclusiveAmount"`
+	AllowanceTotalAmount  *Amount `xml:"cbc:AllowanceTotalAmount"`
+	ChargeTotalAmount     *Amount `xml:"cbc:ChargeTotalAmount"`
+	PayableRoundingAmount *Amount `xml:"cbc:PayableRoundingAmount"`
+	PayableAmount         Amount  `xml:"cbc:PayableAmount"`
+}
+
+type SellersItemIdentification struct {
+	Id string `xml:"cbc:ID"`
+}
+
+type Item struct {
+	Description               string                     `xml:"cbc:Description,omitempty"`
+	Name                      string                     `xml:"cbc:Name"`
+	SellersItemIdentification *SellersItemIdentification `xml:"cac:SellersItemIdentification"`
+	ClassifiedTaxCategory     TaxCategory                `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type Price struct {
+	PriceAmount  Amount    `xml:"cbc:PriceAmount"`
+	BaseQuantity *Quantity `xml:"cbc:BaseQuantity"`
+}
+
+// Line is an InvoiceLine or, with the quantity as CreditedQuantity, a
+// CreditNoteLine.
+type Line struct {
+	Id                  string            `xml:"cbc:ID"`
+	InvoicedQuantity    *Quantity         `xml:"cbc:InvoicedQuantity"`
+	CreditedQuantity    *Quantity         `xml:"cbc:CreditedQuantity"`
+	LineExtensionAmount Amount            `xml:"cbc:LineExtensionAmount"`
+	AllowanceCharges    []AllowanceCharge `xml:"cac:AllowanceCharge"`
+	Item                Item              `xml:"cac:Item"`
+	Price               Price             `xml:"cac:Price"`
+}
+
+// Quantity is the invoiced or credited quantity of the line.
+func (l *Line) Quantity() *Quantity {
+	if l.CreditedQuantity != nil {
+		return l.CreditedQuantity
+	}
+	return l.InvoicedQuantity
+}
+
+// Document is a UBL 2.1 Invoice or CreditNote. The elements the two don't
+// share are left empty for the other, in the order the schemas expect.
+type Document struct {
+	XMLName  xml.Name
+	Xmlns    string `xml:"xmlns,attr"`
+	XmlnsCac string `xml:"xmlns:cac,attr"`
+	XmlnsCbc string `xml:"xmlns:cbc,attr"`
+
+	CustomizationId         string            `xml:"cbc:CustomizationID"`
+	ProfileId               string            `xml:"cbc:ProfileID"`
+	Id                      string            `xml:"cbc:ID"`
+	IssueDate               string            `xml:"cbc:IssueDate"`
+	DueDate                 string            `xml:"cbc:DueDate,omitempty"`
+	InvoiceTypeCode         string            `xml:"cbc:InvoiceTypeCode,omitempty"`
+	CreditNoteTypeCode      string            `xml:"cbc:CreditNoteTypeCode,omitempty"`
+	Notes                   []string          `xml:"cbc:Note"`
+	DocumentCurrencyCode    string            `xml:"cbc:DocumentCurrencyCode"`
+	BuyerReference          string            `xml:"cbc:BuyerReference,omitempty"`
+	BillingReference        *BillingReference `xml:"cac:BillingReference"`
+	AccountingSupplierParty PartyWrapper      `xml:"cac:AccountingSupplierParty"`
+	AccountingCustomerParty PartyWrapper      `xml:"cac:AccountingCustomerParty"`
+	Delivery                *Delivery         `xml:"cac:Delivery"`
+	PaymentMeans            *PaymentMeans     `xml:"cac:PaymentMeans"`
+	PaymentTerms            *PaymentTerms     `xml:"cac:PaymentTerms"`
+	AllowanceCharges        []AllowanceCharge `xml:"cac:AllowanceCharge"`
+	TaxTotal                TaxTotal          `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal      MonetaryTotal     `xml:"cac:LegalMonetaryTotal"`
+	InvoiceLines            []Line            `xml:"cac:InvoiceLine"`
+	CreditNoteLines         []Line            `xml:"cac:CreditNoteLine"`
+}
+
+// IsCreditNote tells whether the document is a CreditNote.
+func (d *Document) IsCreditNote() bool {
+	return d.XMLName.Local == "CreditNote"
+}
+
+// Lines are the invoice or credit note lines of the document.
+func (d *Document) Lines() []Line {
+	if d.IsCreditNote() {
+		return d.CreditNoteLines
+	}
+	return d.InvoiceLines
+}
+
+// TypeCode is the invoice or credit note type code of the document.
+func (d *Document) TypeCode() string {
+	if d.IsCreditNote() {
+		return d.CreditNoteTypeCode
+	}
+	return d.InvoiceTypeCode
+}
+
+// Marshal returns the document as indented XML with its declaration.
+func (d *Document) Marshal() ([]byte, error) {
+	out, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
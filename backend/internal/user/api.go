@@ -0,0 +1,81 @@
+package user
+
+import (
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	pb "invoice-manager/main/proto"
+	"net/http"
+	"time"
+)
+
+type UserApi struct {
+	users *Users
+}
+
+func setSessionCookie(w http.ResponseWriter, req *http.Request, token string, expires_at time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SESSION_COOKIE,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires_at,
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearSessionCookie(w http.ResponseWriter, req *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SESSION_COOKIE,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (ua *UserApi) Login(w http.ResponseWriter, req *http.Request) {
+	var body pb.LoginRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	user, err := ua.users.Authenticate(body.GetEmail(), body.GetPassword())
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Couldn't sign in"))
+		return
+	}
+
+	token, expires_at, err := ua.users.CreateSession(user.Id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Couldn't create a session"))
+		return
+	}
+
+	setSessionCookie(w, req, token, expires_at)
+	helpers.JsonResponse(w, http.StatusOK, &pb.LoginResponse{User: user, ExpiresAt: expires_at.Unix()})
+}
+
+func (ua *UserApi) Logout(w http.ResponseWriter, req *http.Request) {
+	if cookie, err := req.Cookie(SESSION_COOKIE); err == nil {
+		if err = ua.users.DeleteSession(cookie.Value); err != nil {
+			apperr.Write(w, req, apperr.Wrap(err, "Couldn't end the session"))
+			return
+		}
+	}
+
+	clearSessionCookie(w, req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ua *UserApi) GetCurrentUser(w http.ResponseWriter, req *http.Request) {
+	user, _ := FromContext(req.Context())
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCurrentUserResponse{User: user})
+}
+
+func NewUserApi(us *Users) *UserApi {
+	return &UserApi{users: us}
+}
@@ -0,0 +1,26 @@
+package user
+
+import (
+	"context"
+	pb "invoice-manager/main/proto"
+)
+
+type contextKey struct{}
+
+func WithUser(ctx context.Context, user *pb.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+func FromContext(ctx context.Context) (*pb.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*pb.User)
+	return user, ok
+}
+
+// IdFromContext returns the ID of the signed in user, or 0 when the request
+// isn't made on behalf of a user (e.g. from the CLI).
+func IdFromContext(ctx context.Context) uint32 {
+	if user, ok := FromContext(ctx); ok {
+		return user.Id
+	}
+	return 0
+}
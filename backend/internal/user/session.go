@@ -0,0 +1,97 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	pb "invoice-manager/main/proto"
+	"time"
+)
+
+const (
+	SESSION_COOKIE = "invoicer_session"
+	SESSION_TTL    = 30 * 24 * time.Hour
+)
+
+// Only a hash of the session token is stored, so a leaked database can't be
+// used to hijack sessions.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (us *Users) CreateSession(user_id uint32) (string, time.Time, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expires_at := now.Add(SESSION_TTL)
+	_, err = us.insert_session_stmt.Exec(hashToken(token), user_id, now.Unix(), expires_at.Unix())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expires_at, nil
+}
+
+type Session struct {
+	User        *pb.User
+	WorkspaceId uint32
+}
+
+func (us *Users) RetrieveSession(token string) (*Session, error) {
+	session := &Session{User: &pb.User{}}
+	err := us.retrieve_session_stmt.QueryRow(hashToken(token), time.Now().Unix()).Scan(
+		&session.User.Id,
+		&session.User.Email,
+		&session.User.Name,
+		&session.User.CreatedAt,
+		&session.User.UpdatedAt,
+		&session.WorkspaceId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (us *Users) SetSessionWorkspace(token string, workspace_id uint32) error {
+	res, err := us.update_session_workspace_stmt.Exec(workspace_id, hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (us *Users) DeleteSession(token string) error {
+	_, err := us.delete_session_stmt.Exec(hashToken(token))
+	return err
+}
+
+func (us *Users) DeleteExpiredSessions() (int64, error) {
+	res, err := us.delete_expired_sessions_stmt.Exec(time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,295 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	pb "invoice-manager/main/proto"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const MIN_PASSWORD_LENGTH = 8
+
+var (
+	ErrIDNotFound          = apperr.New(apperr.CODE_NOT_FOUND, "user not found")
+	ErrEmailTaken          = apperr.New(apperr.CODE_ALREADY_EXISTS, "email is already registered")
+	ErrInvalidEmail        = apperr.New(apperr.CODE_INVALID_ARGUMENT, "email is invalid")
+	ErrPasswordTooShort    = apperr.Newf(apperr.CODE_INVALID_ARGUMENT, "password must be at least %d characters long", MIN_PASSWORD_LENGTH)
+	ErrInvalidCredentials  = apperr.New(apperr.CODE_UNAUTHENTICATED, "invalid email or password")
+	ErrSessionNotFound     = apperr.New(apperr.CODE_UNAUTHENTICATED, "session not found or expired")
+	dummy_password_hash, _ = bcrypt.GenerateFromPassword([]byte("invoicer-dummy-password"), bcrypt.DefaultCost)
+)
+
+type Users struct {
+	db *sql.DB
+
+	insert_stmt, retrieve_stmt, retrieve_by_email_stmt, retrieve_password_hash_stmt, list_stmt, delete_stmt, update_password_stmt *sql.Stmt
+
+	insert_session_stmt, retrieve_session_stmt, update_session_workspace_stmt, delete_session_stmt, delete_user_sessions_stmt, delete_expired_sessions_stmt *sql.Stmt
+}
+
+func scanUser(row interface{ Scan(...any) error }) (*pb.User, error) {
+	user := &pb.User{}
+	err := row.Scan(
+		&user.Id,
+		&user.Email,
+		&user.Name,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < MIN_PASSWORD_LENGTH {
+		return ErrPasswordTooShort
+	}
+	return nil
+}
+
+func (us *Users) Create(email string, name string, password string) (*pb.User, error) {
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	res, err := us.insert_stmt.Exec(email, strings.TrimSpace(name), string(hash), now, now)
+	var sqlite_err sqlite3.Error
+	if errors.As(err, &sqlite_err) && sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, ErrEmailTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return us.Retrieve(int(id))
+}
+
+func (us *Users) Retrieve(id int) (*pb.User, error) {
+	return scanUser(us.retrieve_stmt.QueryRow(id))
+}
+
+func (us *Users) RetrieveByEmail(email string) (*pb.User, error) {
+	return scanUser(us.retrieve_by_email_stmt.QueryRow(strings.TrimSpace(email)))
+}
+
+func (us *Users) List() ([]*pb.User, error) {
+	rows, err := us.list_stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*pb.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (us *Users) Delete(id int) error {
+	res, err := us.delete_stmt.Exec(id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrIDNotFound
+	}
+
+	return nil
+}
+
+// SetPassword changes the password and signs the user out everywhere.
+func (us *Users) SetPassword(id int, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	res, err := us.update_password_stmt.Exec(string(hash), time.Now().Unix(), id)
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return ErrIDNotFound
+	}
+
+	_, err = us.delete_user_sessions_stmt.Exec(id)
+	return err
+}
+
+func (us *Users) Authenticate(email string, password string) (*pb.User, error) {
+	var id int
+	var hash string
+	err := us.retrieve_password_hash_stmt.QueryRow(strings.TrimSpace(email)).Scan(&id, &hash)
+	if err == sql.ErrNoRows {
+		// Compare against a dummy hash so unknown emails take as long as wrong passwords.
+		bcrypt.CompareHashAndPassword(dummy_password_hash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return us.Retrieve(id)
+}
+
+func NewUsers(db *sql.DB) (*Users, error) {
+	insert_stmt, err := db.Prepare("INSERT INTO users VALUES(NULL, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare(`
+		SELECT user_id, user_email, user_name, user_created_at, user_updated_at
+		FROM users
+		WHERE user_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_by_email_stmt, err := db.Prepare(`
+		SELECT user_id, user_email, user_name, user_created_at, user_updated_at
+		FROM users
+		WHERE user_email = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_password_hash_stmt, err := db.Prepare(
+		"SELECT user_id, user_password_hash FROM users WHERE user_email = ?",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare(`
+		SELECT user_id, user_email, user_name, user_created_at, user_updated_at
+		FROM users
+		ORDER BY user_created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_stmt, err := db.Prepare("DELETE FROM users WHERE user_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	update_password_stmt, err := db.Prepare(
+		"UPDATE users SET user_password_hash = ?, user_updated_at = ? WHERE user_id = ?",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_session_stmt, err := db.Prepare(`
+		INSERT INTO sessions (
+			session_token_hash,
+			session_user_id,
+			session_created_at,
+			session_expires_at
+		) VALUES(?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_session_stmt, err := db.Prepare(`
+		SELECT
+			user_id,
+			user_email,
+			user_name,
+			user_created_at,
+			user_updated_at,
+			COALESCE(session_workspace_id, 0)
+		FROM sessions
+		JOIN users ON user_id = session_user_id
+		WHERE session_token_hash = ? AND session_expires_at > ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_session_workspace_stmt, err := db.Prepare(
+		"UPDATE sessions SET session_workspace_id = ? WHERE session_token_hash = ?",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_session_stmt, err := db.Prepare("DELETE FROM sessions WHERE session_token_hash = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	delete_user_sessions_stmt, err := db.Prepare("DELETE FROM sessions WHERE session_user_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	delete_expired_sessions_stmt, err := db.Prepare("DELETE FROM sessions WHERE session_expires_at <= ?")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Users{
+		db:                            db,
+		insert_stmt:                   insert_stmt,
+		retrieve_stmt:                 retrieve_stmt,
+		retrieve_by_email_stmt:        retrieve_by_email_stmt,
+		retrieve_password_hash_stmt:   retrieve_password_hash_stmt,
+		list_stmt:                     list_stmt,
+		delete_stmt:                   delete_stmt,
+		update_password_stmt:          update_password_stmt,
+		insert_session_stmt:           insert_session_stmt,
+		retrieve_session_stmt:         retrieve_session_stmt,
+		update_session_workspace_stmt: update_session_workspace_stmt,
+		delete_session_stmt:           delete_session_stmt,
+		delete_user_sessions_stmt:     delete_user_sessions_stmt,
+		delete_expired_sessions_stmt:  delete_expired_sessions_stmt,
+	}, nil
+}
@@ -0,0 +1,401 @@
+package workspace
+
+import (
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/helpers"
+	"invoice-manager/main/internal/rbac"
+	"invoice-manager/main/internal/user"
+	pb "invoice-manager/main/proto"
+	"net/http"
+)
+
+var (
+	ErrNoUserWithEmail = apperr.New(apperr.CODE_NOT_FOUND, "no user with this email")
+	ErrNoSession       = apperr.New(apperr.CODE_INVALID_ARGUMENT, "switching workspaces requires a session")
+)
+
+type WorkspaceApi struct {
+	workspaces *Workspaces
+	users      *user.Users
+	roles      *rbac.Roles
+}
+
+func (wa *WorkspaceApi) GetWorkspacesList(w http.ResponseWriter, req *http.Request) {
+	workspaces, err := wa.workspaces.ListForUser(user.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading workspaces"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetWorkspacesResponse{Workspaces: workspaces})
+}
+
+func (wa *WorkspaceApi) CreateWorkspace(w http.ResponseWriter, req *http.Request) {
+	var body pb.CreateWorkspaceRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace, err := wa.workspaces.Create(body.GetSlug(), body.GetName(), body.GetBaseCurrency(), user.IdFromContext(req.Context()))
+	switch err {
+	case nil:
+	case ErrInvalidSlug:
+		apperr.Write(w, req, apperr.Invalid(err.Error(), apperr.Field("slug", err.Error())))
+		return
+	case ErrEmptyName:
+		apperr.Write(w, req, apperr.Invalid(err.Error(), apperr.Field("name", err.Error())))
+		return
+	case ErrInvalidCurrency:
+		apperr.Write(w, req, apperr.Invalid(err.Error(), apperr.Field("baseCurrency", err.Error())))
+		return
+	default:
+		apperr.Write(w, req, apperr.Wrap(err, "Workspace couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.CreateWorkspaceResponse{Workspace: workspace})
+}
+
+// SwitchWorkspace makes the workspace the default one for the current
+// session. Requests can still pick another one with the header or subdomain.
+func (wa *WorkspaceApi) SwitchWorkspace(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	_, err = wa.workspaces.RetrieveMember(id, user.IdFromContext(req.Context()))
+	if err == ErrNotMember {
+		apperr.Write(w, req, ErrIDNotFound)
+		return
+	}
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading member"))
+		return
+	}
+
+	cookie, err := req.Cookie(user.SESSION_COOKIE)
+	if err != nil {
+		apperr.Write(w, req, ErrNoSession)
+		return
+	}
+
+	if err = wa.users.SetSessionWorkspace(cookie.Value, id); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Workspace couldn't be switched"))
+		return
+	}
+
+	workspace, err := wa.workspaces.Retrieve(id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading workspace"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCurrentWorkspaceResponse{Workspace: workspace})
+}
+
+func (wa *WorkspaceApi) GetCurrentWorkspace(w http.ResponseWriter, req *http.Request) {
+	workspace, _ := FromContext(req.Context())
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCurrentWorkspaceResponse{Workspace: workspace})
+}
+
+// SetBaseCurrency changes the currency the current workspace reports in.
+func (wa *WorkspaceApi) SetBaseCurrency(w http.ResponseWriter, req *http.Request) {
+	var body pb.SetBaseCurrencyRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace, err := wa.workspaces.SetBaseCurrency(IdFromContext(req.Context()), body.GetBaseCurrency())
+	if err == ErrInvalidCurrency {
+		apperr.Write(w, req, apperr.Invalid(err.Error(), apperr.Field("baseCurrency", err.Error())))
+		return
+	}
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Base currency couldn't be changed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCurrentWorkspaceResponse{Workspace: workspace})
+}
+
+// SetTaxDetails changes the country and VAT ID of the current workspace.
+func (wa *WorkspaceApi) SetTaxDetails(w http.ResponseWriter, req *http.Request) {
+	var body pb.SetTaxDetailsRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace, err := wa.workspaces.SetTaxDetails(IdFromContext(req.Context()), body.GetCountry(), body.GetVatId())
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Tax details couldn't be changed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetCurrentWorkspaceResponse{Workspace: workspace})
+}
+
+func (wa *WorkspaceApi) GetMembersList(w http.ResponseWriter, req *http.Request) {
+	members, err := wa.workspaces.ListMembers(IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading members"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetWorkspaceMembersResponse{Members: members})
+}
+
+func (wa *WorkspaceApi) AddMember(w http.ResponseWriter, req *http.Request) {
+	var body pb.AddWorkspaceMemberRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	new_member, err := wa.users.RetrieveByEmail(body.GetEmail())
+	if err == user.ErrIDNotFound {
+		apperr.Write(w, req, ErrNoUserWithEmail)
+		return
+	}
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading user"))
+		return
+	}
+
+	role := body.GetRole()
+	if role == "" {
+		role = rbac.DEFAULT_ROLE
+	}
+
+	if err = wa.checkRole(req, role); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	member, err := wa.workspaces.AddMember(IdFromContext(req.Context()), new_member.Id, role)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Member couldn't be added"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.AddWorkspaceMemberResponse{Member: member})
+}
+
+func (wa *WorkspaceApi) RemoveMember(w http.ResponseWriter, req *http.Request) {
+	user_id, err := helpers.PathId(req, "user_id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace_id := IdFromContext(req.Context())
+	member, err := wa.workspaces.RetrieveMember(workspace_id, user_id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading member"))
+		return
+	}
+
+	if member.Role == rbac.ROLE_OWNER {
+		if err = wa.checkOwner(req); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	if err = wa.workspaces.RemoveMember(workspace_id, user_id); err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Member couldn't be removed"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkOwner returns a denial unless the current user may grant and revoke
+// the owner role.
+func (wa *WorkspaceApi) checkOwner(req *http.Request) error {
+	workspace_id := IdFromContext(req.Context())
+	actor, err := wa.workspaces.RetrieveMember(workspace_id, user.IdFromContext(req.Context()))
+	if err != nil {
+		return apperr.Wrap(err, "Error reading member")
+	}
+
+	allowed, err := wa.roles.HasPermission(workspace_id, actor.Role, rbac.PERM_WORKSPACE_MANAGE)
+	if err != nil {
+		return apperr.Wrap(err, "Error reading role")
+	}
+
+	if !allowed {
+		return &rbac.Denial{
+			Reason:     rbac.REASON_OWNER_REQUIRED,
+			Permission: rbac.PERM_WORKSPACE_MANAGE,
+			Role:       actor.Role,
+		}
+	}
+
+	return nil
+}
+
+// checkRole returns an error unless the role exists in the current workspace
+// and may be granted by the current user.
+func (wa *WorkspaceApi) checkRole(req *http.Request, role string) error {
+	_, err := wa.roles.RetrieveByName(IdFromContext(req.Context()), role)
+	if errors.Is(err, rbac.ErrUnknownRole) {
+		return apperr.Invalid(err.Error(), apperr.Field("role", "unknown role"))
+	}
+	if err != nil {
+		return apperr.Wrap(err, "Error reading role")
+	}
+
+	if role == rbac.ROLE_OWNER {
+		return wa.checkOwner(req)
+	}
+
+	return nil
+}
+
+func (wa *WorkspaceApi) SetMemberRole(w http.ResponseWriter, req *http.Request) {
+	user_id, err := helpers.PathId(req, "user_id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.SetMemberRoleRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	workspace_id := IdFromContext(req.Context())
+	member, err := wa.workspaces.RetrieveMember(workspace_id, user_id)
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading member"))
+		return
+	}
+
+	if member.Role == rbac.ROLE_OWNER {
+		if err = wa.checkOwner(req); err != nil {
+			apperr.Write(w, req, err)
+			return
+		}
+	}
+
+	if err = wa.checkRole(req, body.GetRole()); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	member, err = wa.workspaces.SetMemberRole(workspace_id, user_id, body.GetRole())
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Role couldn't be changed"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.SetMemberRoleResponse{Member: member})
+}
+
+// GetPermissions returns what the current user may do in the workspace,
+// e.g. for the frontend to hide actions.
+func (wa *WorkspaceApi) GetPermissions(w http.ResponseWriter, req *http.Request) {
+	workspace_id := IdFromContext(req.Context())
+	member, err := wa.workspaces.RetrieveMember(workspace_id, user.IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading member"))
+		return
+	}
+
+	role, err := wa.roles.RetrieveByName(workspace_id, member.Role)
+	if errors.Is(err, rbac.ErrUnknownRole) {
+		helpers.JsonResponse(w, http.StatusOK, &pb.GetPermissionsResponse{Role: member.Role, Permissions: []string{}})
+		return
+	}
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading role"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetPermissionsResponse{Role: role.Name, Permissions: role.Permissions})
+}
+
+func (wa *WorkspaceApi) GetRolesList(w http.ResponseWriter, req *http.Request) {
+	roles, err := wa.roles.List(IdFromContext(req.Context()))
+	if err != nil {
+		apperr.Write(w, req, apperr.Wrap(err, "Error reading roles"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.GetRolesResponse{Roles: roles, Permissions: rbac.Permissions})
+}
+
+// roleError points validation errors of roles at the offending field.
+func roleError(err error, message string) error {
+	switch {
+	case errors.Is(err, rbac.ErrInvalidName):
+		return apperr.Invalid(err.Error(), apperr.Field("name", err.Error()))
+	case errors.Is(err, rbac.ErrUnknownPermission), errors.Is(err, rbac.ErrNoPermissions):
+		return apperr.Invalid(err.Error(), apperr.Field("permissions", err.Error()))
+	}
+	return apperr.Wrap(err, message)
+}
+
+func (wa *WorkspaceApi) CreateRole(w http.ResponseWriter, req *http.Request) {
+	var body pb.CreateRoleRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	role, err := wa.roles.Create(IdFromContext(req.Context()), body.GetName(), body.GetPermissions())
+	if err != nil {
+		apperr.Write(w, req, roleError(err, "Role couldn't be created"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusCreated, &pb.CreateRoleResponse{Role: role})
+}
+
+func (wa *WorkspaceApi) UpdateRole(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	var body pb.UpdateRoleRequest
+	if err := helpers.DecodeJSONBody(w, req, &body); err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	role, err := wa.roles.Update(IdFromContext(req.Context()), id, body.GetPermissions())
+	if err != nil {
+		apperr.Write(w, req, roleError(err, "Role couldn't be updated"))
+		return
+	}
+
+	helpers.JsonResponse(w, http.StatusOK, &pb.UpdateRoleResponse{Role: role})
+}
+
+func (wa *WorkspaceApi) DeleteRole(w http.ResponseWriter, req *http.Request) {
+	id, err := helpers.PathId(req, "id")
+	if err != nil {
+		apperr.Write(w, req, err)
+		return
+	}
+
+	if err = wa.roles.Delete(IdFromContext(req.Context()), id); err != nil {
+		apperr.Write(w, req, roleError(err, "Role couldn't be deleted"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewWorkspaceApi(ws *Workspaces, us *user.Users, rs *rbac.Roles) *WorkspaceApi {
+	return &WorkspaceApi{workspaces: ws, users: us, roles: rs}
+}
@@ -0,0 +1,26 @@
+package workspace
+
+import (
+	"context"
+	pb "invoice-manager/main/proto"
+)
+
+type contextKey struct{}
+
+func WithWorkspace(ctx context.Context, workspace *pb.Workspace) context.Context {
+	return context.WithValue(ctx, contextKey{}, workspace)
+}
+
+func FromContext(ctx context.Context) (*pb.Workspace, bool) {
+	workspace, ok := ctx.Value(contextKey{}).(*pb.Workspace)
+	return workspace, ok
+}
+
+// IdFromContext returns the ID of the workspace the request was resolved to,
+// or 0 if there is none.
+func IdFromContext(ctx context.Context) uint32 {
+	if workspace, ok := FromContext(ctx); ok {
+		return workspace.Id
+	}
+	return 0
+}
@@ -0,0 +1,533 @@
+package workspace
+
+import (
+	"database/sql"
+	"errors"
+	"invoice-manager/main/internal/apperr"
+	"invoice-manager/main/internal/money"
+	"invoice-manager/main/internal/rbac"
+	pb "invoice-manager/main/proto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	DEFAULT_WORKSPACE_ID  = 1
+	WORKSPACE_HEADER      = "X-Workspace"
+	DEFAULT_BASE_CURRENCY = "EUR"
+)
+
+var (
+	ErrIDNotFound      = apperr.New(apperr.CODE_NOT_FOUND, "workspace not found")
+	ErrInvalidSlug     = apperr.New(apperr.CODE_INVALID_ARGUMENT, "slug must be 2-63 lowercase letters, digits or dashes")
+	ErrSlugTaken       = apperr.New(apperr.CODE_ALREADY_EXISTS, "slug is already taken")
+	ErrEmptyName       = apperr.New(apperr.CODE_INVALID_ARGUMENT, "name is empty")
+	ErrNotMember       = apperr.New(apperr.CODE_NOT_FOUND, "user is not a member of the workspace")
+	ErrAlreadyMember   = apperr.New(apperr.CODE_ALREADY_EXISTS, "user is already a member of the workspace")
+	ErrNoWorkspace     = apperr.New(apperr.CODE_PERMISSION_DENIED, "no workspace selected")
+	ErrLastOwner       = apperr.New(apperr.CODE_FAILED_PRECONDITION, "a workspace needs at least one owner")
+	ErrInvalidCurrency = apperr.New(apperr.CODE_INVALID_ARGUMENT, "base currency must be an ISO 4217 code")
+
+	slug_pattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+	country_pattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	vat_id_pattern  = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9+*]{2,13}$`)
+)
+
+type Workspaces struct {
+	db *sql.DB
+
+	insert_stmt, retrieve_stmt, retrieve_by_slug_stmt, list_stmt, list_for_user_stmt, base_currency_stmt, tax_details_stmt *sql.Stmt
+
+	insert_member_stmt, delete_member_stmt, retrieve_member_stmt, list_members_stmt, update_member_role_stmt, count_owners_stmt *sql.Stmt
+}
+
+func isUniqueViolation(err error) bool {
+	var sqlite_err sqlite3.Error
+	return errors.As(err, &sqlite_err) &&
+		(sqlite_err.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqlite_err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+func scanWorkspace(row interface{ Scan(...any) error }) (*pb.Workspace, error) {
+	workspace := &pb.Workspace{}
+	err := row.Scan(
+		&workspace.Id,
+		&workspace.Slug,
+		&workspace.Name,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+		&workspace.BaseCurrency,
+		&workspace.Country,
+		&workspace.VatId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrIDNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func scanWorkspaces(rows *sql.Rows) ([]*pb.Workspace, error) {
+	defer rows.Close()
+
+	workspaces := []*pb.Workspace{}
+	for rows.Next() {
+		workspace, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, rows.Err()
+}
+
+func scanMember(row interface{ Scan(...any) error }) (*pb.WorkspaceMember, error) {
+	member := &pb.WorkspaceMember{User: &pb.User{}}
+	err := row.Scan(
+		&member.WorkspaceId,
+		&member.JoinedAt,
+		&member.Role,
+		&member.User.Id,
+		&member.User.Email,
+		&member.User.Name,
+		&member.User.CreatedAt,
+		&member.User.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// checkCurrency normalizes the base currency, which defaults to
+// DEFAULT_BASE_CURRENCY.
+func checkCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DEFAULT_BASE_CURRENCY, nil
+	}
+	if !money.Valid(code) {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+// Create makes a new workspace with owner_id as its first member. An
+// owner_id of 0 creates a workspace without members.
+func (ws *Workspaces) Create(slug string, name string, base_currency string, owner_id uint32) (*pb.Workspace, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slug_pattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	base_currency, err := checkCurrency(base_currency)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.Stmt(ws.insert_stmt).Exec(slug, name, now, now, base_currency)
+	if isUniqueViolation(err) {
+		return nil, ErrSlugTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	if owner_id != 0 {
+		if _, err = tx.Stmt(ws.insert_member_stmt).Exec(id, owner_id, now, rbac.ROLE_OWNER); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ws.Retrieve(uint32(id))
+}
+
+func (ws *Workspaces) Retrieve(id uint32) (*pb.Workspace, error) {
+	return scanWorkspace(ws.retrieve_stmt.QueryRow(id))
+}
+
+// SetBaseCurrency changes the currency reports convert amounts to. Issued
+// invoices keep the base currency and rate they were issued with.
+func (ws *Workspaces) SetBaseCurrency(id uint32, base_currency string) (*pb.Workspace, error) {
+	base_currency, err := checkCurrency(base_currency)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ws.base_currency_stmt.Exec(base_currency, time.Now().Unix(), id)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrIDNotFound
+	}
+
+	return ws.Retrieve(id)
+}
+
+// NormalizeVatId strips the separators people write VAT IDs with.
+func NormalizeVatId(vat_id string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '.' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(vat_id)))
+}
+
+// SetTaxDetails changes the country and VAT ID invoices are issued from,
+// which decide whether EU clients are charged VAT.
+func (ws *Workspaces) SetTaxDetails(id uint32, country string, vat_id string) (*pb.Workspace, error) {
+	fields := []apperr.FieldError{}
+
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country != "" && !country_pattern.MatchString(country) {
+		fields = append(fields, apperr.Field("country", "must be an ISO 3166-1 alpha-2 code"))
+	}
+
+	vat_id = NormalizeVatId(vat_id)
+	if vat_id != "" && !vat_id_pattern.MatchString(vat_id) {
+		fields = append(fields, apperr.Field("vatId", "must start with a country prefix followed by 2 to 13 letters or digits"))
+	} else if vat_id != "" && country == "" {
+		fields = append(fields, apperr.Field("country", "is required with a VAT ID"))
+	}
+
+	if len(fields) > 0 {
+		return nil, apperr.Invalid("Tax details are invalid", fields...)
+	}
+
+	res, err := ws.tax_details_stmt.Exec(country, vat_id, time.Now().Unix(), id)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrIDNotFound
+	}
+
+	return ws.Retrieve(id)
+}
+
+func (ws *Workspaces) RetrieveBySlug(slug string) (*pb.Workspace, error) {
+	return scanWorkspace(ws.retrieve_by_slug_stmt.QueryRow(strings.ToLower(slug)))
+}
+
+// RetrieveByRef looks a workspace up by its numeric ID or its slug.
+func (ws *Workspaces) RetrieveByRef(ref string) (*pb.Workspace, error) {
+	if id, err := strconv.ParseUint(ref, 10, 32); err == nil {
+		return ws.Retrieve(uint32(id))
+	}
+	return ws.RetrieveBySlug(ref)
+}
+
+func (ws *Workspaces) List() ([]*pb.Workspace, error) {
+	rows, err := ws.list_stmt.Query()
+	if err != nil {
+		return nil, err
+	}
+	return scanWorkspaces(rows)
+}
+
+func (ws *Workspaces) ListForUser(user_id uint32) ([]*pb.Workspace, error) {
+	rows, err := ws.list_for_user_stmt.Query(user_id)
+	if err != nil {
+		return nil, err
+	}
+	return scanWorkspaces(rows)
+}
+
+// AddMember adds the user with the given role. Callers have to make sure
+// that a custom role exists in the workspace.
+func (ws *Workspaces) AddMember(workspace_id uint32, user_id uint32, role string) (*pb.WorkspaceMember, error) {
+	_, err := ws.insert_member_stmt.Exec(workspace_id, user_id, time.Now().Unix(), role)
+	if isUniqueViolation(err) {
+		return nil, ErrAlreadyMember
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ws.RetrieveMember(workspace_id, user_id)
+}
+
+// checkLastOwner fails if the member is the only owner of the workspace and
+// is about to lose that role.
+func (ws *Workspaces) checkLastOwner(tx *sql.Tx, workspace_id uint32, user_id uint32) error {
+	member, err := scanMember(tx.Stmt(ws.retrieve_member_stmt).QueryRow(workspace_id, user_id))
+	if err != nil {
+		return err
+	}
+	if member.Role != rbac.ROLE_OWNER {
+		return nil
+	}
+
+	var owners int
+	if err = tx.Stmt(ws.count_owners_stmt).QueryRow(workspace_id, rbac.ROLE_OWNER).Scan(&owners); err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+func (ws *Workspaces) RemoveMember(workspace_id uint32, user_id uint32) error {
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = ws.checkLastOwner(tx, workspace_id, user_id); err != nil {
+		return err
+	}
+
+	if _, err = tx.Stmt(ws.delete_member_stmt).Exec(workspace_id, user_id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetMemberRole changes the role of a member. Callers have to make sure
+// that a custom role exists in the workspace.
+func (ws *Workspaces) SetMemberRole(workspace_id uint32, user_id uint32, role string) (*pb.WorkspaceMember, error) {
+	tx, err := ws.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if role != rbac.ROLE_OWNER {
+		if err = ws.checkLastOwner(tx, workspace_id, user_id); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tx.Stmt(ws.update_member_role_stmt).Exec(role, workspace_id, user_id)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, ErrNotMember
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return ws.RetrieveMember(workspace_id, user_id)
+}
+
+func (ws *Workspaces) RetrieveMember(workspace_id uint32, user_id uint32) (*pb.WorkspaceMember, error) {
+	return scanMember(ws.retrieve_member_stmt.QueryRow(workspace_id, user_id))
+}
+
+func (ws *Workspaces) ListMembers(workspace_id uint32) ([]*pb.WorkspaceMember, error) {
+	rows, err := ws.list_members_stmt.Query(workspace_id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*pb.WorkspaceMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+const (
+	WORKSPACE_COLUMNS = `
+		workspace_id,
+		workspace_slug,
+		workspace_name,
+		workspace_created_at,
+		workspace_updated_at,
+		workspace_base_currency,
+		workspace_country,
+		workspace_vat_id
+	`
+	MEMBER_COLUMNS = `
+		workspace_member_workspace_id,
+		workspace_member_joined_at,
+		workspace_member_role,
+		user_id,
+		user_email,
+		user_name,
+		user_created_at,
+		user_updated_at
+	`
+)
+
+func NewWorkspaces(db *sql.DB) (*Workspaces, error) {
+	insert_stmt, err := db.Prepare(`
+		INSERT INTO workspaces (
+			workspace_slug,
+			workspace_name,
+			workspace_created_at,
+			workspace_updated_at,
+			workspace_base_currency
+		) VALUES(?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	base_currency_stmt, err := db.Prepare(`
+		UPDATE workspaces
+		SET workspace_base_currency = ?, workspace_updated_at = ?
+		WHERE workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	tax_details_stmt, err := db.Prepare(`
+		UPDATE workspaces
+		SET workspace_country = ?, workspace_vat_id = ?, workspace_updated_at = ?
+		WHERE workspace_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_stmt, err := db.Prepare("SELECT " + WORKSPACE_COLUMNS + " FROM workspaces WHERE workspace_id = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_by_slug_stmt, err := db.Prepare("SELECT " + WORKSPACE_COLUMNS + " FROM workspaces WHERE workspace_slug = ?")
+	if err != nil {
+		return nil, err
+	}
+
+	list_stmt, err := db.Prepare("SELECT " + WORKSPACE_COLUMNS + " FROM workspaces ORDER BY workspace_id ASC")
+	if err != nil {
+		return nil, err
+	}
+
+	list_for_user_stmt, err := db.Prepare(`
+		SELECT ` + WORKSPACE_COLUMNS + `
+		FROM workspaces
+		JOIN workspace_members ON workspace_member_workspace_id = workspace_id
+		WHERE workspace_member_user_id = ?
+		ORDER BY workspace_member_joined_at ASC, workspace_id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	insert_member_stmt, err := db.Prepare(`
+		INSERT INTO workspace_members (
+			workspace_member_workspace_id,
+			workspace_member_user_id,
+			workspace_member_joined_at,
+			workspace_member_role
+		) VALUES(?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	update_member_role_stmt, err := db.Prepare(`
+		UPDATE workspace_members
+		SET workspace_member_role = ?
+		WHERE workspace_member_workspace_id = ? AND workspace_member_user_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	count_owners_stmt, err := db.Prepare(`
+		SELECT COUNT(*)
+		FROM workspace_members
+		WHERE workspace_member_workspace_id = ? AND workspace_member_role = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	delete_member_stmt, err := db.Prepare(`
+		DELETE FROM workspace_members
+		WHERE workspace_member_workspace_id = ? AND workspace_member_user_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieve_member_stmt, err := db.Prepare(`
+		SELECT ` + MEMBER_COLUMNS + `
+		FROM workspace_members
+		JOIN users ON user_id = workspace_member_user_id
+		WHERE workspace_member_workspace_id = ? AND workspace_member_user_id = ?
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	list_members_stmt, err := db.Prepare(`
+		SELECT ` + MEMBER_COLUMNS + `
+		FROM workspace_members
+		JOIN users ON user_id = workspace_member_user_id
+		WHERE workspace_member_workspace_id = ?
+		ORDER BY workspace_member_joined_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Workspaces{
+		db:                      db,
+		insert_stmt:             insert_stmt,
+		retrieve_stmt:           retrieve_stmt,
+		retrieve_by_slug_stmt:   retrieve_by_slug_stmt,
+		list_stmt:               list_stmt,
+		list_for_user_stmt:      list_for_user_stmt,
+		base_currency_stmt:      base_currency_stmt,
+		tax_details_stmt:        tax_details_stmt,
+		insert_member_stmt:      insert_member_stmt,
+		delete_member_stmt:      delete_member_stmt,
+		retrieve_member_stmt:    retrieve_member_stmt,
+		list_members_stmt:       list_members_stmt,
+		update_member_role_stmt: update_member_role_stmt,
+		count_owners_stmt:       count_owners_stmt,
+	}, nil
+}
@@ -0,0 +1,266 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: client.proto
+
+package protoconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	proto "invoice-manager/main/proto"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// ClientServiceName is the fully-qualified name of the ClientService service.
+	ClientServiceName = "proto.ClientService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// ClientServiceListClientsProcedure is the fully-qualified name of the ClientService's ListClients
+	// RPC.
+	ClientServiceListClientsProcedure = "/proto.ClientService/ListClients"
+	// ClientServiceGetClientProcedure is the fully-qualified name of the ClientService's GetClient RPC.
+	ClientServiceGetClientProcedure = "/proto.ClientService/GetClient"
+	// ClientServiceCreateClientProcedure is the fully-qualified name of the ClientService's
+	// CreateClient RPC.
+	ClientServiceCreateClientProcedure = "/proto.ClientService/CreateClient"
+	// ClientServiceUpdateClientProcedure is the fully-qualified name of the ClientService's
+	// UpdateClient RPC.
+	ClientServiceUpdateClientProcedure = "/proto.ClientService/UpdateClient"
+	// ClientServiceSetClientArchivedProcedure is the fully-qualified name of the ClientService's
+	// SetClientArchived RPC.
+	ClientServiceSetClientArchivedProcedure = "/proto.ClientService/SetClientArchived"
+	// ClientServiceDeleteClientProcedure is the fully-qualified name of the ClientService's
+	// DeleteClient RPC.
+	ClientServiceDeleteClientProcedure = "/proto.ClientService/DeleteClient"
+)
+
+// These variables are the protoreflect.Descriptor objects for the RPCs defined in this package.
+var (
+	clientServiceServiceDescriptor                 = proto.File_client_proto.Services().ByName("ClientService")
+	clientServiceListClientsMethodDescriptor       = clientServiceServiceDescriptor.Methods().ByName("ListClients")
+	clientServiceGetClientMethodDescriptor         = clientServiceServiceDescriptor.Methods().ByName("GetClient")
+	clientServiceCreateClientMethodDescriptor      = clientServiceServiceDescriptor.Methods().ByName("CreateClient")
+	clientServiceUpdateClientMethodDescriptor      = clientServiceServiceDescriptor.Methods().ByName("UpdateClient")
+	clientServiceSetClientArchivedMethodDescriptor = clientServiceServiceDescriptor.Methods().ByName("SetClientArchived")
+	clientServiceDeleteClientMethodDescriptor      = clientServiceServiceDescriptor.Methods().ByName("DeleteClient")
+)
+
+// ClientServiceClient is a client for the proto.ClientService service.
+type ClientServiceClient interface {
+	ListClients(context.Context, *connect.Request[proto.ListClientsRequest]) (*connect.Response[proto.GetClientsResponse], error)
+	GetClient(context.Context, *connect.Request[proto.GetClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	CreateClient(context.Context, *connect.Request[proto.SaveClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	UpdateClient(context.Context, *connect.Request[proto.UpdateClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	SetClientArchived(context.Context, *connect.Request[proto.SetClientArchivedRequest]) (*connect.Response[proto.ClientResponse], error)
+	DeleteClient(context.Context, *connect.Request[proto.DeleteClientRequest]) (*connect.Response[proto.DeleteClientResponse], error)
+}
+
+// NewClientServiceClient constructs a client for the proto.ClientService service. By default, it
+// uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewClientServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) ClientServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &clientServiceClient{
+		listClients: connect.NewClient[proto.ListClientsRequest, proto.GetClientsResponse](
+			httpClient,
+			baseURL+ClientServiceListClientsProcedure,
+			connect.WithSchema(clientServiceListClientsMethodDescriptor),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		getClient: connect.NewClient[proto.GetClientRequest, proto.ClientResponse](
+			httpClient,
+			baseURL+ClientServiceGetClientProcedure,
+			connect.WithSchema(clientServiceGetClientMethodDescriptor),
+			connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+			connect.WithClientOptions(opts...),
+		),
+		createClient: connect.NewClient[proto.SaveClientRequest, proto.ClientResponse](
+			httpClient,
+			baseURL+ClientServiceCreateClientProcedure,
+			connect.WithSchema(clientServiceCreateClientMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		updateClient: connect.NewClient[proto.UpdateClientRequest, proto.ClientResponse](
+			httpClient,
+			baseURL+ClientServiceUpdateClientProcedure,
+			connect.WithSchema(clientServiceUpdateClientMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		setClientArchived: connect.NewClient[proto.SetClientArchivedRequest, proto.ClientResponse](
+			httpClient,
+			baseURL+ClientServiceSetClientArchivedProcedure,
+			connect.WithSchema(clientServiceSetClientArchivedMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+		deleteClient: connect.NewClient[proto.DeleteClientRequest, proto.DeleteClientResponse](
+			httpClient,
+			baseURL+ClientServiceDeleteClientProcedure,
+			connect.WithSchema(clientServiceDeleteClientMethodDescriptor),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// clientServiceClient implements ClientServiceClient.
+type clientServiceClient struct {
+	listClients       *connect.Client[proto.ListClientsRequest, proto.GetClientsResponse]
+	getClient         *connect.Client[proto.GetClientRequest, proto.ClientResponse]
+	createClient      *connect.Client[proto.SaveClientRequest, proto.ClientResponse]
+	updateClient      *connect.Client[proto.UpdateClientRequest, proto.ClientResponse]
+	setClientArchived *connect.Client[proto.SetClientArchivedRequest, proto.ClientResponse]
+	deleteClient      *connect.Client[proto.DeleteClientRequest, proto.DeleteClientResponse]
+}
+
+// ListClients calls proto.ClientService.ListClients.
+func (c *clientServiceClient) ListClients(ctx context.Context, req *connect.Request[proto.ListClientsRequest]) (*connect.Response[proto.GetClientsResponse], error) {
+	return c.listClients.CallUnary(ctx, req)
+}
+
+// GetClient calls proto.ClientService.GetClient.
+func (c *clientServiceClient) GetClient(ctx context.Context, req *connect.Request[proto.GetClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return c.getClient.CallUnary(ctx, req)
+}
+
+// CreateClient calls proto.ClientService.CreateClient.
+func (c *clientServiceClient) CreateClient(ctx context.Context, req *connect.Request[proto.SaveClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return c.createClient.CallUnary(ctx, req)
+}
+
+// UpdateClient calls proto.ClientService.UpdateClient.
+func (c *clientServiceClient) UpdateClient(ctx context.Context, req *connect.Request[proto.UpdateClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return c.updateClient.CallUnary(ctx, req)
+}
+
+// SetClientArchived calls proto.ClientService.SetClientArchived.
+func (c *clientServiceClient) SetClientArchived(ctx context.Context, req *connect.Request[proto.SetClientArchivedRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return c.setClientArchived.CallUnary(ctx, req)
+}
+
+// DeleteClient calls proto.ClientService.DeleteClient.
+func (c *clientServiceClient) DeleteClient(ctx context.Context, req *connect.Request[proto.DeleteClientRequest]) (*connect.Response[proto.DeleteClientResponse], error) {
+	return c.deleteClient.CallUnary(ctx, req)
+}
+
+// ClientServiceHandler is an implementation of the proto.ClientService service.
+type ClientServiceHandler interface {
+	ListClients(context.Context, *connect.Request[proto.ListClientsRequest]) (*connect.Response[proto.GetClientsResponse], error)
+	GetClient(context.Context, *connect.Request[proto.GetClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	CreateClient(context.Context, *connect.Request[proto.SaveClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	UpdateClient(context.Context, *connect.Request[proto.UpdateClientRequest]) (*connect.Response[proto.ClientResponse], error)
+	SetClientArchived(context.Context, *connect.Request[proto.SetClientArchivedRequest]) (*connect.Response[proto.ClientResponse], error)
+	DeleteClient(context.Context, *connect.Request[proto.DeleteClientRequest]) (*connect.Response[proto.DeleteClientResponse], error)
+}
+
+// NewClientServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewClientServiceHandler(svc ClientServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	clientServiceListClientsHandler := connect.NewUnaryHandler(
+		ClientServiceListClientsProcedure,
+		svc.ListClients,
+		connect.WithSchema(clientServiceListClientsMethodDescriptor),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	clientServiceGetClientHandler := connect.NewUnaryHandler(
+		ClientServiceGetClientProcedure,
+		svc.GetClient,
+		connect.WithSchema(clientServiceGetClientMethodDescriptor),
+		connect.WithIdempotency(connect.IdempotencyNoSideEffects),
+		connect.WithHandlerOptions(opts...),
+	)
+	clientServiceCreateClientHandler := connect.NewUnaryHandler(
+		ClientServiceCreateClientProcedure,
+		svc.CreateClient,
+		connect.WithSchema(clientServiceCreateClientMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	clientServiceUpdateClientHandler := connect.NewUnaryHandler(
+		ClientServiceUpdateClientProcedure,
+		svc.UpdateClient,
+		connect.WithSchema(clientServiceUpdateClientMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	clientServiceSetClientArchivedHandler := connect.NewUnaryHandler(
+		ClientServiceSetClientArchivedProcedure,
+		svc.SetClientArchived,
+		connect.WithSchema(clientServiceSetClientArchivedMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	clientServiceDeleteClientHandler := connect.NewUnaryHandler(
+		ClientServiceDeleteClientProcedure,
+		svc.DeleteClient,
+		connect.WithSchema(clientServiceDeleteClientMethodDescriptor),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/proto.ClientService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ClientServiceListClientsProcedure:
+			clientServiceListClientsHandler.ServeHTTP(w, r)
+		case ClientServiceGetClientProcedure:
+			clientServiceGetClientHandler.ServeHTTP(w, r)
+		case ClientServiceCreateClientProcedure:
+			clientServiceCreateClientHandler.ServeHTTP(w, r)
+		case ClientServiceUpdateClientProcedure:
+			clientServiceUpdateClientHandler.ServeHTTP(w, r)
+		case ClientServiceSetClientArchivedProcedure:
+			clientServiceSetClientArchivedHandler.ServeHTTP(w, r)
+		case ClientServiceDeleteClientProcedure:
+			clientServiceDeleteClientHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedClientServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedClientServiceHandler struct{}
+
+func (UnimplementedClientServiceHandler) ListClients(context.Context, *connect.Request[proto.ListClientsRequest]) (*connect.Response[proto.GetClientsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.ListClients is not implemented"))
+}
+
+func (UnimplementedClientServiceHandler) GetClient(context.Context, *connect.Request[proto.GetClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.GetClient is not implemented"))
+}
+
+func (UnimplementedClientServiceHandler) CreateClient(context.Context, *connect.Request[proto.SaveClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.CreateClient is not implemented"))
+}
+
+func (UnimplementedClientServiceHandler) UpdateClient(context.Context, *connect.Request[proto.UpdateClientRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.UpdateClient is not implemented"))
+}
+
+func (UnimplementedClientServiceHandler) SetClientArchived(context.Context, *connect.Request[proto.SetClientArchivedRequest]) (*connect.Response[proto.ClientResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.SetClientArchived is not implemented"))
+}
+
+func (UnimplementedClientServiceHandler) DeleteClient(context.Context, *connect.Request[proto.DeleteClientRequest]) (*connect.Response[proto.DeleteClientResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("proto.ClientService.DeleteClient is not implemented"))
+}
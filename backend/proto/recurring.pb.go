@@ -0,0 +1,759 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        (unknown)
+// source: recurring.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RecurringSchedule generates an invoice from a template invoice once per
+// billing period. Descriptions and notes of the template may use
+// {{period.start}}, {{period.end}}, {{period.year}}, {{period.month}} and
+// {{period.quarter}}, which resolve to the period of each invoice.
+type RecurringSchedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The invoice of every period. Its issue date is the start of the period
+	// and its due date follows from the payment terms of the client.
+	Invoice *SaveInvoiceRequest `protobuf:"bytes,3,opt,name=invoice,proto3" json:"invoice,omitempty"`
+	// weekly, monthly, quarterly, yearly or cron.
+	Cadence string `protobuf:"bytes,4,opt,name=cadence,proto3" json:"cadence,omitempty"`
+	// Standard cron expression with 5 fields, when the cadence is cron.
+	// Periods start on the days it matches.
+	Cron string `protobuf:"bytes,5,opt,name=cron,proto3" json:"cron,omitempty"`
+	// Formatted as YYYY-MM-DD, the first period starts on it.
+	StartDate string `protobuf:"bytes,6,opt,name=startDate,proto3" json:"startDate,omitempty"`
+	// Optional, no period starts after it.
+	EndDate string `protobuf:"bytes,7,opt,name=endDate,proto3" json:"endDate,omitempty"`
+	// Whether the invoices are issued right away or left as drafts.
+	AutoIssue bool `protobuf:"varint,8,opt,name=autoIssue,proto3" json:"autoIssue,omitempty"`
+	Paused    bool `protobuf:"varint,9,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Number of periods generated so far.
+	Periods uint32 `protobuf:"varint,10,opt,name=periods,proto3" json:"periods,omitempty"`
+	// Start of the next period, which isn't generated if it is past the end
+	// date. Empty if the cron expression never matches again.
+	NextDate    string `protobuf:"bytes,11,opt,name=nextDate,proto3" json:"nextDate,omitempty"`
+	CreatedAt   int64  `protobuf:"varint,12,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	UpdatedAt   int64  `protobuf:"varint,13,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`
+	CreatedBy   uint32 `protobuf:"varint,14,opt,name=createdBy,proto3" json:"createdBy,omitempty"`
+	UpdatedBy   uint32 `protobuf:"varint,15,opt,name=updatedBy,proto3" json:"updatedBy,omitempty"`
+	WorkspaceId uint32 `protobuf:"varint,16,opt,name=workspaceId,proto3" json:"workspaceId,omitempty"`
+}
+
+func (x *RecurringSchedule) Reset() {
+	*x = RecurringSchedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecurringSchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecurringSchedule) ProtoMessage() {}
+
+func (x *RecurringSchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecurringSchedule.ProtoReflect.Descriptor instead.
+func (*RecurringSchedule) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RecurringSchedule) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetInvoice() *SaveInvoiceRequest {
+	if x != nil {
+		return x.Invoice
+	}
+	return nil
+}
+
+func (x *RecurringSchedule) GetCadence() string {
+	if x != nil {
+		return x.Cadence
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetAutoIssue() bool {
+	if x != nil {
+		return x.AutoIssue
+	}
+	return false
+}
+
+func (x *RecurringSchedule) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *RecurringSchedule) GetPeriods() uint32 {
+	if x != nil {
+		return x.Periods
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetNextDate() string {
+	if x != nil {
+		return x.NextDate
+	}
+	return ""
+}
+
+func (x *RecurringSchedule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetCreatedBy() uint32 {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetUpdatedBy() uint32 {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return 0
+}
+
+func (x *RecurringSchedule) GetWorkspaceId() uint32 {
+	if x != nil {
+		return x.WorkspaceId
+	}
+	return 0
+}
+
+type SaveRecurringScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string              `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Invoice   *SaveInvoiceRequest `protobuf:"bytes,2,opt,name=invoice,proto3" json:"invoice,omitempty"`
+	Cadence   string              `protobuf:"bytes,3,opt,name=cadence,proto3" json:"cadence,omitempty"`
+	Cron      string              `protobuf:"bytes,4,opt,name=cron,proto3" json:"cron,omitempty"`
+	StartDate string              `protobuf:"bytes,5,opt,name=startDate,proto3" json:"startDate,omitempty"`
+	EndDate   string              `protobuf:"bytes,6,opt,name=endDate,proto3" json:"endDate,omitempty"`
+	AutoIssue bool                `protobuf:"varint,7,opt,name=autoIssue,proto3" json:"autoIssue,omitempty"`
+	Paused    bool                `protobuf:"varint,8,opt,name=paused,proto3" json:"paused,omitempty"`
+}
+
+func (x *SaveRecurringScheduleRequest) Reset() {
+	*x = SaveRecurringScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SaveRecurringScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveRecurringScheduleRequest) ProtoMessage() {}
+
+func (x *SaveRecurringScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveRecurringScheduleRequest.ProtoReflect.Descriptor instead.
+func (*SaveRecurringScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SaveRecurringScheduleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SaveRecurringScheduleRequest) GetInvoice() *SaveInvoiceRequest {
+	if x != nil {
+		return x.Invoice
+	}
+	return nil
+}
+
+func (x *SaveRecurringScheduleRequest) GetCadence() string {
+	if x != nil {
+		return x.Cadence
+	}
+	return ""
+}
+
+func (x *SaveRecurringScheduleRequest) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *SaveRecurringScheduleRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *SaveRecurringScheduleRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *SaveRecurringScheduleRequest) GetAutoIssue() bool {
+	if x != nil {
+		return x.AutoIssue
+	}
+	return false
+}
+
+func (x *SaveRecurringScheduleRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type RecurringScheduleResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schedule *RecurringSchedule `protobuf:"bytes,1,opt,name=schedule,proto3" json:"schedule,omitempty"`
+}
+
+func (x *RecurringScheduleResponse) Reset() {
+	*x = RecurringScheduleResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecurringScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecurringScheduleResponse) ProtoMessage() {}
+
+func (x *RecurringScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecurringScheduleResponse.ProtoReflect.Descriptor instead.
+func (*RecurringScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RecurringScheduleResponse) GetSchedule() *RecurringSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type GetRecurringSchedulesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schedules []*RecurringSchedule `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+}
+
+func (x *GetRecurringSchedulesResponse) Reset() {
+	*x = GetRecurringSchedulesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRecurringSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecurringSchedulesResponse) ProtoMessage() {}
+
+func (x *GetRecurringSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecurringSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*GetRecurringSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetRecurringSchedulesResponse) GetSchedules() []*RecurringSchedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+// RecurringRun is a period a schedule generated an invoice for.
+type RecurringRun struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          uint32 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ScheduleId  uint32 `protobuf:"varint,2,opt,name=scheduleId,proto3" json:"scheduleId,omitempty"`
+	PeriodStart string `protobuf:"bytes,3,opt,name=periodStart,proto3" json:"periodStart,omitempty"`
+	PeriodEnd   string `protobuf:"bytes,4,opt,name=periodEnd,proto3" json:"periodEnd,omitempty"`
+	// Zero once the invoice is deleted, the period isn't generated again.
+	InvoiceId uint32 `protobuf:"varint,5,opt,name=invoiceId,proto3" json:"invoiceId,omitempty"`
+	CreatedAt int64  `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+}
+
+func (x *RecurringRun) Reset() {
+	*x = RecurringRun{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecurringRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecurringRun) ProtoMessage() {}
+
+func (x *RecurringRun) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecurringRun.ProtoReflect.Descriptor instead.
+func (*RecurringRun) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RecurringRun) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecurringRun) GetScheduleId() uint32 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *RecurringRun) GetPeriodStart() string {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return ""
+}
+
+func (x *RecurringRun) GetPeriodEnd() string {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return ""
+}
+
+func (x *RecurringRun) GetInvoiceId() uint32 {
+	if x != nil {
+		return x.InvoiceId
+	}
+	return 0
+}
+
+func (x *RecurringRun) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type GetRecurringRunsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Runs []*RecurringRun `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+}
+
+func (x *GetRecurringRunsResponse) Reset() {
+	*x = GetRecurringRunsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_recurring_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRecurringRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecurringRunsResponse) ProtoMessage() {}
+
+func (x *GetRecurringRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recurring_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecurringRunsResponse.ProtoReflect.Descriptor instead.
+func (*GetRecurringRunsResponse) Descriptor() ([]byte, []int) {
+	return file_recurring_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetRecurringRunsResponse) GetRuns() []*RecurringRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+var File_recurring_proto protoreflect.FileDescriptor
+
+var file_recurring_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x72, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0d, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd8, 0x03, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x75,
+	0x72, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x33, 0x0a, 0x07, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x49,
+	0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x69,
+	0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x61, 0x64, 0x65, 0x6e, 0x63, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x72, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x63, 0x72, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61,
+	0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x61, 0x75, 0x74, 0x6f, 0x49, 0x73, 0x73, 0x75, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61,
+	0x75, 0x73, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73,
+	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x07, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x73, 0x12, 0x1a, 0x0a, 0x08,
+	0x6e, 0x65, 0x78, 0x74, 0x44, 0x61, 0x74, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6e, 0x65, 0x78, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42,
+	0x79, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x42, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x18,
+	0x0f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x42, 0x79,
+	0x12, 0x20, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x49, 0x64, 0x18,
+	0x10, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65,
+	0x49, 0x64, 0x22, 0x83, 0x02, 0x0a, 0x1c, 0x53, 0x61, 0x76, 0x65, 0x52, 0x65, 0x63, 0x75, 0x72,
+	0x72, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x07, 0x69, 0x6e, 0x76, 0x6f, 0x69,
+	0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x53, 0x61, 0x76, 0x65, 0x49, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x52, 0x07, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x61, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x61, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x72, 0x6f, 0x6e, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x72, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x44,
+	0x61, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61,
+	0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x73, 0x73, 0x75, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x75, 0x74, 0x6f, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x22, 0x51, 0x0a, 0x19, 0x52, 0x65, 0x63, 0x75,
+	0x72, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x08, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x52, 0x08, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x22, 0x57, 0x0a, 0x1d, 0x47,
+	0x65, 0x74, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x09,
+	0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e,
+	0x67, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x73, 0x22, 0xba, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69,
+	0x6e, 0x67, 0x52, 0x75, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x69,
+	0x6f, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x45, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x65, 0x72, 0x69,
+	0x6f, 0x64, 0x45, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65,
+	0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63,
+	0x65, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0x43, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e,
+	0x67, 0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a,
+	0x04, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x75, 0x6e,
+	0x52, 0x04, 0x72, 0x75, 0x6e, 0x73, 0x42, 0x6b, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x42, 0x0e, 0x52, 0x65, 0x63, 0x75, 0x72, 0x72, 0x69, 0x6e, 0x67, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x1a, 0x69, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x2d, 0x6d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2f, 0x6d, 0x61, 0x69, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0xa2, 0x02, 0x03, 0x50, 0x58, 0x58, 0xaa, 0x02, 0x05, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0xca,
+	0x02, 0x05, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0xe2, 0x02, 0x11, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x5c,
+	0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x05, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_recurring_proto_rawDescOnce sync.Once
+	file_recurring_proto_rawDescData = file_recurring_proto_rawDesc
+)
+
+func file_recurring_proto_rawDescGZIP() []byte {
+	file_recurring_proto_rawDescOnce.Do(func() {
+		file_recurring_proto_rawDescData = protoimpl.X.CompressGZIP(file_recurring_proto_rawDescData)
+	})
+	return file_recurring_proto_rawDescData
+}
+
+var file_recurring_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_recurring_proto_goTypes = []interface{}{
+	(*RecurringSchedule)(nil),             // 0: proto.RecurringSchedule
+	(*SaveRecurringScheduleRequest)(nil),  // 1: proto.SaveRecurringScheduleRequest
+	(*RecurringScheduleResponse)(nil),     // 2: proto.RecurringScheduleResponse
+	(*GetRecurringSchedulesResponse)(nil), // 3: proto.GetRecurringSchedulesResponse
+	(*RecurringRun)(nil),                  // 4: proto.RecurringRun
+	(*GetRecurringRunsResponse)(nil),      // 5: proto.GetRecurringRunsResponse
+	(*SaveInvoiceRequest)(nil),            // 6: proto.SaveInvoiceRequest
+}
+var file_recurring_proto_depIdxs = []int32{
+	6, // 0: proto.RecurringSchedule.invoice:type_name -> proto.SaveInvoiceRequest
+	6, // 1: proto.SaveRecurringScheduleRequest.invoice:type_name -> proto.SaveInvoiceRequest
+	0, // 2: proto.RecurringScheduleResponse.schedule:type_name -> proto.RecurringSchedule
+	0, // 3: proto.GetRecurringSchedulesResponse.schedules:type_name -> proto.RecurringSchedule
+	4, // 4: proto.GetRecurringRunsResponse.runs:type_name -> proto.RecurringRun
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_recurring_proto_init() }
+func file_recurring_proto_init() {
+	if File_recurring_proto != nil {
+		return
+	}
+	file_invoice_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_recurring_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecurringSchedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_recurring_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SaveRecurringScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_recurring_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecurringScheduleResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_recurring_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRecurringSchedulesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_recurring_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecurringRun); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_recurring_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRecurringRunsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_recurring_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_recurring_proto_goTypes,
+		DependencyIndexes: file_recurring_proto_depIdxs,
+		MessageInfos:      file_recurring_proto_msgTypes,
+	}.Build()
+	File_recurring_proto = out.File
+	file_recurring_proto_rawDesc = nil
+	file_recurring_proto_goTypes = nil
+	file_recurring_proto_depIdxs = nil
+}
@@ -0,0 +1,228 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file apikey.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.ApiKey
+ */
+export class ApiKey extends Message<ApiKey> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 userId = 2;
+   */
+  userId = 0;
+
+  /**
+   * @generated from field: string name = 3;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string prefix = 4;
+   */
+  prefix = "";
+
+  /**
+   * @generated from field: repeated string scopes = 5;
+   */
+  scopes: string[] = [];
+
+  /**
+   * @generated from field: int64 createdAt = 6;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 expiresAt = 7;
+   */
+  expiresAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 lastUsedAt = 8;
+   */
+  lastUsedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 revokedAt = 9;
+   */
+  revokedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 workspaceId = 10;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<ApiKey>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ApiKey";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "userId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "prefix", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "scopes", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 6, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 7, name: "expiresAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 8, name: "lastUsedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 9, name: "revokedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 10, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ApiKey {
+    return new ApiKey().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ApiKey {
+    return new ApiKey().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ApiKey {
+    return new ApiKey().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ApiKey | PlainMessage<ApiKey> | undefined, b: ApiKey | PlainMessage<ApiKey> | undefined): boolean {
+    return proto3.util.equals(ApiKey, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateApiKeyRequest
+ */
+export class CreateApiKeyRequest extends Message<CreateApiKeyRequest> {
+  /**
+   * @generated from field: string name = 1;
+   */
+  name = "";
+
+  /**
+   * @generated from field: repeated string scopes = 2;
+   */
+  scopes: string[] = [];
+
+  /**
+   * @generated from field: optional int64 expiresAt = 3;
+   */
+  expiresAt?: bigint;
+
+  constructor(data?: PartialMessage<CreateApiKeyRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateApiKeyRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "scopes", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 3, name: "expiresAt", kind: "scalar", T: 3 /* ScalarType.INT64 */, opt: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateApiKeyRequest {
+    return new CreateApiKeyRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateApiKeyRequest {
+    return new CreateApiKeyRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateApiKeyRequest {
+    return new CreateApiKeyRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateApiKeyRequest | PlainMessage<CreateApiKeyRequest> | undefined, b: CreateApiKeyRequest | PlainMessage<CreateApiKeyRequest> | undefined): boolean {
+    return proto3.util.equals(CreateApiKeyRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateApiKeyResponse
+ */
+export class CreateApiKeyResponse extends Message<CreateApiKeyResponse> {
+  /**
+   * @generated from field: proto.ApiKey apiKey = 1;
+   */
+  apiKey?: ApiKey;
+
+  /**
+   * @generated from field: string token = 2;
+   */
+  token = "";
+
+  constructor(data?: PartialMessage<CreateApiKeyResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateApiKeyResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "apiKey", kind: "message", T: ApiKey },
+    { no: 2, name: "token", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateApiKeyResponse {
+    return new CreateApiKeyResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateApiKeyResponse {
+    return new CreateApiKeyResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateApiKeyResponse {
+    return new CreateApiKeyResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateApiKeyResponse | PlainMessage<CreateApiKeyResponse> | undefined, b: CreateApiKeyResponse | PlainMessage<CreateApiKeyResponse> | undefined): boolean {
+    return proto3.util.equals(CreateApiKeyResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetApiKeysResponse
+ */
+export class GetApiKeysResponse extends Message<GetApiKeysResponse> {
+  /**
+   * @generated from field: repeated proto.ApiKey apiKeys = 1;
+   */
+  apiKeys: ApiKey[] = [];
+
+  constructor(data?: PartialMessage<GetApiKeysResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetApiKeysResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "apiKeys", kind: "message", T: ApiKey, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetApiKeysResponse {
+    return new GetApiKeysResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetApiKeysResponse {
+    return new GetApiKeysResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetApiKeysResponse {
+    return new GetApiKeysResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetApiKeysResponse | PlainMessage<GetApiKeysResponse> | undefined, b: GetApiKeysResponse | PlainMessage<GetApiKeysResponse> | undefined): boolean {
+    return proto3.util.equals(GetApiKeysResponse, a, b);
+  }
+}
+
@@ -0,0 +1,191 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file audit.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.AuditChange
+ */
+export class AuditChange extends Message<AuditChange> {
+  /**
+   * @generated from field: string field = 1;
+   */
+  field = "";
+
+  /**
+   * @generated from field: string before = 2;
+   */
+  before = "";
+
+  /**
+   * @generated from field: string after = 3;
+   */
+  after = "";
+
+  constructor(data?: PartialMessage<AuditChange>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.AuditChange";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "field", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "before", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "after", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AuditChange {
+    return new AuditChange().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AuditChange {
+    return new AuditChange().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AuditChange {
+    return new AuditChange().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AuditChange | PlainMessage<AuditChange> | undefined, b: AuditChange | PlainMessage<AuditChange> | undefined): boolean {
+    return proto3.util.equals(AuditChange, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.AuditEntry
+ */
+export class AuditEntry extends Message<AuditEntry> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 2;
+   */
+  workspaceId = 0;
+
+  /**
+   * @generated from field: uint32 actorUserId = 3;
+   */
+  actorUserId = 0;
+
+  /**
+   * @generated from field: uint32 actorApiKeyId = 4;
+   */
+  actorApiKeyId = 0;
+
+  /**
+   * @generated from field: string source = 5;
+   */
+  source = "";
+
+  /**
+   * @generated from field: string ip = 6;
+   */
+  ip = "";
+
+  /**
+   * @generated from field: string action = 7;
+   */
+  action = "";
+
+  /**
+   * @generated from field: string targetType = 8;
+   */
+  targetType = "";
+
+  /**
+   * @generated from field: uint32 targetId = 9;
+   */
+  targetId = 0;
+
+  /**
+   * @generated from field: repeated proto.AuditChange changes = 10;
+   */
+  changes: AuditChange[] = [];
+
+  /**
+   * @generated from field: int64 createdAt = 11;
+   */
+  createdAt = protoInt64.zero;
+
+  constructor(data?: PartialMessage<AuditEntry>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.AuditEntry";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "actorUserId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 4, name: "actorApiKeyId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 5, name: "source", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "ip", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "action", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "targetType", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "targetId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "changes", kind: "message", T: AuditChange, repeated: true },
+    { no: 11, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AuditEntry {
+    return new AuditEntry().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AuditEntry {
+    return new AuditEntry().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AuditEntry {
+    return new AuditEntry().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AuditEntry | PlainMessage<AuditEntry> | undefined, b: AuditEntry | PlainMessage<AuditEntry> | undefined): boolean {
+    return proto3.util.equals(AuditEntry, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetAuditLogResponse
+ */
+export class GetAuditLogResponse extends Message<GetAuditLogResponse> {
+  /**
+   * @generated from field: repeated proto.AuditEntry entries = 1;
+   */
+  entries: AuditEntry[] = [];
+
+  constructor(data?: PartialMessage<GetAuditLogResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetAuditLogResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "entries", kind: "message", T: AuditEntry, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetAuditLogResponse {
+    return new GetAuditLogResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetAuditLogResponse {
+    return new GetAuditLogResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetAuditLogResponse {
+    return new GetAuditLogResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetAuditLogResponse | PlainMessage<GetAuditLogResponse> | undefined, b: GetAuditLogResponse | PlainMessage<GetAuditLogResponse> | undefined): boolean {
+    return proto3.util.equals(GetAuditLogResponse, a, b);
+  }
+}
+
@@ -0,0 +1,368 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file catalog.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * Price is the default unit price of a catalog item in one currency.
+ *
+ * @generated from message proto.Price
+ */
+export class Price extends Message<Price> {
+  /**
+   * ISO 4217 code, e.g. EUR.
+   *
+   * @generated from field: string currency = 1;
+   */
+  currency = "";
+
+  /**
+   * @generated from field: string amount = 2;
+   */
+  amount = "";
+
+  constructor(data?: PartialMessage<Price>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Price";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Price {
+    return new Price().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Price {
+    return new Price().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Price {
+    return new Price().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Price | PlainMessage<Price> | undefined, b: Price | PlainMessage<Price> | undefined): boolean {
+    return proto3.util.equals(Price, a, b);
+  }
+}
+
+/**
+ * CatalogItem is a product or service line items can be filled in from.
+ * Lines copy its values when they are saved, later changes to the item
+ * don't change them.
+ *
+ * @generated from message proto.CatalogItem
+ */
+export class CatalogItem extends Message<CatalogItem> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * Unique within the workspace, e.g. "CONS-HOUR".
+   *
+   * @generated from field: string sku = 2;
+   */
+  sku = "";
+
+  /**
+   * @generated from field: string name = 3;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string description = 4;
+   */
+  description = "";
+
+  /**
+   * Unit of measure, e.g. "h" or "pcs".
+   *
+   * @generated from field: string unit = 5;
+   */
+  unit = "";
+
+  /**
+   * @generated from field: repeated proto.Price prices = 6;
+   */
+  prices: Price[] = [];
+
+  /**
+   * Tax category of lines filled in from the item, empty for the default
+   * of the line.
+   *
+   * @generated from field: string taxCategory = 7;
+   */
+  taxCategory = "";
+
+  /**
+   * @generated from field: int64 archivedAt = 8;
+   */
+  archivedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 createdAt = 9;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 10;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 11;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 12;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 13;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<CatalogItem>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CatalogItem";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "sku", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "description", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "unit", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "prices", kind: "message", T: Price, repeated: true },
+    { no: 7, name: "taxCategory", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "archivedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 9, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 10, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 11, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 12, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 13, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CatalogItem {
+    return new CatalogItem().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CatalogItem {
+    return new CatalogItem().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CatalogItem {
+    return new CatalogItem().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CatalogItem | PlainMessage<CatalogItem> | undefined, b: CatalogItem | PlainMessage<CatalogItem> | undefined): boolean {
+    return proto3.util.equals(CatalogItem, a, b);
+  }
+}
+
+/**
+ * SaveCatalogItemRequest creates a catalog item or replaces all of its
+ * fields.
+ *
+ * @generated from message proto.SaveCatalogItemRequest
+ */
+export class SaveCatalogItemRequest extends Message<SaveCatalogItemRequest> {
+  /**
+   * @generated from field: string sku = 1;
+   */
+  sku = "";
+
+  /**
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string description = 3;
+   */
+  description = "";
+
+  /**
+   * @generated from field: string unit = 4;
+   */
+  unit = "";
+
+  /**
+   * @generated from field: repeated proto.Price prices = 5;
+   */
+  prices: Price[] = [];
+
+  /**
+   * @generated from field: string taxCategory = 6;
+   */
+  taxCategory = "";
+
+  constructor(data?: PartialMessage<SaveCatalogItemRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveCatalogItemRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "sku", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "description", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "unit", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "prices", kind: "message", T: Price, repeated: true },
+    { no: 6, name: "taxCategory", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveCatalogItemRequest {
+    return new SaveCatalogItemRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveCatalogItemRequest {
+    return new SaveCatalogItemRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveCatalogItemRequest {
+    return new SaveCatalogItemRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveCatalogItemRequest | PlainMessage<SaveCatalogItemRequest> | undefined, b: SaveCatalogItemRequest | PlainMessage<SaveCatalogItemRequest> | undefined): boolean {
+    return proto3.util.equals(SaveCatalogItemRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CatalogItemResponse
+ */
+export class CatalogItemResponse extends Message<CatalogItemResponse> {
+  /**
+   * @generated from field: proto.CatalogItem item = 1;
+   */
+  item?: CatalogItem;
+
+  constructor(data?: PartialMessage<CatalogItemResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CatalogItemResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "item", kind: "message", T: CatalogItem },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CatalogItemResponse {
+    return new CatalogItemResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CatalogItemResponse {
+    return new CatalogItemResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CatalogItemResponse {
+    return new CatalogItemResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CatalogItemResponse | PlainMessage<CatalogItemResponse> | undefined, b: CatalogItemResponse | PlainMessage<CatalogItemResponse> | undefined): boolean {
+    return proto3.util.equals(CatalogItemResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetCatalogItemsResponse
+ */
+export class GetCatalogItemsResponse extends Message<GetCatalogItemsResponse> {
+  /**
+   * @generated from field: repeated proto.CatalogItem items = 1;
+   */
+  items: CatalogItem[] = [];
+
+  /**
+   * @generated from field: uint32 total = 2;
+   */
+  total = 0;
+
+  constructor(data?: PartialMessage<GetCatalogItemsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetCatalogItemsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "items", kind: "message", T: CatalogItem, repeated: true },
+    { no: 2, name: "total", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetCatalogItemsResponse {
+    return new GetCatalogItemsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetCatalogItemsResponse {
+    return new GetCatalogItemsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetCatalogItemsResponse {
+    return new GetCatalogItemsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetCatalogItemsResponse | PlainMessage<GetCatalogItemsResponse> | undefined, b: GetCatalogItemsResponse | PlainMessage<GetCatalogItemsResponse> | undefined): boolean {
+    return proto3.util.equals(GetCatalogItemsResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ImportCatalogResponse
+ */
+export class ImportCatalogResponse extends Message<ImportCatalogResponse> {
+  /**
+   * @generated from field: uint32 created = 1;
+   */
+  created = 0;
+
+  /**
+   * @generated from field: uint32 updated = 2;
+   */
+  updated = 0;
+
+  constructor(data?: PartialMessage<ImportCatalogResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ImportCatalogResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "created", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "updated", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ImportCatalogResponse {
+    return new ImportCatalogResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ImportCatalogResponse {
+    return new ImportCatalogResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ImportCatalogResponse {
+    return new ImportCatalogResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ImportCatalogResponse | PlainMessage<ImportCatalogResponse> | undefined, b: ImportCatalogResponse | PlainMessage<ImportCatalogResponse> | undefined): boolean {
+    return proto3.util.equals(ImportCatalogResponse, a, b);
+  }
+}
+
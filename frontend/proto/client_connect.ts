@@ -0,0 +1,76 @@
+// @generated by protoc-gen-connect-es v1.4.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file client.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import { ClientResponse, DeleteClientRequest, DeleteClientResponse, GetClientRequest, GetClientsResponse, ListClientsRequest, SaveClientRequest, SetClientArchivedRequest, UpdateClientRequest } from "./client_pb.ts";
+import { MethodIdempotency, MethodKind } from "@bufbuild/protobuf";
+
+/**
+ * ClientService offers the client directory of the workspace the caller
+ * is signed in to, like the /clients routes.
+ *
+ * @generated from service proto.ClientService
+ */
+export const ClientService = {
+  typeName: "proto.ClientService",
+  methods: {
+    /**
+     * @generated from rpc proto.ClientService.ListClients
+     */
+    listClients: {
+      name: "ListClients",
+      I: ListClientsRequest,
+      O: GetClientsResponse,
+      kind: MethodKind.Unary,
+      idempotency: MethodIdempotency.NoSideEffects,
+    },
+    /**
+     * @generated from rpc proto.ClientService.GetClient
+     */
+    getClient: {
+      name: "GetClient",
+      I: GetClientRequest,
+      O: ClientResponse,
+      kind: MethodKind.Unary,
+      idempotency: MethodIdempotency.NoSideEffects,
+    },
+    /**
+     * @generated from rpc proto.ClientService.CreateClient
+     */
+    createClient: {
+      name: "CreateClient",
+      I: SaveClientRequest,
+      O: ClientResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc proto.ClientService.UpdateClient
+     */
+    updateClient: {
+      name: "UpdateClient",
+      I: UpdateClientRequest,
+      O: ClientResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc proto.ClientService.SetClientArchived
+     */
+    setClientArchived: {
+      name: "SetClientArchived",
+      I: SetClientArchivedRequest,
+      O: ClientResponse,
+      kind: MethodKind.Unary,
+    },
+    /**
+     * @generated from rpc proto.ClientService.DeleteClient
+     */
+    deleteClient: {
+      name: "DeleteClient",
+      I: DeleteClientRequest,
+      O: DeleteClientResponse,
+      kind: MethodKind.Unary,
+    },
+  }
+} as const;
+
@@ -0,0 +1,708 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file client.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.Address
+ */
+export class Address extends Message<Address> {
+  /**
+   * @generated from field: string line1 = 1;
+   */
+  line1 = "";
+
+  /**
+   * @generated from field: string line2 = 2;
+   */
+  line2 = "";
+
+  /**
+   * @generated from field: string city = 3;
+   */
+  city = "";
+
+  /**
+   * @generated from field: string postalCode = 4;
+   */
+  postalCode = "";
+
+  /**
+   * @generated from field: string region = 5;
+   */
+  region = "";
+
+  /**
+   * ISO 3166-1 alpha-2 code, e.g. DE.
+   *
+   * @generated from field: string country = 6;
+   */
+  country = "";
+
+  constructor(data?: PartialMessage<Address>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Address";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "line1", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "line2", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "city", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "postalCode", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "region", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "country", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Address {
+    return new Address().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Address {
+    return new Address().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Address {
+    return new Address().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Address | PlainMessage<Address> | undefined, b: Address | PlainMessage<Address> | undefined): boolean {
+    return proto3.util.equals(Address, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.Contact
+ */
+export class Contact extends Message<Contact> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string email = 3;
+   */
+  email = "";
+
+  /**
+   * @generated from field: string phone = 4;
+   */
+  phone = "";
+
+  /**
+   * @generated from field: string role = 5;
+   */
+  role = "";
+
+  /**
+   * @generated from field: bool primary = 6;
+   */
+  primary = false;
+
+  constructor(data?: PartialMessage<Contact>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Contact";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "email", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "phone", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "role", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "primary", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Contact {
+    return new Contact().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Contact {
+    return new Contact().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Contact {
+    return new Contact().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Contact | PlainMessage<Contact> | undefined, b: Contact | PlainMessage<Contact> | undefined): boolean {
+    return proto3.util.equals(Contact, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.Client
+ */
+export class Client extends Message<Client> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string legalName = 2;
+   */
+  legalName = "";
+
+  /**
+   * @generated from field: proto.Address billingAddress = 3;
+   */
+  billingAddress?: Address;
+
+  /**
+   * @generated from field: proto.Address shippingAddress = 4;
+   */
+  shippingAddress?: Address;
+
+  /**
+   * @generated from field: string taxId = 5;
+   */
+  taxId = "";
+
+  /**
+   * @generated from field: repeated proto.Contact contacts = 6;
+   */
+  contacts: Contact[] = [];
+
+  /**
+   * ISO 4217 code, e.g. EUR.
+   *
+   * @generated from field: string defaultCurrency = 7;
+   */
+  defaultCurrency = "";
+
+  /**
+   * BCP 47 tag, e.g. de or en-GB.
+   *
+   * @generated from field: string defaultLanguage = 8;
+   */
+  defaultLanguage = "";
+
+  /**
+   * Days until invoices are due.
+   *
+   * @generated from field: uint32 defaultPaymentTerms = 9;
+   */
+  defaultPaymentTerms = 0;
+
+  /**
+   * @generated from field: uint32 defaultTemplateId = 10;
+   */
+  defaultTemplateId = 0;
+
+  /**
+   * @generated from field: int64 archivedAt = 11;
+   */
+  archivedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 createdAt = 12;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 13;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 14;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 15;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 16;
+   */
+  workspaceId = 0;
+
+  /**
+   * Template of quotes, the default template is used if it is zero.
+   *
+   * @generated from field: uint32 defaultQuoteTemplateId = 17;
+   */
+  defaultQuoteTemplateId = 0;
+
+  constructor(data?: PartialMessage<Client>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Client";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "legalName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "billingAddress", kind: "message", T: Address },
+    { no: 4, name: "shippingAddress", kind: "message", T: Address },
+    { no: 5, name: "taxId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "contacts", kind: "message", T: Contact, repeated: true },
+    { no: 7, name: "defaultCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "defaultLanguage", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "defaultPaymentTerms", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "defaultTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "archivedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 12, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 13, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 14, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 15, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 16, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 17, name: "defaultQuoteTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Client {
+    return new Client().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Client {
+    return new Client().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Client {
+    return new Client().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Client | PlainMessage<Client> | undefined, b: Client | PlainMessage<Client> | undefined): boolean {
+    return proto3.util.equals(Client, a, b);
+  }
+}
+
+/**
+ * SaveClientRequest creates a client or replaces all of its fields.
+ *
+ * @generated from message proto.SaveClientRequest
+ */
+export class SaveClientRequest extends Message<SaveClientRequest> {
+  /**
+   * @generated from field: string legalName = 1;
+   */
+  legalName = "";
+
+  /**
+   * @generated from field: proto.Address billingAddress = 2;
+   */
+  billingAddress?: Address;
+
+  /**
+   * @generated from field: proto.Address shippingAddress = 3;
+   */
+  shippingAddress?: Address;
+
+  /**
+   * @generated from field: string taxId = 4;
+   */
+  taxId = "";
+
+  /**
+   * @generated from field: repeated proto.Contact contacts = 5;
+   */
+  contacts: Contact[] = [];
+
+  /**
+   * @generated from field: string defaultCurrency = 6;
+   */
+  defaultCurrency = "";
+
+  /**
+   * @generated from field: string defaultLanguage = 7;
+   */
+  defaultLanguage = "";
+
+  /**
+   * @generated from field: uint32 defaultPaymentTerms = 8;
+   */
+  defaultPaymentTerms = 0;
+
+  /**
+   * @generated from field: uint32 defaultTemplateId = 9;
+   */
+  defaultTemplateId = 0;
+
+  /**
+   * @generated from field: uint32 defaultQuoteTemplateId = 10;
+   */
+  defaultQuoteTemplateId = 0;
+
+  constructor(data?: PartialMessage<SaveClientRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveClientRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "legalName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "billingAddress", kind: "message", T: Address },
+    { no: 3, name: "shippingAddress", kind: "message", T: Address },
+    { no: 4, name: "taxId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "contacts", kind: "message", T: Contact, repeated: true },
+    { no: 6, name: "defaultCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "defaultLanguage", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "defaultPaymentTerms", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 9, name: "defaultTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "defaultQuoteTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveClientRequest {
+    return new SaveClientRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveClientRequest {
+    return new SaveClientRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveClientRequest {
+    return new SaveClientRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveClientRequest | PlainMessage<SaveClientRequest> | undefined, b: SaveClientRequest | PlainMessage<SaveClientRequest> | undefined): boolean {
+    return proto3.util.equals(SaveClientRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ClientResponse
+ */
+export class ClientResponse extends Message<ClientResponse> {
+  /**
+   * @generated from field: proto.Client client = 1;
+   */
+  client?: Client;
+
+  constructor(data?: PartialMessage<ClientResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ClientResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "client", kind: "message", T: Client },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ClientResponse {
+    return new ClientResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ClientResponse {
+    return new ClientResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ClientResponse {
+    return new ClientResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ClientResponse | PlainMessage<ClientResponse> | undefined, b: ClientResponse | PlainMessage<ClientResponse> | undefined): boolean {
+    return proto3.util.equals(ClientResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetClientsResponse
+ */
+export class GetClientsResponse extends Message<GetClientsResponse> {
+  /**
+   * @generated from field: repeated proto.Client clients = 1;
+   */
+  clients: Client[] = [];
+
+  /**
+   * @generated from field: uint32 total = 2;
+   */
+  total = 0;
+
+  constructor(data?: PartialMessage<GetClientsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetClientsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "clients", kind: "message", T: Client, repeated: true },
+    { no: 2, name: "total", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetClientsResponse {
+    return new GetClientsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetClientsResponse {
+    return new GetClientsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetClientsResponse {
+    return new GetClientsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetClientsResponse | PlainMessage<GetClientsResponse> | undefined, b: GetClientsResponse | PlainMessage<GetClientsResponse> | undefined): boolean {
+    return proto3.util.equals(GetClientsResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ListClientsRequest
+ */
+export class ListClientsRequest extends Message<ListClientsRequest> {
+  /**
+   * Matches the legal name, tax ID and contact emails.
+   *
+   * @generated from field: string query = 1;
+   */
+  query = "";
+
+  /**
+   * "false" (the default), "true" or "all".
+   *
+   * @generated from field: string archived = 2;
+   */
+  archived = "";
+
+  /**
+   * @generated from field: uint32 limit = 3;
+   */
+  limit = 0;
+
+  /**
+   * @generated from field: uint32 offset = 4;
+   */
+  offset = 0;
+
+  constructor(data?: PartialMessage<ListClientsRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ListClientsRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "query", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "archived", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "limit", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 4, name: "offset", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ListClientsRequest {
+    return new ListClientsRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ListClientsRequest {
+    return new ListClientsRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ListClientsRequest {
+    return new ListClientsRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ListClientsRequest | PlainMessage<ListClientsRequest> | undefined, b: ListClientsRequest | PlainMessage<ListClientsRequest> | undefined): boolean {
+    return proto3.util.equals(ListClientsRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetClientRequest
+ */
+export class GetClientRequest extends Message<GetClientRequest> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  constructor(data?: PartialMessage<GetClientRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetClientRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetClientRequest {
+    return new GetClientRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetClientRequest {
+    return new GetClientRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetClientRequest {
+    return new GetClientRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetClientRequest | PlainMessage<GetClientRequest> | undefined, b: GetClientRequest | PlainMessage<GetClientRequest> | undefined): boolean {
+    return proto3.util.equals(GetClientRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.UpdateClientRequest
+ */
+export class UpdateClientRequest extends Message<UpdateClientRequest> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: proto.SaveClientRequest client = 2;
+   */
+  client?: SaveClientRequest;
+
+  constructor(data?: PartialMessage<UpdateClientRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.UpdateClientRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "client", kind: "message", T: SaveClientRequest },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): UpdateClientRequest {
+    return new UpdateClientRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): UpdateClientRequest {
+    return new UpdateClientRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): UpdateClientRequest {
+    return new UpdateClientRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: UpdateClientRequest | PlainMessage<UpdateClientRequest> | undefined, b: UpdateClientRequest | PlainMessage<UpdateClientRequest> | undefined): boolean {
+    return proto3.util.equals(UpdateClientRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SetClientArchivedRequest
+ */
+export class SetClientArchivedRequest extends Message<SetClientArchivedRequest> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: bool archived = 2;
+   */
+  archived = false;
+
+  constructor(data?: PartialMessage<SetClientArchivedRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SetClientArchivedRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "archived", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SetClientArchivedRequest {
+    return new SetClientArchivedRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SetClientArchivedRequest {
+    return new SetClientArchivedRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SetClientArchivedRequest {
+    return new SetClientArchivedRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SetClientArchivedRequest | PlainMessage<SetClientArchivedRequest> | undefined, b: SetClientArchivedRequest | PlainMessage<SetClientArchivedRequest> | undefined): boolean {
+    return proto3.util.equals(SetClientArchivedRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.DeleteClientRequest
+ */
+export class DeleteClientRequest extends Message<DeleteClientRequest> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  constructor(data?: PartialMessage<DeleteClientRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.DeleteClientRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): DeleteClientRequest {
+    return new DeleteClientRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): DeleteClientRequest {
+    return new DeleteClientRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): DeleteClientRequest {
+    return new DeleteClientRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: DeleteClientRequest | PlainMessage<DeleteClientRequest> | undefined, b: DeleteClientRequest | PlainMessage<DeleteClientRequest> | undefined): boolean {
+    return proto3.util.equals(DeleteClientRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.DeleteClientResponse
+ */
+export class DeleteClientResponse extends Message<DeleteClientResponse> {
+  constructor(data?: PartialMessage<DeleteClientResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.DeleteClientResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): DeleteClientResponse {
+    return new DeleteClientResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): DeleteClientResponse {
+    return new DeleteClientResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): DeleteClientResponse {
+    return new DeleteClientResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: DeleteClientResponse | PlainMessage<DeleteClientResponse> | undefined, b: DeleteClientResponse | PlainMessage<DeleteClientResponse> | undefined): boolean {
+    return proto3.util.equals(DeleteClientResponse, a, b);
+  }
+}
+
@@ -0,0 +1,133 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file error.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.FieldViolation
+ */
+export class FieldViolation extends Message<FieldViolation> {
+  /**
+   * @generated from field: string field = 1;
+   */
+  field = "";
+
+  /**
+   * @generated from field: string description = 2;
+   */
+  description = "";
+
+  constructor(data?: PartialMessage<FieldViolation>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.FieldViolation";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "field", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "description", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): FieldViolation {
+    return new FieldViolation().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): FieldViolation {
+    return new FieldViolation().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): FieldViolation {
+    return new FieldViolation().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: FieldViolation | PlainMessage<FieldViolation> | undefined, b: FieldViolation | PlainMessage<FieldViolation> | undefined): boolean {
+    return proto3.util.equals(FieldViolation, a, b);
+  }
+}
+
+/**
+ * Problem mirrors the RFC 7807 problem details returned by the REST API, it
+ * is attached as a detail to Connect errors.
+ *
+ * @generated from message proto.Problem
+ */
+export class Problem extends Message<Problem> {
+  /**
+   * @generated from field: string type = 1;
+   */
+  type = "";
+
+  /**
+   * @generated from field: string title = 2;
+   */
+  title = "";
+
+  /**
+   * @generated from field: uint32 status = 3;
+   */
+  status = 0;
+
+  /**
+   * @generated from field: string detail = 4;
+   */
+  detail = "";
+
+  /**
+   * @generated from field: string instance = 5;
+   */
+  instance = "";
+
+  /**
+   * @generated from field: string code = 6;
+   */
+  code = "";
+
+  /**
+   * @generated from field: repeated proto.FieldViolation errors = 7;
+   */
+  errors: FieldViolation[] = [];
+
+  /**
+   * @generated from field: map<string, string> extensions = 8;
+   */
+  extensions: { [key: string]: string } = {};
+
+  constructor(data?: PartialMessage<Problem>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Problem";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "type", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "title", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "status", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 4, name: "detail", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "instance", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "code", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "errors", kind: "message", T: FieldViolation, repeated: true },
+    { no: 8, name: "extensions", kind: "map", K: 9 /* ScalarType.STRING */, V: {kind: "scalar", T: 9 /* ScalarType.STRING */} },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Problem {
+    return new Problem().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Problem {
+    return new Problem().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Problem {
+    return new Problem().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Problem | PlainMessage<Problem> | undefined, b: Problem | PlainMessage<Problem> | undefined): boolean {
+    return proto3.util.equals(Problem, a, b);
+  }
+}
+
@@ -0,0 +1,212 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file exchange.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * ExchangeRate is how many units of the quote currency one unit of the base
+ * currency was worth on a day, e.g. base EUR, quote USD and rate "1.0956".
+ *
+ * @generated from message proto.ExchangeRate
+ */
+export class ExchangeRate extends Message<ExchangeRate> {
+  /**
+   * Formatted as YYYY-MM-DD.
+   *
+   * @generated from field: string date = 1;
+   */
+  date = "";
+
+  /**
+   * ISO 4217 codes.
+   *
+   * @generated from field: string base = 2;
+   */
+  base = "";
+
+  /**
+   * @generated from field: string quote = 3;
+   */
+  quote = "";
+
+  /**
+   * @generated from field: string rate = 4;
+   */
+  rate = "";
+
+  /**
+   * Where the rate comes from, e.g. ecb or csv, or derived for rates
+   * computed from the inverse or a common currency.
+   *
+   * @generated from field: string source = 5;
+   */
+  source = "";
+
+  /**
+   * @generated from field: int64 importedAt = 6;
+   */
+  importedAt = protoInt64.zero;
+
+  constructor(data?: PartialMessage<ExchangeRate>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ExchangeRate";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "date", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "base", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "quote", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "rate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "source", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "importedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ExchangeRate {
+    return new ExchangeRate().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ExchangeRate {
+    return new ExchangeRate().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ExchangeRate {
+    return new ExchangeRate().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ExchangeRate | PlainMessage<ExchangeRate> | undefined, b: ExchangeRate | PlainMessage<ExchangeRate> | undefined): boolean {
+    return proto3.util.equals(ExchangeRate, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetExchangeRatesResponse
+ */
+export class GetExchangeRatesResponse extends Message<GetExchangeRatesResponse> {
+  /**
+   * @generated from field: repeated proto.ExchangeRate rates = 1;
+   */
+  rates: ExchangeRate[] = [];
+
+  constructor(data?: PartialMessage<GetExchangeRatesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetExchangeRatesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "rates", kind: "message", T: ExchangeRate, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetExchangeRatesResponse {
+    return new GetExchangeRatesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetExchangeRatesResponse {
+    return new GetExchangeRatesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetExchangeRatesResponse {
+    return new GetExchangeRatesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetExchangeRatesResponse | PlainMessage<GetExchangeRatesResponse> | undefined, b: GetExchangeRatesResponse | PlainMessage<GetExchangeRatesResponse> | undefined): boolean {
+    return proto3.util.equals(GetExchangeRatesResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ExchangeRateResponse
+ */
+export class ExchangeRateResponse extends Message<ExchangeRateResponse> {
+  /**
+   * @generated from field: proto.ExchangeRate rate = 1;
+   */
+  rate?: ExchangeRate;
+
+  constructor(data?: PartialMessage<ExchangeRateResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ExchangeRateResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "rate", kind: "message", T: ExchangeRate },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ExchangeRateResponse {
+    return new ExchangeRateResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ExchangeRateResponse {
+    return new ExchangeRateResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ExchangeRateResponse {
+    return new ExchangeRateResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ExchangeRateResponse | PlainMessage<ExchangeRateResponse> | undefined, b: ExchangeRateResponse | PlainMessage<ExchangeRateResponse> | undefined): boolean {
+    return proto3.util.equals(ExchangeRateResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ImportExchangeRatesResponse
+ */
+export class ImportExchangeRatesResponse extends Message<ImportExchangeRatesResponse> {
+  /**
+   * Number of rates added or replaced.
+   *
+   * @generated from field: uint32 imported = 1;
+   */
+  imported = 0;
+
+  /**
+   * Dates of the first and last day imported.
+   *
+   * @generated from field: string from = 2;
+   */
+  from = "";
+
+  /**
+   * @generated from field: string to = 3;
+   */
+  to = "";
+
+  constructor(data?: PartialMessage<ImportExchangeRatesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ImportExchangeRatesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "imported", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "from", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "to", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ImportExchangeRatesResponse {
+    return new ImportExchangeRatesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ImportExchangeRatesResponse {
+    return new ImportExchangeRatesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ImportExchangeRatesResponse {
+    return new ImportExchangeRatesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ImportExchangeRatesResponse | PlainMessage<ImportExchangeRatesResponse> | undefined, b: ImportExchangeRatesResponse | PlainMessage<ImportExchangeRatesResponse> | undefined): boolean {
+    return proto3.util.equals(ImportExchangeRatesResponse, a, b);
+  }
+}
+
@@ -0,0 +1,1189 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file invoice.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+import { Client } from "./client_pb.ts";
+import { Issuer } from "./issuer_pb.ts";
+
+/**
+ * Discount takes either a percentage or a fixed amount off.
+ *
+ * @generated from message proto.Discount
+ */
+export class Discount extends Message<Discount> {
+  /**
+   * @generated from field: string description = 1;
+   */
+  description = "";
+
+  /**
+   * @generated from field: string percent = 2;
+   */
+  percent = "";
+
+  /**
+   * @generated from field: string amount = 3;
+   */
+  amount = "";
+
+  constructor(data?: PartialMessage<Discount>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Discount";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "description", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "percent", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Discount {
+    return new Discount().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Discount {
+    return new Discount().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Discount {
+    return new Discount().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Discount | PlainMessage<Discount> | undefined, b: Discount | PlainMessage<Discount> | undefined): boolean {
+    return proto3.util.equals(Discount, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.LineItem
+ */
+export class LineItem extends Message<LineItem> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string description = 2;
+   */
+  description = "";
+
+  /**
+   * @generated from field: string quantity = 3;
+   */
+  quantity = "";
+
+  /**
+   * Unit of measure, e.g. h or pcs.
+   *
+   * @generated from field: string unit = 4;
+   */
+  unit = "";
+
+  /**
+   * @generated from field: string unitPrice = 5;
+   */
+  unitPrice = "";
+
+  /**
+   * @generated from field: proto.Discount discount = 6;
+   */
+  discount?: Discount;
+
+  /**
+   * Percentage, e.g. "19". Taken from the tax rate if the line has one.
+   *
+   * @generated from field: string taxRate = 7;
+   */
+  taxRate = "";
+
+  /**
+   * Computed by the server, after the discount. Before tax, unless the
+   * prices of the invoice include tax.
+   *
+   * @generated from field: string net = 8;
+   */
+  net = "";
+
+  /**
+   * Line of the original invoice that a credit note line credits, set by the
+   * server.
+   *
+   * @generated from field: uint32 originalLineItemId = 9;
+   */
+  originalLineItemId = 0;
+
+  /**
+   * Tax rate of the workspace the line is taxed with, optional.
+   *
+   * @generated from field: uint32 taxRateId = 10;
+   */
+  taxRateId = 0;
+
+  /**
+   * standard, reduced, zero_rated, exempt or reverse_charge. Lines without
+   * one are standard, or zero rated at 0%. Reverse charge is set by the
+   * server for intra-EU business clients.
+   *
+   * @generated from field: string taxCategory = 11;
+   */
+  taxCategory = "";
+
+  /**
+   * Printed on the invoice for exempt and reverse charge lines.
+   *
+   * @generated from field: string exemptionReason = 12;
+   */
+  exemptionReason = "";
+
+  /**
+   * Catalog item the line is filled in from, optional. Description, unit,
+   * unit price and tax category left empty are taken from the item, along
+   * with its SKU.
+   *
+   * @generated from field: uint32 catalogItemId = 13;
+   */
+  catalogItemId = 0;
+
+  /**
+   * @generated from field: string sku = 14;
+   */
+  sku = "";
+
+  constructor(data?: PartialMessage<LineItem>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.LineItem";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "description", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "quantity", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "unit", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "unitPrice", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "discount", kind: "message", T: Discount },
+    { no: 7, name: "taxRate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "net", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "originalLineItemId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "taxRateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "taxCategory", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "exemptionReason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 13, name: "catalogItemId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 14, name: "sku", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): LineItem {
+    return new LineItem().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): LineItem {
+    return new LineItem().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): LineItem {
+    return new LineItem().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: LineItem | PlainMessage<LineItem> | undefined, b: LineItem | PlainMessage<LineItem> | undefined): boolean {
+    return proto3.util.equals(LineItem, a, b);
+  }
+}
+
+/**
+ * TaxAmount is the tax of one category and rate.
+ *
+ * @generated from message proto.TaxAmount
+ */
+export class TaxAmount extends Message<TaxAmount> {
+  /**
+   * @generated from field: string rate = 1;
+   */
+  rate = "";
+
+  /**
+   * Net amount the tax is computed on.
+   *
+   * @generated from field: string base = 2;
+   */
+  base = "";
+
+  /**
+   * @generated from field: string amount = 3;
+   */
+  amount = "";
+
+  /**
+   * @generated from field: string category = 4;
+   */
+  category = "";
+
+  /**
+   * @generated from field: string exemptionReason = 5;
+   */
+  exemptionReason = "";
+
+  constructor(data?: PartialMessage<TaxAmount>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.TaxAmount";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "rate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "base", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "category", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "exemptionReason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TaxAmount {
+    return new TaxAmount().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TaxAmount {
+    return new TaxAmount().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TaxAmount {
+    return new TaxAmount().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TaxAmount | PlainMessage<TaxAmount> | undefined, b: TaxAmount | PlainMessage<TaxAmount> | undefined): boolean {
+    return proto3.util.equals(TaxAmount, a, b);
+  }
+}
+
+/**
+ * Totals are computed by the server. Invoice level discounts are split
+ * among the tax rates in proportion to their net amounts. With prices that
+ * include tax, the tax of each rate is taken out of its gross amount.
+ *
+ * @generated from message proto.Totals
+ */
+export class Totals extends Message<Totals> {
+  /**
+   * Sum of the net amounts of the line items, including tax if their
+   * prices do.
+   *
+   * @generated from field: string subtotal = 1;
+   */
+  subtotal = "";
+
+  /**
+   * Sum of the invoice level discounts.
+   *
+   * @generated from field: string discount = 2;
+   */
+  discount = "";
+
+  /**
+   * @generated from field: string net = 3;
+   */
+  net = "";
+
+  /**
+   * @generated from field: string tax = 4;
+   */
+  tax = "";
+
+  /**
+   * @generated from field: string gross = 5;
+   */
+  gross = "";
+
+  /**
+   * @generated from field: repeated proto.TaxAmount taxes = 6;
+   */
+  taxes: TaxAmount[] = [];
+
+  constructor(data?: PartialMessage<Totals>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Totals";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "subtotal", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "discount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "net", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "tax", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "gross", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "taxes", kind: "message", T: TaxAmount, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Totals {
+    return new Totals().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Totals {
+    return new Totals().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Totals {
+    return new Totals().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Totals | PlainMessage<Totals> | undefined, b: Totals | PlainMessage<Totals> | undefined): boolean {
+    return proto3.util.equals(Totals, a, b);
+  }
+}
+
+/**
+ * InvoiceSnapshot is what an invoice was issued with, later changes to the
+ * client, issuer or template don't affect it.
+ *
+ * @generated from message proto.InvoiceSnapshot
+ */
+export class InvoiceSnapshot extends Message<InvoiceSnapshot> {
+  /**
+   * @generated from field: proto.Client client = 1;
+   */
+  client?: Client;
+
+  /**
+   * @generated from field: uint32 templateId = 2;
+   */
+  templateId = 0;
+
+  /**
+   * @generated from field: string templateName = 3;
+   */
+  templateName = "";
+
+  /**
+   * SHA-256 of the template HTML the invoice was rendered from.
+   *
+   * @generated from field: string templateSha256 = 4;
+   */
+  templateSha256 = "";
+
+  /**
+   * @generated from field: int64 templateUpdatedAt = 5;
+   */
+  templateUpdatedAt = protoInt64.zero;
+
+  /**
+   * Unset for invoices issued by the workspace itself.
+   *
+   * @generated from field: proto.Issuer issuer = 6;
+   */
+  issuer?: Issuer;
+
+  constructor(data?: PartialMessage<InvoiceSnapshot>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.InvoiceSnapshot";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "client", kind: "message", T: Client },
+    { no: 2, name: "templateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "templateName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "templateSha256", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "templateUpdatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 6, name: "issuer", kind: "message", T: Issuer },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): InvoiceSnapshot {
+    return new InvoiceSnapshot().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): InvoiceSnapshot {
+    return new InvoiceSnapshot().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): InvoiceSnapshot {
+    return new InvoiceSnapshot().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: InvoiceSnapshot | PlainMessage<InvoiceSnapshot> | undefined, b: InvoiceSnapshot | PlainMessage<InvoiceSnapshot> | undefined): boolean {
+    return proto3.util.equals(InvoiceSnapshot, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.Invoice
+ */
+export class Invoice extends Message<Invoice> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string status = 2;
+   */
+  status = "";
+
+  /**
+   * @generated from field: string number = 3;
+   */
+  number = "";
+
+  /**
+   * @generated from field: uint32 clientId = 4;
+   */
+  clientId = 0;
+
+  /**
+   * @generated from field: uint32 templateId = 5;
+   */
+  templateId = 0;
+
+  /**
+   * ISO 4217 code, e.g. EUR.
+   *
+   * @generated from field: string currency = 6;
+   */
+  currency = "";
+
+  /**
+   * BCP 47 tag, e.g. de or en-GB.
+   *
+   * @generated from field: string language = 7;
+   */
+  language = "";
+
+  /**
+   * Dates are formatted as YYYY-MM-DD.
+   *
+   * @generated from field: string issueDate = 8;
+   */
+  issueDate = "";
+
+  /**
+   * @generated from field: string dueDate = 9;
+   */
+  dueDate = "";
+
+  /**
+   * @generated from field: string serviceDate = 10;
+   */
+  serviceDate = "";
+
+  /**
+   * @generated from field: string notes = 11;
+   */
+  notes = "";
+
+  /**
+   * @generated from field: repeated proto.LineItem items = 12;
+   */
+  items: LineItem[] = [];
+
+  /**
+   * @generated from field: repeated proto.Discount discounts = 13;
+   */
+  discounts: Discount[] = [];
+
+  /**
+   * @generated from field: proto.Totals totals = 14;
+   */
+  totals?: Totals;
+
+  /**
+   * @generated from field: int64 createdAt = 15;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 16;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 17;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 18;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 19;
+   */
+  workspaceId = 0;
+
+  /**
+   * @generated from field: proto.InvoiceSnapshot snapshot = 20;
+   */
+  snapshot?: InvoiceSnapshot;
+
+  /**
+   * @generated from field: int64 issuedAt = 21;
+   */
+  issuedAt = protoInt64.zero;
+
+  /**
+   * Sum of the payments allocated to the invoice, less reversed ones.
+   *
+   * @generated from field: string paid = 22;
+   */
+  paid = "";
+
+  /**
+   * What is left to pay, i.e. the gross amount less paid and credited.
+   *
+   * @generated from field: string balance = 23;
+   */
+  balance = "";
+
+  /**
+   * invoice, credit_note, cancellation or quote. Credit notes and
+   * cancellations correct the invoice they reference, with negated amounts.
+   * Invoices converted from a quote reference the quote.
+   *
+   * @generated from field: string kind = 24;
+   */
+  kind = "";
+
+  /**
+   * @generated from field: uint32 originalId = 25;
+   */
+  originalId = 0;
+
+  /**
+   * @generated from field: string originalNumber = 26;
+   */
+  originalNumber = "";
+
+  /**
+   * Sum of the credit notes and cancellations of the invoice, as a positive
+   * amount.
+   *
+   * @generated from field: string credited = 27;
+   */
+  credited = "";
+
+  /**
+   * Percent of a quote converted into invoices so far.
+   *
+   * @generated from field: string converted = 28;
+   */
+  converted = "";
+
+  /**
+   * Percent of the quote an invoice converted from one bills.
+   *
+   * @generated from field: string share = 29;
+   */
+  share = "";
+
+  /**
+   * Base currency of the workspace when the invoice was issued, and how many
+   * units of it one unit of the invoice currency was worth. Drafts only
+   * have them if the rate was set by hand.
+   *
+   * @generated from field: string baseCurrency = 30;
+   */
+  baseCurrency = "";
+
+  /**
+   * @generated from field: string exchangeRate = 31;
+   */
+  exchangeRate = "";
+
+  /**
+   * Gross amount in the base currency, empty until the rate is known.
+   *
+   * @generated from field: string baseGross = 32;
+   */
+  baseGross = "";
+
+  /**
+   * Unit prices and discounts include tax.
+   *
+   * @generated from field: bool pricesIncludeTax = 33;
+   */
+  pricesIncludeTax = false;
+
+  /**
+   * The client owes the VAT of some or all lines, set by the server. Lines
+   * are reverse charged when issuer and client are businesses in different
+   * EU member states.
+   *
+   * @generated from field: bool reverseCharge = 34;
+   */
+  reverseCharge = false;
+
+  /**
+   * Issuer the invoice is issued from, 0 for the workspace itself.
+   *
+   * @generated from field: uint32 issuerId = 35;
+   */
+  issuerId = 0;
+
+  /**
+   * Reference the client asked to quote, e.g. the Leitweg-ID of German
+   * public authorities. It is the BuyerReference of the UBL export.
+   *
+   * @generated from field: string buyerReference = 36;
+   */
+  buyerReference = "";
+
+  constructor(data?: PartialMessage<Invoice>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Invoice";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "status", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "number", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "clientId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 5, name: "templateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 6, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "language", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "issueDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "dueDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 10, name: "serviceDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 11, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "items", kind: "message", T: LineItem, repeated: true },
+    { no: 13, name: "discounts", kind: "message", T: Discount, repeated: true },
+    { no: 14, name: "totals", kind: "message", T: Totals },
+    { no: 15, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 16, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 17, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 18, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 19, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 20, name: "snapshot", kind: "message", T: InvoiceSnapshot },
+    { no: 21, name: "issuedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 22, name: "paid", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 23, name: "balance", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 24, name: "kind", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 25, name: "originalId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 26, name: "originalNumber", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 27, name: "credited", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 28, name: "converted", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 29, name: "share", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 30, name: "baseCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 31, name: "exchangeRate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 32, name: "baseGross", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 33, name: "pricesIncludeTax", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 34, name: "reverseCharge", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 35, name: "issuerId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 36, name: "buyerReference", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Invoice {
+    return new Invoice().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Invoice {
+    return new Invoice().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Invoice {
+    return new Invoice().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Invoice | PlainMessage<Invoice> | undefined, b: Invoice | PlainMessage<Invoice> | undefined): boolean {
+    return proto3.util.equals(Invoice, a, b);
+  }
+}
+
+/**
+ * SaveInvoiceRequest creates a draft or replaces all of its fields. Fields
+ * left empty are taken from the defaults of the issuer and then those of
+ * the client. The due date of a quote is the date it is valid until.
+ *
+ * @generated from message proto.SaveInvoiceRequest
+ */
+export class SaveInvoiceRequest extends Message<SaveInvoiceRequest> {
+  /**
+   * @generated from field: uint32 clientId = 1;
+   */
+  clientId = 0;
+
+  /**
+   * @generated from field: uint32 templateId = 2;
+   */
+  templateId = 0;
+
+  /**
+   * @generated from field: string currency = 3;
+   */
+  currency = "";
+
+  /**
+   * @generated from field: string language = 4;
+   */
+  language = "";
+
+  /**
+   * @generated from field: string issueDate = 5;
+   */
+  issueDate = "";
+
+  /**
+   * @generated from field: string dueDate = 6;
+   */
+  dueDate = "";
+
+  /**
+   * @generated from field: string serviceDate = 7;
+   */
+  serviceDate = "";
+
+  /**
+   * @generated from field: string notes = 8;
+   */
+  notes = "";
+
+  /**
+   * @generated from field: repeated proto.LineItem items = 9;
+   */
+  items: LineItem[] = [];
+
+  /**
+   * @generated from field: repeated proto.Discount discounts = 10;
+   */
+  discounts: Discount[] = [];
+
+  /**
+   * invoice or quote, invoice if empty. Drafts keep their kind.
+   *
+   * @generated from field: string kind = 11;
+   */
+  kind = "";
+
+  /**
+   * Rate to the base currency of the workspace, looked up in the exchange
+   * rates of the issue date when the invoice is issued if empty.
+   *
+   * @generated from field: string exchangeRate = 12;
+   */
+  exchangeRate = "";
+
+  /**
+   * @generated from field: bool pricesIncludeTax = 13;
+   */
+  pricesIncludeTax = false;
+
+  /**
+   * @generated from field: uint32 issuerId = 14;
+   */
+  issuerId = 0;
+
+  /**
+   * @generated from field: string buyerReference = 15;
+   */
+  buyerReference = "";
+
+  constructor(data?: PartialMessage<SaveInvoiceRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveInvoiceRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "clientId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "templateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "language", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "issueDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "dueDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "serviceDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "items", kind: "message", T: LineItem, repeated: true },
+    { no: 10, name: "discounts", kind: "message", T: Discount, repeated: true },
+    { no: 11, name: "kind", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "exchangeRate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 13, name: "pricesIncludeTax", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 14, name: "issuerId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 15, name: "buyerReference", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveInvoiceRequest {
+    return new SaveInvoiceRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveInvoiceRequest {
+    return new SaveInvoiceRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveInvoiceRequest {
+    return new SaveInvoiceRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveInvoiceRequest | PlainMessage<SaveInvoiceRequest> | undefined, b: SaveInvoiceRequest | PlainMessage<SaveInvoiceRequest> | undefined): boolean {
+    return proto3.util.equals(SaveInvoiceRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.InvoiceResponse
+ */
+export class InvoiceResponse extends Message<InvoiceResponse> {
+  /**
+   * @generated from field: proto.Invoice invoice = 1;
+   */
+  invoice?: Invoice;
+
+  constructor(data?: PartialMessage<InvoiceResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.InvoiceResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "invoice", kind: "message", T: Invoice },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): InvoiceResponse {
+    return new InvoiceResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): InvoiceResponse {
+    return new InvoiceResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): InvoiceResponse {
+    return new InvoiceResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: InvoiceResponse | PlainMessage<InvoiceResponse> | undefined, b: InvoiceResponse | PlainMessage<InvoiceResponse> | undefined): boolean {
+    return proto3.util.equals(InvoiceResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetInvoicesResponse
+ */
+export class GetInvoicesResponse extends Message<GetInvoicesResponse> {
+  /**
+   * @generated from field: repeated proto.Invoice invoices = 1;
+   */
+  invoices: Invoice[] = [];
+
+  /**
+   * @generated from field: uint32 total = 2;
+   */
+  total = 0;
+
+  constructor(data?: PartialMessage<GetInvoicesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetInvoicesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "invoices", kind: "message", T: Invoice, repeated: true },
+    { no: 2, name: "total", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetInvoicesResponse {
+    return new GetInvoicesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetInvoicesResponse {
+    return new GetInvoicesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetInvoicesResponse {
+    return new GetInvoicesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetInvoicesResponse | PlainMessage<GetInvoicesResponse> | undefined, b: GetInvoicesResponse | PlainMessage<GetInvoicesResponse> | undefined): boolean {
+    return proto3.util.equals(GetInvoicesResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.InvoiceEvent
+ */
+export class InvoiceEvent extends Message<InvoiceEvent> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 invoiceId = 2;
+   */
+  invoiceId = 0;
+
+  /**
+   * @generated from field: string fromStatus = 3;
+   */
+  fromStatus = "";
+
+  /**
+   * @generated from field: string toStatus = 4;
+   */
+  toStatus = "";
+
+  /**
+   * @generated from field: string note = 5;
+   */
+  note = "";
+
+  /**
+   * @generated from field: uint32 actorUserId = 6;
+   */
+  actorUserId = 0;
+
+  /**
+   * @generated from field: int64 createdAt = 7;
+   */
+  createdAt = protoInt64.zero;
+
+  constructor(data?: PartialMessage<InvoiceEvent>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.InvoiceEvent";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "invoiceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "fromStatus", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "toStatus", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "note", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "actorUserId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 7, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): InvoiceEvent {
+    return new InvoiceEvent().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): InvoiceEvent {
+    return new InvoiceEvent().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): InvoiceEvent {
+    return new InvoiceEvent().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: InvoiceEvent | PlainMessage<InvoiceEvent> | undefined, b: InvoiceEvent | PlainMessage<InvoiceEvent> | undefined): boolean {
+    return proto3.util.equals(InvoiceEvent, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetInvoiceEventsResponse
+ */
+export class GetInvoiceEventsResponse extends Message<GetInvoiceEventsResponse> {
+  /**
+   * @generated from field: repeated proto.InvoiceEvent events = 1;
+   */
+  events: InvoiceEvent[] = [];
+
+  constructor(data?: PartialMessage<GetInvoiceEventsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetInvoiceEventsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "events", kind: "message", T: InvoiceEvent, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetInvoiceEventsResponse {
+    return new GetInvoiceEventsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetInvoiceEventsResponse {
+    return new GetInvoiceEventsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetInvoiceEventsResponse {
+    return new GetInvoiceEventsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetInvoiceEventsResponse | PlainMessage<GetInvoiceEventsResponse> | undefined, b: GetInvoiceEventsResponse | PlainMessage<GetInvoiceEventsResponse> | undefined): boolean {
+    return proto3.util.equals(GetInvoiceEventsResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreditNoteLine
+ */
+export class CreditNoteLine extends Message<CreditNoteLine> {
+  /**
+   * @generated from field: uint32 lineItemId = 1;
+   */
+  lineItemId = 0;
+
+  /**
+   * How much of the quantity of the line to credit, all of it if empty.
+   *
+   * @generated from field: string quantity = 2;
+   */
+  quantity = "";
+
+  constructor(data?: PartialMessage<CreditNoteLine>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreditNoteLine";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "lineItemId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "quantity", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreditNoteLine {
+    return new CreditNoteLine().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreditNoteLine {
+    return new CreditNoteLine().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreditNoteLine {
+    return new CreditNoteLine().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreditNoteLine | PlainMessage<CreditNoteLine> | undefined, b: CreditNoteLine | PlainMessage<CreditNoteLine> | undefined): boolean {
+    return proto3.util.equals(CreditNoteLine, a, b);
+  }
+}
+
+/**
+ * CreateCreditNoteRequest credits the given lines of an invoice, or all of
+ * them if none are given.
+ *
+ * @generated from message proto.CreateCreditNoteRequest
+ */
+export class CreateCreditNoteRequest extends Message<CreateCreditNoteRequest> {
+  /**
+   * @generated from field: repeated proto.CreditNoteLine items = 1;
+   */
+  items: CreditNoteLine[] = [];
+
+  /**
+   * @generated from field: string reason = 2;
+   */
+  reason = "";
+
+  constructor(data?: PartialMessage<CreateCreditNoteRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateCreditNoteRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "items", kind: "message", T: CreditNoteLine, repeated: true },
+    { no: 2, name: "reason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateCreditNoteRequest {
+    return new CreateCreditNoteRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateCreditNoteRequest {
+    return new CreateCreditNoteRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateCreditNoteRequest {
+    return new CreateCreditNoteRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateCreditNoteRequest | PlainMessage<CreateCreditNoteRequest> | undefined, b: CreateCreditNoteRequest | PlainMessage<CreateCreditNoteRequest> | undefined): boolean {
+    return proto3.util.equals(CreateCreditNoteRequest, a, b);
+  }
+}
+
+/**
+ * ConvertQuoteRequest converts percent of an accepted quote into a draft
+ * invoice, e.g. "30" for a milestone, or all that is left if empty.
+ *
+ * @generated from message proto.ConvertQuoteRequest
+ */
+export class ConvertQuoteRequest extends Message<ConvertQuoteRequest> {
+  /**
+   * @generated from field: string percent = 1;
+   */
+  percent = "";
+
+  constructor(data?: PartialMessage<ConvertQuoteRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ConvertQuoteRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "percent", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ConvertQuoteRequest {
+    return new ConvertQuoteRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ConvertQuoteRequest {
+    return new ConvertQuoteRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ConvertQuoteRequest {
+    return new ConvertQuoteRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ConvertQuoteRequest | PlainMessage<ConvertQuoteRequest> | undefined, b: ConvertQuoteRequest | PlainMessage<ConvertQuoteRequest> | undefined): boolean {
+    return proto3.util.equals(ConvertQuoteRequest, a, b);
+  }
+}
+
+/**
+ * TransitionInvoiceRequest moves an invoice to another status, e.g. void,
+ * with an optional note on why.
+ *
+ * @generated from message proto.TransitionInvoiceRequest
+ */
+export class TransitionInvoiceRequest extends Message<TransitionInvoiceRequest> {
+  /**
+   * @generated from field: string note = 1;
+   */
+  note = "";
+
+  constructor(data?: PartialMessage<TransitionInvoiceRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.TransitionInvoiceRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "note", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TransitionInvoiceRequest {
+    return new TransitionInvoiceRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TransitionInvoiceRequest {
+    return new TransitionInvoiceRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TransitionInvoiceRequest {
+    return new TransitionInvoiceRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TransitionInvoiceRequest | PlainMessage<TransitionInvoiceRequest> | undefined, b: TransitionInvoiceRequest | PlainMessage<TransitionInvoiceRequest> | undefined): boolean {
+    return proto3.util.equals(TransitionInvoiceRequest, a, b);
+  }
+}
+
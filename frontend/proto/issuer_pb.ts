@@ -0,0 +1,426 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file issuer.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+import { Address } from "./client_pb.ts";
+
+/**
+ * @generated from message proto.BankAccount
+ */
+export class BankAccount extends Message<BankAccount> {
+  /**
+   * @generated from field: string bankName = 1;
+   */
+  bankName = "";
+
+  /**
+   * Name the account is held in, the legal name of the issuer if empty.
+   *
+   * @generated from field: string accountHolder = 2;
+   */
+  accountHolder = "";
+
+  /**
+   * Stored without spaces, e.g. DE89370400440532013000.
+   *
+   * @generated from field: string iban = 3;
+   */
+  iban = "";
+
+  /**
+   * @generated from field: string bic = 4;
+   */
+  bic = "";
+
+  /**
+   * Invoices print the primary account, or the first one without one.
+   *
+   * @generated from field: bool primary = 5;
+   */
+  primary = false;
+
+  constructor(data?: PartialMessage<BankAccount>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.BankAccount";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "bankName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "accountHolder", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "iban", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "bic", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "primary", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): BankAccount {
+    return new BankAccount().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): BankAccount {
+    return new BankAccount().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): BankAccount {
+    return new BankAccount().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: BankAccount | PlainMessage<BankAccount> | undefined, b: BankAccount | PlainMessage<BankAccount> | undefined): boolean {
+    return proto3.util.equals(BankAccount, a, b);
+  }
+}
+
+/**
+ * RegistrationNumber is how the issuer is registered, e.g. "Commercial
+ * register" and "HRB 12345 B, Amtsgericht Charlottenburg".
+ *
+ * @generated from message proto.RegistrationNumber
+ */
+export class RegistrationNumber extends Message<RegistrationNumber> {
+  /**
+   * @generated from field: string label = 1;
+   */
+  label = "";
+
+  /**
+   * @generated from field: string value = 2;
+   */
+  value = "";
+
+  constructor(data?: PartialMessage<RegistrationNumber>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.RegistrationNumber";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "label", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "value", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RegistrationNumber {
+    return new RegistrationNumber().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RegistrationNumber {
+    return new RegistrationNumber().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RegistrationNumber {
+    return new RegistrationNumber().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RegistrationNumber | PlainMessage<RegistrationNumber> | undefined, b: RegistrationNumber | PlainMessage<RegistrationNumber> | undefined): boolean {
+    return proto3.util.equals(RegistrationNumber, a, b);
+  }
+}
+
+/**
+ * Issuer is a legal entity of the workspace that invoices are issued from.
+ * Documents of an issuer are numbered with the sequences saved with its ID,
+ * or those of the workspace if it has none.
+ *
+ * @generated from message proto.Issuer
+ */
+export class Issuer extends Message<Issuer> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * Tells issuers apart when picking one, e.g. "Consulting".
+   *
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string legalName = 3;
+   */
+  legalName = "";
+
+  /**
+   * @generated from field: proto.Address address = 4;
+   */
+  address?: Address;
+
+  /**
+   * @generated from field: string taxId = 5;
+   */
+  taxId = "";
+
+  /**
+   * Decides with the country of the address whether EU clients are charged
+   * VAT, instead of the tax details of the workspace.
+   *
+   * @generated from field: string vatId = 6;
+   */
+  vatId = "";
+
+  /**
+   * @generated from field: repeated proto.RegistrationNumber registrationNumbers = 7;
+   */
+  registrationNumbers: RegistrationNumber[] = [];
+
+  /**
+   * @generated from field: repeated proto.BankAccount bankAccounts = 8;
+   */
+  bankAccounts: BankAccount[] = [];
+
+  /**
+   * Path of the logo below the static directory, empty without one.
+   *
+   * @generated from field: string logo = 9;
+   */
+  logo = "";
+
+  /**
+   * Template of documents of the issuer that don't name one.
+   *
+   * @generated from field: uint32 defaultTemplateId = 10;
+   */
+  defaultTemplateId = 0;
+
+  /**
+   * Legal text printed in the footer, e.g. the managing directors.
+   *
+   * @generated from field: string footer = 11;
+   */
+  footer = "";
+
+  /**
+   * @generated from field: int64 createdAt = 12;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 13;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 14;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 15;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 16;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<Issuer>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Issuer";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "legalName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "address", kind: "message", T: Address },
+    { no: 5, name: "taxId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "vatId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "registrationNumbers", kind: "message", T: RegistrationNumber, repeated: true },
+    { no: 8, name: "bankAccounts", kind: "message", T: BankAccount, repeated: true },
+    { no: 9, name: "logo", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 10, name: "defaultTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "footer", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 13, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 14, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 15, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 16, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Issuer {
+    return new Issuer().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Issuer {
+    return new Issuer().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Issuer {
+    return new Issuer().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Issuer | PlainMessage<Issuer> | undefined, b: Issuer | PlainMessage<Issuer> | undefined): boolean {
+    return proto3.util.equals(Issuer, a, b);
+  }
+}
+
+/**
+ * SaveIssuerRequest creates an issuer or replaces all of its fields but the
+ * logo, which is uploaded on its own.
+ *
+ * @generated from message proto.SaveIssuerRequest
+ */
+export class SaveIssuerRequest extends Message<SaveIssuerRequest> {
+  /**
+   * @generated from field: string name = 1;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string legalName = 2;
+   */
+  legalName = "";
+
+  /**
+   * @generated from field: proto.Address address = 3;
+   */
+  address?: Address;
+
+  /**
+   * @generated from field: string taxId = 4;
+   */
+  taxId = "";
+
+  /**
+   * @generated from field: string vatId = 5;
+   */
+  vatId = "";
+
+  /**
+   * @generated from field: repeated proto.RegistrationNumber registrationNumbers = 6;
+   */
+  registrationNumbers: RegistrationNumber[] = [];
+
+  /**
+   * @generated from field: repeated proto.BankAccount bankAccounts = 7;
+   */
+  bankAccounts: BankAccount[] = [];
+
+  /**
+   * @generated from field: uint32 defaultTemplateId = 8;
+   */
+  defaultTemplateId = 0;
+
+  /**
+   * @generated from field: string footer = 9;
+   */
+  footer = "";
+
+  constructor(data?: PartialMessage<SaveIssuerRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveIssuerRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "legalName", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "address", kind: "message", T: Address },
+    { no: 4, name: "taxId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "vatId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "registrationNumbers", kind: "message", T: RegistrationNumber, repeated: true },
+    { no: 7, name: "bankAccounts", kind: "message", T: BankAccount, repeated: true },
+    { no: 8, name: "defaultTemplateId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 9, name: "footer", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveIssuerRequest {
+    return new SaveIssuerRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveIssuerRequest {
+    return new SaveIssuerRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveIssuerRequest {
+    return new SaveIssuerRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveIssuerRequest | PlainMessage<SaveIssuerRequest> | undefined, b: SaveIssuerRequest | PlainMessage<SaveIssuerRequest> | undefined): boolean {
+    return proto3.util.equals(SaveIssuerRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.IssuerResponse
+ */
+export class IssuerResponse extends Message<IssuerResponse> {
+  /**
+   * @generated from field: proto.Issuer issuer = 1;
+   */
+  issuer?: Issuer;
+
+  constructor(data?: PartialMessage<IssuerResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.IssuerResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "issuer", kind: "message", T: Issuer },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): IssuerResponse {
+    return new IssuerResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): IssuerResponse {
+    return new IssuerResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): IssuerResponse {
+    return new IssuerResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: IssuerResponse | PlainMessage<IssuerResponse> | undefined, b: IssuerResponse | PlainMessage<IssuerResponse> | undefined): boolean {
+    return proto3.util.equals(IssuerResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetIssuersResponse
+ */
+export class GetIssuersResponse extends Message<GetIssuersResponse> {
+  /**
+   * @generated from field: repeated proto.Issuer issuers = 1;
+   */
+  issuers: Issuer[] = [];
+
+  constructor(data?: PartialMessage<GetIssuersResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetIssuersResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "issuers", kind: "message", T: Issuer, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetIssuersResponse {
+    return new GetIssuersResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetIssuersResponse {
+    return new GetIssuersResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetIssuersResponse {
+    return new GetIssuersResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetIssuersResponse | PlainMessage<GetIssuersResponse> | undefined, b: GetIssuersResponse | PlainMessage<GetIssuersResponse> | undefined): boolean {
+    return proto3.util.equals(GetIssuersResponse, a, b);
+  }
+}
+
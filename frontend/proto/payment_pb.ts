@@ -0,0 +1,626 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file payment.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+import { Invoice } from "./invoice_pb.ts";
+
+/**
+ * PaymentAllocation is the part of a payment that settles an invoice.
+ *
+ * @generated from message proto.PaymentAllocation
+ */
+export class PaymentAllocation extends Message<PaymentAllocation> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 paymentId = 2;
+   */
+  paymentId = 0;
+
+  /**
+   * @generated from field: uint32 invoiceId = 3;
+   */
+  invoiceId = 0;
+
+  /**
+   * @generated from field: string amount = 4;
+   */
+  amount = "";
+
+  /**
+   * Whether the amount was left over as credit and applied later on.
+   *
+   * @generated from field: bool fromCredit = 5;
+   */
+  fromCredit = false;
+
+  /**
+   * @generated from field: int64 createdAt = 6;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 7;
+   */
+  createdBy = 0;
+
+  constructor(data?: PartialMessage<PaymentAllocation>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.PaymentAllocation";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "paymentId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "invoiceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 4, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "fromCredit", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 6, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 7, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PaymentAllocation {
+    return new PaymentAllocation().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PaymentAllocation {
+    return new PaymentAllocation().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PaymentAllocation {
+    return new PaymentAllocation().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PaymentAllocation | PlainMessage<PaymentAllocation> | undefined, b: PaymentAllocation | PlainMessage<PaymentAllocation> | undefined): boolean {
+    return proto3.util.equals(PaymentAllocation, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.Payment
+ */
+export class Payment extends Message<Payment> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 clientId = 2;
+   */
+  clientId = 0;
+
+  /**
+   * @generated from field: string amount = 3;
+   */
+  amount = "";
+
+  /**
+   * ISO 4217 code, e.g. EUR.
+   *
+   * @generated from field: string currency = 4;
+   */
+  currency = "";
+
+  /**
+   * Formatted as YYYY-MM-DD.
+   *
+   * @generated from field: string date = 5;
+   */
+  date = "";
+
+  /**
+   * @generated from field: string method = 6;
+   */
+  method = "";
+
+  /**
+   * Bank reference, transaction ID or similar.
+   *
+   * @generated from field: string reference = 7;
+   */
+  reference = "";
+
+  /**
+   * @generated from field: string notes = 8;
+   */
+  notes = "";
+
+  /**
+   * @generated from field: repeated proto.PaymentAllocation allocations = 9;
+   */
+  allocations: PaymentAllocation[] = [];
+
+  /**
+   * What is not allocated to any invoice, the credit of the client.
+   *
+   * @generated from field: string unallocated = 10;
+   */
+  unallocated = "";
+
+  /**
+   * @generated from field: int64 reversedAt = 11;
+   */
+  reversedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 reversedBy = 12;
+   */
+  reversedBy = 0;
+
+  /**
+   * @generated from field: string reversalReason = 13;
+   */
+  reversalReason = "";
+
+  /**
+   * @generated from field: int64 createdAt = 14;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 15;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 16;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<Payment>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Payment";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "clientId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "date", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "method", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "reference", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "allocations", kind: "message", T: PaymentAllocation, repeated: true },
+    { no: 10, name: "unallocated", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 11, name: "reversedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 12, name: "reversedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 13, name: "reversalReason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 14, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 15, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 16, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Payment {
+    return new Payment().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Payment {
+    return new Payment().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Payment {
+    return new Payment().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Payment | PlainMessage<Payment> | undefined, b: Payment | PlainMessage<Payment> | undefined): boolean {
+    return proto3.util.equals(Payment, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.AllocationRequest
+ */
+export class AllocationRequest extends Message<AllocationRequest> {
+  /**
+   * @generated from field: uint32 invoiceId = 1;
+   */
+  invoiceId = 0;
+
+  /**
+   * @generated from field: string amount = 2;
+   */
+  amount = "";
+
+  constructor(data?: PartialMessage<AllocationRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.AllocationRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "invoiceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AllocationRequest {
+    return new AllocationRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AllocationRequest {
+    return new AllocationRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AllocationRequest {
+    return new AllocationRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AllocationRequest | PlainMessage<AllocationRequest> | undefined, b: AllocationRequest | PlainMessage<AllocationRequest> | undefined): boolean {
+    return proto3.util.equals(AllocationRequest, a, b);
+  }
+}
+
+/**
+ * SavePaymentRequest records a payment of a client. Whatever isn't allocated
+ * to its invoices becomes credit of the client.
+ *
+ * @generated from message proto.SavePaymentRequest
+ */
+export class SavePaymentRequest extends Message<SavePaymentRequest> {
+  /**
+   * @generated from field: uint32 clientId = 1;
+   */
+  clientId = 0;
+
+  /**
+   * @generated from field: string amount = 2;
+   */
+  amount = "";
+
+  /**
+   * @generated from field: string currency = 3;
+   */
+  currency = "";
+
+  /**
+   * @generated from field: string date = 4;
+   */
+  date = "";
+
+  /**
+   * @generated from field: string method = 5;
+   */
+  method = "";
+
+  /**
+   * @generated from field: string reference = 6;
+   */
+  reference = "";
+
+  /**
+   * @generated from field: string notes = 7;
+   */
+  notes = "";
+
+  /**
+   * @generated from field: repeated proto.AllocationRequest allocations = 8;
+   */
+  allocations: AllocationRequest[] = [];
+
+  constructor(data?: PartialMessage<SavePaymentRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SavePaymentRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "clientId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "date", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "method", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "reference", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "notes", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "allocations", kind: "message", T: AllocationRequest, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SavePaymentRequest {
+    return new SavePaymentRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SavePaymentRequest {
+    return new SavePaymentRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SavePaymentRequest {
+    return new SavePaymentRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SavePaymentRequest | PlainMessage<SavePaymentRequest> | undefined, b: SavePaymentRequest | PlainMessage<SavePaymentRequest> | undefined): boolean {
+    return proto3.util.equals(SavePaymentRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.PaymentResponse
+ */
+export class PaymentResponse extends Message<PaymentResponse> {
+  /**
+   * @generated from field: proto.Payment payment = 1;
+   */
+  payment?: Payment;
+
+  constructor(data?: PartialMessage<PaymentResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.PaymentResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "payment", kind: "message", T: Payment },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): PaymentResponse {
+    return new PaymentResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): PaymentResponse {
+    return new PaymentResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): PaymentResponse {
+    return new PaymentResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: PaymentResponse | PlainMessage<PaymentResponse> | undefined, b: PaymentResponse | PlainMessage<PaymentResponse> | undefined): boolean {
+    return proto3.util.equals(PaymentResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetPaymentsResponse
+ */
+export class GetPaymentsResponse extends Message<GetPaymentsResponse> {
+  /**
+   * @generated from field: repeated proto.Payment payments = 1;
+   */
+  payments: Payment[] = [];
+
+  constructor(data?: PartialMessage<GetPaymentsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetPaymentsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "payments", kind: "message", T: Payment, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetPaymentsResponse {
+    return new GetPaymentsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetPaymentsResponse {
+    return new GetPaymentsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetPaymentsResponse {
+    return new GetPaymentsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetPaymentsResponse | PlainMessage<GetPaymentsResponse> | undefined, b: GetPaymentsResponse | PlainMessage<GetPaymentsResponse> | undefined): boolean {
+    return proto3.util.equals(GetPaymentsResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ReversePaymentRequest
+ */
+export class ReversePaymentRequest extends Message<ReversePaymentRequest> {
+  /**
+   * @generated from field: string reason = 1;
+   */
+  reason = "";
+
+  constructor(data?: PartialMessage<ReversePaymentRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ReversePaymentRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "reason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ReversePaymentRequest {
+    return new ReversePaymentRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ReversePaymentRequest {
+    return new ReversePaymentRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ReversePaymentRequest {
+    return new ReversePaymentRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ReversePaymentRequest | PlainMessage<ReversePaymentRequest> | undefined, b: ReversePaymentRequest | PlainMessage<ReversePaymentRequest> | undefined): boolean {
+    return proto3.util.equals(ReversePaymentRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreditBalance
+ */
+export class CreditBalance extends Message<CreditBalance> {
+  /**
+   * @generated from field: string currency = 1;
+   */
+  currency = "";
+
+  /**
+   * @generated from field: string amount = 2;
+   */
+  amount = "";
+
+  constructor(data?: PartialMessage<CreditBalance>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreditBalance";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "currency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreditBalance {
+    return new CreditBalance().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreditBalance {
+    return new CreditBalance().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreditBalance {
+    return new CreditBalance().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreditBalance | PlainMessage<CreditBalance> | undefined, b: CreditBalance | PlainMessage<CreditBalance> | undefined): boolean {
+    return proto3.util.equals(CreditBalance, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetCreditResponse
+ */
+export class GetCreditResponse extends Message<GetCreditResponse> {
+  /**
+   * @generated from field: repeated proto.CreditBalance balances = 1;
+   */
+  balances: CreditBalance[] = [];
+
+  constructor(data?: PartialMessage<GetCreditResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetCreditResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "balances", kind: "message", T: CreditBalance, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetCreditResponse {
+    return new GetCreditResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetCreditResponse {
+    return new GetCreditResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetCreditResponse {
+    return new GetCreditResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetCreditResponse | PlainMessage<GetCreditResponse> | undefined, b: GetCreditResponse | PlainMessage<GetCreditResponse> | undefined): boolean {
+    return proto3.util.equals(GetCreditResponse, a, b);
+  }
+}
+
+/**
+ * ApplyCreditRequest settles an invoice with the credit of its client, the
+ * oldest credit is used first.
+ *
+ * @generated from message proto.ApplyCreditRequest
+ */
+export class ApplyCreditRequest extends Message<ApplyCreditRequest> {
+  /**
+   * @generated from field: uint32 invoiceId = 1;
+   */
+  invoiceId = 0;
+
+  /**
+   * @generated from field: string amount = 2;
+   */
+  amount = "";
+
+  constructor(data?: PartialMessage<ApplyCreditRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ApplyCreditRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "invoiceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "amount", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ApplyCreditRequest {
+    return new ApplyCreditRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ApplyCreditRequest {
+    return new ApplyCreditRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ApplyCreditRequest {
+    return new ApplyCreditRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ApplyCreditRequest | PlainMessage<ApplyCreditRequest> | undefined, b: ApplyCreditRequest | PlainMessage<ApplyCreditRequest> | undefined): boolean {
+    return proto3.util.equals(ApplyCreditRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.ApplyCreditResponse
+ */
+export class ApplyCreditResponse extends Message<ApplyCreditResponse> {
+  /**
+   * @generated from field: proto.Invoice invoice = 1;
+   */
+  invoice?: Invoice;
+
+  /**
+   * @generated from field: repeated proto.PaymentAllocation allocations = 2;
+   */
+  allocations: PaymentAllocation[] = [];
+
+  constructor(data?: PartialMessage<ApplyCreditResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.ApplyCreditResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "invoice", kind: "message", T: Invoice },
+    { no: 2, name: "allocations", kind: "message", T: PaymentAllocation, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): ApplyCreditResponse {
+    return new ApplyCreditResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): ApplyCreditResponse {
+    return new ApplyCreditResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): ApplyCreditResponse {
+    return new ApplyCreditResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: ApplyCreditResponse | PlainMessage<ApplyCreditResponse> | undefined, b: ApplyCreditResponse | PlainMessage<ApplyCreditResponse> | undefined): boolean {
+    return proto3.util.equals(ApplyCreditResponse, a, b);
+  }
+}
+
@@ -0,0 +1,161 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file quota.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.QuotaUsage
+ */
+export class QuotaUsage extends Message<QuotaUsage> {
+  /**
+   * @generated from field: string quota = 1;
+   */
+  quota = "";
+
+  /**
+   * @generated from field: int64 used = 2;
+   */
+  used = protoInt64.zero;
+
+  /**
+   * 0 means unlimited.
+   *
+   * @generated from field: int64 limit = 3;
+   */
+  limit = protoInt64.zero;
+
+  /**
+   * Unix time the usage is counted from, 0 for quotas without a window.
+   *
+   * @generated from field: int64 windowStart = 4;
+   */
+  windowStart = protoInt64.zero;
+
+  constructor(data?: PartialMessage<QuotaUsage>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.QuotaUsage";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "quota", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "used", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 3, name: "limit", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 4, name: "windowStart", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): QuotaUsage {
+    return new QuotaUsage().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): QuotaUsage {
+    return new QuotaUsage().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): QuotaUsage {
+    return new QuotaUsage().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: QuotaUsage | PlainMessage<QuotaUsage> | undefined, b: QuotaUsage | PlainMessage<QuotaUsage> | undefined): boolean {
+    return proto3.util.equals(QuotaUsage, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.RateLimitStatus
+ */
+export class RateLimitStatus extends Message<RateLimitStatus> {
+  /**
+   * @generated from field: int64 limit = 1;
+   */
+  limit = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 remaining = 2;
+   */
+  remaining = protoInt64.zero;
+
+  /**
+   * Seconds until the bucket is full again.
+   *
+   * @generated from field: int64 resetIn = 3;
+   */
+  resetIn = protoInt64.zero;
+
+  constructor(data?: PartialMessage<RateLimitStatus>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.RateLimitStatus";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "limit", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 2, name: "remaining", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 3, name: "resetIn", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RateLimitStatus {
+    return new RateLimitStatus().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RateLimitStatus {
+    return new RateLimitStatus().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RateLimitStatus {
+    return new RateLimitStatus().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RateLimitStatus | PlainMessage<RateLimitStatus> | undefined, b: RateLimitStatus | PlainMessage<RateLimitStatus> | undefined): boolean {
+    return proto3.util.equals(RateLimitStatus, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetUsageResponse
+ */
+export class GetUsageResponse extends Message<GetUsageResponse> {
+  /**
+   * @generated from field: repeated proto.QuotaUsage quotas = 1;
+   */
+  quotas: QuotaUsage[] = [];
+
+  /**
+   * @generated from field: proto.RateLimitStatus rateLimit = 2;
+   */
+  rateLimit?: RateLimitStatus;
+
+  constructor(data?: PartialMessage<GetUsageResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetUsageResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "quotas", kind: "message", T: QuotaUsage, repeated: true },
+    { no: 2, name: "rateLimit", kind: "message", T: RateLimitStatus },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetUsageResponse {
+    return new GetUsageResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetUsageResponse {
+    return new GetUsageResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetUsageResponse {
+    return new GetUsageResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetUsageResponse | PlainMessage<GetUsageResponse> | undefined, b: GetUsageResponse | PlainMessage<GetUsageResponse> | undefined): boolean {
+    return proto3.util.equals(GetUsageResponse, a, b);
+  }
+}
+
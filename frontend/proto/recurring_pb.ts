@@ -0,0 +1,421 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file recurring.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+import { SaveInvoiceRequest } from "./invoice_pb.ts";
+
+/**
+ * RecurringSchedule generates an invoice from a template invoice once per
+ * billing period. Descriptions and notes of the template may use
+ * {{period.start}}, {{period.end}}, {{period.year}}, {{period.month}} and
+ * {{period.quarter}}, which resolve to the period of each invoice.
+ *
+ * @generated from message proto.RecurringSchedule
+ */
+export class RecurringSchedule extends Message<RecurringSchedule> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * The invoice of every period. Its issue date is the start of the period
+   * and its due date follows from the payment terms of the client.
+   *
+   * @generated from field: proto.SaveInvoiceRequest invoice = 3;
+   */
+  invoice?: SaveInvoiceRequest;
+
+  /**
+   * weekly, monthly, quarterly, yearly or cron.
+   *
+   * @generated from field: string cadence = 4;
+   */
+  cadence = "";
+
+  /**
+   * Standard cron expression with 5 fields, when the cadence is cron.
+   * Periods start on the days it matches.
+   *
+   * @generated from field: string cron = 5;
+   */
+  cron = "";
+
+  /**
+   * Formatted as YYYY-MM-DD, the first period starts on it.
+   *
+   * @generated from field: string startDate = 6;
+   */
+  startDate = "";
+
+  /**
+   * Optional, no period starts after it.
+   *
+   * @generated from field: string endDate = 7;
+   */
+  endDate = "";
+
+  /**
+   * Whether the invoices are issued right away or left as drafts.
+   *
+   * @generated from field: bool autoIssue = 8;
+   */
+  autoIssue = false;
+
+  /**
+   * @generated from field: bool paused = 9;
+   */
+  paused = false;
+
+  /**
+   * Number of periods generated so far.
+   *
+   * @generated from field: uint32 periods = 10;
+   */
+  periods = 0;
+
+  /**
+   * Start of the next period, which isn't generated if it is past the end
+   * date. Empty if the cron expression never matches again.
+   *
+   * @generated from field: string nextDate = 11;
+   */
+  nextDate = "";
+
+  /**
+   * @generated from field: int64 createdAt = 12;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 13;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 14;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 15;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 16;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<RecurringSchedule>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.RecurringSchedule";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "invoice", kind: "message", T: SaveInvoiceRequest },
+    { no: 4, name: "cadence", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "cron", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "startDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "endDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "autoIssue", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 9, name: "paused", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 10, name: "periods", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "nextDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 12, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 13, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 14, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 15, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 16, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RecurringSchedule {
+    return new RecurringSchedule().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RecurringSchedule {
+    return new RecurringSchedule().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RecurringSchedule {
+    return new RecurringSchedule().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RecurringSchedule | PlainMessage<RecurringSchedule> | undefined, b: RecurringSchedule | PlainMessage<RecurringSchedule> | undefined): boolean {
+    return proto3.util.equals(RecurringSchedule, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SaveRecurringScheduleRequest
+ */
+export class SaveRecurringScheduleRequest extends Message<SaveRecurringScheduleRequest> {
+  /**
+   * @generated from field: string name = 1;
+   */
+  name = "";
+
+  /**
+   * @generated from field: proto.SaveInvoiceRequest invoice = 2;
+   */
+  invoice?: SaveInvoiceRequest;
+
+  /**
+   * @generated from field: string cadence = 3;
+   */
+  cadence = "";
+
+  /**
+   * @generated from field: string cron = 4;
+   */
+  cron = "";
+
+  /**
+   * @generated from field: string startDate = 5;
+   */
+  startDate = "";
+
+  /**
+   * @generated from field: string endDate = 6;
+   */
+  endDate = "";
+
+  /**
+   * @generated from field: bool autoIssue = 7;
+   */
+  autoIssue = false;
+
+  /**
+   * @generated from field: bool paused = 8;
+   */
+  paused = false;
+
+  constructor(data?: PartialMessage<SaveRecurringScheduleRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveRecurringScheduleRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "invoice", kind: "message", T: SaveInvoiceRequest },
+    { no: 3, name: "cadence", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "cron", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "startDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "endDate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "autoIssue", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 8, name: "paused", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveRecurringScheduleRequest {
+    return new SaveRecurringScheduleRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveRecurringScheduleRequest {
+    return new SaveRecurringScheduleRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveRecurringScheduleRequest {
+    return new SaveRecurringScheduleRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveRecurringScheduleRequest | PlainMessage<SaveRecurringScheduleRequest> | undefined, b: SaveRecurringScheduleRequest | PlainMessage<SaveRecurringScheduleRequest> | undefined): boolean {
+    return proto3.util.equals(SaveRecurringScheduleRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.RecurringScheduleResponse
+ */
+export class RecurringScheduleResponse extends Message<RecurringScheduleResponse> {
+  /**
+   * @generated from field: proto.RecurringSchedule schedule = 1;
+   */
+  schedule?: RecurringSchedule;
+
+  constructor(data?: PartialMessage<RecurringScheduleResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.RecurringScheduleResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "schedule", kind: "message", T: RecurringSchedule },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RecurringScheduleResponse {
+    return new RecurringScheduleResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RecurringScheduleResponse {
+    return new RecurringScheduleResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RecurringScheduleResponse {
+    return new RecurringScheduleResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RecurringScheduleResponse | PlainMessage<RecurringScheduleResponse> | undefined, b: RecurringScheduleResponse | PlainMessage<RecurringScheduleResponse> | undefined): boolean {
+    return proto3.util.equals(RecurringScheduleResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetRecurringSchedulesResponse
+ */
+export class GetRecurringSchedulesResponse extends Message<GetRecurringSchedulesResponse> {
+  /**
+   * @generated from field: repeated proto.RecurringSchedule schedules = 1;
+   */
+  schedules: RecurringSchedule[] = [];
+
+  constructor(data?: PartialMessage<GetRecurringSchedulesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetRecurringSchedulesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "schedules", kind: "message", T: RecurringSchedule, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetRecurringSchedulesResponse {
+    return new GetRecurringSchedulesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetRecurringSchedulesResponse {
+    return new GetRecurringSchedulesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetRecurringSchedulesResponse {
+    return new GetRecurringSchedulesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetRecurringSchedulesResponse | PlainMessage<GetRecurringSchedulesResponse> | undefined, b: GetRecurringSchedulesResponse | PlainMessage<GetRecurringSchedulesResponse> | undefined): boolean {
+    return proto3.util.equals(GetRecurringSchedulesResponse, a, b);
+  }
+}
+
+/**
+ * RecurringRun is a period a schedule generated an invoice for.
+ *
+ * @generated from message proto.RecurringRun
+ */
+export class RecurringRun extends Message<RecurringRun> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 scheduleId = 2;
+   */
+  scheduleId = 0;
+
+  /**
+   * @generated from field: string periodStart = 3;
+   */
+  periodStart = "";
+
+  /**
+   * @generated from field: string periodEnd = 4;
+   */
+  periodEnd = "";
+
+  /**
+   * Zero once the invoice is deleted, the period isn't generated again.
+   *
+   * @generated from field: uint32 invoiceId = 5;
+   */
+  invoiceId = 0;
+
+  /**
+   * @generated from field: int64 createdAt = 6;
+   */
+  createdAt = protoInt64.zero;
+
+  constructor(data?: PartialMessage<RecurringRun>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.RecurringRun";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "scheduleId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "periodStart", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "periodEnd", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "invoiceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 6, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): RecurringRun {
+    return new RecurringRun().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): RecurringRun {
+    return new RecurringRun().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): RecurringRun {
+    return new RecurringRun().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: RecurringRun | PlainMessage<RecurringRun> | undefined, b: RecurringRun | PlainMessage<RecurringRun> | undefined): boolean {
+    return proto3.util.equals(RecurringRun, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetRecurringRunsResponse
+ */
+export class GetRecurringRunsResponse extends Message<GetRecurringRunsResponse> {
+  /**
+   * @generated from field: repeated proto.RecurringRun runs = 1;
+   */
+  runs: RecurringRun[] = [];
+
+  constructor(data?: PartialMessage<GetRecurringRunsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetRecurringRunsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "runs", kind: "message", T: RecurringRun, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetRecurringRunsResponse {
+    return new GetRecurringRunsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetRecurringRunsResponse {
+    return new GetRecurringRunsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetRecurringRunsResponse {
+    return new GetRecurringRunsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetRecurringRunsResponse | PlainMessage<GetRecurringRunsResponse> | undefined, b: GetRecurringRunsResponse | PlainMessage<GetRecurringRunsResponse> | undefined): boolean {
+    return proto3.util.equals(GetRecurringRunsResponse, a, b);
+  }
+}
+
@@ -0,0 +1,321 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file role.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * @generated from message proto.Role
+ */
+export class Role extends Message<Role> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 2;
+   */
+  workspaceId = 0;
+
+  /**
+   * @generated from field: string name = 3;
+   */
+  name = "";
+
+  /**
+   * @generated from field: repeated string permissions = 4;
+   */
+  permissions: string[] = [];
+
+  /**
+   * @generated from field: bool builtIn = 5;
+   */
+  builtIn = false;
+
+  /**
+   * @generated from field: int64 createdAt = 6;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 7;
+   */
+  updatedAt = protoInt64.zero;
+
+  constructor(data?: PartialMessage<Role>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Role";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "permissions", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+    { no: 5, name: "builtIn", kind: "scalar", T: 8 /* ScalarType.BOOL */ },
+    { no: 6, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 7, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Role {
+    return new Role().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Role {
+    return new Role().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Role {
+    return new Role().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Role | PlainMessage<Role> | undefined, b: Role | PlainMessage<Role> | undefined): boolean {
+    return proto3.util.equals(Role, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetRolesResponse
+ */
+export class GetRolesResponse extends Message<GetRolesResponse> {
+  /**
+   * @generated from field: repeated proto.Role roles = 1;
+   */
+  roles: Role[] = [];
+
+  /**
+   * @generated from field: repeated string permissions = 2;
+   */
+  permissions: string[] = [];
+
+  constructor(data?: PartialMessage<GetRolesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetRolesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "roles", kind: "message", T: Role, repeated: true },
+    { no: 2, name: "permissions", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetRolesResponse {
+    return new GetRolesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetRolesResponse {
+    return new GetRolesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetRolesResponse {
+    return new GetRolesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetRolesResponse | PlainMessage<GetRolesResponse> | undefined, b: GetRolesResponse | PlainMessage<GetRolesResponse> | undefined): boolean {
+    return proto3.util.equals(GetRolesResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateRoleRequest
+ */
+export class CreateRoleRequest extends Message<CreateRoleRequest> {
+  /**
+   * @generated from field: string name = 1;
+   */
+  name = "";
+
+  /**
+   * @generated from field: repeated string permissions = 2;
+   */
+  permissions: string[] = [];
+
+  constructor(data?: PartialMessage<CreateRoleRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateRoleRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "permissions", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateRoleRequest {
+    return new CreateRoleRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateRoleRequest {
+    return new CreateRoleRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateRoleRequest {
+    return new CreateRoleRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateRoleRequest | PlainMessage<CreateRoleRequest> | undefined, b: CreateRoleRequest | PlainMessage<CreateRoleRequest> | undefined): boolean {
+    return proto3.util.equals(CreateRoleRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateRoleResponse
+ */
+export class CreateRoleResponse extends Message<CreateRoleResponse> {
+  /**
+   * @generated from field: proto.Role role = 1;
+   */
+  role?: Role;
+
+  constructor(data?: PartialMessage<CreateRoleResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateRoleResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "role", kind: "message", T: Role },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateRoleResponse {
+    return new CreateRoleResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateRoleResponse {
+    return new CreateRoleResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateRoleResponse {
+    return new CreateRoleResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateRoleResponse | PlainMessage<CreateRoleResponse> | undefined, b: CreateRoleResponse | PlainMessage<CreateRoleResponse> | undefined): boolean {
+    return proto3.util.equals(CreateRoleResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.UpdateRoleRequest
+ */
+export class UpdateRoleRequest extends Message<UpdateRoleRequest> {
+  /**
+   * @generated from field: repeated string permissions = 1;
+   */
+  permissions: string[] = [];
+
+  constructor(data?: PartialMessage<UpdateRoleRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.UpdateRoleRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "permissions", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): UpdateRoleRequest {
+    return new UpdateRoleRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): UpdateRoleRequest {
+    return new UpdateRoleRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): UpdateRoleRequest {
+    return new UpdateRoleRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: UpdateRoleRequest | PlainMessage<UpdateRoleRequest> | undefined, b: UpdateRoleRequest | PlainMessage<UpdateRoleRequest> | undefined): boolean {
+    return proto3.util.equals(UpdateRoleRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.UpdateRoleResponse
+ */
+export class UpdateRoleResponse extends Message<UpdateRoleResponse> {
+  /**
+   * @generated from field: proto.Role role = 1;
+   */
+  role?: Role;
+
+  constructor(data?: PartialMessage<UpdateRoleResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.UpdateRoleResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "role", kind: "message", T: Role },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): UpdateRoleResponse {
+    return new UpdateRoleResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): UpdateRoleResponse {
+    return new UpdateRoleResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): UpdateRoleResponse {
+    return new UpdateRoleResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: UpdateRoleResponse | PlainMessage<UpdateRoleResponse> | undefined, b: UpdateRoleResponse | PlainMessage<UpdateRoleResponse> | undefined): boolean {
+    return proto3.util.equals(UpdateRoleResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetPermissionsResponse
+ */
+export class GetPermissionsResponse extends Message<GetPermissionsResponse> {
+  /**
+   * @generated from field: string role = 1;
+   */
+  role = "";
+
+  /**
+   * @generated from field: repeated string permissions = 2;
+   */
+  permissions: string[] = [];
+
+  constructor(data?: PartialMessage<GetPermissionsResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetPermissionsResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "role", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "permissions", kind: "scalar", T: 9 /* ScalarType.STRING */, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetPermissionsResponse {
+    return new GetPermissionsResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetPermissionsResponse {
+    return new GetPermissionsResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetPermissionsResponse {
+    return new GetPermissionsResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetPermissionsResponse | PlainMessage<GetPermissionsResponse> | undefined, b: GetPermissionsResponse | PlainMessage<GetPermissionsResponse> | undefined): boolean {
+    return proto3.util.equals(GetPermissionsResponse, a, b);
+  }
+}
+
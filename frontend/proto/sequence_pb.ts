@@ -0,0 +1,294 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file sequence.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * Sequence numbers one type of document, e.g. invoice, of a workspace or
+ * of one of its issuers.
+ *
+ * @generated from message proto.Sequence
+ */
+export class Sequence extends Message<Sequence> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string documentType = 2;
+   */
+  documentType = "";
+
+  /**
+   * 0 for the sequence used by issuers without one of their own.
+   *
+   * @generated from field: uint32 issuerId = 3;
+   */
+  issuerId = 0;
+
+  /**
+   * Format of the numbers, e.g. INV-{YYYY}-{seq:05}. Patterns can use
+   * {PREFIX}, {YYYY}, {YY}, {MM}, {DD} and {seq}, optionally zero padded
+   * as {seq:05}.
+   *
+   * @generated from field: string pattern = 4;
+   */
+  pattern = "";
+
+  /**
+   * @generated from field: string prefix = 5;
+   */
+  prefix = "";
+
+  /**
+   * When the counter starts over: never, yearly or monthly.
+   *
+   * @generated from field: string resetPeriod = 6;
+   */
+  resetPeriod = "";
+
+  /**
+   * @generated from field: int64 createdAt = 7;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 8;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 9;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 10;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 11;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<Sequence>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Sequence";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "documentType", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "issuerId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 4, name: "pattern", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "prefix", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "resetPeriod", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 8, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 9, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Sequence {
+    return new Sequence().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Sequence {
+    return new Sequence().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Sequence {
+    return new Sequence().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Sequence | PlainMessage<Sequence> | undefined, b: Sequence | PlainMessage<Sequence> | undefined): boolean {
+    return proto3.util.equals(Sequence, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SaveSequenceRequest
+ */
+export class SaveSequenceRequest extends Message<SaveSequenceRequest> {
+  /**
+   * @generated from field: string documentType = 1;
+   */
+  documentType = "";
+
+  /**
+   * @generated from field: uint32 issuerId = 2;
+   */
+  issuerId = 0;
+
+  /**
+   * @generated from field: string pattern = 3;
+   */
+  pattern = "";
+
+  /**
+   * @generated from field: string prefix = 4;
+   */
+  prefix = "";
+
+  /**
+   * @generated from field: string resetPeriod = 5;
+   */
+  resetPeriod = "";
+
+  constructor(data?: PartialMessage<SaveSequenceRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveSequenceRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "documentType", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "issuerId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 3, name: "pattern", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "prefix", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "resetPeriod", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveSequenceRequest {
+    return new SaveSequenceRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveSequenceRequest {
+    return new SaveSequenceRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveSequenceRequest {
+    return new SaveSequenceRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveSequenceRequest | PlainMessage<SaveSequenceRequest> | undefined, b: SaveSequenceRequest | PlainMessage<SaveSequenceRequest> | undefined): boolean {
+    return proto3.util.equals(SaveSequenceRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SequenceResponse
+ */
+export class SequenceResponse extends Message<SequenceResponse> {
+  /**
+   * @generated from field: proto.Sequence sequence = 1;
+   */
+  sequence?: Sequence;
+
+  constructor(data?: PartialMessage<SequenceResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SequenceResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "sequence", kind: "message", T: Sequence },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SequenceResponse {
+    return new SequenceResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SequenceResponse {
+    return new SequenceResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SequenceResponse {
+    return new SequenceResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SequenceResponse | PlainMessage<SequenceResponse> | undefined, b: SequenceResponse | PlainMessage<SequenceResponse> | undefined): boolean {
+    return proto3.util.equals(SequenceResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetSequencesResponse
+ */
+export class GetSequencesResponse extends Message<GetSequencesResponse> {
+  /**
+   * @generated from field: repeated proto.Sequence sequences = 1;
+   */
+  sequences: Sequence[] = [];
+
+  constructor(data?: PartialMessage<GetSequencesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetSequencesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "sequences", kind: "message", T: Sequence, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetSequencesResponse {
+    return new GetSequencesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetSequencesResponse {
+    return new GetSequencesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetSequencesResponse {
+    return new GetSequencesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetSequencesResponse | PlainMessage<GetSequencesResponse> | undefined, b: GetSequencesResponse | PlainMessage<GetSequencesResponse> | undefined): boolean {
+    return proto3.util.equals(GetSequencesResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SequencePreviewResponse
+ */
+export class SequencePreviewResponse extends Message<SequencePreviewResponse> {
+  /**
+   * @generated from field: string number = 1;
+   */
+  number = "";
+
+  /**
+   * @generated from field: proto.Sequence sequence = 2;
+   */
+  sequence?: Sequence;
+
+  constructor(data?: PartialMessage<SequencePreviewResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SequencePreviewResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "number", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "sequence", kind: "message", T: Sequence },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SequencePreviewResponse {
+    return new SequencePreviewResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SequencePreviewResponse {
+    return new SequencePreviewResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SequencePreviewResponse {
+    return new SequencePreviewResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SequencePreviewResponse | PlainMessage<SequencePreviewResponse> | undefined, b: SequencePreviewResponse | PlainMessage<SequencePreviewResponse> | undefined): boolean {
+    return proto3.util.equals(SequencePreviewResponse, a, b);
+  }
+}
+
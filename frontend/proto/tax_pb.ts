@@ -0,0 +1,281 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file tax.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+
+/**
+ * TaxRate is a named rate of a country, valid for a period. Line items
+ * referencing one take its category, percentage and exemption reason.
+ *
+ * @generated from message proto.TaxRate
+ */
+export class TaxRate extends Message<TaxRate> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * E.g. "DE standard" or "Medical services".
+   *
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * ISO 3166-1 alpha-2 code of the country levying the tax, e.g. DE.
+   *
+   * @generated from field: string country = 3;
+   */
+  country = "";
+
+  /**
+   * standard, reduced, zero_rated, exempt or reverse_charge.
+   *
+   * @generated from field: string category = 4;
+   */
+  category = "";
+
+  /**
+   * Percentage, e.g. "19". Only standard and reduced rates have one.
+   *
+   * @generated from field: string rate = 5;
+   */
+  rate = "";
+
+  /**
+   * Dates are formatted as YYYY-MM-DD. validTo is the last day the rate
+   * applies and empty while it is in force.
+   *
+   * @generated from field: string validFrom = 6;
+   */
+  validFrom = "";
+
+  /**
+   * @generated from field: string validTo = 7;
+   */
+  validTo = "";
+
+  /**
+   * Printed on invoices with lines of the rate, e.g. the article of the law
+   * an exemption is based on. Exempt rates need one.
+   *
+   * @generated from field: string exemptionReason = 8;
+   */
+  exemptionReason = "";
+
+  /**
+   * @generated from field: int64 createdAt = 9;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 10;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: uint32 createdBy = 11;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 12;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 13;
+   */
+  workspaceId = 0;
+
+  constructor(data?: PartialMessage<TaxRate>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.TaxRate";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "country", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "category", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "rate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "validFrom", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "validTo", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "exemptionReason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 9, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 10, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 11, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 12, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 13, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TaxRate {
+    return new TaxRate().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TaxRate {
+    return new TaxRate().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TaxRate {
+    return new TaxRate().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TaxRate | PlainMessage<TaxRate> | undefined, b: TaxRate | PlainMessage<TaxRate> | undefined): boolean {
+    return proto3.util.equals(TaxRate, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SaveTaxRateRequest
+ */
+export class SaveTaxRateRequest extends Message<SaveTaxRateRequest> {
+  /**
+   * @generated from field: string name = 1;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string country = 2;
+   */
+  country = "";
+
+  /**
+   * @generated from field: string category = 3;
+   */
+  category = "";
+
+  /**
+   * @generated from field: string rate = 4;
+   */
+  rate = "";
+
+  /**
+   * @generated from field: string validFrom = 5;
+   */
+  validFrom = "";
+
+  /**
+   * @generated from field: string validTo = 6;
+   */
+  validTo = "";
+
+  /**
+   * @generated from field: string exemptionReason = 7;
+   */
+  exemptionReason = "";
+
+  constructor(data?: PartialMessage<SaveTaxRateRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SaveTaxRateRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "country", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "category", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "rate", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 5, name: "validFrom", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 6, name: "validTo", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "exemptionReason", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SaveTaxRateRequest {
+    return new SaveTaxRateRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SaveTaxRateRequest {
+    return new SaveTaxRateRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SaveTaxRateRequest {
+    return new SaveTaxRateRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SaveTaxRateRequest | PlainMessage<SaveTaxRateRequest> | undefined, b: SaveTaxRateRequest | PlainMessage<SaveTaxRateRequest> | undefined): boolean {
+    return proto3.util.equals(SaveTaxRateRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.TaxRateResponse
+ */
+export class TaxRateResponse extends Message<TaxRateResponse> {
+  /**
+   * @generated from field: proto.TaxRate taxRate = 1;
+   */
+  taxRate?: TaxRate;
+
+  constructor(data?: PartialMessage<TaxRateResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.TaxRateResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "taxRate", kind: "message", T: TaxRate },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): TaxRateResponse {
+    return new TaxRateResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): TaxRateResponse {
+    return new TaxRateResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): TaxRateResponse {
+    return new TaxRateResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: TaxRateResponse | PlainMessage<TaxRateResponse> | undefined, b: TaxRateResponse | PlainMessage<TaxRateResponse> | undefined): boolean {
+    return proto3.util.equals(TaxRateResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetTaxRatesResponse
+ */
+export class GetTaxRatesResponse extends Message<GetTaxRatesResponse> {
+  /**
+   * @generated from field: repeated proto.TaxRate taxRates = 1;
+   */
+  taxRates: TaxRate[] = [];
+
+  constructor(data?: PartialMessage<GetTaxRatesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetTaxRatesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "taxRates", kind: "message", T: TaxRate, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetTaxRatesResponse {
+    return new GetTaxRatesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetTaxRatesResponse {
+    return new GetTaxRatesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetTaxRatesResponse {
+    return new GetTaxRatesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetTaxRatesResponse | PlainMessage<GetTaxRatesResponse> | undefined, b: GetTaxRatesResponse | PlainMessage<GetTaxRatesResponse> | undefined): boolean {
+    return proto3.util.equals(GetTaxRatesResponse, a, b);
+  }
+}
+
@@ -50,6 +50,21 @@ export class Template extends Message<Template> {
    */
   updatedAt = protoInt64.zero;
 
+  /**
+   * @generated from field: uint32 createdBy = 9;
+   */
+  createdBy = 0;
+
+  /**
+   * @generated from field: uint32 updatedBy = 10;
+   */
+  updatedBy = 0;
+
+  /**
+   * @generated from field: uint32 workspaceId = 11;
+   */
+  workspaceId = 0;
+
   constructor(data?: PartialMessage<Template>) {
     super();
     proto3.util.initPartial(data, this);
@@ -66,6 +81,9 @@ export class Template extends Message<Template> {
     { no: 6, name: "thumbnail", kind: "scalar", T: 9 /* ScalarType.STRING */ },
     { no: 7, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
     { no: 8, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 9, name: "createdBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 10, name: "updatedBy", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 11, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
   ]);
 
   static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Template {
@@ -0,0 +1,579 @@
+// @generated by protoc-gen-es v1.8.0 with parameter "target=ts,import_extension=.ts"
+// @generated from file workspace.proto (package proto, syntax proto3)
+/* eslint-disable */
+// @ts-nocheck
+
+import type { BinaryReadOptions, FieldList, JsonReadOptions, JsonValue, PartialMessage, PlainMessage } from "@bufbuild/protobuf";
+import { Message, proto3, protoInt64 } from "@bufbuild/protobuf";
+import { User } from "./user_pb.ts";
+
+/**
+ * @generated from message proto.Workspace
+ */
+export class Workspace extends Message<Workspace> {
+  /**
+   * @generated from field: uint32 id = 1;
+   */
+  id = 0;
+
+  /**
+   * @generated from field: string slug = 2;
+   */
+  slug = "";
+
+  /**
+   * @generated from field: string name = 3;
+   */
+  name = "";
+
+  /**
+   * @generated from field: int64 createdAt = 4;
+   */
+  createdAt = protoInt64.zero;
+
+  /**
+   * @generated from field: int64 updatedAt = 5;
+   */
+  updatedAt = protoInt64.zero;
+
+  /**
+   * ISO 4217 code reports convert amounts to, EUR unless set.
+   *
+   * @generated from field: string baseCurrency = 6;
+   */
+  baseCurrency = "";
+
+  /**
+   * ISO 3166-1 alpha-2 code of the country the workspace is established in
+   * and its VAT ID, which tell whether invoices are reverse charged.
+   *
+   * @generated from field: string country = 7;
+   */
+  country = "";
+
+  /**
+   * @generated from field: string vatId = 8;
+   */
+  vatId = "";
+
+  constructor(data?: PartialMessage<Workspace>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.Workspace";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "id", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "slug", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 4, name: "createdAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 5, name: "updatedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 6, name: "baseCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 7, name: "country", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 8, name: "vatId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): Workspace {
+    return new Workspace().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): Workspace {
+    return new Workspace().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): Workspace {
+    return new Workspace().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: Workspace | PlainMessage<Workspace> | undefined, b: Workspace | PlainMessage<Workspace> | undefined): boolean {
+    return proto3.util.equals(Workspace, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.WorkspaceMember
+ */
+export class WorkspaceMember extends Message<WorkspaceMember> {
+  /**
+   * @generated from field: uint32 workspaceId = 1;
+   */
+  workspaceId = 0;
+
+  /**
+   * @generated from field: proto.User user = 2;
+   */
+  user?: User;
+
+  /**
+   * @generated from field: int64 joinedAt = 3;
+   */
+  joinedAt = protoInt64.zero;
+
+  /**
+   * @generated from field: string role = 4;
+   */
+  role = "";
+
+  constructor(data?: PartialMessage<WorkspaceMember>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.WorkspaceMember";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "workspaceId", kind: "scalar", T: 13 /* ScalarType.UINT32 */ },
+    { no: 2, name: "user", kind: "message", T: User },
+    { no: 3, name: "joinedAt", kind: "scalar", T: 3 /* ScalarType.INT64 */ },
+    { no: 4, name: "role", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): WorkspaceMember {
+    return new WorkspaceMember().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): WorkspaceMember {
+    return new WorkspaceMember().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): WorkspaceMember {
+    return new WorkspaceMember().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: WorkspaceMember | PlainMessage<WorkspaceMember> | undefined, b: WorkspaceMember | PlainMessage<WorkspaceMember> | undefined): boolean {
+    return proto3.util.equals(WorkspaceMember, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetWorkspacesResponse
+ */
+export class GetWorkspacesResponse extends Message<GetWorkspacesResponse> {
+  /**
+   * @generated from field: repeated proto.Workspace workspaces = 1;
+   */
+  workspaces: Workspace[] = [];
+
+  constructor(data?: PartialMessage<GetWorkspacesResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetWorkspacesResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "workspaces", kind: "message", T: Workspace, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetWorkspacesResponse {
+    return new GetWorkspacesResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetWorkspacesResponse {
+    return new GetWorkspacesResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetWorkspacesResponse {
+    return new GetWorkspacesResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetWorkspacesResponse | PlainMessage<GetWorkspacesResponse> | undefined, b: GetWorkspacesResponse | PlainMessage<GetWorkspacesResponse> | undefined): boolean {
+    return proto3.util.equals(GetWorkspacesResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateWorkspaceRequest
+ */
+export class CreateWorkspaceRequest extends Message<CreateWorkspaceRequest> {
+  /**
+   * @generated from field: string slug = 1;
+   */
+  slug = "";
+
+  /**
+   * @generated from field: string name = 2;
+   */
+  name = "";
+
+  /**
+   * @generated from field: string baseCurrency = 3;
+   */
+  baseCurrency = "";
+
+  constructor(data?: PartialMessage<CreateWorkspaceRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateWorkspaceRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "slug", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "name", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 3, name: "baseCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateWorkspaceRequest {
+    return new CreateWorkspaceRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateWorkspaceRequest {
+    return new CreateWorkspaceRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateWorkspaceRequest {
+    return new CreateWorkspaceRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateWorkspaceRequest | PlainMessage<CreateWorkspaceRequest> | undefined, b: CreateWorkspaceRequest | PlainMessage<CreateWorkspaceRequest> | undefined): boolean {
+    return proto3.util.equals(CreateWorkspaceRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SetBaseCurrencyRequest
+ */
+export class SetBaseCurrencyRequest extends Message<SetBaseCurrencyRequest> {
+  /**
+   * @generated from field: string baseCurrency = 1;
+   */
+  baseCurrency = "";
+
+  constructor(data?: PartialMessage<SetBaseCurrencyRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SetBaseCurrencyRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "baseCurrency", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SetBaseCurrencyRequest {
+    return new SetBaseCurrencyRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SetBaseCurrencyRequest {
+    return new SetBaseCurrencyRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SetBaseCurrencyRequest {
+    return new SetBaseCurrencyRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SetBaseCurrencyRequest | PlainMessage<SetBaseCurrencyRequest> | undefined, b: SetBaseCurrencyRequest | PlainMessage<SetBaseCurrencyRequest> | undefined): boolean {
+    return proto3.util.equals(SetBaseCurrencyRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SetTaxDetailsRequest
+ */
+export class SetTaxDetailsRequest extends Message<SetTaxDetailsRequest> {
+  /**
+   * @generated from field: string country = 1;
+   */
+  country = "";
+
+  /**
+   * @generated from field: string vatId = 2;
+   */
+  vatId = "";
+
+  constructor(data?: PartialMessage<SetTaxDetailsRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SetTaxDetailsRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "country", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "vatId", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SetTaxDetailsRequest {
+    return new SetTaxDetailsRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SetTaxDetailsRequest {
+    return new SetTaxDetailsRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SetTaxDetailsRequest {
+    return new SetTaxDetailsRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SetTaxDetailsRequest | PlainMessage<SetTaxDetailsRequest> | undefined, b: SetTaxDetailsRequest | PlainMessage<SetTaxDetailsRequest> | undefined): boolean {
+    return proto3.util.equals(SetTaxDetailsRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.CreateWorkspaceResponse
+ */
+export class CreateWorkspaceResponse extends Message<CreateWorkspaceResponse> {
+  /**
+   * @generated from field: proto.Workspace workspace = 1;
+   */
+  workspace?: Workspace;
+
+  constructor(data?: PartialMessage<CreateWorkspaceResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.CreateWorkspaceResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "workspace", kind: "message", T: Workspace },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): CreateWorkspaceResponse {
+    return new CreateWorkspaceResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): CreateWorkspaceResponse {
+    return new CreateWorkspaceResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): CreateWorkspaceResponse {
+    return new CreateWorkspaceResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: CreateWorkspaceResponse | PlainMessage<CreateWorkspaceResponse> | undefined, b: CreateWorkspaceResponse | PlainMessage<CreateWorkspaceResponse> | undefined): boolean {
+    return proto3.util.equals(CreateWorkspaceResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetCurrentWorkspaceResponse
+ */
+export class GetCurrentWorkspaceResponse extends Message<GetCurrentWorkspaceResponse> {
+  /**
+   * @generated from field: proto.Workspace workspace = 1;
+   */
+  workspace?: Workspace;
+
+  constructor(data?: PartialMessage<GetCurrentWorkspaceResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetCurrentWorkspaceResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "workspace", kind: "message", T: Workspace },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetCurrentWorkspaceResponse {
+    return new GetCurrentWorkspaceResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetCurrentWorkspaceResponse {
+    return new GetCurrentWorkspaceResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetCurrentWorkspaceResponse {
+    return new GetCurrentWorkspaceResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetCurrentWorkspaceResponse | PlainMessage<GetCurrentWorkspaceResponse> | undefined, b: GetCurrentWorkspaceResponse | PlainMessage<GetCurrentWorkspaceResponse> | undefined): boolean {
+    return proto3.util.equals(GetCurrentWorkspaceResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.GetWorkspaceMembersResponse
+ */
+export class GetWorkspaceMembersResponse extends Message<GetWorkspaceMembersResponse> {
+  /**
+   * @generated from field: repeated proto.WorkspaceMember members = 1;
+   */
+  members: WorkspaceMember[] = [];
+
+  constructor(data?: PartialMessage<GetWorkspaceMembersResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.GetWorkspaceMembersResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "members", kind: "message", T: WorkspaceMember, repeated: true },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): GetWorkspaceMembersResponse {
+    return new GetWorkspaceMembersResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): GetWorkspaceMembersResponse {
+    return new GetWorkspaceMembersResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): GetWorkspaceMembersResponse {
+    return new GetWorkspaceMembersResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: GetWorkspaceMembersResponse | PlainMessage<GetWorkspaceMembersResponse> | undefined, b: GetWorkspaceMembersResponse | PlainMessage<GetWorkspaceMembersResponse> | undefined): boolean {
+    return proto3.util.equals(GetWorkspaceMembersResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.AddWorkspaceMemberRequest
+ */
+export class AddWorkspaceMemberRequest extends Message<AddWorkspaceMemberRequest> {
+  /**
+   * @generated from field: string email = 1;
+   */
+  email = "";
+
+  /**
+   * @generated from field: string role = 2;
+   */
+  role = "";
+
+  constructor(data?: PartialMessage<AddWorkspaceMemberRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.AddWorkspaceMemberRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "email", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+    { no: 2, name: "role", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AddWorkspaceMemberRequest {
+    return new AddWorkspaceMemberRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AddWorkspaceMemberRequest {
+    return new AddWorkspaceMemberRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AddWorkspaceMemberRequest {
+    return new AddWorkspaceMemberRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AddWorkspaceMemberRequest | PlainMessage<AddWorkspaceMemberRequest> | undefined, b: AddWorkspaceMemberRequest | PlainMessage<AddWorkspaceMemberRequest> | undefined): boolean {
+    return proto3.util.equals(AddWorkspaceMemberRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.AddWorkspaceMemberResponse
+ */
+export class AddWorkspaceMemberResponse extends Message<AddWorkspaceMemberResponse> {
+  /**
+   * @generated from field: proto.WorkspaceMember member = 1;
+   */
+  member?: WorkspaceMember;
+
+  constructor(data?: PartialMessage<AddWorkspaceMemberResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.AddWorkspaceMemberResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "member", kind: "message", T: WorkspaceMember },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): AddWorkspaceMemberResponse {
+    return new AddWorkspaceMemberResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): AddWorkspaceMemberResponse {
+    return new AddWorkspaceMemberResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): AddWorkspaceMemberResponse {
+    return new AddWorkspaceMemberResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: AddWorkspaceMemberResponse | PlainMessage<AddWorkspaceMemberResponse> | undefined, b: AddWorkspaceMemberResponse | PlainMessage<AddWorkspaceMemberResponse> | undefined): boolean {
+    return proto3.util.equals(AddWorkspaceMemberResponse, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SetMemberRoleRequest
+ */
+export class SetMemberRoleRequest extends Message<SetMemberRoleRequest> {
+  /**
+   * @generated from field: string role = 1;
+   */
+  role = "";
+
+  constructor(data?: PartialMessage<SetMemberRoleRequest>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SetMemberRoleRequest";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "role", kind: "scalar", T: 9 /* ScalarType.STRING */ },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SetMemberRoleRequest {
+    return new SetMemberRoleRequest().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SetMemberRoleRequest {
+    return new SetMemberRoleRequest().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SetMemberRoleRequest {
+    return new SetMemberRoleRequest().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SetMemberRoleRequest | PlainMessage<SetMemberRoleRequest> | undefined, b: SetMemberRoleRequest | PlainMessage<SetMemberRoleRequest> | undefined): boolean {
+    return proto3.util.equals(SetMemberRoleRequest, a, b);
+  }
+}
+
+/**
+ * @generated from message proto.SetMemberRoleResponse
+ */
+export class SetMemberRoleResponse extends Message<SetMemberRoleResponse> {
+  /**
+   * @generated from field: proto.WorkspaceMember member = 1;
+   */
+  member?: WorkspaceMember;
+
+  constructor(data?: PartialMessage<SetMemberRoleResponse>) {
+    super();
+    proto3.util.initPartial(data, this);
+  }
+
+  static readonly runtime: typeof proto3 = proto3;
+  static readonly typeName = "proto.SetMemberRoleResponse";
+  static readonly fields: FieldList = proto3.util.newFieldList(() => [
+    { no: 1, name: "member", kind: "message", T: WorkspaceMember },
+  ]);
+
+  static fromBinary(bytes: Uint8Array, options?: Partial<BinaryReadOptions>): SetMemberRoleResponse {
+    return new SetMemberRoleResponse().fromBinary(bytes, options);
+  }
+
+  static fromJson(jsonValue: JsonValue, options?: Partial<JsonReadOptions>): SetMemberRoleResponse {
+    return new SetMemberRoleResponse().fromJson(jsonValue, options);
+  }
+
+  static fromJsonString(jsonString: string, options?: Partial<JsonReadOptions>): SetMemberRoleResponse {
+    return new SetMemberRoleResponse().fromJsonString(jsonString, options);
+  }
+
+  static equals(a: SetMemberRoleResponse | PlainMessage<SetMemberRoleResponse> | undefined, b: SetMemberRoleResponse | PlainMessage<SetMemberRoleResponse> | undefined): boolean {
+    return proto3.util.equals(SetMemberRoleResponse, a, b);
+  }
+}
+
@@ -7,20 +7,45 @@ import {
 	UpdateTemplateRequest,
 	UpdateTemplateResponse
 } from 'proto/template_pb';
+import type { Problem } from 'proto/error_pb';
+
+type ProblemBody = Partial<PlainMessage<Problem>> & { reason?: string };
+
+// ApiError carries the problem details of a failed request. reason tells
+// rejections apart, e.g. pdf_encrypted or pdf_javascript for uploads.
+export class ApiError extends Error {
+	constructor(
+		message: string,
+		public code?: string,
+		public reason?: string
+	) {
+		super(message);
+	}
+}
+
+// Failed requests are answered with RFC 7807 problem details.
+async function ensureOk(request: Promise<Response>) {
+	const res = await request;
+	if (res.ok) return res;
+	const problem: ProblemBody = await res.json().catch(() => ({}));
+	throw new ApiError(problem.detail || res.statusText, problem.code, problem.reason);
+}
 
 export const api = (customFetch = fetch) => ({
 	getTemplates: async () => {
-		const res = await customFetch(`${env.PUBLIC_API_URL}/templates`, { method: 'GET' });
+		const res = await ensureOk(customFetch(`${env.PUBLIC_API_URL}/templates`, { method: 'GET' }));
 		const json = await res.json();
 		return json;
 		// return new GetTemplatesResponse(json).templates;
 	},
 
 	uploadFile: async (formData: FormData) => {
-		const res = await customFetch(`${env.PUBLIC_API_URL}/templates`, {
-			method: 'POST',
-			body: formData
-		});
+		const res = await ensureOk(
+			customFetch(`${env.PUBLIC_API_URL}/templates`, {
+				method: 'POST',
+				body: formData
+			})
+		);
 		const json = await res.json();
 		return new FileUploadResponse(json).template;
 	},
@@ -29,33 +54,39 @@ export const api = (customFetch = fetch) => ({
 		id,
 		name
 	}: Pick<Template, 'id'> & PlainMessage<UpdateTemplateRequest>) => {
-		const res = await customFetch(`${env.PUBLIC_API_URL}/templates/${id}`, {
-			method: 'PATCH',
-			body: JSON.stringify({ name } satisfies PlainMessage<UpdateTemplateRequest>),
-			headers: { 'Content-Type': 'application/json' }
-		});
+		const res = await ensureOk(
+			customFetch(`${env.PUBLIC_API_URL}/templates/${id}`, {
+				method: 'PATCH',
+				body: JSON.stringify({ name } satisfies PlainMessage<UpdateTemplateRequest>),
+				headers: { 'Content-Type': 'application/json' }
+			})
+		);
 		const json = await res.json();
 		return new UpdateTemplateResponse(json).template;
 	},
 
 	deleteTemplate: async ({ id }: Pick<Template, 'id'>) => {
-		return customFetch(`${env.PUBLIC_API_URL}/templates/${id}`, {
-			method: 'DELETE',
-			headers: { 'Content-Type': 'text/html' }
-		});
+		return ensureOk(
+			customFetch(`${env.PUBLIC_API_URL}/templates/${id}`, {
+				method: 'DELETE',
+				headers: { 'Content-Type': 'text/html' }
+			})
+		);
 	},
 
 	getHtml: async (url: string) => {
-		const res = await customFetch(url, { cache: 'no-cache' });
+		const res = await ensureOk(customFetch(url, { cache: 'no-cache' }));
 		const text = await res.text();
 		return text;
 	},
 
 	updateTemplateHtml: async ({ id, html }: Pick<Template, 'id'> & { html: string }) => {
-		const res = await customFetch(`${env.PUBLIC_API_URL}/templates/${id}/html`, {
-			method: 'PUT',
-			body: html
-		});
+		const res = await ensureOk(
+			customFetch(`${env.PUBLIC_API_URL}/templates/${id}/html`, {
+				method: 'PUT',
+				body: html
+			})
+		);
 		const text = await res.text();
 		return text;
 	}